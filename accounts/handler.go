@@ -4,133 +4,120 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"sort"
 	"strings"
 	"time"
 
 	"go.ngs.io/google-mcp-server/auth"
+	"go.ngs.io/google-mcp-server/logging"
 	"go.ngs.io/google-mcp-server/server"
 )
 
+var logger = logging.Logger("accounts")
+
 // Handler implements account management tools
 type Handler struct {
 	accountManager *auth.AccountManager
+	limiter        *auth.RateLimiter   // reports quota usage; may be nil
+	cache          *auth.ResponseCache // reports cache hits; may be nil
 }
 
 // NewHandler creates a new accounts handler
-func NewHandler(accountManager *auth.AccountManager) *Handler {
+func NewHandler(accountManager *auth.AccountManager, limiter *auth.RateLimiter) *Handler {
 	return &Handler{
 		accountManager: accountManager,
+		limiter:        limiter,
 	}
 }
 
-// GetTools returns the available account management tools
-func (h *Handler) GetTools() []server.Tool {
-	return []server.Tool{
-		{
-			Name:        "accounts_list",
-			Description: "List all authenticated Google accounts",
-			InputSchema: server.InputSchema{
-				Type:       "object",
-				Properties: map[string]server.Property{},
-			},
-		},
-		{
-			Name:        "accounts_details",
-			Description: "Get detailed information about a specific account",
-			InputSchema: server.InputSchema{
-				Type: "object",
+// accountsListOutputSchema describes the result of accounts_list
+var accountsListOutputSchema = &server.OutputSchema{
+	Type: "object",
+	Properties: map[string]server.Property{
+		"accounts": {
+			Type:        "array",
+			Description: "Authenticated accounts, most recently used first",
+			Items: &server.Property{
+				Type:        "object",
+				Description: "Account summary",
 				Properties: map[string]server.Property{
-					"email": {
-						Type:        "string",
-						Description: "Email address of the account (optional, shows all if not specified)",
-					},
+					"email":     {Type: "string", Description: "Email address of the account"},
+					"name":      {Type: "string", Description: "Display name"},
+					"last_used": {Type: "string", Description: "RFC 3339 time the account was last used"},
+					"active":    {Type: "boolean", Description: "Whether the account has a valid token"},
 				},
 			},
 		},
-		{
+		"count": {Type: "integer", Description: "Number of accounts"},
+	},
+	Required: []string{"accounts", "count"},
+}
+
+// Arguments of the account tools. The input schemas are generated from
+// these structs, see server.SchemaOf.
+
+type accountFilterArgs struct {
+	Email string `json:"email" description:"Email address of the account (optional, shows all if not specified)"`
+}
+
+type removeAccountArgs struct {
+	Email string `json:"email" required:"true" description:"Email address of the account to remove"`
+}
+
+type refreshAccountArgs struct {
+	Email string `json:"email" required:"true" description:"Email address of the account to refresh"`
+}
+
+// ToolDefinitions declares the account management tools together with
+// their handlers
+func (h *Handler) ToolDefinitions() server.ToolSet {
+	return server.ToolSet{
+		server.NewTool(server.Tool{
+			Name:         "accounts_list",
+			Description:  "List all authenticated Google accounts",
+			Annotations:  server.ReadOnlyAnnotations().Local(),
+			OutputSchema: accountsListOutputSchema,
+		}, h.handleAccountsList),
+		server.NewTool(server.Tool{
+			Name:        "accounts_details",
+			Description: "Get detailed information about a specific account",
+			Annotations: server.ReadOnlyAnnotations().Local(),
+		}, h.handleAccountsDetails),
+		server.NewTool(server.Tool{
 			Name:        "accounts_add",
 			Description: "Add a new Google account (initiates OAuth flow)",
-			InputSchema: server.InputSchema{
-				Type:       "object",
-				Properties: map[string]server.Property{},
-			},
-		},
-		{
+			Annotations: server.AdditiveAnnotations().Local(),
+		}, h.handleAccountsAdd),
+		server.NewTool(server.Tool{
 			Name:        "accounts_remove",
 			Description: "Remove an authenticated account",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"email": {
-						Type:        "string",
-						Description: "Email address of the account to remove",
-					},
-				},
-				Required: []string{"email"},
-			},
-		},
-		{
+			Annotations: server.DestructiveAnnotations().Local(),
+		}, h.handleAccountsRemove),
+		server.NewTool(server.Tool{
+			Name:        "accounts_quota",
+			Description: "Show the Google API quota units each account has used since the server started, the client-side rate limits, and how many reads the response cache answered",
+			Annotations: server.ReadOnlyAnnotations().Local(),
+		}, h.handleAccountsQuota),
+		server.NewTool(server.Tool{
 			Name:        "accounts_refresh",
 			Description: "Refresh authentication token for an account",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"email": {
-						Type:        "string",
-						Description: "Email address of the account to refresh",
-					},
-				},
-				Required: []string{"email"},
-			},
-		},
+			Annotations: server.IdempotentAnnotations().Local(),
+		}, h.handleAccountsRefresh),
 	}
 }
 
+// GetTools returns the available account management tools
+func (h *Handler) GetTools() []server.Tool {
+	return h.ToolDefinitions().Tools()
+}
+
 // HandleToolCall handles a tool call for account management
 func (h *Handler) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
-	switch name {
-	case "accounts_list":
-		return h.handleAccountsList(ctx)
-
-	case "accounts_details":
-		var args struct {
-			Email string `json:"email"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleAccountsDetails(ctx, args.Email)
-
-	case "accounts_add":
-		return h.handleAccountsAdd(ctx)
-
-	case "accounts_remove":
-		var args struct {
-			Email string `json:"email"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleAccountsRemove(ctx, args.Email)
-
-	case "accounts_refresh":
-		var args struct {
-			Email string `json:"email"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleAccountsRefresh(ctx, args.Email)
-
-	default:
-		return nil, fmt.Errorf("unknown tool: %s", name)
-	}
+	return h.ToolDefinitions().Call(ctx, name, arguments)
 }
 
 // handleAccountsList lists all authenticated accounts
-func (h *Handler) handleAccountsList(ctx context.Context) (interface{}, error) {
+func (h *Handler) handleAccountsList(ctx context.Context, _ struct{}) (interface{}, error) {
 	accounts := h.accountManager.ListAccounts()
 
 	// Sort by last used time
@@ -162,7 +149,8 @@ func (h *Handler) handleAccountsList(ctx context.Context) (interface{}, error) {
 }
 
 // handleAccountsDetails shows detailed information about accounts
-func (h *Handler) handleAccountsDetails(ctx context.Context, email string) (interface{}, error) {
+func (h *Handler) handleAccountsDetails(ctx context.Context, args accountFilterArgs) (interface{}, error) {
+	email := args.Email
 	if email == "" {
 		// Show all accounts with details
 		accounts := h.accountManager.ListAccounts()
@@ -232,7 +220,7 @@ func (h *Handler) handleAccountsDetails(ctx context.Context, email string) (inte
 }
 
 // handleAccountsAdd initiates OAuth flow to add a new account
-func (h *Handler) handleAccountsAdd(ctx context.Context) (interface{}, error) {
+func (h *Handler) handleAccountsAdd(ctx context.Context, _ struct{}) (interface{}, error) {
 	// Get OAuth config from account manager
 	config := h.accountManager.GetOAuthConfig()
 
@@ -244,18 +232,18 @@ func (h *Handler) handleAccountsAdd(ctx context.Context) (interface{}, error) {
 	go func() {
 		token, err := callbackServer.StartAndWaitForCallback(context.Background())
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "OAuth authentication failed: %v\n", err)
+			logger.Error("OAuth authentication failed", "error", err)
 			return
 		}
 
 		// Add the account
 		account, err := h.accountManager.AddAccount(context.Background(), token)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to add account: %v\n", err)
+			logger.Error("failed to add account", "error", err)
 			return
 		}
 
-		fmt.Fprintf(os.Stderr, "Successfully added account: %s\n", account.Email)
+		logger.Info("account added", "account", account.Email)
 	}()
 
 	// Wait a moment for server to start
@@ -280,7 +268,8 @@ func (h *Handler) handleAccountsAdd(ctx context.Context) (interface{}, error) {
 }
 
 // handleAccountsRemove removes an account
-func (h *Handler) handleAccountsRemove(ctx context.Context, email string) (interface{}, error) {
+func (h *Handler) handleAccountsRemove(ctx context.Context, args removeAccountArgs) (interface{}, error) {
+	email := args.Email
 	if err := h.accountManager.RemoveAccount(email); err != nil {
 		return nil, err
 	}
@@ -292,7 +281,8 @@ func (h *Handler) handleAccountsRemove(ctx context.Context, email string) (inter
 }
 
 // handleAccountsRefresh refreshes the token for an account
-func (h *Handler) handleAccountsRefresh(ctx context.Context, email string) (interface{}, error) {
+func (h *Handler) handleAccountsRefresh(ctx context.Context, args refreshAccountArgs) (interface{}, error) {
+	email := args.Email
 	if err := h.accountManager.RefreshToken(ctx, email); err != nil {
 		return nil, err
 	}
@@ -310,9 +300,66 @@ func (h *Handler) handleAccountsRefresh(ctx context.Context, email string) (inte
 	}, nil
 }
 
+// SetCache makes the quota report include the hits and misses of the API
+// response cache, which spares quota
+func (h *Handler) SetCache(cache *auth.ResponseCache) {
+	h.cache = cache
+}
+
+// handleAccountsQuota reports the quota used per account and API, and the
+// reads answered from the cache
+func (h *Handler) handleAccountsQuota(ctx context.Context, args accountFilterArgs) (interface{}, error) {
+	email := args.Email
+	if h.limiter == nil {
+		return nil, fmt.Errorf("quota tracking is not enabled")
+	}
+
+	type APIUsage struct {
+		Account       string  `json:"account"`
+		API           string  `json:"api"`
+		Requests      int64   `json:"requests"`
+		Units         int64   `json:"units"`
+		Throttled     int64   `json:"throttled"`
+		WaitedSeconds float64 `json:"waited_seconds"`
+	}
+
+	usage := []APIUsage{}
+	for _, u := range h.limiter.Usage() {
+		if email != "" && u.Account != email {
+			continue
+		}
+		usage = append(usage, APIUsage{
+			Account:       u.Account,
+			API:           u.API,
+			Requests:      u.Requests,
+			Units:         u.Units,
+			Throttled:     u.Throttled,
+			WaitedSeconds: u.Waited.Seconds(),
+		})
+	}
+
+	report := map[string]interface{}{
+		"since":  h.limiter.Since(),
+		"usage":  usage,
+		"limits": h.limiter.Limits(),
+	}
+	if h.cache != nil {
+		summary := h.cache.Summary()
+		endpoints := []auth.CacheStats{}
+		for _, stats := range summary.Endpoints {
+			if email == "" || stats.Account == email {
+				endpoints = append(endpoints, stats)
+			}
+		}
+		summary.Endpoints = endpoints
+		report["cache"] = summary
+	}
+	return report, nil
+}
+
 // GetResources returns the available resources
 func (h *Handler) GetResources() []server.Resource {
-	return []server.Resource{
+	resources := []server.Resource{
 		{
 			URI:         "accounts://list",
 			Name:        "Authenticated Accounts",
@@ -320,12 +367,49 @@ func (h *Handler) GetResources() []server.Resource {
 			MimeType:    "application/json",
 		},
 	}
+	if h.limiter != nil {
+		resources = append(resources, server.Resource{
+			URI:         "accounts://quota",
+			Name:        "Quota Usage",
+			Description: "Google API quota units used per account and API since the server started",
+			MimeType:    "application/json",
+		})
+	}
+	return resources
 }
 
 // HandleResourceCall handles a resource call
 func (h *Handler) HandleResourceCall(ctx context.Context, uri string) (interface{}, error) {
-	if uri == "accounts://list" {
-		return h.handleAccountsList(ctx)
+	switch uri {
+	case "accounts://list":
+		return h.handleAccountsList(ctx, struct{}{})
+	case "accounts://quota":
+		return h.handleAccountsQuota(ctx, accountFilterArgs{})
 	}
 	return nil, fmt.Errorf("unknown resource: %s", uri)
 }
+
+// CompleteAccounts suggests the email addresses of the authenticated accounts.
+// It is registered as the server-wide completer for "account" arguments.
+func (h *Handler) CompleteAccounts(ctx context.Context, arguments map[string]string) ([]server.CompletionCandidate, error) {
+	accounts := h.accountManager.ListAccounts()
+	candidates := make([]server.CompletionCandidate, 0, len(accounts))
+	for _, account := range accounts {
+		candidates = append(candidates, server.CompletionCandidate{
+			Value: account.Email,
+			Label: account.Name,
+		})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Value < candidates[j].Value
+	})
+	return candidates, nil
+}
+
+// CompleteArgument completes the email argument of the account tools
+func (h *Handler) CompleteArgument(ctx context.Context, ref server.CompletionRef, argument string, arguments map[string]string) ([]server.CompletionCandidate, error) {
+	if argument == "email" {
+		return h.CompleteAccounts(ctx, arguments)
+	}
+	return nil, nil
+}
@@ -0,0 +1,69 @@
+package accounts
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.ngs.io/google-mcp-server/auth"
+	"go.ngs.io/google-mcp-server/config"
+	"go.ngs.io/google-mcp-server/internal/mcptest"
+	"go.ngs.io/google-mcp-server/server"
+)
+
+func TestAccountsRemoveDryRunKeepsTokenFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	tokenFile := filepath.Join(home, ".google-mcp-accounts", "alice_at_example.com.json")
+	if err := os.MkdirAll(filepath.Dir(tokenFile), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(tokenFile, []byte(`{"email":"alice@example.com","token":{"access_token":"token","token_type":"Bearer"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	accountManager, err := auth.NewAccountManager(context.Background(), auth.OAuthConfig{ClientID: "id", ClientSecret: "secret"})
+	if err != nil {
+		t.Fatalf("failed to create account manager: %v", err)
+	}
+	if _, err := accountManager.GetAccount("alice@example.com"); err != nil {
+		t.Fatalf("expected the stored account to be loaded: %v", err)
+	}
+
+	cfg := &config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP}}
+	srv := server.NewMCPServer(cfg)
+	srv.RegisterService("accounts", NewHandler(accountManager, nil))
+	ts := httptest.NewServer(srv.HTTPHandler())
+	defer ts.Close()
+	defer func() { _ = srv.Stop() }()
+	endpoint := ts.URL + "/mcp"
+
+	sessionID := mcptest.Initialize(t, endpoint, server.ProtocolVersion20250618, `{}`)
+
+	remove := func(arguments string) string {
+		t.Helper()
+		text, isError := mcptest.CallTool(t, endpoint, sessionID, `{"name":"accounts_remove","arguments":`+arguments+`}`)
+		if !isError {
+			return ""
+		}
+		return text
+	}
+
+	// Both a requested and a server-wide dry run are refused
+	if text := remove(`{"email":"alice@example.com","dry_run":true}`); !strings.Contains(text, "dry run") {
+		t.Errorf("expected the dry run of accounts_remove to be refused, got %q", text)
+	}
+	cfg.Global.DryRun = true
+	if text := remove(`{"email":"alice@example.com"}`); !strings.Contains(text, "dry run") {
+		t.Errorf("expected accounts_remove to be refused under a server-wide dry run, got %q", text)
+	}
+
+	if _, err := os.Stat(tokenFile); err != nil {
+		t.Errorf("expected the token file to be kept: %v", err)
+	}
+	if _, err := accountManager.GetAccount("alice@example.com"); err != nil {
+		t.Errorf("expected the account to be kept: %v", err)
+	}
+}
@@ -22,6 +22,10 @@ type AccountManager struct {
 	configDir   string
 	oauthConfig *oauth2.Config
 	mu          sync.RWMutex
+
+	// listeners are called after accounts are added, refreshed or removed
+	listeners   []func()
+	listenersMu sync.Mutex
 }
 
 // Account represents a single authenticated Google account
@@ -73,13 +77,13 @@ func NewAccountManager(ctx context.Context, oauthConfig OAuthConfig) (*AccountMa
 	// Load existing accounts
 	if err := am.loadAccounts(ctx); err != nil {
 		// Log error but don't fail - accounts can be added later
-		fmt.Fprintf(os.Stderr, "Warning: failed to load existing accounts: %v\n", err)
+		logger.WarnContext(ctx, "failed to load existing accounts", "error", err)
 	}
 
 	// Check for legacy token and migrate it
 	if len(am.accounts) == 0 {
 		if err := am.migrateLegacyToken(ctx, oauthConfig); err == nil {
-			fmt.Fprintf(os.Stderr, "[INFO] Migrated legacy token to multi-account format\n")
+			logger.InfoContext(ctx, "migrated legacy token to multi-account format")
 		}
 	}
 
@@ -108,23 +112,23 @@ func (am *AccountManager) loadAccounts(ctx context.Context) error {
 		// Check file permissions before reading (token files should be 0600)
 		info, err := os.Stat(tokenFile)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to stat token file %s: %v\n", tokenFile, err)
+			logger.Warn("failed to stat token file", "file", tokenFile, "error", err)
 			continue
 		}
 		if perm := info.Mode().Perm(); perm != 0600 {
-			fmt.Fprintf(os.Stderr, "Warning: token file %s has insecure permissions %o (expected 0600), skipping\n", tokenFile, perm)
+			logger.Warn("skipping token file with insecure permissions (expected 0600)", "file", tokenFile, "mode", fmt.Sprintf("%o", perm))
 			continue
 		}
 
 		data, err := os.ReadFile(tokenFile)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to read token file %s: %v\n", tokenFile, err)
+			logger.Warn("failed to read token file", "file", tokenFile, "error", err)
 			continue
 		}
 
 		var account Account
 		if err := json.Unmarshal(data, &account); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to parse token file %s: %v\n", tokenFile, err)
+			logger.Warn("failed to parse token file", "file", tokenFile, "error", err)
 			continue
 		}
 
@@ -132,17 +136,18 @@ func (am *AccountManager) loadAccounts(ctx context.Context) error {
 
 		// Create OAuth client for this account
 		oauthClient := &OAuthClient{
-			config:     am.oauthConfig,
-			token:      account.Token,
-			tokenFile:  tokenFile,
-			httpClient: am.oauthConfig.Client(ctx, account.Token),
+			config:    am.oauthConfig,
+			token:     account.Token,
+			tokenFile: tokenFile,
+			account:   account.Email,
 		}
+		oauthClient.httpClient = oauthClient.newHTTPClient(ctx, account.Token)
 		account.OAuthClient = oauthClient
 
 		// Get user info if not available
 		if account.Email == "" {
 			if err := am.updateUserInfo(ctx, &account); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to get user info: %v\n", err)
+				logger.WarnContext(ctx, "failed to get user info", "error", err)
 			}
 		}
 
@@ -154,21 +159,55 @@ func (am *AccountManager) loadAccounts(ctx context.Context) error {
 	return nil
 }
 
+// OnAccountsChanged registers fn to be called after an account is added,
+// refreshed or removed, e.g. to offer tools whose scopes were just granted.
+// fn runs on the goroutine that changed the account.
+func (am *AccountManager) OnAccountsChanged(fn func()) {
+	am.listenersMu.Lock()
+	defer am.listenersMu.Unlock()
+	am.listeners = append(am.listeners, fn)
+}
+
+// accountsChanged calls the registered listeners; callers must not hold am.mu
+func (am *AccountManager) accountsChanged() {
+	am.listenersMu.Lock()
+	listeners := append([]func(){}, am.listeners...)
+	am.listenersMu.Unlock()
+
+	for _, fn := range listeners {
+		fn()
+	}
+}
+
 // AddAccount adds a new account or updates existing one
 func (am *AccountManager) AddAccount(ctx context.Context, token *oauth2.Token) (*Account, error) {
+	account, err := am.addAccount(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	am.accountsChanged()
+	return account, nil
+}
+
+func (am *AccountManager) addAccount(ctx context.Context, token *oauth2.Token) (*Account, error) {
 	am.mu.Lock()
 	defer am.mu.Unlock()
 
-	// Create temporary OAuth client to get user info
-	tempClient := am.oauthConfig.Client(ctx, token)
+	// Create the OAuth client of the account; it learns the email address
+	// from the user info it fetches
+	oauthClient := &OAuthClient{
+		config: am.oauthConfig,
+		token:  token,
+	}
+	oauthClient.httpClient = oauthClient.newHTTPClient(ctx, token)
 
 	// Get user info
-	oauth2Service, err := oauth2api.NewService(ctx, option.WithHTTPClient(tempClient))
+	oauth2Service, err := oauth2api.NewService(ctx, option.WithHTTPClient(oauthClient.httpClient))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create oauth2 service: %w", err)
 	}
 
-	userInfo, err := oauth2Service.Userinfo.Get().Do()
+	userInfo, err := oauth2Service.Userinfo.Get().Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user info: %w", err)
 	}
@@ -187,13 +226,9 @@ func (am *AccountManager) AddAccount(ctx context.Context, token *oauth2.Token) (
 	safeEmail = strings.ReplaceAll(safeEmail, ".", "_")
 	account.TokenFile = filepath.Join(am.configDir, fmt.Sprintf("%s.json", safeEmail))
 
-	// Create OAuth client for this account
-	account.OAuthClient = &OAuthClient{
-		config:     am.oauthConfig,
-		token:      token,
-		tokenFile:  account.TokenFile,
-		httpClient: tempClient,
-	}
+	oauthClient.tokenFile = account.TokenFile
+	oauthClient.setAccount(account.Email)
+	account.OAuthClient = oauthClient
 
 	// Save account
 	if err := am.saveAccount(account); err != nil {
@@ -229,7 +264,7 @@ func (am *AccountManager) updateUserInfo(ctx context.Context, account *Account)
 		return fmt.Errorf("failed to create oauth2 service: %w", err)
 	}
 
-	userInfo, err := oauth2Service.Userinfo.Get().Do()
+	userInfo, err := oauth2Service.Userinfo.Get().Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("failed to get user info: %w", err)
 	}
@@ -237,6 +272,7 @@ func (am *AccountManager) updateUserInfo(ctx context.Context, account *Account)
 	account.Email = userInfo.Email
 	account.Name = userInfo.Name
 	account.Picture = userInfo.Picture
+	account.OAuthClient.setAccount(account.Email)
 
 	return am.saveAccount(account)
 }
@@ -254,7 +290,7 @@ func (am *AccountManager) GetAccount(email string) (*Account, error) {
 	// Update last used time (synchronous to avoid race with goroutine after unlock)
 	account.LastUsed = time.Now()
 	if err := am.saveAccount(account); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to update last used time for %s: %v\n", email, err)
+		logger.Warn("failed to update last used time", "account", email, "error", err)
 	}
 
 	return account, nil
@@ -284,7 +320,7 @@ func (am *AccountManager) GetAccountForContext(ctx context.Context, hint string)
 			if strings.Contains(hint, email) {
 				account.LastUsed = time.Now()
 				if err := am.saveAccount(account); err != nil {
-					fmt.Fprintf(os.Stderr, "Warning: failed to update last used time for %s: %v\n", email, err)
+					logger.Warn("failed to update last used time", "account", email, "error", err)
 				}
 				return account, nil
 			}
@@ -302,7 +338,7 @@ func (am *AccountManager) GetAccountForContext(ctx context.Context, hint string)
 			if strings.Contains(hint, domain) {
 				account.LastUsed = time.Now()
 				if err := am.saveAccount(account); err != nil {
-					fmt.Fprintf(os.Stderr, "Warning: failed to update last used time for %s: %v\n", email, err)
+					logger.Warn("failed to update last used time", "account", email, "error", err)
 				}
 				return account, nil
 			}
@@ -314,7 +350,7 @@ func (am *AccountManager) GetAccountForContext(ctx context.Context, hint string)
 		for email, account := range am.accounts {
 			account.LastUsed = time.Now()
 			if err := am.saveAccount(account); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to update last used time for %s: %v\n", email, err)
+				logger.Warn("failed to update last used time", "account", email, "error", err)
 			}
 			return account, nil
 		}
@@ -335,6 +371,14 @@ func (am *AccountManager) GetAccountForContext(ctx context.Context, hint string)
 
 // RemoveAccount removes an account
 func (am *AccountManager) RemoveAccount(email string) error {
+	if err := am.removeAccount(email); err != nil {
+		return err
+	}
+	am.accountsChanged()
+	return nil
+}
+
+func (am *AccountManager) removeAccount(email string) error {
 	am.mu.Lock()
 	defer am.mu.Unlock()
 
@@ -371,7 +415,11 @@ func (am *AccountManager) RefreshToken(ctx context.Context, email string) error
 	account.OAuthClient.token = newToken
 	am.mu.Unlock()
 
-	return am.saveAccount(account)
+	if err := am.saveAccount(account); err != nil {
+		return err
+	}
+	am.accountsChanged()
+	return nil
 }
 
 // GetOAuthConfig returns the OAuth configuration
@@ -435,6 +483,6 @@ func (am *AccountManager) migrateLegacyToken(ctx context.Context, oauthConfig OA
 		return fmt.Errorf("failed to migrate account: %w", err)
 	}
 
-	fmt.Fprintf(os.Stderr, "[INFO] Successfully migrated account: %s\n", account.Email)
+	logger.Info("migrated account", "account", account.Email)
 	return nil
 }
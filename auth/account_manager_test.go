@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAccountsChangedListeners(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "me_at_example_com.json")
+	if err := os.WriteFile(tokenFile, []byte("{}"), 0600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+	am := &AccountManager{accounts: map[string]*Account{
+		"me@example.com": {Email: "me@example.com", TokenFile: tokenFile},
+	}}
+
+	calls := 0
+	am.OnAccountsChanged(func() {
+		// Listeners may read the accounts without deadlocking
+		_ = am.ListAccounts()
+		calls++
+	})
+
+	if err := am.RemoveAccount("missing@example.com"); err == nil {
+		t.Error("expected removing an unknown account to fail")
+	}
+	if calls != 0 {
+		t.Errorf("expected no notification for a failed removal, got %d", calls)
+	}
+
+	if err := am.RemoveAccount("me@example.com"); err != nil {
+		t.Fatalf("RemoveAccount failed: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected one notification after removing an account, got %d", calls)
+	}
+}
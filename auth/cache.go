@@ -0,0 +1,442 @@
+package auth
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTLs are how long the responses of each endpoint are reused,
+// keyed by API and collection as named by apiEndpoint. They cover what
+// agents look up again and again while working: the calendars, task lists,
+// tasks, file metadata and spreadsheets they are working on. Endpoints not
+// listed are not cached.
+var DefaultCacheTTLs = map[string]time.Duration{
+	"calendar.calendarList": 5 * time.Minute,
+	"tasks.lists":           2 * time.Minute,
+	"tasks.tasks":           30 * time.Second,
+	"drive.files":           time.Minute,
+	"sheets.spreadsheets":   time.Minute,
+}
+
+// DefaultCacheMaxEntries is the number of responses kept by default
+const DefaultCacheMaxEntries = 1000
+
+// maxCachedBodySize is the largest response body that is cached
+const maxCachedBodySize = 1 << 20
+
+// CachedResponse is a response kept for reuse
+type CachedResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Expires    time.Time
+}
+
+// CacheStore keeps cached responses. Implementations must be safe for
+// concurrent use; NewLRUStore is the in-memory one.
+type CacheStore interface {
+	// Get returns the response stored under key
+	Get(key string) (CachedResponse, bool)
+
+	// Put stores a response under key and returns the number of other
+	// responses evicted to make room for it
+	Put(key string, response CachedResponse) int
+
+	// DeleteFunc removes the responses whose key matches and returns how
+	// many it removed
+	DeleteFunc(match func(key string) bool) int
+
+	// Len returns the number of responses stored
+	Len() int
+}
+
+// LRUStore is a CacheStore of bounded size that evicts the least recently
+// used response first
+type LRUStore struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	order   *list.List // of *lruEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key      string
+	response CachedResponse
+}
+
+// NewLRUStore creates a store keeping up to maxEntries responses, or
+// DefaultCacheMaxEntries when maxEntries is not positive
+func NewLRUStore(maxEntries int) *LRUStore {
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheMaxEntries
+	}
+	return &LRUStore{maxEntries: maxEntries, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// Get implements CacheStore
+func (s *LRUStore) Get(key string) (CachedResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	element, ok := s.entries[key]
+	if !ok {
+		return CachedResponse{}, false
+	}
+	s.order.MoveToFront(element)
+	return element.Value.(*lruEntry).response, true
+}
+
+// Put implements CacheStore
+func (s *LRUStore) Put(key string, response CachedResponse) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if element, ok := s.entries[key]; ok {
+		element.Value.(*lruEntry).response = response
+		s.order.MoveToFront(element)
+		return 0
+	}
+	s.entries[key] = s.order.PushFront(&lruEntry{key: key, response: response})
+
+	evicted := 0
+	for s.order.Len() > s.maxEntries {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*lruEntry).key)
+		evicted++
+	}
+	return evicted
+}
+
+// DeleteFunc implements CacheStore
+func (s *LRUStore) DeleteFunc(match func(key string) bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	deleted := 0
+	for key, element := range s.entries {
+		if match(key) {
+			s.order.Remove(element)
+			delete(s.entries, key)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// Len implements CacheStore
+func (s *LRUStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// CacheStats counts the lookups of an account's requests to one endpoint
+type CacheStats struct {
+	Account  string `json:"account"`
+	Endpoint string `json:"endpoint"`
+	Hits     int64  `json:"hits"`
+	Misses   int64  `json:"misses"`
+
+	// Invalidated counts the responses dropped because the server
+	// changed what they describe
+	Invalidated int64 `json:"invalidated"`
+}
+
+// CacheSummary is the state of a ResponseCache
+type CacheSummary struct {
+	Entries   int          `json:"entries"`
+	Evictions int64        `json:"evictions"`
+	Endpoints []CacheStats `json:"endpoints"`
+}
+
+// cacheStatsKey identifies the counters of an account and endpoint
+type cacheStatsKey struct {
+	account  string
+	endpoint string
+}
+
+// ResponseCache reuses the responses of Google API reads for the TTL of
+// their endpoint, per account, and drops them when the server writes to
+// what they describe. See CacheTransport.
+type ResponseCache struct {
+	store CacheStore
+	ttls  map[string]time.Duration
+	now   func() time.Time
+
+	mu        sync.Mutex
+	stats     map[cacheStatsKey]*CacheStats
+	evictions int64
+
+	// writes counts the writes of each account to each API, so that a
+	// read that was sent before a write is not cached after it
+	writes map[string]uint64
+}
+
+// NewResponseCache creates a cache keeping responses in store, applying
+// DefaultCacheTTLs with the TTLs of the endpoints named in overrides
+// replaced. A TTL of 0 turns caching off for its endpoint.
+func NewResponseCache(store CacheStore, overrides map[string]time.Duration) *ResponseCache {
+	ttls := make(map[string]time.Duration, len(DefaultCacheTTLs)+len(overrides))
+	for endpoint, ttl := range DefaultCacheTTLs {
+		ttls[endpoint] = ttl
+	}
+	for endpoint, ttl := range overrides {
+		ttls[endpoint] = ttl
+	}
+	return &ResponseCache{
+		store:  store,
+		ttls:   ttls,
+		now:    time.Now,
+		stats:  make(map[cacheStatsKey]*CacheStats),
+		writes: make(map[string]uint64),
+	}
+}
+
+// Summary returns the number of cached responses and the lookups of each
+// account and endpoint, ordered by account and endpoint
+func (c *ResponseCache) Summary() CacheSummary {
+	c.mu.Lock()
+	summary := CacheSummary{Evictions: c.evictions, Endpoints: make([]CacheStats, 0, len(c.stats))}
+	for _, stats := range c.stats {
+		summary.Endpoints = append(summary.Endpoints, *stats)
+	}
+	c.mu.Unlock()
+
+	summary.Entries = c.store.Len()
+	sort.Slice(summary.Endpoints, func(i, j int) bool {
+		a, b := summary.Endpoints[i], summary.Endpoints[j]
+		if a.Account != b.Account {
+			return a.Account < b.Account
+		}
+		return a.Endpoint < b.Endpoint
+	})
+	return summary
+}
+
+// countLocked returns the counters of an account and endpoint. Callers
+// hold c.mu.
+func (c *ResponseCache) countLocked(account, endpoint string) *CacheStats {
+	key := cacheStatsKey{account: account, endpoint: endpoint}
+	stats, ok := c.stats[key]
+	if !ok {
+		stats = &CacheStats{Account: account, Endpoint: endpoint}
+		c.stats[key] = stats
+	}
+	return stats
+}
+
+// cacheKey identifies a response: the account, the API and the request.
+// The fields are separated by newlines, which cannot appear in a URL.
+func cacheKey(account, api string, req *http.Request) string {
+	return account + "\n" + api + "\n" + req.Method + " " + req.URL.String()
+}
+
+// parseCacheKey returns the account, API and URL path of a key
+func parseCacheKey(key string) (account, api, path string) {
+	account, rest, _ := strings.Cut(key, "\n")
+	api, request, _ := strings.Cut(rest, "\n")
+	_, raw, _ := strings.Cut(request, " ")
+	if u, err := url.Parse(raw); err == nil {
+		path = u.Path
+	}
+	return account, api, path
+}
+
+// versionSegment matches the version in an API path, such as v3 or v1beta
+var versionSegment = regexp.MustCompile(`^v\d+`)
+
+// apiEndpoint names the collection a request addresses, such as
+// "drive.files" for /drive/v3/files/abc or "tasks.tasks" for
+// /tasks/v1/lists/xyz/tasks, and returns the path of that collection and
+// the IDs of the resources on the way to it. Paths alternate between
+// collections and IDs after the version.
+func apiEndpoint(api, path string) (endpoint, collection string, ids []string) {
+	// Uploads address the same collections as other requests
+	path = strings.TrimPrefix(path, "/upload/")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	start := 0
+	for i, segment := range segments {
+		if versionSegment.MatchString(segment) {
+			start = i + 1
+			break
+		}
+	}
+	rest := segments[start:]
+	if len(rest) == 0 {
+		return api, path, nil
+	}
+
+	// Custom methods, as in spreadsheets/abc:batchUpdate, act on the
+	// resource before the colon
+	last := len(rest) - 1
+	rest[last], _, _ = strings.Cut(rest[last], ":")
+
+	index := last &^ 1
+	for i := 1; i < len(rest); i += 2 {
+		ids = append(ids, rest[i])
+	}
+	collection = "/" + strings.Join(segments[:start+index+1], "/")
+	return api + "." + rest[index], collection, ids
+}
+
+// TTL returns how long the responses of an endpoint are reused; 0 means
+// they are not cached
+func (c *ResponseCache) TTL(endpoint string) time.Duration {
+	return c.ttls[endpoint]
+}
+
+// invalidate drops the responses of account that a write to path of api
+// may have made stale: those within the collection it wrote to, and those
+// of the resources it wrote under
+func (c *ResponseCache) invalidate(account, api, path string) {
+	c.mu.Lock()
+	c.writes[account+"\n"+api]++
+	c.mu.Unlock()
+
+	_, collection, ids := apiEndpoint(api, path)
+	batch := strings.Contains(path, "/batch/")
+	removed := make(map[string]int64)
+	c.store.DeleteFunc(func(key string) bool {
+		keyAccount, keyAPI, keyPath := parseCacheKey(key)
+		if keyAccount != account || keyAPI != api {
+			return false
+		}
+		stale := batch || keyPath == collection || strings.HasPrefix(keyPath, collection+"/")
+		for _, id := range ids {
+			stale = stale || containsSegment(keyPath, id)
+		}
+		if stale {
+			endpoint, _, _ := apiEndpoint(keyAPI, keyPath)
+			removed[endpoint]++
+		}
+		return stale
+	})
+
+	c.mu.Lock()
+	for endpoint, n := range removed {
+		c.countLocked(account, endpoint).Invalidated += n
+	}
+	c.mu.Unlock()
+}
+
+// containsSegment reports whether one of the segments of path is segment
+func containsSegment(path, segment string) bool {
+	for _, s := range strings.Split(path, "/") {
+		if s == segment {
+			return true
+		}
+	}
+	return false
+}
+
+// CacheTransport is an http.RoundTripper that answers Google API reads
+// from a ResponseCache when it can, and caches the successful responses of
+// the endpoints that have a TTL. Any other request to an API invalidates
+// the cached responses it may affect, whether it succeeds or not. The
+// account is taken from the request context, see AccountFromContext.
+type CacheTransport struct {
+	Base  http.RoundTripper
+	Cache *ResponseCache
+}
+
+// NewCacheTransport wraps base, or http.DefaultTransport when nil
+func NewCacheTransport(base http.RoundTripper, cache *ResponseCache) *CacheTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &CacheTransport{Base: base, Cache: cache}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *CacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	api := APIService(req.URL)
+	if api == "" || api == "oauth2" {
+		return t.Base.RoundTrip(req)
+	}
+	account := AccountFromContext(req.Context())
+
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		resp, err := t.Base.RoundTrip(req)
+		t.Cache.invalidate(account, api, req.URL.Path)
+		return resp, err
+	}
+
+	endpoint, _, _ := apiEndpoint(api, req.URL.Path)
+	ttl := t.Cache.TTL(endpoint)
+	if ttl <= 0 || req.Method != http.MethodGet || req.Header.Get("Range") != "" || req.URL.Query().Get("alt") == "media" {
+		return t.Base.RoundTrip(req)
+	}
+
+	c := t.Cache
+	key := cacheKey(account, api, req)
+	if cached, ok := c.store.Get(key); ok && c.now().Before(cached.Expires) {
+		c.mu.Lock()
+		c.countLocked(account, endpoint).Hits++
+		c.mu.Unlock()
+		return cachedResponse(req, cached), nil
+	}
+
+	c.mu.Lock()
+	c.countLocked(account, endpoint).Misses++
+	writes := c.writes[account+"\n"+api]
+	c.mu.Unlock()
+
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCachedBodySize+1))
+	if err != nil || len(body) > maxCachedBodySize {
+		// Hand the response on as it is, without caching it
+		rest := resp.Body
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), rest), rest}
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	c.mu.Lock()
+	stale := c.writes[account+"\n"+api] != writes
+	c.mu.Unlock()
+	if !stale {
+		evicted := c.store.Put(key, CachedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+			Body:       body,
+			Expires:    c.now().Add(ttl),
+		})
+		if evicted > 0 {
+			c.mu.Lock()
+			c.evictions += int64(evicted)
+			c.mu.Unlock()
+		}
+	}
+	return resp, nil
+}
+
+// cachedResponse builds the response to req from a cached one
+func cachedResponse(req *http.Request, cached CachedResponse) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", cached.StatusCode, http.StatusText(cached.StatusCode)),
+		StatusCode:    cached.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        cached.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(cached.Body)),
+		ContentLength: int64(len(cached.Body)),
+		Request:       req,
+	}
+}
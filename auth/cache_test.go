@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIEndpoint(t *testing.T) {
+	tests := []struct {
+		api, path  string
+		endpoint   string
+		collection string
+		ids        string
+	}{
+		{"calendar", "/calendar/v3/users/me/calendarList", "calendar.calendarList", "/calendar/v3/users/me/calendarList", "me"},
+		{"tasks", "/tasks/v1/users/@me/lists", "tasks.lists", "/tasks/v1/users/@me/lists", "@me"},
+		{"tasks", "/tasks/v1/lists/L1/tasks", "tasks.tasks", "/tasks/v1/lists/L1/tasks", "L1"},
+		{"tasks", "/tasks/v1/lists/L1/tasks/T1", "tasks.tasks", "/tasks/v1/lists/L1/tasks", "L1,T1"},
+		{"drive", "/drive/v3/files/F1", "drive.files", "/drive/v3/files", "F1"},
+		{"drive", "/upload/drive/v3/files", "drive.files", "/drive/v3/files", ""},
+		{"drive", "/drive/v3/files/F1/permissions", "drive.permissions", "/drive/v3/files/F1/permissions", "F1"},
+		{"sheets", "/v4/spreadsheets/S1", "sheets.spreadsheets", "/v4/spreadsheets", "S1"},
+		{"sheets", "/v4/spreadsheets/S1:batchUpdate", "sheets.spreadsheets", "/v4/spreadsheets", "S1"},
+		{"sheets", "/v4/spreadsheets/S1/values:batchGet", "sheets.values", "/v4/spreadsheets/S1/values", "S1"},
+	}
+	for _, tt := range tests {
+		endpoint, collection, ids := apiEndpoint(tt.api, tt.path)
+		if endpoint != tt.endpoint || collection != tt.collection || strings.Join(ids, ",") != tt.ids {
+			t.Errorf("apiEndpoint(%s) = %s, %s, %v; want %s, %s, %s", tt.path, endpoint, collection, ids, tt.endpoint, tt.collection, tt.ids)
+		}
+	}
+}
+
+func TestLRUStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	store := NewLRUStore(2)
+	store.Put("a", CachedResponse{})
+	store.Put("b", CachedResponse{})
+	store.Get("a")
+	if evicted := store.Put("c", CachedResponse{}); evicted != 1 {
+		t.Errorf("expected 1 eviction, got %d", evicted)
+	}
+	if _, ok := store.Get("b"); ok {
+		t.Error("expected the least recently used entry to be evicted")
+	}
+	if _, ok := store.Get("a"); !ok || store.Len() != 2 {
+		t.Errorf("expected a and c to remain, got %d entries", store.Len())
+	}
+}
+
+// cachingClient sends requests through a CacheTransport to a fake API that
+// counts them by path
+func cachingClient(cache *ResponseCache) (*http.Client, map[string]int) {
+	sent := make(map[string]int)
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sent[req.Method+" "+req.URL.Path]++
+		body := fmt.Sprintf(`{"path":%q,"n":%d}`, req.URL.Path, sent[req.Method+" "+req.URL.Path])
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+	})
+	return &http.Client{Transport: NewCacheTransport(base, cache)}, sent
+}
+
+func TestCacheTransport(t *testing.T) {
+	cache := NewResponseCache(NewLRUStore(0), map[string]time.Duration{"drive.files": 0})
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+	client, sent := cachingClient(cache)
+
+	alice := WithAccount(context.Background(), "alice@example.com")
+	bob := WithAccount(context.Background(), "bob@example.com")
+	get := func(ctx context.Context, url string) string {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	lists := "https://tasks.googleapis.com/tasks/v1/users/@me/lists"
+	first := get(alice, lists)
+	if again := get(alice, lists); again != first || sent["GET /tasks/v1/users/@me/lists"] != 1 {
+		t.Errorf("expected the second read to be answered from the cache, got %s after %d requests", again, sent["GET /tasks/v1/users/@me/lists"])
+	}
+
+	// Accounts do not share responses
+	get(bob, lists)
+	if sent["GET /tasks/v1/users/@me/lists"] != 2 {
+		t.Error("expected another account's read to be sent")
+	}
+
+	// Responses expire with their TTL
+	now = now.Add(DefaultCacheTTLs["tasks.lists"] + time.Second)
+	get(alice, lists)
+	if sent["GET /tasks/v1/users/@me/lists"] != 3 {
+		t.Error("expected an expired response to be read again")
+	}
+
+	// Endpoints with a TTL of 0 and endpoints without one are not cached
+	for i := 0; i < 2; i++ {
+		get(alice, "https://www.googleapis.com/drive/v3/files/F1")
+		get(alice, "https://gmail.googleapis.com/gmail/v1/users/me/labels")
+	}
+	if sent["GET /drive/v3/files/F1"] != 2 || sent["GET /gmail/v1/users/me/labels"] != 2 {
+		t.Errorf("expected uncached endpoints to be read every time, got %v", sent)
+	}
+
+	// A write drops the responses it may have made stale
+	tasksL1 := "https://tasks.googleapis.com/tasks/v1/lists/L1/tasks"
+	tasksL2 := "https://tasks.googleapis.com/tasks/v1/lists/L2/tasks"
+	get(alice, tasksL1)
+	get(alice, tasksL2)
+	get(bob, tasksL1)
+	req, _ := http.NewRequestWithContext(alice, http.MethodPost, tasksL1, strings.NewReader(`{"title":"x"}`))
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	get(alice, tasksL1)
+	get(alice, tasksL2)
+	get(bob, tasksL1)
+	if sent["GET /tasks/v1/lists/L1/tasks"] != 3 || sent["GET /tasks/v1/lists/L2/tasks"] != 1 {
+		t.Errorf("expected only alice's tasks of L1 to be read again, got %v", sent)
+	}
+
+	summary := cache.Summary()
+	var aliceTasks, aliceLists CacheStats
+	for _, stats := range summary.Endpoints {
+		if stats.Account == "alice@example.com" {
+			switch stats.Endpoint {
+			case "tasks.tasks":
+				aliceTasks = stats
+			case "tasks.lists":
+				aliceLists = stats
+			}
+		}
+	}
+	if aliceLists.Hits != 1 || aliceLists.Misses != 2 {
+		t.Errorf("unexpected task list stats %+v", aliceLists)
+	}
+	if aliceTasks.Hits != 1 || aliceTasks.Misses != 3 || aliceTasks.Invalidated != 1 {
+		t.Errorf("unexpected task stats %+v", aliceTasks)
+	}
+	if summary.Entries != 5 {
+		t.Errorf("expected 5 cached responses, got %d", summary.Entries)
+	}
+}
@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ErrDryRun is returned in place of the response to a request that a dry
+// run recorded instead of sending
+var ErrDryRun = errors.New("dry run: the request was recorded and not sent")
+
+// maxPlannedBodySize bounds the request body a dry run reads and records
+const maxPlannedBodySize = 1 << 20
+
+// PlannedRequest is a Google API request that a dry run did not send
+type PlannedRequest struct {
+	API     string          `json:"api"`
+	Account string          `json:"account"`
+	Method  string          `json:"method"`
+	URL     string          `json:"url"`
+	Body    json.RawMessage `json:"body,omitempty"`
+
+	// Media describes uploaded content, which is not recorded
+	Media *PlannedMedia `json:"media,omitempty"`
+}
+
+// PlannedMedia is the content a planned request would upload
+type PlannedMedia struct {
+	ContentType string `json:"content_type,omitempty"`
+	Size        int64  `json:"size"`
+}
+
+// DryRun records the requests that change data in place of sending them.
+// Requests that only read are sent, so that a tool can still look up what
+// it needs to build its writes.
+type DryRun struct {
+	mu       sync.Mutex
+	requests []PlannedRequest
+}
+
+type dryRunKey struct{}
+
+// WithDryRun returns a context whose API requests that change data are
+// recorded in the returned DryRun and fail with ErrDryRun, see
+// DryRunTransport
+func WithDryRun(ctx context.Context) (context.Context, *DryRun) {
+	d := &DryRun{}
+	return context.WithValue(ctx, dryRunKey{}, d), d
+}
+
+// Requests returns the requests recorded so far, in the order they were
+// made
+func (d *DryRun) Requests() []PlannedRequest {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]PlannedRequest(nil), d.requests...)
+}
+
+func (d *DryRun) record(planned PlannedRequest) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.requests = append(d.requests, planned)
+}
+
+// DryRunTransport is an http.RoundTripper that holds back the Google API
+// requests that change data when their context comes from WithDryRun.
+// Reads, token refreshes and requests outside a dry run pass through.
+type DryRunTransport struct {
+	Base http.RoundTripper
+}
+
+// NewDryRunTransport wraps base, or http.DefaultTransport when nil
+func NewDryRunTransport(base http.RoundTripper) *DryRunTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &DryRunTransport{Base: base}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *DryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	d, _ := req.Context().Value(dryRunKey{}).(*DryRun)
+	api := APIService(req.URL)
+	if d == nil || api == "" || api == "oauth2" || !changesData(req.Method) {
+		return t.Base.RoundTrip(req)
+	}
+
+	planned := PlannedRequest{
+		API:     api,
+		Account: AccountFromContext(req.Context()),
+		Method:  req.Method,
+		URL:     req.URL.String(),
+	}
+	if req.Body != nil {
+		planned.Body, planned.Media = plannedBody(req)
+		_ = req.Body.Close()
+	}
+	d.record(planned)
+	logger.InfoContext(req.Context(), "dry run: request not sent", "method", req.Method, "api", api, "path", req.URL.Path)
+	return nil, ErrDryRun
+}
+
+// changesData reports whether a request with method may change data
+func changesData(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// plannedBody reads the body of a request: JSON is kept as it is, the
+// metadata part of a multipart upload too, and any other content is only
+// described
+func plannedBody(req *http.Request) (json.RawMessage, *PlannedMedia) {
+	data, err := io.ReadAll(io.LimitReader(req.Body, maxPlannedBodySize))
+	if err != nil || len(data) == 0 {
+		return nil, nil
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch {
+	case json.Valid(data):
+		return json.RawMessage(data), nil
+	case strings.HasPrefix(mediaType, "multipart/"):
+		var body json.RawMessage
+		media := &PlannedMedia{}
+		parts := multipart.NewReader(bytes.NewReader(data), params["boundary"])
+		for {
+			part, err := parts.NextPart()
+			if err != nil {
+				break
+			}
+			content, _ := io.ReadAll(part)
+			if body == nil && json.Valid(content) {
+				body = content
+				continue
+			}
+			media.ContentType = part.Header.Get("Content-Type")
+			media.Size += int64(len(content))
+		}
+		if media.Size == 0 {
+			media = nil
+		}
+		return body, media
+	default:
+		size := req.ContentLength
+		if size <= 0 {
+			size = int64(len(data))
+		}
+		return nil, &PlannedMedia{ContentType: mediaType, Size: size}
+	}
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDryRunTransportHoldsBackWrites(t *testing.T) {
+	var sent atomic.Int32
+	client := &http.Client{Transport: NewDryRunTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sent.Add(1)
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	}))}
+
+	ctx, plan := WithDryRun(WithAccount(context.Background(), "alice@example.com"))
+	do := func(ctx context.Context, method, url, contentType string, body []byte) error {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		resp, err := client.Do(req)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		return err
+	}
+
+	// Reads and token refreshes are sent
+	if err := do(ctx, http.MethodGet, "https://www.googleapis.com/calendar/v3/calendars/primary/events/abc", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := do(ctx, http.MethodPost, "https://oauth2.googleapis.com/token", "application/x-www-form-urlencoded", []byte("grant_type=refresh_token")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Writes are recorded instead
+	event := `{"summary":"Standup","start":{"dateTime":"2024-05-01T09:00:00Z"}}`
+	err := do(ctx, http.MethodPost, "https://www.googleapis.com/calendar/v3/calendars/primary/events?alt=json", "application/json", []byte(event))
+	if !errors.Is(err, ErrDryRun) {
+		t.Fatalf("expected the write to fail with ErrDryRun, got %v", err)
+	}
+
+	var upload bytes.Buffer
+	writer := multipart.NewWriter(&upload)
+	part, _ := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/json"}})
+	_, _ = part.Write([]byte(`{"name":"report.txt"}`))
+	part, _ = writer.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain"}})
+	_, _ = part.Write([]byte("hello"))
+	_ = writer.Close()
+	err = do(ctx, http.MethodPost, "https://www.googleapis.com/upload/drive/v3/files?uploadType=multipart", "multipart/related; boundary="+writer.Boundary(), upload.Bytes())
+	if !errors.Is(err, ErrDryRun) {
+		t.Fatalf("expected the upload to fail with ErrDryRun, got %v", err)
+	}
+
+	if sent.Load() != 2 {
+		t.Errorf("expected only the read and the token refresh to be sent, got %d requests", sent.Load())
+	}
+
+	requests := plan.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 planned requests, got %+v", requests)
+	}
+	if r := requests[0]; r.API != "calendar" || r.Account != "alice@example.com" || r.Method != http.MethodPost || string(r.Body) != event {
+		t.Errorf("unexpected planned event %+v", r)
+	}
+	if r := requests[1]; r.API != "drive" || string(r.Body) != `{"name":"report.txt"}` || r.Media == nil || r.Media.ContentType != "text/plain" || r.Media.Size != 5 {
+		t.Errorf("unexpected planned upload %+v", r)
+	}
+
+	// Outside a dry run, writes are sent
+	if err := do(context.Background(), http.MethodDelete, "https://tasks.googleapis.com/tasks/v1/users/@me/lists/abc", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if sent.Load() != 3 {
+		t.Errorf("expected the write outside the dry run to be sent")
+	}
+}
@@ -0,0 +1,26 @@
+package auth
+
+import "sync"
+
+// MaxParallelAccounts is the number of accounts a cross-account operation
+// calls at the same time
+const MaxParallelAccounts = 4
+
+// ForEachAccount calls fn with the client of every account, for at most
+// MaxParallelAccounts accounts at once, and returns when all calls have.
+// Calls for different accounts run concurrently, so fn must guard what
+// it shares.
+func ForEachAccount[C any](clients map[string]C, fn func(email string, client C)) {
+	slots := make(chan struct{}, MaxParallelAccounts)
+	var wg sync.WaitGroup
+	for email, client := range clients {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			fn(email, client)
+		}()
+	}
+	wg.Wait()
+}
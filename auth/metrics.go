@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"net/http"
+	"strconv"
+
+	"go.ngs.io/google-mcp-server/metrics"
+)
+
+var (
+	apiRequests = metrics.NewCounterVec("google_mcp_api_requests_total",
+		`Requests sent to Google APIs, by service and HTTP status code. The code is "error" when no response was received.`,
+		"service", "code")
+
+	tokenRefreshes = metrics.NewCounterVec("google_mcp_token_refreshes_total",
+		"Scheduled OAuth token refreshes, by outcome: success, failure or save_failure when the new token could not be stored.",
+		"outcome")
+)
+
+// MetricsTransport is an http.RoundTripper that counts the Google API
+// requests it sends by service and status code. Placed under the retry and
+// rate limit layers it counts every attempt, and none of the responses
+// served from the cache.
+type MetricsTransport struct {
+	Base http.RoundTripper
+}
+
+// NewMetricsTransport wraps base, or http.DefaultTransport when nil
+func NewMetricsTransport(base http.RoundTripper) *MetricsTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &MetricsTransport{Base: base}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *MetricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	api := APIService(req.URL)
+	resp, err := t.Base.RoundTrip(req)
+	if api != "" {
+		code := "error"
+		if err == nil {
+			code = strconv.Itoa(resp.StatusCode)
+		}
+		apiRequests.Inc(api, code)
+	}
+	return resp, err
+}
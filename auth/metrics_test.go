@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestMetricsTransportCountsAPIRequests(t *testing.T) {
+	client := &http.Client{Transport: NewMetricsTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.Contains(req.URL.Path, "broken") {
+			return nil, errors.New("connection reset")
+		}
+		return &http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody, Request: req}, nil
+	}))}
+	notFound := apiRequests.Value("tasks", "404")
+	failed := apiRequests.Value("tasks", "error")
+
+	for _, url := range []string{
+		"https://tasks.googleapis.com/tasks/v1/lists/L1",
+		"https://tasks.googleapis.com/tasks/v1/broken",
+		"https://example.com/tasks",
+	} {
+		if resp, err := client.Get(url); err == nil {
+			_ = resp.Body.Close()
+		}
+	}
+
+	if got := apiRequests.Value("tasks", "404") - notFound; got != 1 {
+		t.Errorf("expected 1 request answered with 404, got %v", got)
+	}
+	if got := apiRequests.Value("tasks", "error") - failed; got != 1 {
+		t.Errorf("expected 1 request without a response, got %v", got)
+	}
+}
+
+func TestRefreshTokenCountsOutcomes(t *testing.T) {
+	fail := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"access_token":"new","token_type":"Bearer","expires_in":3600}`)
+	}))
+	defer ts.Close()
+
+	c := &OAuthClient{
+		config:    &oauth2.Config{ClientID: "id", Endpoint: oauth2.Endpoint{TokenURL: ts.URL}},
+		token:     &oauth2.Token{AccessToken: "old", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Minute)},
+		tokenFile: filepath.Join(t.TempDir(), "token.json"),
+	}
+	defer func() {
+		if c.refreshTimer != nil {
+			c.refreshTimer.Stop()
+		}
+	}()
+	succeeded := tokenRefreshes.Value("success")
+	failed := tokenRefreshes.Value("failure")
+
+	c.refreshToken(context.Background())
+	fail = true
+	c.token.Expiry = time.Now().Add(-time.Minute)
+	c.refreshToken(context.Background())
+
+	if got := tokenRefreshes.Value("success") - succeeded; got != 1 {
+		t.Errorf("expected 1 successful refresh, got %v", got)
+	}
+	if got := tokenRefreshes.Value("failure") - failed; got != 1 {
+		t.Errorf("expected 1 failed refresh, got %v", got)
+	}
+}
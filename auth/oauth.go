@@ -14,11 +14,14 @@ import (
 	"time"
 
 	"github.com/pkg/browser"
+	"go.ngs.io/google-mcp-server/logging"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/option"
 )
 
+var logger = logging.Logger("auth")
+
 // tokenRefreshBuffer is how long before expiry to trigger a token refresh
 const tokenRefreshBuffer = 5 * time.Minute
 
@@ -31,6 +34,10 @@ type OAuthClient struct {
 	mu           sync.RWMutex
 	refreshTimer *time.Timer
 	oauthState   string
+
+	// account is the email address of the account the client acts for,
+	// empty for the default client
+	account string
 }
 
 // generateOAuthState generates a cryptographically random state string for CSRF protection
@@ -92,7 +99,7 @@ func NewOAuthClient(ctx context.Context, config OAuthConfig) (*OAuthClient, erro
 	// Try to load existing token
 	if err := client.loadToken(); err == nil && client.token != nil {
 		// Token loaded successfully, create HTTP client
-		client.httpClient = oauthConfig.Client(ctx, client.token)
+		client.httpClient = client.newHTTPClient(ctx, client.token)
 		client.startTokenRefresh(ctx)
 		return client, nil
 	}
@@ -121,6 +128,23 @@ func DefaultScopes() []string {
 	}
 }
 
+// accountName returns the account requests of the client are attributed to
+func (c *OAuthClient) accountName() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.account == "" {
+		return defaultAccount
+	}
+	return c.account
+}
+
+// setAccount sets the account the client acts for
+func (c *OAuthClient) setAccount(email string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.account = email
+}
+
 // GetHTTPClient returns the authenticated HTTP client
 func (c *OAuthClient) GetHTTPClient() *http.Client {
 	c.mu.RLock()
@@ -145,12 +169,13 @@ func (c *OAuthClient) authenticate(ctx context.Context) error {
 	// Generate authorization URL with random state
 	authURL := c.config.AuthCodeURL(c.oauthState, oauth2.AccessTypeOffline)
 
-	fmt.Printf("Opening browser for authentication...\n")
-	fmt.Printf("If browser doesn't open, visit this URL:\n%s\n", authURL)
+	// Instructions for the user go to stderr; stdout carries the protocol stream
+	fmt.Fprintf(os.Stderr, "Opening browser for authentication...\n")
+	fmt.Fprintf(os.Stderr, "If browser doesn't open, visit this URL:\n%s\n", authURL)
 
 	// Open browser
 	if err := browser.OpenURL(authURL); err != nil {
-		fmt.Printf("Failed to open browser: %v\n", err)
+		logger.Warn("failed to open browser", "error", err)
 	}
 
 	// Start local server to handle callback
@@ -196,7 +221,7 @@ func (c *OAuthClient) authenticate(ctx context.Context) error {
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 		if err := server.Shutdown(shutdownCtx); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to shutdown callback server: %v\n", err)
+			logger.Warn("failed to shut down callback server", "error", err)
 		}
 	}()
 
@@ -219,15 +244,15 @@ func (c *OAuthClient) authenticate(ctx context.Context) error {
 
 	c.mu.Lock()
 	c.token = token
-	c.httpClient = c.config.Client(ctx, token)
+	c.httpClient = c.newHTTPClient(ctx, token)
 	c.mu.Unlock()
 
 	// Save token for future use
 	if err := c.saveToken(); err != nil {
-		fmt.Printf("Warning: failed to save token: %v\n", err)
+		logger.Warn("failed to save token", "error", err)
 	}
 
-	fmt.Println("Authentication successful!")
+	fmt.Fprintln(os.Stderr, "Authentication successful!")
 	return nil
 }
 
@@ -248,7 +273,7 @@ func (c *OAuthClient) loadToken() error {
 	}
 	defer func() {
 		if err := file.Close(); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to close token file: %v\n", err)
+			logger.Warn("failed to close token file", "error", err)
 		}
 	}()
 
@@ -345,7 +370,8 @@ func (c *OAuthClient) refreshToken(ctx context.Context) {
 	tokenSource := c.config.TokenSource(ctx, currentToken)
 	newToken, err := tokenSource.Token()
 	if err != nil {
-		fmt.Printf("Warning: failed to refresh token: %v\n", err)
+		tokenRefreshes.Inc("failure")
+		logger.Warn("failed to refresh token", "error", err)
 		return
 	}
 
@@ -355,7 +381,10 @@ func (c *OAuthClient) refreshToken(ctx context.Context) {
 
 	// Save the new token
 	if err := c.saveToken(); err != nil {
-		fmt.Printf("Warning: failed to save refreshed token: %v\n", err)
+		tokenRefreshes.Inc("save_failure")
+		logger.Warn("failed to save refreshed token", "error", err)
+	} else {
+		tokenRefreshes.Inc("success")
 	}
 
 	c.mu.Lock()
@@ -399,13 +428,13 @@ func (c *OAuthClient) Revoke(ctx context.Context) error {
 
 	// Revoke access token via POST body (not URL query to avoid proxy/log leaks)
 	if err := revokeToken(ctx, httpClient, c.token.AccessToken); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to revoke access token: %v\n", err)
+		logger.WarnContext(ctx, "failed to revoke access token", "error", err)
 	}
 
 	// Also revoke refresh token to prevent continued access
 	if c.token.RefreshToken != "" {
 		if err := revokeToken(ctx, httpClient, c.token.RefreshToken); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to revoke refresh token: %v\n", err)
+			logger.WarnContext(ctx, "failed to revoke refresh token", "error", err)
 		}
 	}
 
@@ -435,7 +464,7 @@ func revokeToken(ctx context.Context, client *http.Client, token string) error {
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to close response body: %v\n", err)
+			logger.Warn("failed to close response body", "error", err)
 		}
 	}()
 
@@ -0,0 +1,306 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit is the client-side budget of one Google API for each account,
+// in quota units. Most APIs charge one unit per request; Gmail charges
+// between 1 and 100 depending on the method.
+type RateLimit struct {
+	// Rate is the number of units each account may spend per second;
+	// 0 turns the limit off
+	Rate float64 `json:"rate"`
+
+	// Burst is the number of units that may be spent at once after a
+	// quiet period; it defaults to one second's worth
+	Burst int `json:"burst,omitempty"`
+}
+
+// DefaultRateLimits stay within the per-user quotas Google documents for
+// each API, so that a busy session is slowed down before it is refused
+var DefaultRateLimits = map[string]RateLimit{
+	"calendar": {Rate: 10, Burst: 20},   // 600 requests per minute
+	"drive":    {Rate: 200, Burst: 200}, // 12,000 requests per minute
+	"gmail":    {Rate: 250, Burst: 250}, // 250 units per second
+	"sheets":   {Rate: 1, Burst: 10},    // 60 requests per minute
+	"docs":     {Rate: 1, Burst: 10},    // 60 requests per minute
+	"slides":   {Rate: 1, Burst: 10},    // 60 requests per minute
+	"tasks":    {Rate: 5, Burst: 10},
+}
+
+// burst returns the bucket size of the limit
+func (l RateLimit) burst() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return math.Max(1, math.Ceil(l.Rate))
+}
+
+// Validate reports a limit that cannot be applied
+func (l RateLimit) Validate() error {
+	if l.Rate < 0 {
+		return fmt.Errorf("rate must not be negative, got %g", l.Rate)
+	}
+	if l.Burst < 0 {
+		return fmt.Errorf("burst must not be negative, got %d", l.Burst)
+	}
+	return nil
+}
+
+// rateKey identifies the bucket and the usage counters of an account's
+// calls to one API
+type rateKey struct {
+	account string
+	api     string
+}
+
+// tokenBucket refills at rate units per second up to burst. Spending more
+// than is available leaves it in debt, which later requests wait out.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// reserve takes units from the bucket and returns how long the caller must
+// wait before they are considered spent
+func (b *tokenBucket) reserve(units float64, now time.Time) time.Duration {
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens -= units
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// QuotaUsage is the quota an account has spent on one API since startup
+type QuotaUsage struct {
+	Account  string `json:"account"`
+	API      string `json:"api"`
+	Requests int64  `json:"requests"`
+	Units    int64  `json:"units"`
+
+	// Throttled counts the requests the limiter held back, and Waited is
+	// how long they waited in total
+	Throttled int64         `json:"throttled"`
+	Waited    time.Duration `json:"-"`
+}
+
+// RateLimiter keeps a token bucket for each account and API, and counts
+// the quota units they spend
+type RateLimiter struct {
+	limits map[string]RateLimit
+	start  time.Time
+	now    func() time.Time
+
+	mu      sync.Mutex
+	buckets map[rateKey]*tokenBucket
+	usage   map[rateKey]*QuotaUsage
+}
+
+// NewRateLimiter creates a limiter applying DefaultRateLimits, with the
+// limits of the APIs named in overrides replaced
+func NewRateLimiter(overrides map[string]RateLimit) *RateLimiter {
+	limits := make(map[string]RateLimit, len(DefaultRateLimits)+len(overrides))
+	for api, limit := range DefaultRateLimits {
+		limits[api] = limit
+	}
+	for api, limit := range overrides {
+		limits[api] = limit
+	}
+	return &RateLimiter{
+		limits:  limits,
+		start:   time.Now(),
+		now:     time.Now,
+		buckets: make(map[rateKey]*tokenBucket),
+		usage:   make(map[rateKey]*QuotaUsage),
+	}
+}
+
+// Limits returns the limit of each API
+func (l *RateLimiter) Limits() map[string]RateLimit {
+	limits := make(map[string]RateLimit, len(l.limits))
+	for api, limit := range l.limits {
+		limit.Burst = int(limit.burst())
+		limits[api] = limit
+	}
+	return limits
+}
+
+// Since returns when the limiter started counting
+func (l *RateLimiter) Since() time.Time {
+	return l.start
+}
+
+// Wait blocks until account may spend units on api, or until ctx is done.
+// Units are counted as spent once Wait returns nil, whatever the outcome
+// of the request, as Google charges for failed requests too.
+func (l *RateLimiter) Wait(ctx context.Context, account, api string, units int) error {
+	key := rateKey{account: account, api: api}
+	delay := l.reserve(key, units)
+	if delay <= 0 {
+		return nil
+	}
+
+	logger.DebugContext(ctx, "waiting for rate limit", "account", account, "api", api, "delay", delay)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.refund(key, units)
+		return ctx.Err()
+	}
+}
+
+// reserve records the spending of units and returns how long to wait
+func (l *RateLimiter) reserve(key rateKey, units int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	usage, ok := l.usage[key]
+	if !ok {
+		usage = &QuotaUsage{Account: key.account, API: key.api}
+		l.usage[key] = usage
+	}
+	usage.Requests++
+	usage.Units += int64(units)
+
+	limit, ok := l.limits[key.api]
+	if !ok || limit.Rate <= 0 {
+		return 0
+	}
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{rate: limit.Rate, burst: limit.burst(), tokens: limit.burst(), last: l.now()}
+		l.buckets[key] = bucket
+	}
+	delay := bucket.reserve(float64(units), l.now())
+	if delay > 0 {
+		usage.Throttled++
+		usage.Waited += delay
+	}
+	return delay
+}
+
+// refund gives back the units of a request that was abandoned while it
+// waited, and so never reached Google
+func (l *RateLimiter) refund(key rateKey, units int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if bucket, ok := l.buckets[key]; ok {
+		bucket.tokens = math.Min(bucket.burst, bucket.tokens+float64(units))
+	}
+	usage := l.usage[key]
+	usage.Requests--
+	usage.Units -= int64(units)
+}
+
+// Usage returns the quota spent by each account on each API, ordered by
+// account and API
+func (l *RateLimiter) Usage() []QuotaUsage {
+	l.mu.Lock()
+	usage := make([]QuotaUsage, 0, len(l.usage))
+	for _, u := range l.usage {
+		usage = append(usage, *u)
+	}
+	l.mu.Unlock()
+
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Account != usage[j].Account {
+			return usage[i].Account < usage[j].Account
+		}
+		return usage[i].API < usage[j].API
+	})
+	return usage
+}
+
+// RateLimitTransport is an http.RoundTripper that holds each Google API
+// request back until its account has the quota for it. The account is
+// taken from the request context, see AccountFromContext. Requests to
+// hosts that are not Google APIs pass through.
+type RateLimitTransport struct {
+	Base    http.RoundTripper
+	Limiter *RateLimiter
+}
+
+// NewRateLimitTransport wraps base, or http.DefaultTransport when nil
+func NewRateLimitTransport(base http.RoundTripper, limiter *RateLimiter) *RateLimitTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &RateLimitTransport{Base: base, Limiter: limiter}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if api := APIService(req.URL); api != "" {
+		account := AccountFromContext(req.Context())
+		if err := t.Limiter.Wait(req.Context(), account, api, quotaCost(api, req)); err != nil {
+			if req.Body != nil {
+				_ = req.Body.Close()
+			}
+			return nil, err
+		}
+	}
+	return t.Base.RoundTrip(req)
+}
+
+// gmailCosts are the quota units of the Gmail methods, matched against the
+// end of the request path; the first match wins
+var gmailCosts = []struct {
+	method string
+	suffix string
+	units  int
+}{
+	{http.MethodPost, "/messages/send", 100},
+	{http.MethodPost, "/drafts/send", 100},
+	{http.MethodPost, "/messages/batchModify", 50},
+	{http.MethodPost, "/messages/batchDelete", 50},
+	{http.MethodPost, "/messages/import", 25},
+	{http.MethodPost, "/messages", 25}, // insert
+	{http.MethodPost, "/drafts", 10},
+	{http.MethodDelete, "/threads", 20},
+	{http.MethodDelete, "", 10},
+	{"", "/threads", 10},
+	{"", "/profile", 1},
+	{"", "/history", 2},
+	{"", "/watch", 100},
+}
+
+// gmailDefaultCost is charged for the Gmail methods not in gmailCosts, such
+// as messages.list, messages.get, messages.modify and messages.trash
+const gmailDefaultCost = 5
+
+// quotaCost returns the quota units a request is charged
+func quotaCost(api string, req *http.Request) int {
+	if api != "gmail" {
+		return 1
+	}
+
+	path := req.URL.Path
+	// threads.get and threads.modify cost as much as threads.list
+	if i := strings.Index(path, "/threads/"); i >= 0 {
+		path = path[:i] + "/threads"
+	}
+	if strings.Contains(path, "/labels") || strings.Contains(path, "/settings") {
+		return 1
+	}
+	for _, cost := range gmailCosts {
+		if (cost.method == "" || cost.method == req.Method) && strings.HasSuffix(path, cost.suffix) {
+			return cost.units
+		}
+	}
+	return gmailDefaultCost
+}
@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// roundTripFunc lets a function stand in for the network
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func okTransport() http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	})
+}
+
+func TestRateLimiterBuckets(t *testing.T) {
+	limiter := NewRateLimiter(map[string]RateLimit{"sheets": {Rate: 2, Burst: 3}, "drive": {Rate: 0}})
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	alice := rateKey{account: "alice@example.com", api: "sheets"}
+	bob := rateKey{account: "bob@example.com", api: "sheets"}
+
+	// The burst is spent without waiting, then requests wait for the refill
+	for i := 0; i < 3; i++ {
+		if delay := limiter.reserve(alice, 1); delay != 0 {
+			t.Fatalf("request %d: expected no wait within the burst, got %v", i+1, delay)
+		}
+	}
+	if delay := limiter.reserve(alice, 1); delay != 500*time.Millisecond {
+		t.Errorf("expected to wait for half a second, got %v", delay)
+	}
+	if delay := limiter.reserve(alice, 1); delay != time.Second {
+		t.Errorf("expected a second request over the limit to queue behind the first, got %v", delay)
+	}
+
+	// Each account has its own bucket
+	if delay := limiter.reserve(bob, 1); delay != 0 {
+		t.Errorf("expected another account not to wait, got %v", delay)
+	}
+
+	// The bucket refills with time
+	now = now.Add(5 * time.Second)
+	if delay := limiter.reserve(alice, 3); delay != 0 {
+		t.Errorf("expected the refilled burst to be available, got %v", delay)
+	}
+
+	// A rate of 0 turns the limit off
+	for i := 0; i < 1000; i++ {
+		if delay := limiter.reserve(rateKey{account: "alice@example.com", api: "drive"}, 1); delay != 0 {
+			t.Fatalf("expected drive not to be limited, got %v", delay)
+		}
+	}
+
+	usage := limiter.Usage()
+	if len(usage) != 3 {
+		t.Fatalf("expected usage of 3 account and API pairs, got %+v", usage)
+	}
+	if u := usage[1]; u.Account != "alice@example.com" || u.API != "sheets" || u.Requests != 6 || u.Units != 8 || u.Throttled != 2 || u.Waited != 1500*time.Millisecond {
+		t.Errorf("unexpected sheets usage %+v", u)
+	}
+	if limiter.Limits()["gmail"] != DefaultRateLimits["gmail"] {
+		t.Errorf("expected limits without an override to keep their default")
+	}
+}
+
+func TestRateLimiterWaitRefundsCancelledRequests(t *testing.T) {
+	limiter := NewRateLimiter(map[string]RateLimit{"docs": {Rate: 0.001, Burst: 1}})
+	ctx := context.Background()
+	if err := limiter.Wait(ctx, "alice@example.com", "docs", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx, "alice@example.com", "docs", 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the wait to end with the context, got %v", err)
+	}
+
+	usage := limiter.Usage()
+	if len(usage) != 1 || usage[0].Requests != 1 || usage[0].Units != 1 {
+		t.Errorf("expected only the request that was sent to count, got %+v", usage)
+	}
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	limiter := NewRateLimiter(nil)
+	client := &http.Client{Transport: NewRateLimitTransport(okTransport(), limiter)}
+
+	send := func(ctx context.Context, method, url string) {
+		t.Helper()
+		req, _ := http.NewRequestWithContext(ctx, method, url, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	alice := WithAccount(context.Background(), "alice@example.com")
+	send(alice, http.MethodGet, "https://gmail.googleapis.com/gmail/v1/users/me/messages")
+	send(alice, http.MethodPost, "https://gmail.googleapis.com/gmail/v1/users/me/messages/send")
+	send(alice, http.MethodGet, "https://www.googleapis.com/drive/v3/files")
+	send(context.Background(), http.MethodGet, "https://www.googleapis.com/calendar/v3/calendars/primary/events")
+	send(alice, http.MethodGet, "https://drive.google.com/thumbnail?id=abc")
+
+	want := []QuotaUsage{
+		{Account: "alice@example.com", API: "drive", Requests: 1, Units: 1},
+		{Account: "alice@example.com", API: "gmail", Requests: 2, Units: 105},
+		{Account: "default", API: "calendar", Requests: 1, Units: 1},
+	}
+	got := limiter.Usage()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected usage %v, got %v", want, got)
+	}
+}
+
+func TestQuotaCost(t *testing.T) {
+	tests := []struct {
+		method string
+		url    string
+		units  int
+	}{
+		{http.MethodGet, "https://gmail.googleapis.com/gmail/v1/users/me/messages", 5},
+		{http.MethodGet, "https://gmail.googleapis.com/gmail/v1/users/me/messages/abc", 5},
+		{http.MethodPost, "https://gmail.googleapis.com/gmail/v1/users/me/messages/abc/modify", 5},
+		{http.MethodPost, "https://gmail.googleapis.com/gmail/v1/users/me/messages/send", 100},
+		{http.MethodPost, "https://gmail.googleapis.com/upload/gmail/v1/users/me/messages/send", 100},
+		{http.MethodPost, "https://gmail.googleapis.com/gmail/v1/users/me/drafts", 10},
+		{http.MethodDelete, "https://gmail.googleapis.com/gmail/v1/users/me/messages/abc", 10},
+		{http.MethodGet, "https://gmail.googleapis.com/gmail/v1/users/me/threads/abc", 10},
+		{http.MethodDelete, "https://gmail.googleapis.com/gmail/v1/users/me/threads/abc", 20},
+		{http.MethodGet, "https://gmail.googleapis.com/gmail/v1/users/me/labels", 1},
+		{http.MethodGet, "https://gmail.googleapis.com/gmail/v1/users/me/profile", 1},
+		{http.MethodPost, "https://www.googleapis.com/drive/v3/files", 1},
+		{http.MethodGet, "https://sheets.googleapis.com/v4/spreadsheets/abc", 1},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, tt.url, nil)
+		if got := quotaCost(APIService(req.URL), req); got != tt.units {
+			t.Errorf("%s %s costs %d units, want %d", tt.method, strings.TrimPrefix(tt.url, "https://"), got, tt.units)
+		}
+	}
+}
+
+func TestForEachAccountBoundsConcurrency(t *testing.T) {
+	clients := make(map[string]int)
+	for i := 0; i < 3*MaxParallelAccounts; i++ {
+		clients[fmt.Sprintf("user%d@example.com", i)] = i
+	}
+
+	var running, peak atomic.Int32
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	ForEachAccount(clients, func(email string, client int) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+
+		mu.Lock()
+		seen[email] = true
+		mu.Unlock()
+	})
+
+	if len(seen) != len(clients) {
+		t.Errorf("expected every account to be visited, got %d of %d", len(seen), len(clients))
+	}
+	if peak.Load() > MaxParallelAccounts {
+		t.Errorf("expected at most %d accounts at once, got %d", MaxParallelAccounts, peak.Load())
+	}
+}
@@ -0,0 +1,268 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	// maxRetryDelay caps the exponential backoff between attempts
+	maxRetryDelay = 30 * time.Second
+
+	// maxRetryAfter is the longest Retry-After the transport waits for;
+	// responses asking for more are returned to the caller as they are
+	maxRetryAfter = 2 * time.Minute
+
+	// maxErrorBodySize is how much of a 403 response is read to tell a
+	// rate limit from a permission error
+	maxErrorBodySize = 64 * 1024
+)
+
+// RetryPolicy configures a RetryTransport
+type RetryPolicy struct {
+	// MaxRetries is the number of times a request is sent again after
+	// the first attempt failed
+	MaxRetries int
+
+	// BaseDelay is the backoff before the first retry; it doubles with
+	// each further attempt, up to 30 seconds
+	BaseDelay time.Duration
+
+	// ServiceRetries overrides MaxRetries for the requests of a service,
+	// keyed by the name APIService gives it
+	ServiceRetries map[string]int
+}
+
+// maxRetries returns the number of retries allowed for req
+func (p RetryPolicy) maxRetries(req *http.Request) int {
+	if n, ok := p.ServiceRetries[APIService(req.URL)]; ok {
+		return n
+	}
+	return p.MaxRetries
+}
+
+// RetryTransport is an http.RoundTripper that sends a request again when
+// Google answers 429, a rate limit 403 or a 5xx, or when the connection
+// fails, waiting with jittered exponential backoff or for as long as the
+// Retry-After header asks.
+//
+// Only idempotent requests (GET, HEAD, OPTIONS, PUT and DELETE) are retried
+// after a server error or a broken connection, since the first attempt may
+// have taken effect. Other requests are retried only when it is certain
+// they were not processed: the connection could not be established, or the
+// server refused them with a rate limit. Requests whose body cannot be
+// replayed are never retried.
+type RetryTransport struct {
+	Base   http.RoundTripper
+	Policy RetryPolicy
+}
+
+// NewRetryTransport wraps base, or http.DefaultTransport when nil
+func NewRetryTransport(base http.RoundTripper, policy RetryPolicy) *RetryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &RetryTransport{Base: base, Policy: policy}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	retries := t.Policy.maxRetries(req)
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		retries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		resp, err := t.Base.RoundTrip(req)
+		if attempt >= retries {
+			return resp, err
+		}
+
+		delay, retry := t.shouldRetry(req, resp, err, attempt)
+		if !retry {
+			return resp, err
+		}
+		if resp != nil {
+			drainBody(resp)
+		}
+
+		logger.DebugContext(req.Context(), "retrying API request",
+			"method", req.Method, "host", req.URL.Host, "path", req.URL.Path,
+			"attempt", attempt+1, "delay", delay, "status", statusOf(resp), "error", err)
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// shouldRetry decides whether the outcome of an attempt is worth another
+// one, and how long to wait before it
+func (t *RetryTransport) shouldRetry(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	idempotent := isIdempotent(req.Method)
+	backoff := t.backoff(attempt)
+
+	if err != nil {
+		if req.Context().Err() != nil {
+			return 0, false
+		}
+		if isDialError(err) {
+			return backoff, true
+		}
+		return backoff, idempotent && isTransientError(err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+	case resp.StatusCode == http.StatusForbidden && isRateLimitResponse(resp):
+	case resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented:
+		if !idempotent {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+
+	if wait, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		if wait > maxRetryAfter {
+			return 0, false
+		}
+		return wait, true
+	}
+	return backoff, true
+}
+
+// backoff returns the delay before retry number attempt+1: the base delay
+// doubled for each earlier attempt, capped, with the upper half jittered so
+// that clients failing together do not retry together
+func (t *RetryTransport) backoff(attempt int) time.Duration {
+	delay := t.Policy.BaseDelay
+	for i := 0; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// isIdempotent reports whether sending a request with method twice has the
+// same effect as sending it once
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isDialError reports whether the connection failed before the request was
+// written, in which case the server cannot have seen it
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsTemporary
+}
+
+// isTransientError reports whether a network error may go away by itself
+func isTransientError(err error) bool {
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isRateLimitResponse reports whether a 403 is one of the rate limit errors
+// that Google asks clients to retry with backoff. The body is read and put
+// back, so the caller still sees it when the request is not retried.
+func isRateLimitResponse(resp *http.Response) bool {
+	if resp.Body == nil {
+		return false
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	rest := resp.Body
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), rest), rest}
+	if err != nil {
+		return false
+	}
+	return bytes.Contains(body, []byte(`"rateLimitExceeded"`)) ||
+		bytes.Contains(body, []byte(`"userRateLimitExceeded"`))
+}
+
+// retryAfter parses a Retry-After header given in seconds or as a date
+func retryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := at.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// drainBody discards the rest of a response that will not be returned, so
+// that its connection can be reused
+func drainBody(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBodySize))
+	_ = resp.Body.Close()
+}
+
+// sleep waits for d, or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// statusOf returns the status code of resp, or 0 when there is none
+func statusOf(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
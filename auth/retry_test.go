@@ -0,0 +1,238 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyServer answers with the given statuses in turn, then 200, and
+// records the body of every attempt
+func flakyServer(t *testing.T, statuses []int, body string) (*httptest.Server, *atomic.Int32, *[]string) {
+	t.Helper()
+	var attempts atomic.Int32
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		n := int(attempts.Add(1))
+		if n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			_, _ = io.WriteString(w, body)
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	t.Cleanup(ts.Close)
+	return ts, &attempts, &bodies
+}
+
+func testRetryClient(maxRetries int) *http.Client {
+	return &http.Client{Transport: NewRetryTransport(nil, RetryPolicy{MaxRetries: maxRetries, BaseDelay: time.Millisecond})}
+}
+
+func TestRetryTransportRetriesIdempotentRequests(t *testing.T) {
+	ts, attempts, bodies := flakyServer(t, []int{http.StatusServiceUnavailable, http.StatusBadGateway}, "")
+
+	req, _ := http.NewRequest(http.MethodPut, ts.URL, strings.NewReader(`{"name":"x"}`))
+	resp, err := testRetryClient(3).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || attempts.Load() != 3 {
+		t.Errorf("expected success on the third attempt, got %d after %d", resp.StatusCode, attempts.Load())
+	}
+	for i, body := range *bodies {
+		if body != `{"name":"x"}` {
+			t.Errorf("attempt %d sent body %q", i+1, body)
+		}
+	}
+}
+
+func TestRetryTransportGivesUpAfterMaxRetries(t *testing.T) {
+	ts, attempts, _ := flakyServer(t, []int{500, 500, 500, 500}, "")
+
+	resp, err := testRetryClient(2).Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusInternalServerError || attempts.Load() != 3 {
+		t.Errorf("expected the last error after 3 attempts, got %d after %d", resp.StatusCode, attempts.Load())
+	}
+}
+
+func TestRetryTransportProtectsNonIdempotentRequests(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		attempts int32
+	}{
+		{"server error may have been processed", http.StatusServiceUnavailable, "", 1},
+		{"not implemented is final", http.StatusNotImplemented, "", 1},
+		{"too many requests was refused", http.StatusTooManyRequests, "", 2},
+		{"rate limit was refused", http.StatusForbidden, `{"error":{"errors":[{"reason":"userRateLimitExceeded"}]}}`, 2},
+		{"permission errors are final", http.StatusForbidden, `{"error":{"errors":[{"reason":"insufficientPermissions"}]}}`, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts, attempts, _ := flakyServer(t, []int{tt.status}, tt.body)
+
+			resp, err := testRetryClient(3).Post(ts.URL, "application/json", strings.NewReader(`{}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+
+			if attempts.Load() != tt.attempts {
+				t.Errorf("expected %d attempts, got %d", tt.attempts, attempts.Load())
+			}
+			// A response that is not retried reaches the caller intact
+			if tt.attempts == 1 && string(body) != tt.body {
+				t.Errorf("expected body %q, got %q", tt.body, body)
+			}
+		})
+	}
+}
+
+func TestRetryTransportRetriesRefusedConnections(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	addr := ts.URL
+	ts.Close()
+
+	start := time.Now()
+	_, err := testRetryClient(2).Post(addr, "application/json", strings.NewReader(`{}`))
+	if err == nil {
+		t.Fatal("expected the closed server to be unreachable")
+	}
+	if !isDialError(err) {
+		t.Errorf("expected a dial error, got %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("retries took %v", time.Since(start))
+	}
+}
+
+func TestRetryTransportHonorsRetryAfter(t *testing.T) {
+	var attempts atomic.Int32
+	var retried time.Time
+	first := time.Now()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		retried = time.Now()
+	}))
+	defer ts.Close()
+
+	resp, err := testRetryClient(1).Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the retry to succeed, got %d", resp.StatusCode)
+	}
+	if wait := retried.Sub(first); wait < time.Second {
+		t.Errorf("expected to wait for Retry-After, retried after %v", wait)
+	}
+}
+
+func TestRetryTransportStopsWhenCancelled(t *testing.T) {
+	ts, attempts, _ := flakyServer(t, []int{503, 503, 503}, "")
+	client := &http.Client{Transport: NewRetryTransport(nil, RetryPolicy{MaxRetries: 3, BaseDelay: time.Hour})}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, nil)
+	_, err := client.Do(req)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the wait to end with the context, got %v", err)
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("expected 1 attempt, got %d", attempts.Load())
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{"-1", 0, false},
+		{"Wed, 01 May 2024 12:00:30 GMT", 30 * time.Second, true},
+		{"Wed, 01 May 2024 11:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	transport := NewRetryTransport(nil, RetryPolicy{BaseDelay: time.Second})
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		for i := 0; i < 20; i++ {
+			if got := transport.backoff(attempt); got < want/2 || got > want {
+				t.Errorf("backoff(%d) = %v, want between %v and %v", attempt, got, want/2, want)
+			}
+		}
+	}
+	if got := transport.backoff(20); got > maxRetryDelay {
+		t.Errorf("expected backoff to be capped at %v, got %v", maxRetryDelay, got)
+	}
+}
+
+func TestAPIService(t *testing.T) {
+	tests := map[string]string{
+		"https://www.googleapis.com/drive/v3/files":                  "drive",
+		"https://www.googleapis.com/upload/drive/v3/files":           "drive",
+		"https://www.googleapis.com/calendar/v3/calendars/primary":   "calendar",
+		"https://sheets.googleapis.com/v4/spreadsheets/abc":          "sheets",
+		"https://gmail.googleapis.com/gmail/v1/users/me/messages":    "gmail",
+		"https://tasks.googleapis.com/tasks/v1/users/@me/lists":      "tasks",
+		"https://www.mtls.googleapis.com/drive/v3/files":             "drive",
+		"https://drive.google.com/thumbnail?id=abc":                  "",
+		"https://lh3.googleusercontent.com/d/abc=s220":               "",
+		"https://oauth2.googleapis.com/token":                        "oauth2",
+		"https://docs.googleapis.com/v1/documents/abc:batchUpdate":   "docs",
+		"https://slides.googleapis.com/v1/presentations/abc":         "slides",
+		"https://www.googleapis.com/batch/drive/v3":                  "drive",
+		"https://www.googleapis.com/oauth2/v2/userinfo?alt=json&x=1": "oauth2",
+	}
+	for raw, want := range tests {
+		u, _ := url.Parse(raw)
+		if got := APIService(u); got != want {
+			t.Errorf("APIService(%s) = %q, want %q", raw, got, want)
+		}
+	}
+
+	policy := RetryPolicy{MaxRetries: 3, ServiceRetries: map[string]int{"drive": 5}}
+	drive, _ := http.NewRequest(http.MethodGet, "https://www.googleapis.com/drive/v3/files", nil)
+	sheets, _ := http.NewRequest(http.MethodGet, "https://sheets.googleapis.com/v4/spreadsheets/abc", nil)
+	if policy.maxRetries(drive) != 5 || policy.maxRetries(sheets) != 3 {
+		t.Errorf("expected drive to use its own limit, got %d and %d", policy.maxRetries(drive), policy.maxRetries(sheets))
+	}
+}
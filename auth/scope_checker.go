@@ -3,6 +3,7 @@ package auth
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"golang.org/x/oauth2"
@@ -87,6 +88,43 @@ func (am *AccountManager) CheckScopes(ctx context.Context, account *Account, ser
 	return nil
 }
 
+// ServicesForScopes returns the services whose required scopes are all
+// included in scopes, sorted by name
+func ServicesForScopes(scopes []string) []string {
+	var services []string
+	for service, required := range RequiredScopes {
+		if hasAllScopes(required, scopes) {
+			services = append(services, service)
+		}
+	}
+	sort.Strings(services)
+	return services
+}
+
+// GrantedServices returns the services that at least one account has the
+// scopes for. Accounts whose scopes cannot be looked up, for example because
+// the token expired, are skipped and reported in the error, so a non-nil
+// error means the result may be incomplete.
+func (am *AccountManager) GrantedServices(ctx context.Context) (map[string]bool, error) {
+	granted := make(map[string]bool)
+	var failed []string
+	for _, account := range am.ListAccounts() {
+		scopes, err := am.GetTokenScopes(ctx, account)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", account.Email, err))
+			continue
+		}
+		for _, service := range ServicesForScopes(scopes) {
+			granted[service] = true
+		}
+	}
+
+	if len(failed) > 0 {
+		return granted, fmt.Errorf("failed to get scopes of %d account(s): %s", len(failed), strings.Join(failed, "; "))
+	}
+	return granted, nil
+}
+
 // GetTokenScopes retrieves the scopes associated with an account's token
 func (am *AccountManager) GetTokenScopes(ctx context.Context, account *Account) ([]string, error) {
 	if account.Token == nil {
@@ -95,7 +133,8 @@ func (am *AccountManager) GetTokenScopes(ctx context.Context, account *Account)
 
 	// Create a temporary client with the token
 	tokenSource := am.oauthConfig.TokenSource(ctx, account.Token)
-	httpClient := oauth2.NewClient(ctx, tokenSource)
+	email := account.Email
+	httpClient := oauth2.NewClient(withTransport(ctx, func() string { return email }), tokenSource)
 
 	// Use the tokeninfo endpoint to get scope information
 	oauth2Service, err := oauth2v2.NewService(ctx, option.WithHTTPClient(httpClient))
@@ -103,7 +142,7 @@ func (am *AccountManager) GetTokenScopes(ctx context.Context, account *Account)
 		return nil, fmt.Errorf("failed to create oauth2 service: %w", err)
 	}
 
-	tokenInfo, err := oauth2Service.Tokeninfo().AccessToken(account.Token.AccessToken).Do()
+	tokenInfo, err := oauth2Service.Tokeninfo().AccessToken(account.Token.AccessToken).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get token info: %w", err)
 	}
@@ -143,11 +182,36 @@ func (am *AccountManager) RefreshAccountWithScopes(ctx context.Context, email st
 
 // Helper functions
 
-func hasAllScopes(required, current []string) bool {
-	currentMap := make(map[string]bool)
+// impliedScopes lists the narrower scopes that a broader scope grants, so a
+// token with full Drive access satisfies a requirement for drive.file
+var impliedScopes = map[string][]string{
+	"https://www.googleapis.com/auth/calendar": {
+		"https://www.googleapis.com/auth/calendar.events",
+		"https://www.googleapis.com/auth/calendar.readonly",
+	},
+	"https://www.googleapis.com/auth/drive": {
+		"https://www.googleapis.com/auth/drive.file",
+		"https://www.googleapis.com/auth/drive.readonly",
+	},
+	"https://www.googleapis.com/auth/gmail.modify": {
+		"https://www.googleapis.com/auth/gmail.readonly",
+	},
+}
+
+// grantedScopeSet returns the scopes in current together with those they imply
+func grantedScopeSet(current []string) map[string]bool {
+	granted := make(map[string]bool)
 	for _, scope := range current {
-		currentMap[scope] = true
+		granted[scope] = true
+		for _, implied := range impliedScopes[scope] {
+			granted[implied] = true
+		}
 	}
+	return granted
+}
+
+func hasAllScopes(required, current []string) bool {
+	currentMap := grantedScopeSet(current)
 
 	for _, scope := range required {
 		if !currentMap[scope] {
@@ -159,10 +223,7 @@ func hasAllScopes(required, current []string) bool {
 }
 
 func getMissingScopes(required, current []string) []string {
-	currentMap := make(map[string]bool)
-	for _, scope := range current {
-		currentMap[scope] = true
-	}
+	currentMap := grantedScopeSet(current)
 
 	missing := []string{}
 	for _, scope := range required {
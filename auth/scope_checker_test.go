@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestServicesForScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		scopes   []string
+		expected []string
+	}{
+		{
+			name:     "default scopes grant every service",
+			scopes:   DefaultScopes(),
+			expected: []string{"calendar", "docs", "drive", "gmail", "sheets", "slides", "tasks"},
+		},
+		{
+			name: "narrow scopes",
+			scopes: []string{
+				"https://www.googleapis.com/auth/tasks",
+				"https://www.googleapis.com/auth/drive.file",
+				"https://www.googleapis.com/auth/userinfo.email",
+			},
+			expected: []string{"tasks"},
+		},
+		{
+			name:     "no scopes",
+			scopes:   nil,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ServicesForScopes(tt.scopes); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ServicesForScopes() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMissingScopesHonorImpliedScopes(t *testing.T) {
+	current := []string{"https://www.googleapis.com/auth/calendar"}
+	if missing := getMissingScopes(RequiredScopes["calendar"], current); len(missing) != 0 {
+		t.Errorf("expected the calendar scope to cover calendar.events, missing %v", missing)
+	}
+	if missing := getMissingScopes(RequiredScopes["drive"], current); len(missing) != 2 {
+		t.Errorf("expected both drive scopes to be missing, got %v", missing)
+	}
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+var (
+	// transport carries the requests of every API client created by this
+	// package, underneath the OAuth2 layer that authorizes them
+	transport   http.RoundTripper = http.DefaultTransport
+	transportMu sync.RWMutex
+)
+
+// SetTransport installs the RoundTripper shared by the API clients created
+// from now on, such as a RetryTransport wrapping http.DefaultTransport. It
+// is called once at startup, before accounts are loaded.
+func SetTransport(rt http.RoundTripper) {
+	transportMu.Lock()
+	defer transportMu.Unlock()
+	transport = rt
+}
+
+// Transport returns the RoundTripper installed with SetTransport
+func Transport() http.RoundTripper {
+	transportMu.RLock()
+	defer transportMu.RUnlock()
+	return transport
+}
+
+// withTransport makes the oauth2 package build clients on the shared
+// transport, with their requests attributed to an account. Tokens refreshed
+// by those clients go through it as well. The account is looked up for each
+// request, as it may only be known once the client has fetched it.
+func withTransport(ctx context.Context, account func() string) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, &http.Client{
+		Transport: &accountTransport{account: account},
+	})
+}
+
+// accountTransport records the account of each request in its context,
+// where the shared transport finds it with AccountFromContext
+type accountTransport struct {
+	account func() string
+}
+
+func (t *accountTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	account := t.account()
+	RecordAccountUse(req.Context(), account)
+	return Transport().RoundTrip(req.WithContext(WithAccount(req.Context(), account)))
+}
+
+// AccountUse records the accounts a tool call acted for, see WithAccountUse
+type AccountUse struct {
+	mu       sync.Mutex
+	accounts map[string]bool
+}
+
+type accountUseKey struct{}
+
+// WithAccountUse returns a context whose API requests record the account
+// they are made for in the returned AccountUse. The account is the one the
+// client was resolved to, whether or not the call named it.
+func WithAccountUse(ctx context.Context) (context.Context, *AccountUse) {
+	u := &AccountUse{accounts: make(map[string]bool)}
+	return context.WithValue(ctx, accountUseKey{}, u), u
+}
+
+// RecordAccountUse records that the call of ctx acted for account. API
+// clients created by this package record every request; services that act
+// for an account without one can call it themselves.
+func RecordAccountUse(ctx context.Context, account string) {
+	u, ok := ctx.Value(accountUseKey{}).(*AccountUse)
+	if !ok || account == "" {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.accounts[account] = true
+}
+
+// Accounts returns the accounts used so far, sorted
+func (u *AccountUse) Accounts() []string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	accounts := make([]string, 0, len(u.accounts))
+	for account := range u.accounts {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+	return accounts
+}
+
+type accountContextKey struct{}
+
+// WithAccount returns a context for requests made on behalf of account
+func WithAccount(ctx context.Context, account string) context.Context {
+	return context.WithValue(ctx, accountContextKey{}, account)
+}
+
+// AccountFromContext returns the account a request is made for, or
+// "default" when it is not known
+func AccountFromContext(ctx context.Context) string {
+	if account, ok := ctx.Value(accountContextKey{}).(string); ok && account != "" {
+		return account
+	}
+	return defaultAccount
+}
+
+// defaultAccount names the account of the single-account OAuth client
+const defaultAccount = "default"
+
+// newHTTPClient returns an HTTP client authorized with token that sends its
+// requests through the shared transport on behalf of the client's account
+func (c *OAuthClient) newHTTPClient(ctx context.Context, token *oauth2.Token) *http.Client {
+	return c.config.Client(withTransport(ctx, c.accountName), token)
+}
+
+// APIService names the Google service a request URL belongs to, such as
+// "drive" for https://www.googleapis.com/drive/v3/files and "sheets" for
+// https://sheets.googleapis.com/v4/spreadsheets. It returns "" for hosts
+// that are not Google APIs.
+func APIService(u *url.URL) string {
+	host := u.Hostname()
+	if !strings.HasSuffix(host, ".googleapis.com") {
+		return ""
+	}
+	if name := strings.TrimSuffix(host, ".googleapis.com"); name != "www" && name != "www.mtls" {
+		return strings.TrimSuffix(name, ".mtls")
+	}
+
+	// Older APIs share www.googleapis.com and are told apart by their path
+	path := strings.TrimPrefix(u.Path, "/")
+	path = strings.TrimPrefix(path, "upload/")
+	path = strings.TrimPrefix(path, "batch/")
+	name, _, _ := strings.Cut(path, "/")
+	return name
+}
@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestAccountUseRecordsResolvedAccounts(t *testing.T) {
+	previous := Transport()
+	SetTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	}))
+	defer SetTransport(previous)
+
+	get := func(ctx context.Context, account string) {
+		client := &http.Client{Transport: &accountTransport{account: func() string { return account }}}
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://www.googleapis.com/drive/v3/files", nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	ctx, use := WithAccountUse(context.Background())
+	get(ctx, "bob@example.com")
+	get(ctx, "alice@example.com")
+	get(ctx, "bob@example.com")
+	get(context.Background(), "carol@example.com")
+	if got, want := use.Accounts(), []string{"alice@example.com", "bob@example.com"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the accounts of the call's requests %v, got %v", want, got)
+	}
+
+	// Recording without an AccountUse does nothing
+	RecordAccountUse(context.Background(), "dave@example.com")
+}
@@ -0,0 +1,141 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go.ngs.io/google-mcp-server/server"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// syncPageSize is the page size used while catching up with a sync token
+const syncPageSize = 250
+
+// errSyncTokenExpired is returned when Google no longer accepts a sync token
+// and a full sync is needed
+var errSyncTokenExpired = errors.New("sync token expired")
+
+// eventURIPattern matches calendar://event URIs so subscriptions can tell
+// which event they watch
+var eventURIPattern = server.MustParseURITemplate(eventURITemplate)
+
+// EventsChangedSince lists the IDs of events in calendarID that were created,
+// updated or deleted after syncToken, and returns the next sync token. An
+// empty syncToken performs a full sync, which only yields the token.
+func (c *Client) EventsChangedSince(ctx context.Context, calendarID, syncToken string) ([]string, string, error) {
+	call := c.service.Events.List(calendarID).
+		Fields("nextPageToken, nextSyncToken, items(id)").
+		MaxResults(syncPageSize)
+	if syncToken != "" {
+		call = call.SyncToken(syncToken)
+	}
+
+	var eventIDs []string
+	var next string
+	err := call.Pages(ctx, func(page *calendar.Events) error {
+		if syncToken != "" {
+			for _, event := range page.Items {
+				eventIDs = append(eventIDs, event.Id)
+			}
+		}
+		next = page.NextSyncToken
+		return nil
+	})
+	if err != nil {
+		return nil, "", syncError("events", err)
+	}
+	return eventIDs, next, nil
+}
+
+// CalendarListChangedSince reports whether calendars were added to, removed
+// from or changed in the calendar list after syncToken, and returns the next
+// sync token. An empty syncToken performs a full sync.
+func (c *Client) CalendarListChangedSince(ctx context.Context, syncToken string) (bool, string, error) {
+	call := c.service.CalendarList.List().
+		Fields("nextPageToken, nextSyncToken, items(id)").
+		MaxResults(syncPageSize)
+	if syncToken != "" {
+		call = call.SyncToken(syncToken)
+	}
+
+	changed := false
+	var next string
+	err := call.Pages(ctx, func(page *calendar.CalendarList) error {
+		changed = changed || len(page.Items) > 0
+		next = page.NextSyncToken
+		return nil
+	})
+	if err != nil {
+		return false, "", syncError("calendar list", err)
+	}
+	return changed && syncToken != "", next, nil
+}
+
+// syncError maps 410 Gone, which Google returns for expired sync tokens, to
+// errSyncTokenExpired
+func syncError(what string, err error) error {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusGone {
+		return errSyncTokenExpired
+	}
+	return fmt.Errorf("failed to sync %s: %w", what, err)
+}
+
+// DetectChanges implements server.ChangeDetector with Calendar sync tokens.
+// The cursor is the sync token of the events or calendar list behind uri.
+// When the token has expired a full sync fetches a new one and the resource
+// is reported as changed, since changes may have been missed.
+func (h *MultiAccountHandler) DetectChanges(ctx context.Context, uri string, cursor string) (bool, string, error) {
+	if variables, ok := eventURIPattern.Match(uri); ok {
+		client, err := h.getClientForAccount(ctx, variables["account"])
+		if err != nil {
+			return false, "", err
+		}
+		return detectEventChanges(ctx, client, variables["calendarId"], variables["eventId"], cursor)
+	}
+
+	if h.defaultClient == nil {
+		return false, "", server.ErrNotWatchable
+	}
+	switch uri {
+	case primaryEventsURI:
+		return detectEventChanges(ctx, h.defaultClient, "primary", "", cursor)
+
+	case calendarsURI:
+		changed, next, err := h.defaultClient.CalendarListChangedSince(ctx, cursor)
+		if errors.Is(err, errSyncTokenExpired) {
+			_, next, err = h.defaultClient.CalendarListChangedSince(ctx, "")
+			return err == nil, next, err
+		}
+		return changed, next, err
+
+	default:
+		return false, "", server.ErrNotWatchable
+	}
+}
+
+// detectEventChanges reports whether events in calendarID changed since
+// cursor; with an eventID only changes to that event count
+func detectEventChanges(ctx context.Context, client *Client, calendarID, eventID, cursor string) (bool, string, error) {
+	eventIDs, next, err := client.EventsChangedSince(ctx, calendarID, cursor)
+	if errors.Is(err, errSyncTokenExpired) {
+		_, next, err = client.EventsChangedSince(ctx, calendarID, "")
+		return err == nil, next, err
+	}
+	if err != nil {
+		return false, "", err
+	}
+
+	if eventID == "" {
+		return len(eventIDs) > 0, next, nil
+	}
+	for _, id := range eventIDs {
+		if id == eventID {
+			return true, next, nil
+		}
+	}
+	return false, next, nil
+}
@@ -0,0 +1,126 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"go.ngs.io/google-mcp-server/server"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/option"
+)
+
+// fakeSyncBackend serves events and the calendar list with sync tokens that
+// are positions in a log of changed IDs. Tokens below expiredBefore are
+// answered with 410 Gone.
+type fakeSyncBackend struct {
+	mu            sync.Mutex
+	events        []string
+	calendars     []string
+	expiredBefore int
+}
+
+func (f *fakeSyncBackend) changeEvent(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, id)
+}
+
+func (f *fakeSyncBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var log []string
+	switch r.URL.Path {
+	case "/calendars/primary/events":
+		log = f.events
+	case "/users/me/calendarList":
+		log = f.calendars
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	start := 0
+	if token := r.URL.Query().Get("syncToken"); token != "" {
+		start, _ = strconv.Atoi(token)
+		if start < f.expiredBefore {
+			http.Error(w, `{"error":{"code":410,"message":"Sync token is no longer valid"}}`, http.StatusGone)
+			return
+		}
+	}
+	items := make([]map[string]string, 0)
+	for _, id := range log[start:] {
+		items = append(items, map[string]string{"id": id})
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"items":         items,
+		"nextSyncToken": strconv.Itoa(len(log)),
+	})
+}
+
+func TestCalendarDetectChanges(t *testing.T) {
+	ctx := context.Background()
+	backend := &fakeSyncBackend{events: []string{"standup"}, calendars: []string{"primary"}}
+	ts := httptest.NewServer(backend)
+	defer ts.Close()
+
+	service, err := calendar.NewService(ctx, option.WithEndpoint(ts.URL+"/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("failed to create calendar service: %v", err)
+	}
+	handler := &MultiAccountHandler{defaultClient: &Client{service: service}}
+
+	detect := func(uri, cursor string) (bool, string) {
+		t.Helper()
+		changed, next, err := handler.DetectChanges(ctx, uri, cursor)
+		if err != nil {
+			t.Fatalf("DetectChanges(%s) failed: %v", uri, err)
+		}
+		return changed, next
+	}
+
+	changed, events := detect(primaryEventsURI, "")
+	if changed || events != "1" {
+		t.Fatalf("expected a full sync to return token 1 without a change, got changed=%v token=%s", changed, events)
+	}
+	_, standup := detect("calendar://event/primary/standup", "")
+	_, calendars := detect(calendarsURI, "")
+
+	backend.changeEvent("retro")
+	changed, events = detect(primaryEventsURI, events)
+	if !changed {
+		t.Error("expected the primary events to change")
+	}
+	changed, standup = detect("calendar://event/primary/standup", standup)
+	if changed {
+		t.Error("expected the standup event not to change when another event does")
+	}
+	if changed, _ := detect(calendarsURI, calendars); changed {
+		t.Error("expected the calendar list not to change")
+	}
+
+	backend.changeEvent("standup")
+	if changed, _ := detect("calendar://event/primary/standup", standup); !changed {
+		t.Error("expected the standup event to change")
+	}
+
+	// An expired token triggers a full sync and counts as a change
+	backend.mu.Lock()
+	backend.expiredBefore = 3
+	backend.mu.Unlock()
+	changed, events = detect(primaryEventsURI, events)
+	if !changed || events != "3" {
+		t.Errorf("expected an expired token to report a change and resync to 3, got changed=%v token=%s", changed, events)
+	}
+
+	_, _, err = handler.DetectChanges(ctx, "calendar://other", "")
+	if !errors.Is(err, server.ErrNotWatchable) {
+		t.Errorf("expected unknown calendar resources not to be watchable, got %v", err)
+	}
+}
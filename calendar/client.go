@@ -6,9 +6,12 @@ import (
 	"time"
 
 	"go.ngs.io/google-mcp-server/auth"
+	"go.ngs.io/google-mcp-server/logging"
 	"google.golang.org/api/calendar/v3"
 )
 
+var logger = logging.Logger("calendar")
+
 // Client wraps the Google Calendar API client
 type Client struct {
 	service *calendar.Service
@@ -27,10 +30,9 @@ func NewClient(ctx context.Context, oauth *auth.OAuthClient) (*Client, error) {
 }
 
 // ListCalendars lists all calendars
-func (c *Client) ListCalendars() ([]*calendar.CalendarListEntry, error) {
+func (c *Client) ListCalendars(ctx context.Context) ([]*calendar.CalendarListEntry, error) {
 	var calendars []*calendar.CalendarListEntry
 
-	ctx := context.Background()
 	call := c.service.CalendarList.List()
 	err := call.Pages(ctx, func(page *calendar.CalendarList) error {
 		calendars = append(calendars, page.Items...)
@@ -45,7 +47,7 @@ func (c *Client) ListCalendars() ([]*calendar.CalendarListEntry, error) {
 }
 
 // ListEvents lists events from a calendar
-func (c *Client) ListEvents(calendarID string, timeMin, timeMax time.Time, maxResults int64) ([]*calendar.Event, error) {
+func (c *Client) ListEvents(ctx context.Context, calendarID string, timeMin, timeMax time.Time, maxResults int64) ([]*calendar.Event, error) {
 	call := c.service.Events.List(calendarID).
 		ShowDeleted(false).
 		SingleEvents(true).
@@ -61,7 +63,7 @@ func (c *Client) ListEvents(calendarID string, timeMin, timeMax time.Time, maxRe
 		call = call.MaxResults(maxResults)
 	}
 
-	events, err := call.Do()
+	events, err := call.Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list events: %w", err)
 	}
@@ -70,8 +72,8 @@ func (c *Client) ListEvents(calendarID string, timeMin, timeMax time.Time, maxRe
 }
 
 // GetEvent gets a specific event
-func (c *Client) GetEvent(calendarID, eventID string) (*calendar.Event, error) {
-	event, err := c.service.Events.Get(calendarID, eventID).Do()
+func (c *Client) GetEvent(ctx context.Context, calendarID, eventID string) (*calendar.Event, error) {
+	event, err := c.service.Events.Get(calendarID, eventID).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get event: %w", err)
 	}
@@ -79,8 +81,8 @@ func (c *Client) GetEvent(calendarID, eventID string) (*calendar.Event, error) {
 }
 
 // CreateEvent creates a new event
-func (c *Client) CreateEvent(calendarID string, event *calendar.Event) (*calendar.Event, error) {
-	created, err := c.service.Events.Insert(calendarID, event).Do()
+func (c *Client) CreateEvent(ctx context.Context, calendarID string, event *calendar.Event) (*calendar.Event, error) {
+	created, err := c.service.Events.Insert(calendarID, event).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create event: %w", err)
 	}
@@ -88,8 +90,8 @@ func (c *Client) CreateEvent(calendarID string, event *calendar.Event) (*calenda
 }
 
 // UpdateEvent updates an existing event
-func (c *Client) UpdateEvent(calendarID, eventID string, event *calendar.Event) (*calendar.Event, error) {
-	updated, err := c.service.Events.Update(calendarID, eventID, event).Do()
+func (c *Client) UpdateEvent(ctx context.Context, calendarID, eventID string, event *calendar.Event) (*calendar.Event, error) {
+	updated, err := c.service.Events.Update(calendarID, eventID, event).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to update event: %w", err)
 	}
@@ -97,8 +99,8 @@ func (c *Client) UpdateEvent(calendarID, eventID string, event *calendar.Event)
 }
 
 // DeleteEvent deletes an event
-func (c *Client) DeleteEvent(calendarID, eventID string) error {
-	err := c.service.Events.Delete(calendarID, eventID).Do()
+func (c *Client) DeleteEvent(ctx context.Context, calendarID, eventID string) error {
+	err := c.service.Events.Delete(calendarID, eventID).Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("failed to delete event: %w", err)
 	}
@@ -106,7 +108,7 @@ func (c *Client) DeleteEvent(calendarID, eventID string) error {
 }
 
 // SearchEvents searches for events
-func (c *Client) SearchEvents(calendarID, query string, timeMin, timeMax time.Time) ([]*calendar.Event, error) {
+func (c *Client) SearchEvents(ctx context.Context, calendarID, query string, timeMin, timeMax time.Time) ([]*calendar.Event, error) {
 	call := c.service.Events.List(calendarID).
 		Q(query).
 		ShowDeleted(false).
@@ -120,7 +122,7 @@ func (c *Client) SearchEvents(calendarID, query string, timeMin, timeMax time.Ti
 		call = call.TimeMax(timeMax.Format(time.RFC3339))
 	}
 
-	events, err := call.Do()
+	events, err := call.Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to search events: %w", err)
 	}
@@ -129,7 +131,7 @@ func (c *Client) SearchEvents(calendarID, query string, timeMin, timeMax time.Ti
 }
 
 // QueryFreeBusy queries free/busy information
-func (c *Client) QueryFreeBusy(calendarIDs []string, timeMin, timeMax time.Time) (*calendar.FreeBusyResponse, error) {
+func (c *Client) QueryFreeBusy(ctx context.Context, calendarIDs []string, timeMin, timeMax time.Time) (*calendar.FreeBusyResponse, error) {
 	items := make([]*calendar.FreeBusyRequestItem, len(calendarIDs))
 	for i, id := range calendarIDs {
 		items[i] = &calendar.FreeBusyRequestItem{Id: id}
@@ -141,7 +143,7 @@ func (c *Client) QueryFreeBusy(calendarIDs []string, timeMin, timeMax time.Time)
 		Items:   items,
 	}
 
-	response, err := c.service.Freebusy.Query(request).Do()
+	response, err := c.service.Freebusy.Query(request).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to query free/busy: %w", err)
 	}
@@ -150,7 +152,7 @@ func (c *Client) QueryFreeBusy(calendarIDs []string, timeMin, timeMax time.Time)
 }
 
 // CreateEventFromDetails creates an event from basic details
-func (c *Client) CreateEventFromDetails(calendarID, summary, description, location string,
+func (c *Client) CreateEventFromDetails(ctx context.Context, calendarID, summary, description, location string,
 	startTime, endTime time.Time, attendees []string, reminders []int) (*calendar.Event, error) {
 
 	event := &calendar.Event{
@@ -193,12 +195,12 @@ func (c *Client) CreateEventFromDetails(calendarID, summary, description, locati
 		}
 	}
 
-	return c.CreateEvent(calendarID, event)
+	return c.CreateEvent(ctx, calendarID, event)
 }
 
 // GetCalendarByID gets a calendar by ID
-func (c *Client) GetCalendarByID(calendarID string) (*calendar.CalendarListEntry, error) {
-	cal, err := c.service.CalendarList.Get(calendarID).Do()
+func (c *Client) GetCalendarByID(ctx context.Context, calendarID string) (*calendar.CalendarListEntry, error) {
+	cal, err := c.service.CalendarList.Get(calendarID).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get calendar: %w", err)
 	}
@@ -206,6 +208,6 @@ func (c *Client) GetCalendarByID(calendarID string) (*calendar.CalendarListEntry
 }
 
 // GetPrimaryCalendar gets the primary calendar
-func (c *Client) GetPrimaryCalendar() (*calendar.CalendarListEntry, error) {
-	return c.GetCalendarByID("primary")
+func (c *Client) GetPrimaryCalendar(ctx context.Context) (*calendar.CalendarListEntry, error) {
+	return c.GetCalendarByID(ctx, "primary")
 }
@@ -67,7 +67,7 @@ func TestListCalendars(t *testing.T) {
 	client := &Client{service: service}
 
 	// Test ListCalendars
-	calendars, err := client.ListCalendars()
+	calendars, err := client.ListCalendars(context.Background())
 	if err != nil {
 		// This is expected to fail with the mock setup, but we're testing the logic
 		t.Logf("ListCalendars failed as expected with mock: %v", err)
@@ -132,7 +132,7 @@ func TestListEvents(t *testing.T) {
 	timeMin := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
 	timeMax := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
 
-	events, err := client.ListEvents("primary", timeMin, timeMax, 10)
+	events, err := client.ListEvents(context.Background(), "primary", timeMin, timeMax, 10)
 	if err != nil {
 		t.Logf("ListEvents failed as expected with mock: %v", err)
 	} else {
@@ -189,7 +189,7 @@ func TestCreateEvent(t *testing.T) {
 		},
 	}
 
-	created, err := client.CreateEvent("primary", event)
+	created, err := client.CreateEvent(context.Background(), "primary", event)
 	if err != nil {
 		t.Logf("CreateEvent failed as expected with mock: %v", err)
 	} else {
@@ -257,6 +257,7 @@ func TestCreateEventFromDetails(t *testing.T) {
 	reminders := []int{10, 30}
 
 	event, err := client.CreateEventFromDetails(
+		context.Background(),
 		"primary",
 		"Meeting",
 		"Team meeting",
@@ -328,7 +329,7 @@ func TestUpdateEvent(t *testing.T) {
 		},
 	}
 
-	updated, err := client.UpdateEvent("primary", "event1", event)
+	updated, err := client.UpdateEvent(context.Background(), "primary", "event1", event)
 	if err != nil {
 		t.Logf("UpdateEvent failed as expected with mock: %v", err)
 	} else {
@@ -359,7 +360,7 @@ func TestDeleteEvent(t *testing.T) {
 	client := &Client{service: service}
 
 	// Test DeleteEvent
-	err = client.DeleteEvent("primary", "event1")
+	err = client.DeleteEvent(context.Background(), "primary", "event1")
 	if err != nil {
 		t.Logf("DeleteEvent failed as expected with mock: %v", err)
 	}
@@ -406,7 +407,7 @@ func TestSearchEvents(t *testing.T) {
 	timeMin := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
 	timeMax := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
 
-	events, err := client.SearchEvents("primary", "Meeting", timeMin, timeMax)
+	events, err := client.SearchEvents(context.Background(), "primary", "Meeting", timeMin, timeMax)
 	if err != nil {
 		t.Logf("SearchEvents failed as expected with mock: %v", err)
 	} else {
@@ -471,7 +472,7 @@ func TestQueryFreeBusy(t *testing.T) {
 	timeMin := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
 	timeMax := time.Date(2024, 1, 15, 23, 59, 59, 0, time.UTC)
 
-	response, err := client.QueryFreeBusy(calendarIDs, timeMin, timeMax)
+	response, err := client.QueryFreeBusy(context.Background(), calendarIDs, timeMin, timeMax)
 	if err != nil {
 		t.Logf("QueryFreeBusy failed as expected with mock: %v", err)
 	} else {
@@ -520,7 +521,7 @@ func TestGetEvent(t *testing.T) {
 	client := &Client{service: service}
 
 	// Test GetEvent
-	event, err := client.GetEvent("primary", "event1")
+	event, err := client.GetEvent(context.Background(), "primary", "event1")
 	if err != nil {
 		t.Logf("GetEvent failed as expected with mock: %v", err)
 	} else {
@@ -564,7 +565,7 @@ func TestGetCalendarByID(t *testing.T) {
 	client := &Client{service: service}
 
 	// Test GetCalendarByID
-	cal, err := client.GetCalendarByID("test-calendar")
+	cal, err := client.GetCalendarByID(context.Background(), "test-calendar")
 	if err != nil {
 		t.Logf("GetCalendarByID failed as expected with mock: %v", err)
 	} else {
@@ -607,7 +608,7 @@ func TestGetPrimaryCalendar(t *testing.T) {
 	client := &Client{service: service}
 
 	// Test GetPrimaryCalendar
-	cal, err := client.GetPrimaryCalendar()
+	cal, err := client.GetPrimaryCalendar(context.Background())
 	if err != nil {
 		t.Logf("GetPrimaryCalendar failed as expected with mock: %v", err)
 	} else {
@@ -850,7 +851,7 @@ func BenchmarkCreateEvent(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = client.CreateEvent("primary", event)
+		_, _ = client.CreateEvent(context.Background(), "primary", event)
 	}
 }
 
@@ -876,6 +877,6 @@ func BenchmarkListEvents(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = client.ListEvents("primary", timeMin, timeMax, 10)
+		_, _ = client.ListEvents(context.Background(), "primary", timeMin, timeMax, 10)
 	}
 }
@@ -0,0 +1,34 @@
+package calendar
+
+import (
+	"context"
+
+	"go.ngs.io/google-mcp-server/server"
+)
+
+// CompleteArgument suggests calendar IDs for calendar_id arguments and the
+// calendarId variable of the event resource template
+func (h *MultiAccountHandler) CompleteArgument(ctx context.Context, ref server.CompletionRef, argument string, arguments map[string]string) ([]server.CompletionCandidate, error) {
+	if argument != "calendar_id" && argument != "calendarId" {
+		return nil, nil
+	}
+
+	client, err := h.getClientForAccount(ctx, arguments["account"])
+	if err != nil {
+		return nil, err
+	}
+
+	calendars, err := client.ListCalendars(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []server.CompletionCandidate{{Value: "primary", Label: "Primary calendar"}}
+	for _, cal := range calendars {
+		candidates = append(candidates, server.CompletionCandidate{
+			Value: cal.Id,
+			Label: cal.Summary,
+		})
+	}
+	return candidates, nil
+}
@@ -0,0 +1,46 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ConfirmationMessage implements server.Confirmer for event deletion. The
+// message gives the event's title and start, and says when attendees will
+// lose it too, so the user can tell which event is meant.
+func (h *MultiAccountHandler) ConfirmationMessage(ctx context.Context, name string, arguments json.RawMessage) (string, error) {
+	if name != "calendar_event_delete" {
+		return "", nil
+	}
+
+	var args accountEventArgs
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	client, err := h.getClientForAccount(ctx, args.Account)
+	if err != nil {
+		return fmt.Sprintf("Delete event %s from calendar %s?", args.EventID, args.CalendarID), nil
+	}
+	event, err := client.GetEvent(ctx, args.CalendarID, args.EventID)
+	if err != nil {
+		return fmt.Sprintf("Delete event %s from calendar %s?", args.EventID, args.CalendarID), nil
+	}
+
+	var message strings.Builder
+	fmt.Fprintf(&message, "Delete the event %q", event.Summary)
+	if event.Start != nil {
+		start := event.Start.DateTime
+		if start == "" {
+			start = event.Start.Date
+		}
+		fmt.Fprintf(&message, " starting %s", start)
+	}
+	message.WriteString("?")
+	if len(event.Attendees) > 0 {
+		fmt.Fprintf(&message, " It has %d attendee(s), who will also lose it.", len(event.Attendees))
+	}
+	return message.String(), nil
+}
@@ -0,0 +1,86 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.ngs.io/google-mcp-server/config"
+	"go.ngs.io/google-mcp-server/internal/mcptest"
+	"go.ngs.io/google-mcp-server/server"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/option"
+)
+
+func TestEventDeleteAsksTheUser(t *testing.T) {
+	var deleted atomic.Int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/calendars/primary/events/E1" {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":"E1","summary":"Planning","start":{"dateTime":"2024-05-01T10:00:00Z"},"attendees":[{"email":"bob@example.com"}]}`))
+		case http.MethodDelete:
+			deleted.Add(1)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer api.Close()
+	service, err := calendar.NewService(context.Background(), option.WithEndpoint(api.URL+"/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("failed to create calendar service: %v", err)
+	}
+
+	srv := server.NewMCPServer(&config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP}})
+	srv.RegisterService("calendar", NewMultiAccountHandler(nil, &Client{service: service}))
+	ts := httptest.NewServer(srv.HTTPHandler())
+	defer ts.Close()
+	defer func() { _ = srv.Stop() }()
+	endpoint := ts.URL + "/mcp"
+	sessionID := mcptest.Initialize(t, endpoint, server.ProtocolVersion20250618, `{"elicitation":{}}`)
+	requests := mcptest.Listen(t, endpoint, sessionID, "elicitation/create")
+
+	// answer checks that the user is asked about the event and replies
+	answer := func(result string) {
+		select {
+		case msg := <-requests:
+			var request struct {
+				Params struct {
+					Message string `json:"message"`
+				} `json:"params"`
+			}
+			_ = json.Unmarshal(msg, &request)
+			if !strings.Contains(request.Params.Message, `"Planning"`) || !strings.Contains(request.Params.Message, "1 attendee(s)") {
+				t.Errorf("expected the confirmation to describe the event, got %q", request.Params.Message)
+			}
+			mcptest.Reply(t, endpoint, sessionID, msg, result)
+		case <-time.After(5 * time.Second):
+			t.Error("expected an elicitation request")
+		}
+	}
+	params := `{"name":"calendar_event_delete","arguments":{"calendar_id":"primary","event_id":"E1"}}`
+
+	go answer(`{"action":"decline"}`)
+	if _, isError := mcptest.CallTool(t, endpoint, sessionID, params); !isError {
+		t.Error("expected the declined deletion to fail")
+	}
+	if deleted.Load() != 0 {
+		t.Fatalf("expected nothing to be deleted, got %d deletions", deleted.Load())
+	}
+
+	go answer(`{"action":"accept","content":{"confirm":true}}`)
+	if text, isError := mcptest.CallTool(t, endpoint, sessionID, params); isError {
+		t.Fatalf("expected the confirmed deletion to succeed: %s", text)
+	}
+	if deleted.Load() != 1 {
+		t.Errorf("expected the event to be deleted once, got %d deletions", deleted.Load())
+	}
+}
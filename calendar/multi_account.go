@@ -7,6 +7,7 @@ import (
 	"sync"
 
 	"go.ngs.io/google-mcp-server/auth"
+	"go.ngs.io/google-mcp-server/server"
 	"google.golang.org/api/calendar/v3"
 	"google.golang.org/api/option"
 )
@@ -29,7 +30,7 @@ func NewMultiAccountClient(ctx context.Context, accountManager *auth.AccountMana
 	for email, oauthClient := range accountManager.GetAllOAuthClients() {
 		service, err := calendar.NewService(ctx, option.WithHTTPClient(oauthClient.GetHTTPClient()))
 		if err != nil {
-			fmt.Printf("Warning: failed to create calendar service for %s: %v\n", email, err)
+			logger.Warn("failed to create calendar service", "account", email, "error", err)
 			continue
 		}
 		mac.clients[email] = &Client{service: service}
@@ -94,7 +95,6 @@ func (mac *MultiAccountClient) GetClientForContext(ctx context.Context, hint str
 // SearchAcrossAccounts searches for events across all accounts
 func (mac *MultiAccountClient) SearchAcrossAccounts(ctx context.Context, query string, timeMin, timeMax string) (map[string][]*calendar.Event, error) {
 	results := make(map[string][]*calendar.Event)
-	var wg sync.WaitGroup
 	var mu sync.Mutex
 	errors := make([]error, 0)
 
@@ -105,34 +105,30 @@ func (mac *MultiAccountClient) SearchAcrossAccounts(ctx context.Context, query s
 	}
 	mac.mu.RUnlock()
 
-	for email, client := range clients {
-		wg.Add(1)
-		go func(email string, client *Client) {
-			defer wg.Done()
-
-			call := client.service.Events.List("primary").Q(query)
-			if timeMin != "" {
-				call = call.TimeMin(timeMin)
-			}
-			if timeMax != "" {
-				call = call.TimeMax(timeMax)
-			}
-
-			events, err := call.Do()
-			if err != nil {
-				mu.Lock()
-				errors = append(errors, fmt.Errorf("%s: %w", email, err))
-				mu.Unlock()
-				return
-			}
+	progress := server.NewProgressCounter(ctx, len(clients))
+	auth.ForEachAccount(clients, func(email string, client *Client) {
+		defer progress.Step("searched " + email)
 
+		call := client.service.Events.List("primary").Q(query)
+		if timeMin != "" {
+			call = call.TimeMin(timeMin)
+		}
+		if timeMax != "" {
+			call = call.TimeMax(timeMax)
+		}
+
+		events, err := call.Context(ctx).Do()
+		if err != nil {
 			mu.Lock()
-			results[email] = events.Items
+			errors = append(errors, fmt.Errorf("%s: %w", email, err))
 			mu.Unlock()
-		}(email, client)
-	}
+			return
+		}
 
-	wg.Wait()
+		mu.Lock()
+		results[email] = events.Items
+		mu.Unlock()
+	})
 
 	// If all searches failed, return the first error
 	if len(errors) == len(clients) && len(errors) > 0 {
@@ -145,7 +141,6 @@ func (mac *MultiAccountClient) SearchAcrossAccounts(ctx context.Context, query s
 // ListCalendarsAcrossAccounts lists calendars from all accounts
 func (mac *MultiAccountClient) ListCalendarsAcrossAccounts(ctx context.Context) (map[string][]*calendar.CalendarListEntry, error) {
 	results := make(map[string][]*calendar.CalendarListEntry)
-	var wg sync.WaitGroup
 	var mu sync.Mutex
 	errors := make([]error, 0)
 
@@ -156,26 +151,22 @@ func (mac *MultiAccountClient) ListCalendarsAcrossAccounts(ctx context.Context)
 	}
 	mac.mu.RUnlock()
 
-	for email, client := range clients {
-		wg.Add(1)
-		go func(email string, client *Client) {
-			defer wg.Done()
-
-			calendars, err := client.service.CalendarList.List().Do()
-			if err != nil {
-				mu.Lock()
-				errors = append(errors, fmt.Errorf("%s: %w", email, err))
-				mu.Unlock()
-				return
-			}
+	progress := server.NewProgressCounter(ctx, len(clients))
+	auth.ForEachAccount(clients, func(email string, client *Client) {
+		defer progress.Step("listed " + email)
 
+		calendars, err := client.service.CalendarList.List().Context(ctx).Do()
+		if err != nil {
 			mu.Lock()
-			results[email] = calendars.Items
+			errors = append(errors, fmt.Errorf("%s: %w", email, err))
 			mu.Unlock()
-		}(email, client)
-	}
+			return
+		}
 
-	wg.Wait()
+		mu.Lock()
+		results[email] = calendars.Items
+		mu.Unlock()
+	})
 
 	// If all searches failed, return the first error
 	if len(errors) == len(clients) && len(errors) > 0 {
@@ -195,5 +186,5 @@ func (mac *MultiAccountClient) CreateEventWithAccount(ctx context.Context, email
 		return nil, fmt.Errorf("no client for account %s", email)
 	}
 
-	return client.service.Events.Insert(calendarID, event).Do()
+	return client.service.Events.Insert(calendarID, event).Context(ctx).Do()
 }
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
@@ -28,148 +27,95 @@ func NewMultiAccountHandler(accountManager *auth.AccountManager, defaultClient *
 	}
 }
 
-// GetTools returns the available Calendar tools
-func (h *MultiAccountHandler) GetTools() []server.Tool {
-	// Return the same tools as the original handler
-	return []server.Tool{
-		{
+// Arguments of the multi-account Calendar tools. The input schemas are
+// generated from these structs, see server.SchemaOf.
+
+type accountArgs struct {
+	Account string `json:"account,omitempty" description:"Email address of the account to use (optional)"`
+}
+
+type accountEventsListArgs struct {
+	CalendarID string `json:"calendar_id" required:"true" description:"Calendar ID (use 'primary' for main calendar)"`
+	TimeMin    string `json:"time_min" format:"date-time" description:"Start time (RFC3339 format)"`
+	TimeMax    string `json:"time_max" format:"date-time" description:"End time (RFC3339 format)"`
+	MaxResults int64  `json:"max_results" description:"Maximum number of events to return"`
+	accountArgs
+}
+
+type accountEventCreateArgs struct {
+	CalendarID  string         `json:"calendar_id" required:"true" description:"Calendar ID (use 'primary' for main calendar)"`
+	Summary     string         `json:"summary" required:"true" description:"Event title"`
+	Description string         `json:"description" description:"Event description"`
+	Location    string         `json:"location" description:"Event location"`
+	StartTime   string         `json:"start_time" required:"true" format:"date-time" description:"Start time (RFC3339 format)"`
+	EndTime     string         `json:"end_time" required:"true" format:"date-time" description:"End time (RFC3339 format)"`
+	Attendees   emailAddresses `json:"attendees" description:"List of attendee email addresses"`
+	Reminders   []int          `json:"reminders" description:"List of reminder times in minutes"`
+	accountArgs
+}
+
+type accountEventArgs struct {
+	CalendarID string `json:"calendar_id" required:"true" description:"Calendar ID (use 'primary' for main calendar)"`
+	EventID    string `json:"event_id" required:"true" description:"Event ID"`
+	accountArgs
+}
+
+type allAccountsEventsArgs struct {
+	TimeMin    string `json:"time_min" format:"date-time" description:"Start time (RFC3339 format, defaults to today)"`
+	TimeMax    string `json:"time_max" format:"date-time" description:"End time (RFC3339 format, defaults to end of today)"`
+	MaxResults int64  `json:"max_results" description:"Maximum number of events per account"`
+}
+
+// ToolDefinitions declares the multi-account Calendar tools together with
+// their handlers
+func (h *MultiAccountHandler) ToolDefinitions() server.ToolSet {
+	return server.ToolSet{
+		server.NewTool(server.Tool{
 			Name:        "calendar_list",
 			Description: "List all accessible calendars",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"account": {
-						Type:        "string",
-						Description: "Email address of the account to use (optional)",
-					},
-				},
-			},
-		},
-		{
+			Annotations: server.ReadOnlyAnnotations(),
+		}, h.handleCalendarList),
+		server.NewTool(server.Tool{
 			Name:        "calendar_events_list",
 			Description: "List events from a calendar with optional date range",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"calendar_id": {
-						Type:        "string",
-						Description: "Calendar ID (use 'primary' for main calendar)",
-					},
-					"time_min": {
-						Type:        "string",
-						Description: "Start time (RFC3339 format)",
-					},
-					"time_max": {
-						Type:        "string",
-						Description: "End time (RFC3339 format)",
-					},
-					"max_results": {
-						Type:        "number",
-						Description: "Maximum number of events to return",
-					},
-					"account": {
-						Type:        "string",
-						Description: "Email address of the account to use (optional)",
-					},
-				},
-				Required: []string{"calendar_id"},
-			},
-		},
-		{
+			Annotations: server.ReadOnlyAnnotations(),
+		}, h.handleEventsList),
+		server.NewTool(server.Tool{
 			Name:        "calendar_event_create",
 			Description: "Create a new calendar event",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"calendar_id": {
-						Type:        "string",
-						Description: "Calendar ID (use 'primary' for main calendar)",
-					},
-					"summary": {
-						Type:        "string",
-						Description: "Event title",
-					},
-					"description": {
-						Type:        "string",
-						Description: "Event description",
-					},
-					"location": {
-						Type:        "string",
-						Description: "Event location",
-					},
-					"start_time": {
-						Type:        "string",
-						Description: "Start time (RFC3339 format)",
-					},
-					"end_time": {
-						Type:        "string",
-						Description: "End time (RFC3339 format)",
-					},
-					"attendees": {
-						Type:        "array",
-						Description: "List of attendee email addresses",
-						Items: &server.Property{
-							Type: "string",
-						},
-					},
-					"reminders": {
-						Type:        "array",
-						Description: "List of reminder times in minutes",
-						Items: &server.Property{
-							Type: "number",
-						},
-					},
-					"account": {
-						Type:        "string",
-						Description: "Email address of the account to use (optional)",
-					},
-				},
-				Required: []string{"calendar_id", "summary", "start_time", "end_time"},
-			},
-		},
-		{
+			Annotations: server.AdditiveAnnotations(),
+		}, h.handleEventCreate),
+		server.NewTool(server.Tool{
+			Name:        "calendar_event_delete",
+			Description: "Delete a calendar event",
+			Annotations: server.DestructiveAnnotations(),
+		}, h.handleEventDelete),
+		server.NewTool(server.Tool{
 			Name:        "calendar_events_list_all_accounts",
 			Description: "List events from all authenticated accounts for today or specified date range",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"time_min": {
-						Type:        "string",
-						Description: "Start time (RFC3339 format, defaults to today)",
-					},
-					"time_max": {
-						Type:        "string",
-						Description: "End time (RFC3339 format, defaults to end of today)",
-					},
-					"max_results": {
-						Type:        "number",
-						Description: "Maximum number of events per account",
-					},
-				},
-			},
-		},
+			Annotations: server.ReadOnlyAnnotations(),
+		}, h.handleEventsListAllAccounts),
 	}
 }
 
+// GetTools returns the available Calendar tools
+func (h *MultiAccountHandler) GetTools() []server.Tool {
+	return h.ToolDefinitions().Tools()
+}
+
 // HandleToolCall handles a tool call
 func (h *MultiAccountHandler) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
-	switch name {
-	case "calendar_list":
-		return h.handleCalendarList(ctx, arguments)
-	case "calendar_events_list":
-		return h.handleEventsList(ctx, arguments)
-	case "calendar_event_create":
-		return h.handleEventCreate(ctx, arguments)
-	case "calendar_events_list_all_accounts":
-		return h.handleEventsListAllAccounts(ctx, arguments)
-	default:
-		// Fall back to default client for other operations
-		if h.defaultClient != nil {
-			origHandler := NewHandler(h.defaultClient)
-			return origHandler.HandleToolCall(ctx, name, arguments)
-		}
-		return nil, fmt.Errorf("unknown tool: %s", name)
+	definitions := h.ToolDefinitions()
+	if _, ok := definitions.Lookup(name); ok {
+		return definitions.Call(ctx, name, arguments)
+	}
+
+	// Fall back to default client for other operations
+	if h.defaultClient != nil {
+		origHandler := NewHandler(h.defaultClient)
+		return origHandler.HandleToolCall(ctx, name, arguments)
 	}
+	return nil, fmt.Errorf("unknown tool: %s", name)
 }
 
 // getClientForAccount gets or creates a calendar client for the specified account
@@ -205,20 +151,13 @@ func (h *MultiAccountHandler) getClientForAccount(ctx context.Context, email str
 }
 
 // handleCalendarList lists calendars for the specified account
-func (h *MultiAccountHandler) handleCalendarList(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
-	var args struct {
-		Account string `json:"account"`
-	}
-	if err := json.Unmarshal(arguments, &args); err != nil {
-		return nil, fmt.Errorf("invalid arguments: %w", err)
-	}
-
+func (h *MultiAccountHandler) handleCalendarList(ctx context.Context, args accountArgs) (interface{}, error) {
 	client, err := h.getClientForAccount(ctx, args.Account)
 	if err != nil {
 		return nil, err
 	}
 
-	calendars, err := client.ListCalendars()
+	calendars, err := client.ListCalendars(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list calendars: %w", err)
 	}
@@ -229,18 +168,7 @@ func (h *MultiAccountHandler) handleCalendarList(ctx context.Context, arguments
 }
 
 // handleEventsList lists events for the specified account
-func (h *MultiAccountHandler) handleEventsList(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
-	var args struct {
-		CalendarID string `json:"calendar_id"`
-		TimeMin    string `json:"time_min"`
-		TimeMax    string `json:"time_max"`
-		MaxResults int64  `json:"max_results"`
-		Account    string `json:"account"`
-	}
-	if err := json.Unmarshal(arguments, &args); err != nil {
-		return nil, fmt.Errorf("invalid arguments: %w", err)
-	}
-
+func (h *MultiAccountHandler) handleEventsList(ctx context.Context, args accountEventsListArgs) (interface{}, error) {
 	// Determine which account to use based on calendar_id
 	accountEmail := args.Account
 	if accountEmail == "" && strings.Contains(args.CalendarID, "@") {
@@ -274,7 +202,7 @@ func (h *MultiAccountHandler) handleEventsList(ctx context.Context, arguments js
 		timeMax, _ = time.Parse(time.RFC3339, args.TimeMax)
 	}
 
-	events, err := client.ListEvents(calendarID, timeMin, timeMax, args.MaxResults)
+	events, err := client.ListEvents(ctx, calendarID, timeMin, timeMax, args.MaxResults)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list events: %w", err)
 	}
@@ -286,22 +214,7 @@ func (h *MultiAccountHandler) handleEventsList(ctx context.Context, arguments js
 }
 
 // handleEventCreate creates an event
-func (h *MultiAccountHandler) handleEventCreate(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
-	var args struct {
-		CalendarID  string   `json:"calendar_id"`
-		Summary     string   `json:"summary"`
-		Description string   `json:"description"`
-		Location    string   `json:"location"`
-		StartTime   string   `json:"start_time"`
-		EndTime     string   `json:"end_time"`
-		Attendees   []string `json:"attendees"`
-		Reminders   []int    `json:"reminders"`
-		Account     string   `json:"account"`
-	}
-	if err := json.Unmarshal(arguments, &args); err != nil {
-		return nil, fmt.Errorf("invalid arguments: %w", err)
-	}
-
+func (h *MultiAccountHandler) handleEventCreate(ctx context.Context, args accountEventCreateArgs) (interface{}, error) {
 	client, err := h.getClientForAccount(ctx, args.Account)
 	if err != nil {
 		return nil, err
@@ -346,7 +259,7 @@ func (h *MultiAccountHandler) handleEventCreate(ctx context.Context, arguments j
 		}
 	}
 
-	createdEvent, err := client.CreateEvent(args.CalendarID, event)
+	createdEvent, err := client.CreateEvent(ctx, args.CalendarID, event)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create event: %w", err)
 	}
@@ -357,17 +270,21 @@ func (h *MultiAccountHandler) handleEventCreate(ctx context.Context, arguments j
 	}, nil
 }
 
-// handleEventsListAllAccounts lists events from all accounts
-func (h *MultiAccountHandler) handleEventsListAllAccounts(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
-	var args struct {
-		TimeMin    string `json:"time_min"`
-		TimeMax    string `json:"time_max"`
-		MaxResults int64  `json:"max_results"`
+// handleEventDelete deletes an event for the specified account
+func (h *MultiAccountHandler) handleEventDelete(ctx context.Context, args accountEventArgs) (interface{}, error) {
+	client, err := h.getClientForAccount(ctx, args.Account)
+	if err != nil {
+		return nil, err
 	}
-	if err := json.Unmarshal(arguments, &args); err != nil {
-		return nil, fmt.Errorf("invalid arguments: %w", err)
+
+	if err := client.DeleteEvent(ctx, args.CalendarID, args.EventID); err != nil {
+		return nil, err
 	}
+	return map[string]string{"status": "deleted", "event_id": args.EventID}, nil
+}
 
+// handleEventsListAllAccounts lists events from all accounts
+func (h *MultiAccountHandler) handleEventsListAllAccounts(ctx context.Context, args allAccountsEventsArgs) (interface{}, error) {
 	// Default to today if no time range specified
 	if args.TimeMin == "" {
 		now := time.Now()
@@ -392,10 +309,13 @@ func (h *MultiAccountHandler) handleEventsListAllAccounts(ctx context.Context, a
 	// Collect events from all accounts
 	allEvents := make(map[string]interface{})
 
-	for _, account := range accounts {
+	total := float64(len(accounts))
+	for i, account := range accounts {
+		server.ReportProgress(ctx, float64(i), total, "listing events of "+account.Email)
+
 		client, err := h.getClientForAccount(ctx, account.Email)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to get client for %s: %v\n", account.Email, err)
+			logger.WarnContext(ctx, "skipping account", "account", account.Email, "error", err)
 			continue
 		}
 
@@ -404,9 +324,9 @@ func (h *MultiAccountHandler) handleEventsListAllAccounts(ctx context.Context, a
 		timeMax, _ := time.Parse(time.RFC3339, args.TimeMax)
 
 		// Get events from primary calendar
-		events, err := client.ListEvents("primary", timeMin, timeMax, args.MaxResults)
+		events, err := client.ListEvents(ctx, "primary", timeMin, timeMax, args.MaxResults)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to list events for %s: %v\n", account.Email, err)
+			logger.WarnContext(ctx, "failed to list events", "account", account.Email, "error", err)
 			continue
 		}
 
@@ -417,6 +337,7 @@ func (h *MultiAccountHandler) handleEventsListAllAccounts(ctx context.Context, a
 			}
 		}
 	}
+	server.ReportProgress(ctx, total, total, "listed events of all accounts")
 
 	return map[string]interface{}{
 		"accounts": allEvents,
@@ -445,3 +366,38 @@ func (h *MultiAccountHandler) HandleResourceCall(ctx context.Context, uri string
 	}
 	return nil, fmt.Errorf("no default client available")
 }
+
+// eventURITemplate addresses a single event within a calendar
+const eventURITemplate = "calendar://event/{calendarId}/{eventId}{?account}"
+
+// GetResourceTemplates returns the parameterized Calendar resources
+func (h *MultiAccountHandler) GetResourceTemplates() []server.ResourceTemplate {
+	return []server.ResourceTemplate{
+		{
+			URITemplate: eventURITemplate,
+			Name:        "Calendar Event",
+			Description: "A calendar event by calendar ID and event ID (use 'primary' for the main calendar); add ?account=<email> to choose the account",
+			MimeType:    "application/json",
+		},
+	}
+}
+
+// HandleResourceTemplateCall reads a parameterized Calendar resource
+func (h *MultiAccountHandler) HandleResourceTemplateCall(ctx context.Context, uri string, uriTemplate string, variables map[string]string) (interface{}, error) {
+	switch uriTemplate {
+	case eventURITemplate:
+		client, err := h.getClientForAccount(ctx, variables["account"])
+		if err != nil {
+			return nil, err
+		}
+
+		event, err := client.GetEvent(ctx, variables["calendarId"], variables["eventId"])
+		if err != nil {
+			return nil, err
+		}
+		return formatEvent(event), nil
+
+	default:
+		return nil, fmt.Errorf("unknown calendar resource: %s", uri)
+	}
+}
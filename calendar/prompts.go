@@ -0,0 +1,144 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.ngs.io/google-mcp-server/server"
+	"google.golang.org/api/calendar/v3"
+)
+
+// nextMeetingWindow is how far ahead the meeting preparation prompt looks
+const nextMeetingWindow = 7 * 24 * time.Hour
+
+// GetPrompts returns the Calendar prompt templates
+func (h *MultiAccountHandler) GetPrompts() []server.Prompt {
+	return []server.Prompt{
+		{
+			Name:        "calendar_prepare_next_meeting",
+			Description: "Prepare for my next meeting: agenda, attendees, related email and documents",
+			Arguments: []server.PromptArgument{
+				{
+					Name:        "account",
+					Description: "Email address of the account whose calendar to use (optional)",
+				},
+				{
+					Name:        "calendar_id",
+					Description: "Calendar to look at (default: primary)",
+				},
+			},
+		},
+	}
+}
+
+// GetPrompt renders a Calendar prompt template
+func (h *MultiAccountHandler) GetPrompt(ctx context.Context, name string, arguments map[string]string) (*server.PromptResult, error) {
+	switch name {
+	case "calendar_prepare_next_meeting":
+		return h.prepareNextMeetingPrompt(ctx, arguments), nil
+	default:
+		return nil, fmt.Errorf("unknown prompt: %s", name)
+	}
+}
+
+// prepareNextMeetingPrompt looks up the next meeting and embeds its details
+// in the prompt. If the lookup fails, the model is asked to find it instead.
+func (h *MultiAccountHandler) prepareNextMeetingPrompt(ctx context.Context, arguments map[string]string) *server.PromptResult {
+	account := strings.TrimSpace(arguments["account"])
+	calendarID := strings.TrimSpace(arguments["calendar_id"])
+	if calendarID == "" {
+		calendarID = "primary"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Help me prepare for my next meeting.\n\n")
+
+	event := h.findNextMeeting(ctx, account, calendarID)
+	if event != nil {
+		sb.WriteString("Here is the meeting:\n")
+		sb.WriteString(describeEvent(event))
+		sb.WriteString("\n")
+	} else {
+		fmt.Fprintf(&sb, "1. Find it by calling calendar_events_list with calendar_id %q, time_min set to now and max_results 5", calendarID)
+		if account != "" {
+			fmt.Fprintf(&sb, " and account %q", account)
+		}
+		sb.WriteString(". Skip all-day events and events I have declined.\n")
+	}
+
+	sb.WriteString("Then:\n")
+	sb.WriteString("- Search recent email with gmail_messages_list for threads mentioning the meeting title or exchanged with the attendees, and summarize what is relevant.\n")
+	sb.WriteString("- Look for related documents with drive_files_search using keywords from the title and description.\n")
+	sb.WriteString("- Produce a short briefing: purpose, attendees and their context, open questions, and a suggested agenda.\n")
+	sb.WriteString("- List anything I should read or send before the meeting starts.")
+
+	return server.UserPrompt("Briefing for the next meeting on the calendar", sb.String())
+}
+
+// findNextMeeting returns the next timed event the user has not declined, or nil
+func (h *MultiAccountHandler) findNextMeeting(ctx context.Context, account, calendarID string) *calendar.Event {
+	client, err := h.getClientForAccount(ctx, account)
+	if err != nil {
+		return nil
+	}
+
+	now := time.Now()
+	events, err := client.ListEvents(ctx, calendarID, now, now.Add(nextMeetingWindow), 10)
+	if err != nil {
+		return nil
+	}
+
+	for _, event := range events {
+		if event.Start == nil || event.Start.DateTime == "" {
+			continue // all-day event
+		}
+		if declinedBySelf(event) {
+			continue
+		}
+		return event
+	}
+	return nil
+}
+
+// declinedBySelf reports whether the calendar owner declined the event
+func declinedBySelf(event *calendar.Event) bool {
+	for _, attendee := range event.Attendees {
+		if attendee.Self && attendee.ResponseStatus == "declined" {
+			return true
+		}
+	}
+	return false
+}
+
+// describeEvent renders the fields of an event that matter for preparation
+func describeEvent(event *calendar.Event) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "- Title: %s\n", event.Summary)
+	fmt.Fprintf(&sb, "- Starts: %s\n", event.Start.DateTime)
+	if event.End != nil && event.End.DateTime != "" {
+		fmt.Fprintf(&sb, "- Ends: %s\n", event.End.DateTime)
+	}
+	if event.Location != "" {
+		fmt.Fprintf(&sb, "- Location: %s\n", event.Location)
+	}
+	if event.HangoutLink != "" {
+		fmt.Fprintf(&sb, "- Video call: %s\n", event.HangoutLink)
+	}
+	if event.Organizer != nil && event.Organizer.Email != "" {
+		fmt.Fprintf(&sb, "- Organizer: %s\n", event.Organizer.Email)
+	}
+	if len(event.Attendees) > 0 {
+		attendees := make([]string, 0, len(event.Attendees))
+		for _, attendee := range event.Attendees {
+			attendees = append(attendees, attendee.Email)
+		}
+		fmt.Fprintf(&sb, "- Attendees: %s\n", strings.Join(attendees, ", "))
+	}
+	if event.Description != "" {
+		fmt.Fprintf(&sb, "- Description: %s\n", event.Description)
+	}
+	fmt.Fprintf(&sb, "- Event ID: %s\n", event.Id)
+	return sb.String()
+}
@@ -9,17 +9,23 @@ import (
 	"go.ngs.io/google-mcp-server/server"
 )
 
+// URIs of the static Calendar resources
+const (
+	primaryEventsURI = "calendar://primary/events"
+	calendarsURI     = "calendar://calendars"
+)
+
 // GetResources returns the available Calendar resources
 func (h *Handler) GetResources() []server.Resource {
 	return []server.Resource{
 		{
-			URI:         "calendar://primary/events",
+			URI:         primaryEventsURI,
 			Name:        "Primary Calendar Events",
 			Description: "Events from the user's primary calendar",
 			MimeType:    "application/json",
 		},
 		{
-			URI:         "calendar://calendars",
+			URI:         calendarsURI,
 			Name:        "Calendar List",
 			Description: "List of all accessible calendars",
 			MimeType:    "application/json",
@@ -54,7 +60,7 @@ func (h *Handler) HandleResourceCall(ctx context.Context, uri string) (interface
 
 func (h *Handler) getPrimaryCalendarEvents(ctx context.Context) (interface{}, error) {
 	// Get events from primary calendar (no date filter, get upcoming events)
-	events, err := h.client.ListEvents("primary", time.Now(), time.Time{}, 100)
+	events, err := h.client.ListEvents(ctx, "primary", time.Now(), time.Time{}, 100)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get primary calendar events: %w", err)
 	}
@@ -73,7 +79,7 @@ func (h *Handler) getPrimaryCalendarEvents(ctx context.Context) (interface{}, er
 }
 
 func (h *Handler) getCalendarsList(ctx context.Context) (interface{}, error) {
-	calendars, err := h.client.ListCalendars()
+	calendars, err := h.client.ListCalendars(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list calendars: %w", err)
 	}
@@ -19,321 +19,125 @@ func NewHandler(client *Client) *Handler {
 	return &Handler{client: client}
 }
 
-// GetTools returns the available Calendar tools
-func (h *Handler) GetTools() []server.Tool {
-	return []server.Tool{
-		{
+// Arguments of the Calendar tools. The input schemas are generated from
+// these structs, see server.SchemaOf.
+
+type eventsListArgs struct {
+	CalendarID string `json:"calendar_id" required:"true" description:"Calendar ID (use 'primary' for main calendar)"`
+	TimeMin    string `json:"time_min" format:"date-time" description:"Start time in UTC (RFC3339 format, e.g., 2025-10-17T00:00:00Z)"`
+	TimeMax    string `json:"time_max" format:"date-time" description:"End time in UTC (RFC3339 format, e.g., 2025-10-18T00:00:00Z)"`
+	MaxResults int64  `json:"max_results" description:"Maximum number of events to return"`
+}
+
+type eventCreateArgs struct {
+	CalendarID  string         `json:"calendar_id" required:"true" description:"Calendar ID (use 'primary' for main calendar)"`
+	Summary     string         `json:"summary" required:"true" description:"Event title"`
+	Description string         `json:"description" description:"Event description"`
+	Location    string         `json:"location" description:"Event location"`
+	StartTime   string         `json:"start_time" required:"true" format:"date-time" description:"Start time in UTC (RFC3339 format, e.g., 2025-10-17T10:30:00Z for 19:30 JST)"`
+	EndTime     string         `json:"end_time" required:"true" format:"date-time" description:"End time in UTC (RFC3339 format, e.g., 2025-10-17T14:00:00Z for 23:00 JST)"`
+	Attendees   emailAddresses `json:"attendees" description:"List of attendee email addresses"`
+	Reminders   []int          `json:"reminders" description:"List of reminder times in minutes"`
+}
+
+type eventUpdateArgs struct {
+	CalendarID  string `json:"calendar_id" required:"true" description:"Calendar ID"`
+	EventID     string `json:"event_id" required:"true" description:"Event ID"`
+	Summary     string `json:"summary" description:"Event title"`
+	Description string `json:"description" description:"Event description"`
+	Location    string `json:"location" description:"Event location"`
+	StartTime   string `json:"start_time" format:"date-time" description:"Start time in UTC (RFC3339 format, e.g., 2025-10-17T10:30:00Z for 19:30 JST)"`
+	EndTime     string `json:"end_time" format:"date-time" description:"End time in UTC (RFC3339 format, e.g., 2025-10-17T14:00:00Z for 23:00 JST)"`
+}
+
+type eventArgs struct {
+	CalendarID string `json:"calendar_id" required:"true" description:"Calendar ID"`
+	EventID    string `json:"event_id" required:"true" description:"Event ID"`
+}
+
+type freeBusyArgs struct {
+	CalendarIDs []string `json:"calendar_ids" required:"true" description:"List of calendar IDs to check"`
+	TimeMin     string   `json:"time_min" required:"true" format:"date-time" description:"Start time in UTC (RFC3339 format, e.g., 2025-10-17T00:00:00Z)"`
+	TimeMax     string   `json:"time_max" required:"true" format:"date-time" description:"End time in UTC (RFC3339 format, e.g., 2025-10-18T00:00:00Z)"`
+}
+
+type eventSearchArgs struct {
+	CalendarID string `json:"calendar_id" required:"true" description:"Calendar ID"`
+	Query      string `json:"query" required:"true" description:"Search query"`
+	TimeMin    string `json:"time_min" format:"date-time" description:"Start time in UTC (RFC3339 format, e.g., 2025-10-17T00:00:00Z)"`
+	TimeMax    string `json:"time_max" format:"date-time" description:"End time in UTC (RFC3339 format, e.g., 2025-10-18T00:00:00Z)"`
+}
+
+// emailAddresses are the attendees of an event
+type emailAddresses []string
+
+// PropertySchema implements server.PropertySchemer
+func (emailAddresses) PropertySchema() server.Property {
+	return server.Property{
+		Type:  "array",
+		Items: &server.Property{Type: "string", Format: server.FormatEmail},
+	}
+}
+
+// ToolDefinitions declares the Calendar tools together with their handlers
+func (h *Handler) ToolDefinitions() server.ToolSet {
+	return server.ToolSet{
+		server.NewTool(server.Tool{
 			Name:        "calendar_list",
 			Description: "List all accessible calendars",
-			InputSchema: server.InputSchema{
-				Type:       "object",
-				Properties: map[string]server.Property{},
-			},
-		},
-		{
+			Annotations: server.ReadOnlyAnnotations(),
+		}, h.handleCalendarList),
+		server.NewTool(server.Tool{
 			Name:        "calendar_events_list",
 			Description: "List events from a calendar with optional date range",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"calendar_id": {
-						Type:        "string",
-						Description: "Calendar ID (use 'primary' for main calendar)",
-					},
-					"time_min": {
-						Type:        "string",
-						Description: "Start time in UTC (RFC3339 format, e.g., 2025-10-17T00:00:00Z)",
-					},
-					"time_max": {
-						Type:        "string",
-						Description: "End time in UTC (RFC3339 format, e.g., 2025-10-18T00:00:00Z)",
-					},
-					"max_results": {
-						Type:        "number",
-						Description: "Maximum number of events to return",
-					},
-				},
-				Required: []string{"calendar_id"},
-			},
-		},
-		{
+			Annotations: server.ReadOnlyAnnotations(),
+		}, h.handleEventsList),
+		server.NewTool(server.Tool{
 			Name:        "calendar_event_create",
 			Description: "Create a new calendar event",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"calendar_id": {
-						Type:        "string",
-						Description: "Calendar ID (use 'primary' for main calendar)",
-					},
-					"summary": {
-						Type:        "string",
-						Description: "Event title",
-					},
-					"description": {
-						Type:        "string",
-						Description: "Event description",
-					},
-					"location": {
-						Type:        "string",
-						Description: "Event location",
-					},
-					"start_time": {
-						Type:        "string",
-						Description: "Start time in UTC (RFC3339 format, e.g., 2025-10-17T10:30:00Z for 19:30 JST)",
-					},
-					"end_time": {
-						Type:        "string",
-						Description: "End time in UTC (RFC3339 format, e.g., 2025-10-17T14:00:00Z for 23:00 JST)",
-					},
-					"attendees": {
-						Type:        "array",
-						Description: "List of attendee email addresses",
-						Items: &server.Property{
-							Type: "string",
-						},
-					},
-					"reminders": {
-						Type:        "array",
-						Description: "List of reminder times in minutes",
-						Items: &server.Property{
-							Type: "number",
-						},
-					},
-				},
-				Required: []string{"calendar_id", "summary", "start_time", "end_time"},
-			},
-		},
-		{
+			Annotations: server.AdditiveAnnotations(),
+		}, h.handleEventCreate),
+		server.NewTool(server.Tool{
 			Name:        "calendar_event_update",
 			Description: "Update an existing calendar event",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"calendar_id": {
-						Type:        "string",
-						Description: "Calendar ID",
-					},
-					"event_id": {
-						Type:        "string",
-						Description: "Event ID",
-					},
-					"summary": {
-						Type:        "string",
-						Description: "Event title",
-					},
-					"description": {
-						Type:        "string",
-						Description: "Event description",
-					},
-					"location": {
-						Type:        "string",
-						Description: "Event location",
-					},
-					"start_time": {
-						Type:        "string",
-						Description: "Start time in UTC (RFC3339 format, e.g., 2025-10-17T10:30:00Z for 19:30 JST)",
-					},
-					"end_time": {
-						Type:        "string",
-						Description: "End time in UTC (RFC3339 format, e.g., 2025-10-17T14:00:00Z for 23:00 JST)",
-					},
-				},
-				Required: []string{"calendar_id", "event_id"},
-			},
-		},
-		{
+			Annotations: server.DestructiveAnnotations(),
+		}, h.handleEventUpdate),
+		server.NewTool(server.Tool{
 			Name:        "calendar_event_delete",
 			Description: "Delete a calendar event",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"calendar_id": {
-						Type:        "string",
-						Description: "Calendar ID",
-					},
-					"event_id": {
-						Type:        "string",
-						Description: "Event ID",
-					},
-				},
-				Required: []string{"calendar_id", "event_id"},
-			},
-		},
-		{
+			Annotations: server.DestructiveAnnotations(),
+		}, h.handleEventDelete),
+		server.NewTool(server.Tool{
 			Name:        "calendar_event_get",
 			Description: "Get details of a specific event",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"calendar_id": {
-						Type:        "string",
-						Description: "Calendar ID",
-					},
-					"event_id": {
-						Type:        "string",
-						Description: "Event ID",
-					},
-				},
-				Required: []string{"calendar_id", "event_id"},
-			},
-		},
-		{
+			Annotations: server.ReadOnlyAnnotations(),
+		}, h.handleEventGet),
+		server.NewTool(server.Tool{
 			Name:        "calendar_freebusy_query",
 			Description: "Query free/busy information for calendars",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"calendar_ids": {
-						Type:        "array",
-						Description: "List of calendar IDs to check",
-						Items: &server.Property{
-							Type: "string",
-						},
-					},
-					"time_min": {
-						Type:        "string",
-						Description: "Start time in UTC (RFC3339 format, e.g., 2025-10-17T00:00:00Z)",
-					},
-					"time_max": {
-						Type:        "string",
-						Description: "End time in UTC (RFC3339 format, e.g., 2025-10-18T00:00:00Z)",
-					},
-				},
-				Required: []string{"calendar_ids", "time_min", "time_max"},
-			},
-		},
-		{
+			Annotations: server.ReadOnlyAnnotations(),
+		}, h.handleFreeBusyQuery),
+		server.NewTool(server.Tool{
 			Name:        "calendar_event_search",
 			Description: "Search for events in a calendar",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"calendar_id": {
-						Type:        "string",
-						Description: "Calendar ID",
-					},
-					"query": {
-						Type:        "string",
-						Description: "Search query",
-					},
-					"time_min": {
-						Type:        "string",
-						Description: "Start time in UTC (RFC3339 format, e.g., 2025-10-17T00:00:00Z)",
-					},
-					"time_max": {
-						Type:        "string",
-						Description: "End time in UTC (RFC3339 format, e.g., 2025-10-18T00:00:00Z)",
-					},
-				},
-				Required: []string{"calendar_id", "query"},
-			},
-		},
+			Annotations: server.ReadOnlyAnnotations(),
+		}, h.handleEventSearch),
 	}
 }
 
+// GetTools returns the available Calendar tools
+func (h *Handler) GetTools() []server.Tool {
+	return h.ToolDefinitions().Tools()
+}
+
 // HandleToolCall handles a tool call for Calendar service
 func (h *Handler) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
-	switch name {
-	case "calendar_list":
-		return h.handleCalendarList(ctx)
-
-	case "calendar_events_list":
-		var args struct {
-			CalendarID string  `json:"calendar_id"`
-			TimeMin    string  `json:"time_min"`
-			TimeMax    string  `json:"time_max"`
-			MaxResults float64 `json:"max_results"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleEventsList(ctx, args.CalendarID, args.TimeMin, args.TimeMax, int64(args.MaxResults))
-
-	case "calendar_event_create":
-		var args struct {
-			CalendarID  string   `json:"calendar_id"`
-			Summary     string   `json:"summary"`
-			Description string   `json:"description"`
-			Location    string   `json:"location"`
-			StartTime   string   `json:"start_time"`
-			EndTime     string   `json:"end_time"`
-			Attendees   []string `json:"attendees"`
-			Reminders   []int    `json:"reminders"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleEventCreate(ctx, args.CalendarID, args.Summary, args.Description,
-			args.Location, args.StartTime, args.EndTime, args.Attendees, args.Reminders)
-
-	case "calendar_event_update":
-		var args struct {
-			CalendarID  string `json:"calendar_id"`
-			EventID     string `json:"event_id"`
-			Summary     string `json:"summary"`
-			Description string `json:"description"`
-			Location    string `json:"location"`
-			StartTime   string `json:"start_time"`
-			EndTime     string `json:"end_time"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleEventUpdate(ctx, args.CalendarID, args.EventID, args.Summary,
-			args.Description, args.Location, args.StartTime, args.EndTime)
-
-	case "calendar_event_delete":
-		var args struct {
-			CalendarID string `json:"calendar_id"`
-			EventID    string `json:"event_id"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleEventDelete(ctx, args.CalendarID, args.EventID)
-
-	case "calendar_event_get":
-		var args struct {
-			CalendarID string `json:"calendar_id"`
-			EventID    string `json:"event_id"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleEventGet(ctx, args.CalendarID, args.EventID)
-
-	case "calendar_freebusy_query":
-		var args struct {
-			CalendarIDs []string `json:"calendar_ids"`
-			TimeMin     string   `json:"time_min"`
-			TimeMax     string   `json:"time_max"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleFreeBusyQuery(ctx, args.CalendarIDs, args.TimeMin, args.TimeMax)
-
-	case "calendar_event_search":
-		var args struct {
-			CalendarID string `json:"calendar_id"`
-			Query      string `json:"query"`
-			TimeMin    string `json:"time_min"`
-			TimeMax    string `json:"time_max"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleEventSearch(ctx, args.CalendarID, args.Query, args.TimeMin, args.TimeMax)
-
-	default:
-		return nil, fmt.Errorf("unknown tool: %s", name)
-	}
+	return h.ToolDefinitions().Call(ctx, name, arguments)
 }
 
 // Tool handlers
-func (h *Handler) handleCalendarList(ctx context.Context) (interface{}, error) {
-	calendars, err := h.client.ListCalendars()
+func (h *Handler) handleCalendarList(ctx context.Context, _ struct{}) (interface{}, error) {
+	calendars, err := h.client.ListCalendars(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -357,25 +161,25 @@ func (h *Handler) handleCalendarList(ctx context.Context) (interface{}, error) {
 	}, nil
 }
 
-func (h *Handler) handleEventsList(ctx context.Context, calendarID, timeMinStr, timeMaxStr string, maxResults int64) (interface{}, error) {
+func (h *Handler) handleEventsList(ctx context.Context, args eventsListArgs) (interface{}, error) {
 	var timeMin, timeMax time.Time
 	var err error
 
-	if timeMinStr != "" {
-		timeMin, err = parseTimeString(timeMinStr)
+	if args.TimeMin != "" {
+		timeMin, err = parseTimeString(args.TimeMin)
 		if err != nil {
 			return nil, fmt.Errorf("invalid time_min format: %w", err)
 		}
 	}
 
-	if timeMaxStr != "" {
-		timeMax, err = parseTimeString(timeMaxStr)
+	if args.TimeMax != "" {
+		timeMax, err = parseTimeString(args.TimeMax)
 		if err != nil {
 			return nil, fmt.Errorf("invalid time_max format: %w", err)
 		}
 	}
 
-	events, err := h.client.ListEvents(calendarID, timeMin, timeMax, maxResults)
+	events, err := h.client.ListEvents(ctx, args.CalendarID, timeMin, timeMax, args.MaxResults)
 	if err != nil {
 		return nil, err
 	}
@@ -391,21 +195,19 @@ func (h *Handler) handleEventsList(ctx context.Context, calendarID, timeMinStr,
 	}, nil
 }
 
-func (h *Handler) handleEventCreate(ctx context.Context, calendarID, summary, description, location,
-	startTimeStr, endTimeStr string, attendees []string, reminders []int) (interface{}, error) {
-
-	startTime, err := parseTimeString(startTimeStr)
+func (h *Handler) handleEventCreate(ctx context.Context, args eventCreateArgs) (interface{}, error) {
+	startTime, err := parseTimeString(args.StartTime)
 	if err != nil {
 		return nil, fmt.Errorf("invalid start_time format: %w", err)
 	}
 
-	endTime, err := parseTimeString(endTimeStr)
+	endTime, err := parseTimeString(args.EndTime)
 	if err != nil {
 		return nil, fmt.Errorf("invalid end_time format: %w", err)
 	}
 
-	event, err := h.client.CreateEventFromDetails(calendarID, summary, description, location,
-		startTime, endTime, attendees, reminders)
+	event, err := h.client.CreateEventFromDetails(ctx, args.CalendarID, args.Summary, args.Description, args.Location,
+		startTime, endTime, args.Attendees, args.Reminders)
 	if err != nil {
 		return nil, err
 	}
@@ -413,35 +215,33 @@ func (h *Handler) handleEventCreate(ctx context.Context, calendarID, summary, de
 	return formatEvent(event), nil
 }
 
-func (h *Handler) handleEventUpdate(ctx context.Context, calendarID, eventID, summary,
-	description, location, startTimeStr, endTimeStr string) (interface{}, error) {
-
+func (h *Handler) handleEventUpdate(ctx context.Context, args eventUpdateArgs) (interface{}, error) {
 	// Get existing event
-	event, err := h.client.GetEvent(calendarID, eventID)
+	event, err := h.client.GetEvent(ctx, args.CalendarID, args.EventID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Update fields if provided
-	if summary != "" {
-		event.Summary = summary
+	if args.Summary != "" {
+		event.Summary = args.Summary
 	}
-	if description != "" {
-		event.Description = description
+	if args.Description != "" {
+		event.Description = args.Description
 	}
-	if location != "" {
-		event.Location = location
+	if args.Location != "" {
+		event.Location = args.Location
 	}
-	if startTimeStr != "" {
-		startTime, err := parseTimeString(startTimeStr)
+	if args.StartTime != "" {
+		startTime, err := parseTimeString(args.StartTime)
 		if err != nil {
 			return nil, fmt.Errorf("invalid start_time format: %w", err)
 		}
 		event.Start.DateTime = startTime.Format(time.RFC3339)
 		event.Start.TimeZone = startTime.Location().String()
 	}
-	if endTimeStr != "" {
-		endTime, err := parseTimeString(endTimeStr)
+	if args.EndTime != "" {
+		endTime, err := parseTimeString(args.EndTime)
 		if err != nil {
 			return nil, fmt.Errorf("invalid end_time format: %w", err)
 		}
@@ -449,7 +249,7 @@ func (h *Handler) handleEventUpdate(ctx context.Context, calendarID, eventID, su
 		event.End.TimeZone = endTime.Location().String()
 	}
 
-	updated, err := h.client.UpdateEvent(calendarID, eventID, event)
+	updated, err := h.client.UpdateEvent(ctx, args.CalendarID, args.EventID, event)
 	if err != nil {
 		return nil, err
 	}
@@ -457,33 +257,33 @@ func (h *Handler) handleEventUpdate(ctx context.Context, calendarID, eventID, su
 	return formatEvent(updated), nil
 }
 
-func (h *Handler) handleEventDelete(ctx context.Context, calendarID, eventID string) (interface{}, error) {
-	if err := h.client.DeleteEvent(calendarID, eventID); err != nil {
+func (h *Handler) handleEventDelete(ctx context.Context, args eventArgs) (interface{}, error) {
+	if err := h.client.DeleteEvent(ctx, args.CalendarID, args.EventID); err != nil {
 		return nil, err
 	}
-	return map[string]string{"status": "deleted", "event_id": eventID}, nil
+	return map[string]string{"status": "deleted", "event_id": args.EventID}, nil
 }
 
-func (h *Handler) handleEventGet(ctx context.Context, calendarID, eventID string) (interface{}, error) {
-	event, err := h.client.GetEvent(calendarID, eventID)
+func (h *Handler) handleEventGet(ctx context.Context, args eventArgs) (interface{}, error) {
+	event, err := h.client.GetEvent(ctx, args.CalendarID, args.EventID)
 	if err != nil {
 		return nil, err
 	}
 	return formatEvent(event), nil
 }
 
-func (h *Handler) handleFreeBusyQuery(ctx context.Context, calendarIDs []string, timeMinStr, timeMaxStr string) (interface{}, error) {
-	timeMin, err := parseTimeString(timeMinStr)
+func (h *Handler) handleFreeBusyQuery(ctx context.Context, args freeBusyArgs) (interface{}, error) {
+	timeMin, err := parseTimeString(args.TimeMin)
 	if err != nil {
 		return nil, fmt.Errorf("invalid time_min format: %w", err)
 	}
 
-	timeMax, err := parseTimeString(timeMaxStr)
+	timeMax, err := parseTimeString(args.TimeMax)
 	if err != nil {
 		return nil, fmt.Errorf("invalid time_max format: %w", err)
 	}
 
-	response, err := h.client.QueryFreeBusy(calendarIDs, timeMin, timeMax)
+	response, err := h.client.QueryFreeBusy(ctx, args.CalendarIDs, timeMin, timeMax)
 	if err != nil {
 		return nil, err
 	}
@@ -516,25 +316,25 @@ func (h *Handler) handleFreeBusyQuery(ctx context.Context, calendarIDs []string,
 	return result, nil
 }
 
-func (h *Handler) handleEventSearch(ctx context.Context, calendarID, query, timeMinStr, timeMaxStr string) (interface{}, error) {
+func (h *Handler) handleEventSearch(ctx context.Context, args eventSearchArgs) (interface{}, error) {
 	var timeMin, timeMax time.Time
 	var err error
 
-	if timeMinStr != "" {
-		timeMin, err = parseTimeString(timeMinStr)
+	if args.TimeMin != "" {
+		timeMin, err = parseTimeString(args.TimeMin)
 		if err != nil {
 			return nil, fmt.Errorf("invalid time_min format: %w", err)
 		}
 	}
 
-	if timeMaxStr != "" {
-		timeMax, err = parseTimeString(timeMaxStr)
+	if args.TimeMax != "" {
+		timeMax, err = parseTimeString(args.TimeMax)
 		if err != nil {
 			return nil, fmt.Errorf("invalid time_max format: %w", err)
 		}
 	}
 
-	events, err := h.client.SearchEvents(calendarID, query, timeMin, timeMax)
+	events, err := h.client.SearchEvents(ctx, args.CalendarID, args.Query, timeMin, timeMax)
 	if err != nil {
 		return nil, err
 	}
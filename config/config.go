@@ -4,16 +4,70 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
 
 	"go.ngs.io/google-mcp-server/auth"
+	"go.ngs.io/google-mcp-server/logging"
 )
 
+// Supported values for GlobalConfig.Transport
+const (
+	TransportStdio = "stdio"
+	TransportHTTP  = "http"
+)
+
+// Supported values for GlobalConfig.ConfirmationFallback
+const (
+	ConfirmationAllow = "allow"
+	ConfirmationDeny  = "deny"
+)
+
+// DefaultHTTPAddr is the listen address used by the HTTP transport when none is configured
+const DefaultHTTPAddr = "127.0.0.1:8765"
+
+// DefaultResourcePollInterval is the number of seconds between checks for
+// changes to subscribed resources
+const DefaultResourcePollInterval = 60
+
+// DefaultTimeout is the number of seconds a tool call may run before it is
+// abandoned with a timeout error
+const DefaultTimeout = 300
+
+// DefaultAuditMaxSizeMB is the size in megabytes at which the audit log is
+// rotated
+const DefaultAuditMaxSizeMB = 100
+
+// DefaultMaxConcurrency is the number of tool calls, resource reads and
+// other long-running requests the server handles at the same time
+const DefaultMaxConcurrency = 10
+
 // Config represents the application configuration
 type Config struct {
 	OAuth    auth.OAuthConfig `json:"oauth"`
 	Services ServicesConfig   `json:"services"`
 	Global   GlobalConfig     `json:"global"`
+	Prompts  []PromptConfig   `json:"prompts,omitempty"`
+}
+
+// PromptConfig defines a user prompt template served via prompts/list and prompts/get.
+// Template uses Go text/template syntax and receives the arguments as a map,
+// e.g. "Summarize the emails from {{.sender}}".
+type PromptConfig struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Arguments   []PromptArgumentConfig `json:"arguments,omitempty"`
+	Template    string                 `json:"template"`
+}
+
+// PromptArgumentConfig describes an argument of a user-defined prompt
+type PromptArgumentConfig struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
 }
 
 // ServicesConfig represents configuration for all services
@@ -31,6 +85,8 @@ type ServicesConfig struct {
 // AccountsConfig represents Accounts service configuration
 type AccountsConfig struct {
 	Enabled bool `json:"enabled"`
+
+	ToolPolicy
 }
 
 // CalendarConfig represents Calendar service configuration
@@ -39,6 +95,8 @@ type CalendarConfig struct {
 	DefaultCalendar string `json:"default_calendar,omitempty"`
 	ReminderMinutes int    `json:"reminder_minutes,omitempty"`
 	TimeZone        string `json:"time_zone,omitempty"`
+
+	ToolPolicy
 }
 
 // DriveConfig represents Drive service configuration
@@ -47,6 +105,8 @@ type DriveConfig struct {
 	DefaultFolder string `json:"default_folder,omitempty"`
 	ChunkSize     int    `json:"chunk_size,omitempty"`
 	MaxRetries    int    `json:"max_retries,omitempty"`
+
+	ToolPolicy
 }
 
 // GmailConfig represents Gmail service configuration
@@ -56,6 +116,8 @@ type GmailConfig struct {
 	DefaultLabels []string `json:"default_labels,omitempty"`
 	Signature     string   `json:"signature,omitempty"`
 	MaxResults    int      `json:"max_results,omitempty"`
+
+	ToolPolicy
 }
 
 // SheetsConfig represents Sheets service configuration
@@ -65,6 +127,8 @@ type SheetsConfig struct {
 	BatchSize    int    `json:"batch_size,omitempty"`
 	NumberFormat string `json:"number_format,omitempty"`
 	DateFormat   string `json:"date_format,omitempty"`
+
+	ToolPolicy
 }
 
 // DocsConfig represents Docs service configuration
@@ -72,6 +136,8 @@ type DocsConfig struct {
 	Enabled        bool   `json:"enabled"`
 	DefaultFormat  string `json:"default_format,omitempty"`
 	TemplateFolder string `json:"template_folder,omitempty"`
+
+	ToolPolicy
 }
 
 // SlidesConfig represents Slides service configuration
@@ -80,12 +146,16 @@ type SlidesConfig struct {
 	DefaultFontSize int  `json:"default_font_size,omitempty"`
 	SlideWidth      int  `json:"slide_width,omitempty"`
 	SlideHeight     int  `json:"slide_height,omitempty"`
+
+	ToolPolicy
 }
 
 // TasksConfig represents Tasks service configuration
 type TasksConfig struct {
 	Enabled       bool   `json:"enabled"`
 	DefaultListID string `json:"default_list_id,omitempty"`
+
+	ToolPolicy
 }
 
 // GlobalConfig represents global configuration
@@ -95,6 +165,156 @@ type GlobalConfig struct {
 	RetryCount     int    `json:"retry_count,omitempty"`
 	RetryDelay     int    `json:"retry_delay,omitempty"`
 	MaxConcurrency int    `json:"max_concurrency,omitempty"`
+	Transport      string `json:"transport,omitempty"` // "stdio" (default) or "http"
+	HTTPAddr       string `json:"http_addr,omitempty"` // listen address for the HTTP transport
+
+	// AllowedHosts are the host names, besides loopback ones, that the HTTP
+	// transport answers for. Requests whose Host or Origin names another
+	// host are refused, which defeats DNS rebinding.
+	AllowedHosts []string `json:"allowed_hosts,omitempty"`
+
+	// HTTPAuthToken, when set, is the bearer token every request to the
+	// HTTP transport must send in its Authorization header. The transport
+	// refuses to listen beyond loopback, or for allowed hosts, without one.
+	HTTPAuthToken string `json:"http_auth_token,omitempty"`
+
+	// ResourcePollInterval is how often, in seconds, subscribed resources
+	// are checked for changes
+	ResourcePollInterval int `json:"resource_poll_interval,omitempty"`
+
+	// ConfirmationFallback decides what happens to destructive tool calls
+	// when the client cannot ask the user to confirm them: "allow" (default)
+	// runs them, "deny" refuses them
+	ConfirmationFallback string `json:"confirmation_fallback,omitempty"`
+
+	// ServiceTimeouts and ToolTimeouts override Timeout, in seconds, for
+	// the tools of a service and for single tools, keyed by service and
+	// tool name. A tool override wins over its service's; 0 removes the
+	// deadline.
+	ServiceTimeouts map[string]int `json:"service_timeouts,omitempty"`
+	ToolTimeouts    map[string]int `json:"tool_timeouts,omitempty"`
+
+	// Audit configures the audit log of calls to tools that change data
+	Audit AuditConfig `json:"audit,omitzero"`
+
+	// ToolPolicy restricts the tools of every service; the policy of a
+	// service in Services can restrict them further
+	ToolPolicy
+
+	// DryRun makes every call of a tool that changes data return the API
+	// requests it would send instead of sending them
+	DryRun bool `json:"dry_run,omitempty"`
+
+	// Cache configures the cache of Google API reads
+	Cache CacheConfig `json:"cache,omitzero"`
+
+	// RateLimits overrides the client-side rate limit of an API, keyed by
+	// service name ("gmail", "drive", ...); see auth.DefaultRateLimits
+	RateLimits map[string]auth.RateLimit `json:"rate_limits,omitempty"`
+
+	// MetricsAddr is the listen address of the Prometheus metrics
+	// endpoint, served at /metrics. Empty, the default, turns it off.
+	MetricsAddr string `json:"metrics_addr,omitempty"`
+}
+
+// ToolPolicy restricts the tools a server offers. Patterns are matched
+// against tool names with path.Match, e.g. "drive_*" or "*_delete".
+type ToolPolicy struct {
+	// ReadOnly offers only the tools annotated as read-only
+	ReadOnly bool `json:"read_only,omitempty"`
+
+	// AllowTools, when not empty, offers only the tools matching one of
+	// its patterns
+	AllowTools []string `json:"allow_tools,omitempty"`
+
+	// DenyTools withholds the tools matching one of its patterns, even
+	// when AllowTools matches them too
+	DenyTools []string `json:"deny_tools,omitempty"`
+}
+
+// Check reports why the policy withholds a tool, or "" when it does not.
+// readOnly tells whether the tool is annotated as read-only.
+func (p ToolPolicy) Check(tool string, readOnly bool) string {
+	if p.ReadOnly && !readOnly {
+		return "read-only mode allows only tools that do not change data"
+	}
+	for _, pattern := range p.DenyTools {
+		if matched, _ := path.Match(pattern, tool); matched {
+			return fmt.Sprintf("denied by pattern %q", pattern)
+		}
+	}
+	if len(p.AllowTools) == 0 {
+		return ""
+	}
+	for _, pattern := range p.AllowTools {
+		if matched, _ := path.Match(pattern, tool); matched {
+			return ""
+		}
+	}
+	return "not matched by any allowed pattern"
+}
+
+// validate reports malformed patterns
+func (p ToolPolicy) validate() error {
+	for _, patterns := range [][]string{p.AllowTools, p.DenyTools} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Policy returns the tool policy configured in the section of a service,
+// keyed by service name ("gmail", "drive", ...)
+func (s *ServicesConfig) Policy(service string) ToolPolicy {
+	if policy := s.policies()[service]; policy != nil {
+		return *policy
+	}
+	return ToolPolicy{}
+}
+
+// policies returns the tool policy of each service section
+func (s *ServicesConfig) policies() map[string]*ToolPolicy {
+	return map[string]*ToolPolicy{
+		"accounts": &s.Accounts.ToolPolicy,
+		"calendar": &s.Calendar.ToolPolicy,
+		"drive":    &s.Drive.ToolPolicy,
+		"gmail":    &s.Gmail.ToolPolicy,
+		"sheets":   &s.Sheets.ToolPolicy,
+		"docs":     &s.Docs.ToolPolicy,
+		"slides":   &s.Slides.ToolPolicy,
+		"tasks":    &s.Tasks.ToolPolicy,
+	}
+}
+
+// CacheConfig configures the cache of Google API reads
+type CacheConfig struct {
+	// Disabled sends every read to Google
+	Disabled bool `json:"disabled,omitempty"`
+
+	// MaxEntries is the number of responses kept; the least recently
+	// used are dropped first
+	MaxEntries int `json:"max_entries,omitempty"`
+
+	// TTLs overrides how long, in seconds, the responses of an endpoint
+	// are reused, keyed by endpoint ("drive.files", "tasks.tasks", ...);
+	// 0 turns caching off for the endpoint. See auth.DefaultCacheTTLs.
+	TTLs map[string]int `json:"ttls,omitempty"`
+}
+
+// AuditConfig configures the audit log: one JSON line for each call of a
+// tool that is not read-only, chained by hashes so that edits are detected
+type AuditConfig struct {
+	// Path is the file records are appended to; empty disables the log
+	Path string `json:"path,omitempty"`
+
+	// MaxSizeMB is the size at which the file is rotated
+	MaxSizeMB int `json:"max_size_mb,omitempty"`
+
+	// RotateDaily starts a new file on each day (UTC) as well
+	RotateDaily bool `json:"rotate_daily,omitempty"`
 }
 
 // Load loads configuration from various sources
@@ -112,11 +332,15 @@ func Load() (*Config, error) {
 			Tasks:    TasksConfig{Enabled: true},
 		},
 		Global: GlobalConfig{
-			LogLevel:       "info",
-			Timeout:        300,
-			RetryCount:     3,
-			RetryDelay:     1000,
-			MaxConcurrency: 10,
+			LogLevel:             "info",
+			Timeout:              DefaultTimeout,
+			RetryCount:           3,
+			RetryDelay:           1000,
+			MaxConcurrency:       DefaultMaxConcurrency,
+			Transport:            TransportStdio,
+			HTTPAddr:             DefaultHTTPAddr,
+			ResourcePollInterval: DefaultResourcePollInterval,
+			ConfirmationFallback: ConfirmationAllow,
 		},
 	}
 
@@ -189,15 +413,101 @@ func (c *Config) loadFromEnv() error {
 	if os.Getenv("DISABLE_TASKS") == "true" {
 		c.Services.Tasks.Enabled = false
 	}
+	for service, policy := range c.Services.policies() {
+		if os.Getenv("READ_ONLY_"+strings.ToUpper(service)) == "true" {
+			policy.ReadOnly = true
+		}
+	}
 
 	// Global settings
 	if logLevel := os.Getenv("LOG_LEVEL"); logLevel != "" {
 		c.Global.LogLevel = logLevel
 	}
+	if transport := os.Getenv("MCP_TRANSPORT"); transport != "" {
+		c.Global.Transport = transport
+	}
+	if httpAddr := os.Getenv("MCP_HTTP_ADDR"); httpAddr != "" {
+		c.Global.HTTPAddr = httpAddr
+	}
+	if hosts := os.Getenv("MCP_ALLOWED_HOSTS"); hosts != "" {
+		c.Global.AllowedHosts = splitPatterns(hosts)
+	}
+	if token := os.Getenv("MCP_HTTP_AUTH_TOKEN"); token != "" {
+		c.Global.HTTPAuthToken = token
+	}
+	if metricsAddr := os.Getenv("MCP_METRICS_ADDR"); metricsAddr != "" {
+		c.Global.MetricsAddr = metricsAddr
+	}
+	if interval := os.Getenv("MCP_RESOURCE_POLL_INTERVAL"); interval != "" {
+		seconds, err := strconv.Atoi(interval)
+		if err != nil {
+			return fmt.Errorf("invalid MCP_RESOURCE_POLL_INTERVAL %q: %w", interval, err)
+		}
+		c.Global.ResourcePollInterval = seconds
+	}
+	if fallback := os.Getenv("MCP_CONFIRMATION_FALLBACK"); fallback != "" {
+		c.Global.ConfirmationFallback = fallback
+	}
+	if auditLog := os.Getenv("MCP_AUDIT_LOG"); auditLog != "" {
+		c.Global.Audit.Path = auditLog
+	}
+	if readOnly := os.Getenv("MCP_READ_ONLY"); readOnly != "" {
+		enabled, err := strconv.ParseBool(readOnly)
+		if err != nil {
+			return fmt.Errorf("invalid MCP_READ_ONLY %q: %w", readOnly, err)
+		}
+		c.Global.ReadOnly = enabled
+	}
+	if disabled := os.Getenv("MCP_CACHE_DISABLED"); disabled != "" {
+		enabled, err := strconv.ParseBool(disabled)
+		if err != nil {
+			return fmt.Errorf("invalid MCP_CACHE_DISABLED %q: %w", disabled, err)
+		}
+		c.Global.Cache.Disabled = enabled
+	}
+	if dryRun := os.Getenv("MCP_DRY_RUN"); dryRun != "" {
+		enabled, err := strconv.ParseBool(dryRun)
+		if err != nil {
+			return fmt.Errorf("invalid MCP_DRY_RUN %q: %w", dryRun, err)
+		}
+		c.Global.DryRun = enabled
+	}
+	if allow := os.Getenv("MCP_ALLOW_TOOLS"); allow != "" {
+		c.Global.AllowTools = splitPatterns(allow)
+	}
+	if deny := os.Getenv("MCP_DENY_TOOLS"); deny != "" {
+		c.Global.DenyTools = splitPatterns(deny)
+	}
+	if timeout := os.Getenv("MCP_TOOL_TIMEOUT"); timeout != "" {
+		seconds, err := strconv.Atoi(timeout)
+		if err != nil {
+			return fmt.Errorf("invalid MCP_TOOL_TIMEOUT %q: %w", timeout, err)
+		}
+		c.Global.Timeout = seconds
+	}
+	if concurrency := os.Getenv("MCP_MAX_CONCURRENCY"); concurrency != "" {
+		n, err := strconv.Atoi(concurrency)
+		if err != nil {
+			return fmt.Errorf("invalid MCP_MAX_CONCURRENCY %q: %w", concurrency, err)
+		}
+		c.Global.MaxConcurrency = n
+	}
 
 	return nil
 }
 
+// splitPatterns parses a comma-separated list, such as tool name patterns
+// or host names
+func splitPatterns(list string) []string {
+	var patterns []string
+	for _, pattern := range strings.Split(list, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
 // loadFromFile loads configuration from a JSON file
 func (c *Config) loadFromFile(path string) error {
 	// Clean the path to prevent directory traversal
@@ -230,6 +540,102 @@ func (c *Config) validate() error {
 		return fmt.Errorf("at least one service must be enabled")
 	}
 
+	if c.Global.LogLevel != "" {
+		if _, err := logging.ParseLevel(c.Global.LogLevel); err != nil {
+			return err
+		}
+	}
+
+	switch c.Global.Transport {
+	case "", TransportStdio, TransportHTTP:
+	default:
+		return fmt.Errorf("unsupported transport %q (expected %q or %q)", c.Global.Transport, TransportStdio, TransportHTTP)
+	}
+
+	if c.Global.ResourcePollInterval < 0 {
+		return fmt.Errorf("resource_poll_interval must not be negative, got %d", c.Global.ResourcePollInterval)
+	}
+
+	if c.Global.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative, got %d", c.Global.Timeout)
+	}
+	for name, seconds := range c.Global.ServiceTimeouts {
+		if seconds < 0 {
+			return fmt.Errorf("service_timeouts.%s must not be negative, got %d", name, seconds)
+		}
+	}
+	for name, seconds := range c.Global.ToolTimeouts {
+		if seconds < 0 {
+			return fmt.Errorf("tool_timeouts.%s must not be negative, got %d", name, seconds)
+		}
+	}
+
+	if c.Global.Audit.MaxSizeMB < 0 {
+		return fmt.Errorf("audit max_size_mb must not be negative, got %d", c.Global.Audit.MaxSizeMB)
+	}
+
+	if c.Global.Cache.MaxEntries < 0 {
+		return fmt.Errorf("cache max_entries must not be negative, got %d", c.Global.Cache.MaxEntries)
+	}
+	for endpoint, ttl := range c.Global.Cache.TTLs {
+		if ttl < 0 {
+			return fmt.Errorf("cache ttls.%s must not be negative, got %d", endpoint, ttl)
+		}
+	}
+
+	if err := c.Global.ToolPolicy.validate(); err != nil {
+		return fmt.Errorf("global tool policy: %w", err)
+	}
+	for service, policy := range c.Services.policies() {
+		if err := policy.validate(); err != nil {
+			return fmt.Errorf("%s tool policy: %w", service, err)
+		}
+	}
+
+	if c.Global.RetryCount < 0 {
+		return fmt.Errorf("retry_count must not be negative, got %d", c.Global.RetryCount)
+	}
+	if c.Global.RetryDelay < 0 {
+		return fmt.Errorf("retry_delay must not be negative, got %d", c.Global.RetryDelay)
+	}
+	if c.Services.Drive.MaxRetries < 0 {
+		return fmt.Errorf("drive max_retries must not be negative, got %d", c.Services.Drive.MaxRetries)
+	}
+
+	for api, limit := range c.Global.RateLimits {
+		if err := limit.Validate(); err != nil {
+			return fmt.Errorf("rate_limits.%s: %w", api, err)
+		}
+	}
+
+	if c.Global.MaxConcurrency < 0 {
+		return fmt.Errorf("max_concurrency must not be negative, got %d", c.Global.MaxConcurrency)
+	}
+
+	switch c.Global.ConfirmationFallback {
+	case "", ConfirmationAllow, ConfirmationDeny:
+	default:
+		return fmt.Errorf("unsupported confirmation_fallback %q (expected %q or %q)", c.Global.ConfirmationFallback, ConfirmationAllow, ConfirmationDeny)
+	}
+
+	// User-defined prompts need a unique name and a template that parses
+	seen := make(map[string]bool)
+	for i, prompt := range c.Prompts {
+		if prompt.Name == "" {
+			return fmt.Errorf("prompt %d: name is required", i)
+		}
+		if seen[prompt.Name] {
+			return fmt.Errorf("prompt %q is defined more than once", prompt.Name)
+		}
+		seen[prompt.Name] = true
+		if prompt.Template == "" {
+			return fmt.Errorf("prompt %q: template is required", prompt.Name)
+		}
+		if _, err := template.New(prompt.Name).Parse(prompt.Template); err != nil {
+			return fmt.Errorf("prompt %q: invalid template: %w", prompt.Name, err)
+		}
+	}
+
 	return nil
 }
 
@@ -265,6 +671,32 @@ func (c *Config) setDefaults() {
 		}
 	}
 
+	// Transport defaults
+	if c.Global.Transport == "" {
+		c.Global.Transport = TransportStdio
+	}
+	if c.Global.HTTPAddr == "" {
+		c.Global.HTTPAddr = DefaultHTTPAddr
+	}
+	if c.Global.ResourcePollInterval == 0 {
+		c.Global.ResourcePollInterval = DefaultResourcePollInterval
+	}
+	if c.Global.Timeout == 0 {
+		c.Global.Timeout = DefaultTimeout
+	}
+	if c.Global.Audit.MaxSizeMB == 0 {
+		c.Global.Audit.MaxSizeMB = DefaultAuditMaxSizeMB
+	}
+	if c.Global.Cache.MaxEntries == 0 {
+		c.Global.Cache.MaxEntries = auth.DefaultCacheMaxEntries
+	}
+	if c.Global.MaxConcurrency == 0 {
+		c.Global.MaxConcurrency = DefaultMaxConcurrency
+	}
+	if c.Global.ConfirmationFallback == "" {
+		c.Global.ConfirmationFallback = ConfirmationAllow
+	}
+
 	// Calendar defaults
 	if c.Services.Calendar.Enabled {
 		if c.Services.Calendar.TimeZone == "" {
@@ -329,11 +761,15 @@ func SaveExample(path string) error {
 			},
 		},
 		Global: GlobalConfig{
-			LogLevel:       "info",
-			Timeout:        300,
-			RetryCount:     3,
-			RetryDelay:     1000,
-			MaxConcurrency: 10,
+			LogLevel:             "info",
+			Timeout:              DefaultTimeout,
+			RetryCount:           3,
+			RetryDelay:           1000,
+			MaxConcurrency:       DefaultMaxConcurrency,
+			Transport:            TransportStdio,
+			HTTPAddr:             DefaultHTTPAddr,
+			ResourcePollInterval: DefaultResourcePollInterval,
+			ConfirmationFallback: ConfirmationAllow,
 		},
 	}
 
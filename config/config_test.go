@@ -3,7 +3,10 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"go.ngs.io/google-mcp-server/auth"
 )
 
 func TestConfigDefaults(t *testing.T) {
@@ -57,6 +60,25 @@ func TestConfigDefaults(t *testing.T) {
 	if cfg.Services.Calendar.ReminderMinutes != 10 {
 		t.Errorf("Expected Calendar ReminderMinutes to be 10, got %d", cfg.Services.Calendar.ReminderMinutes)
 	}
+
+	// Test transport defaults
+	if cfg.Global.Transport != TransportStdio {
+		t.Errorf("Expected Transport to be %q, got %q", TransportStdio, cfg.Global.Transport)
+	}
+	if cfg.Global.HTTPAddr != DefaultHTTPAddr {
+		t.Errorf("Expected HTTPAddr to be %q, got %q", DefaultHTTPAddr, cfg.Global.HTTPAddr)
+	}
+	if cfg.Global.ResourcePollInterval != DefaultResourcePollInterval {
+		t.Errorf("Expected ResourcePollInterval to be %d, got %d", DefaultResourcePollInterval, cfg.Global.ResourcePollInterval)
+	}
+	if cfg.Global.MaxConcurrency != DefaultMaxConcurrency {
+		t.Errorf("Expected MaxConcurrency to be %d, got %d", DefaultMaxConcurrency, cfg.Global.MaxConcurrency)
+	}
+
+	// The metrics endpoint is off unless configured
+	if cfg.Global.MetricsAddr != "" {
+		t.Errorf("Expected MetricsAddr to be empty, got %q", cfg.Global.MetricsAddr)
+	}
 }
 
 func TestConfigValidation(t *testing.T) {
@@ -82,6 +104,160 @@ func TestConfigValidation(t *testing.T) {
 	if err != nil {
 		t.Errorf("Expected no validation error with Calendar enabled, got: %v", err)
 	}
+
+	// Test with an unknown transport
+	cfg.Global.Transport = "websocket"
+	err = cfg.validate()
+	if err == nil {
+		t.Error("Expected validation error for unsupported transport")
+	}
+	cfg.Global.Transport = TransportStdio
+
+	// Test a negative poll interval
+	cfg.Global.ResourcePollInterval = -1
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected validation error for negative resource poll interval")
+	}
+	cfg.Global.ResourcePollInterval = 0
+
+	// Test negative timeouts
+	cfg.Global.ToolTimeouts = map[string]int{"drive_file_upload": -1}
+	if err := cfg.validate(); err == nil || !strings.Contains(err.Error(), "tool_timeouts.drive_file_upload") {
+		t.Errorf("Expected validation error naming the tool timeout, got %v", err)
+	}
+	cfg.Global.ToolTimeouts = nil
+
+	// Test negative retry settings
+	cfg.Global.RetryCount = -1
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected validation error for negative retry count")
+	}
+	cfg.Global.RetryCount = 0
+	cfg.Services.Drive.MaxRetries = -1
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected validation error for negative drive max retries")
+	}
+	cfg.Services.Drive.MaxRetries = 0
+
+	// Test an invalid rate limit override
+	cfg.Global.RateLimits = map[string]auth.RateLimit{"gmail": {Rate: -5}}
+	if err := cfg.validate(); err == nil || !strings.Contains(err.Error(), "rate_limits.gmail") {
+		t.Errorf("Expected validation error naming the gmail rate limit, got %v", err)
+	}
+	cfg.Global.RateLimits = nil
+
+	// Test a negative concurrency limit
+	cfg.Global.MaxConcurrency = -1
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected validation error for negative max concurrency")
+	}
+	cfg.Global.MaxConcurrency = 0
+
+	// Test a negative audit log size
+	cfg.Global.Audit.MaxSizeMB = -1
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected validation error for negative audit log size")
+	}
+	cfg.Global.Audit.MaxSizeMB = 0
+
+	// Test a negative cache TTL
+	cfg.Global.Cache.TTLs = map[string]int{"drive.files": -1}
+	if err := cfg.validate(); err == nil || !strings.Contains(err.Error(), "drive.files") {
+		t.Errorf("Expected validation error naming the drive.files TTL, got %v", err)
+	}
+	cfg.Global.Cache.TTLs = nil
+
+	// Test malformed tool patterns
+	cfg.Services.Drive.DenyTools = []string{"drive_[files"}
+	if err := cfg.validate(); err == nil || !strings.Contains(err.Error(), "drive tool policy") {
+		t.Errorf("Expected validation error naming the drive tool policy, got %v", err)
+	}
+	cfg.Services.Drive.DenyTools = nil
+
+	// Test an unknown confirmation fallback
+	cfg.Global.ConfirmationFallback = "ask"
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected validation error for unknown confirmation fallback")
+	}
+	cfg.Global.ConfirmationFallback = ConfirmationDeny
+	if err := cfg.validate(); err != nil {
+		t.Errorf("Expected deny confirmation fallback to pass validation, got: %v", err)
+	}
+	cfg.Global.ConfirmationFallback = ""
+
+	// Test log levels
+	cfg.Global.LogLevel = "warn"
+	if err := cfg.validate(); err != nil {
+		t.Errorf("Expected warn log level to pass validation, got: %v", err)
+	}
+	cfg.Global.LogLevel = "verbose"
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected validation error for unknown log level")
+	}
+	cfg.Global.LogLevel = "info"
+
+	// Test prompt definitions
+	cfg.Prompts = []PromptConfig{{Name: "weekly", Template: "Summarize {{.topic}}"}}
+	if err := cfg.validate(); err != nil {
+		t.Errorf("Expected valid prompt to pass validation, got: %v", err)
+	}
+	cfg.Prompts = append(cfg.Prompts, PromptConfig{Name: "weekly", Template: "again"})
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected validation error for duplicate prompt name")
+	}
+	cfg.Prompts = []PromptConfig{{Name: "broken", Template: "{{.topic"}}
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected validation error for invalid prompt template")
+	}
+}
+
+func TestToolPolicyCheck(t *testing.T) {
+	policy := ToolPolicy{
+		AllowTools: []string{"drive_*", "gmail_messages_list"},
+		DenyTools:  []string{"*_delete"},
+	}
+	tests := []struct {
+		tool     string
+		readOnly bool
+		allowed  bool
+	}{
+		{"drive_files_list", true, true},
+		{"drive_file_delete", false, false},
+		{"gmail_messages_list", true, true},
+		{"gmail_send", false, false},
+	}
+	for _, tt := range tests {
+		if reason := policy.Check(tt.tool, tt.readOnly); (reason == "") != tt.allowed {
+			t.Errorf("Check(%s) = %q, expected allowed %v", tt.tool, reason, tt.allowed)
+		}
+	}
+
+	readOnly := ToolPolicy{ReadOnly: true}
+	if readOnly.Check("drive_files_list", true) != "" || readOnly.Check("drive_file_upload", false) == "" {
+		t.Error("Expected read-only mode to offer only read-only tools")
+	}
+}
+
+func TestToolPolicyFromEnv(t *testing.T) {
+	t.Setenv("MCP_READ_ONLY", "1")
+	t.Setenv("MCP_DENY_TOOLS", "gmail_send, *_delete")
+	t.Setenv("READ_ONLY_DRIVE", "true")
+
+	cfg := &Config{}
+	if err := cfg.loadFromEnv(); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Global.ReadOnly || strings.Join(cfg.Global.DenyTools, "|") != "gmail_send|*_delete" {
+		t.Errorf("Expected the global policy from the environment, got %+v", cfg.Global.ToolPolicy)
+	}
+	if !cfg.Services.Policy("drive").ReadOnly || cfg.Services.Policy("gmail").ReadOnly {
+		t.Errorf("Expected only drive to be read-only, got %+v", cfg.Services)
+	}
+
+	t.Setenv("MCP_READ_ONLY", "sometimes")
+	if err := cfg.loadFromEnv(); err == nil {
+		t.Error("Expected an error for an invalid MCP_READ_ONLY")
+	}
 }
 
 func TestSaveExample(t *testing.T) {
@@ -26,8 +26,8 @@ func NewClient(ctx context.Context, oauth *auth.OAuthClient) (*Client, error) {
 }
 
 // GetDocument gets a document by ID
-func (c *Client) GetDocument(documentID string) (*docs.Document, error) {
-	doc, err := c.service.Documents.Get(documentID).Do()
+func (c *Client) GetDocument(ctx context.Context, documentID string) (*docs.Document, error) {
+	doc, err := c.service.Documents.Get(documentID).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get document: %w", err)
 	}
@@ -35,11 +35,11 @@ func (c *Client) GetDocument(documentID string) (*docs.Document, error) {
 }
 
 // CreateDocument creates a new document
-func (c *Client) CreateDocument(title string) (*docs.Document, error) {
+func (c *Client) CreateDocument(ctx context.Context, title string) (*docs.Document, error) {
 	doc := &docs.Document{
 		Title: title,
 	}
-	created, err := c.service.Documents.Create(doc).Do()
+	created, err := c.service.Documents.Create(doc).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create document: %w", err)
 	}
@@ -47,11 +47,11 @@ func (c *Client) CreateDocument(title string) (*docs.Document, error) {
 }
 
 // BatchUpdate performs batch updates on a document
-func (c *Client) BatchUpdate(documentID string, requests []*docs.Request) (*docs.BatchUpdateDocumentResponse, error) {
+func (c *Client) BatchUpdate(ctx context.Context, documentID string, requests []*docs.Request) (*docs.BatchUpdateDocumentResponse, error) {
 	batchUpdate := &docs.BatchUpdateDocumentRequest{
 		Requests: requests,
 	}
-	response, err := c.service.Documents.BatchUpdate(documentID, batchUpdate).Do()
+	response, err := c.service.Documents.BatchUpdate(documentID, batchUpdate).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to batch update: %w", err)
 	}
@@ -59,12 +59,12 @@ func (c *Client) BatchUpdate(documentID string, requests []*docs.Request) (*docs
 }
 
 // UpdateDocument updates a document's content
-func (c *Client) UpdateDocument(documentID string, content string, mode string) (*docs.BatchUpdateDocumentResponse, error) {
+func (c *Client) UpdateDocument(ctx context.Context, documentID string, content string, mode string) (*docs.BatchUpdateDocumentResponse, error) {
 	var requests []*docs.Request
 
 	if mode == "replace" {
 		// First, get the document to find the end index
-		doc, err := c.GetDocument(documentID)
+		doc, err := c.GetDocument(ctx, documentID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get document for replacement: %w", err)
 		}
@@ -101,7 +101,7 @@ func (c *Client) UpdateDocument(documentID string, content string, mode string)
 		})
 	} else {
 		// Append mode: get the document to find where to append
-		doc, err := c.GetDocument(documentID)
+		doc, err := c.GetDocument(ctx, documentID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get document for appending: %w", err)
 		}
@@ -126,5 +126,5 @@ func (c *Client) UpdateDocument(documentID string, content string, mode string)
 		})
 	}
 
-	return c.BatchUpdate(documentID, requests)
+	return c.BatchUpdate(ctx, documentID, requests)
 }
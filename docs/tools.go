@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"go.ngs.io/google-mcp-server/server"
+	"google.golang.org/api/docs/v1"
 )
 
 // Handler implements the ServiceHandler interface for Docs
@@ -18,152 +19,97 @@ func NewHandler(client *Client) *Handler {
 	return &Handler{client: client}
 }
 
-// GetTools returns the available Docs tools
-func (h *Handler) GetTools() []server.Tool {
-	return []server.Tool{
-		{
+// Arguments of the Docs tools. The input schemas are generated from these
+// structs, see server.SchemaOf.
+
+type documentArgs struct {
+	DocumentID string `json:"document_id" required:"true" description:"Document ID"`
+}
+
+type createDocumentArgs struct {
+	Title string `json:"title" required:"true" description:"Document title"`
+}
+
+type updateDocumentArgs struct {
+	DocumentID string `json:"document_id" required:"true" description:"Document ID"`
+	Content    string `json:"content" required:"true" description:"Text content to add to the document"`
+	Mode       string `json:"mode" description:"Update mode: 'append' (default) or 'replace'"`
+}
+
+// ToolDefinitions declares the Docs tools together with their handlers
+func (h *Handler) ToolDefinitions() server.ToolSet {
+	return server.ToolSet{
+		server.NewTool(server.Tool{
 			Name:        "docs_document_get",
 			Description: "Get document content",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"document_id": {
-						Type:        "string",
-						Description: "Document ID",
-					},
-				},
-				Required: []string{"document_id"},
-			},
-		},
-		{
+			Annotations: server.ReadOnlyAnnotations(),
+		}, h.handleDocumentGet),
+		server.NewTool(server.Tool{
 			Name:        "docs_document_create",
 			Description: "Create a new plain text document (for Markdown use drive_markdown_upload instead)",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"title": {
-						Type:        "string",
-						Description: "Document title",
-					},
-				},
-				Required: []string{"title"},
-			},
-		},
-		{
+			Annotations: server.AdditiveAnnotations(),
+		}, h.handleDocumentCreate),
+		server.NewTool(server.Tool{
 			Name:        "docs_document_update",
 			Description: "Update plain text document content - append or replace (for Markdown use drive_markdown_replace)",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"document_id": {
-						Type:        "string",
-						Description: "Document ID",
-					},
-					"content": {
-						Type:        "string",
-						Description: "Text content to add to the document",
-					},
-					"mode": {
-						Type:        "string",
-						Description: "Update mode: 'append' (default) or 'replace'",
-					},
-				},
-				Required: []string{"document_id", "content"},
-			},
-		},
+			Annotations: server.DestructiveAnnotations().NotIdempotent(),
+		}, h.handleDocumentUpdate),
 	}
 }
 
+// GetTools returns the available Docs tools
+func (h *Handler) GetTools() []server.Tool {
+	return h.ToolDefinitions().Tools()
+}
+
 // HandleToolCall handles a tool call for Docs service
 func (h *Handler) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
-	switch name {
-	case "docs_document_get":
-		var args struct {
-			DocumentID string `json:"document_id"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		doc, err := h.client.GetDocument(args.DocumentID)
-		if err != nil {
-			return nil, err
-		}
-
-		// Format document for response
-		result := map[string]interface{}{
-			"documentId": doc.DocumentId,
-			"title":      doc.Title,
-		}
-
-		// Extract text content from body
-		if doc.Body != nil && doc.Body.Content != nil {
-			var textContent string
-			for _, element := range doc.Body.Content {
-				if element.Paragraph != nil {
-					for _, elem := range element.Paragraph.Elements {
-						if elem.TextRun != nil {
-							textContent += elem.TextRun.Content
-						}
-					}
-				}
-			}
-			result["content"] = textContent
-		}
-
-		return result, nil
+	return h.ToolDefinitions().Call(ctx, name, arguments)
+}
 
-	case "docs_document_create":
-		var args struct {
-			Title string `json:"title"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		doc, err := h.client.CreateDocument(args.Title)
-		if err != nil {
-			return nil, err
-		}
+func (h *Handler) handleDocumentGet(ctx context.Context, args documentArgs) (interface{}, error) {
+	doc, err := h.client.GetDocument(ctx, args.DocumentID)
+	if err != nil {
+		return nil, err
+	}
 
-		// Format created document response
-		result := map[string]interface{}{
-			"documentId": doc.DocumentId,
-			"title":      doc.Title,
-			"revisionId": doc.RevisionId,
-		}
-		return result, nil
+	return formatDocument(doc), nil
+}
 
-	case "docs_document_update":
-		var args struct {
-			DocumentID string `json:"document_id"`
-			Content    string `json:"content"`
-			Mode       string `json:"mode"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
+func (h *Handler) handleDocumentCreate(ctx context.Context, args createDocumentArgs) (interface{}, error) {
+	doc, err := h.client.CreateDocument(ctx, args.Title)
+	if err != nil {
+		return nil, err
+	}
 
-		// Default to append mode
-		if args.Mode == "" {
-			args.Mode = "append"
-		}
+	// Format created document response
+	result := map[string]interface{}{
+		"documentId": doc.DocumentId,
+		"title":      doc.Title,
+		"revisionId": doc.RevisionId,
+	}
+	return result, nil
+}
 
-		// Update the document
-		response, err := h.client.UpdateDocument(args.DocumentID, args.Content, args.Mode)
-		if err != nil {
-			return nil, err
-		}
+func (h *Handler) handleDocumentUpdate(ctx context.Context, args updateDocumentArgs) (interface{}, error) {
+	// Default to append mode
+	if args.Mode == "" {
+		args.Mode = "append"
+	}
 
-		// Format response
-		result := map[string]interface{}{
-			"documentId": response.DocumentId,
-			"replies":    len(response.Replies),
-			"success":    true,
-		}
-		return result, nil
+	// Update the document
+	response, err := h.client.UpdateDocument(ctx, args.DocumentID, args.Content, args.Mode)
+	if err != nil {
+		return nil, err
+	}
 
-	default:
-		return nil, fmt.Errorf("unknown tool: %s", name)
+	// Format response
+	result := map[string]interface{}{
+		"documentId": response.DocumentId,
+		"replies":    len(response.Replies),
+		"success":    true,
 	}
+	return result, nil
 }
 
 // GetResources returns the available Docs resources
@@ -175,3 +121,58 @@ func (h *Handler) GetResources() []server.Resource {
 func (h *Handler) HandleResourceCall(ctx context.Context, uri string) (interface{}, error) {
 	return nil, fmt.Errorf("no resources available for docs")
 }
+
+// documentURITemplate addresses a single document by ID
+const documentURITemplate = "docs://document/{id}"
+
+// GetResourceTemplates returns the parameterized Docs resources
+func (h *Handler) GetResourceTemplates() []server.ResourceTemplate {
+	return []server.ResourceTemplate{
+		{
+			URITemplate: documentURITemplate,
+			Name:        "Google Doc",
+			Description: "Title and plain text content of a Google Doc by ID",
+			MimeType:    "application/json",
+		},
+	}
+}
+
+// HandleResourceTemplateCall reads a parameterized Docs resource
+func (h *Handler) HandleResourceTemplateCall(ctx context.Context, uri string, uriTemplate string, variables map[string]string) (interface{}, error) {
+	switch uriTemplate {
+	case documentURITemplate:
+		doc, err := h.client.GetDocument(ctx, variables["id"])
+		if err != nil {
+			return nil, err
+		}
+		return formatDocument(doc), nil
+
+	default:
+		return nil, fmt.Errorf("unknown docs resource: %s", uri)
+	}
+}
+
+// formatDocument extracts the title and paragraph text of a document
+func formatDocument(doc *docs.Document) map[string]interface{} {
+	result := map[string]interface{}{
+		"documentId": doc.DocumentId,
+		"title":      doc.Title,
+	}
+
+	// Extract text content from body
+	if doc.Body != nil && doc.Body.Content != nil {
+		var textContent string
+		for _, element := range doc.Body.Content {
+			if element.Paragraph != nil {
+				for _, elem := range element.Paragraph.Elements {
+					if elem.TextRun != nil {
+						textContent += elem.TextRun.Content
+					}
+				}
+			}
+		}
+		result["content"] = textContent
+	}
+
+	return result
+}
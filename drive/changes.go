@@ -0,0 +1,104 @@
+package drive
+
+import (
+	"context"
+	"fmt"
+
+	"go.ngs.io/google-mcp-server/server"
+)
+
+// changesPageSize is the number of changes fetched per request when catching
+// up with a page token
+const changesPageSize = 1000
+
+// fileURIPattern matches drive://file URIs so subscriptions can tell which
+// file they watch
+var fileURIPattern = server.MustParseURITemplate(fileURITemplate)
+
+// StartPageToken returns the page token from which future changes are listed
+func (c *Client) StartPageToken(ctx context.Context) (string, error) {
+	response, err := c.service.Changes.GetStartPageToken().Context(ctx).Do()
+	if err != nil {
+		return "", fmt.Errorf("failed to get start page token: %w", err)
+	}
+	return response.StartPageToken, nil
+}
+
+// ChangesSince lists the IDs of files changed after pageToken, including
+// removed and trashed files, and returns the token to continue from
+func (c *Client) ChangesSince(ctx context.Context, pageToken string) ([]string, string, error) {
+	var fileIDs []string
+	for {
+		response, err := c.service.Changes.List(pageToken).
+			Fields("nextPageToken, newStartPageToken, changes(fileId)").
+			PageSize(changesPageSize).
+			Context(ctx).
+			Do()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to list changes: %w", err)
+		}
+
+		for _, change := range response.Changes {
+			fileIDs = append(fileIDs, change.FileId)
+		}
+		if response.NewStartPageToken != "" {
+			return fileIDs, response.NewStartPageToken, nil
+		}
+		if response.NextPageToken == "" {
+			return nil, "", fmt.Errorf("failed to list changes: response has no page token")
+		}
+		pageToken = response.NextPageToken
+	}
+}
+
+// DetectChanges implements server.ChangeDetector with the Drive changes feed.
+// The listing resources (root, recent, starred, trash) change whenever any
+// file changes, since a rename or move can affect each of them; a file
+// resource only changes when that file does. The cursor is a changes page
+// token of the account serving the resource.
+func (h *MultiAccountHandler) DetectChanges(ctx context.Context, uri string, cursor string) (bool, string, error) {
+	var client *Client
+	var fileID string
+	if variables, ok := fileURIPattern.Match(uri); ok {
+		var err error
+		client, _, err = h.clientForResource(ctx, variables["account"])
+		if err != nil {
+			return false, "", err
+		}
+		fileID = variables["id"]
+	} else {
+		if h.handler == nil || !h.servesResource(uri) {
+			return false, "", server.ErrNotWatchable
+		}
+		client = h.handler.client
+	}
+
+	if cursor == "" {
+		token, err := client.StartPageToken(ctx)
+		return false, token, err
+	}
+
+	fileIDs, next, err := client.ChangesSince(ctx, cursor)
+	if err != nil {
+		return false, "", err
+	}
+	if fileID == "" {
+		return len(fileIDs) > 0, next, nil
+	}
+	for _, id := range fileIDs {
+		if id == fileID {
+			return true, next, nil
+		}
+	}
+	return false, next, nil
+}
+
+// servesResource reports whether uri is one of the static Drive resources
+func (h *MultiAccountHandler) servesResource(uri string) bool {
+	for _, resource := range h.GetResources() {
+		if resource.URI == uri {
+			return true
+		}
+	}
+	return false
+}
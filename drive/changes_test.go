@@ -0,0 +1,116 @@
+package drive
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"go.ngs.io/google-mcp-server/auth"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+// fakeChangesFeed serves the Drive changes endpoints. Page tokens are
+// positions in the list of changed file IDs, and pages hold two changes so
+// that callers have to follow nextPageToken.
+type fakeChangesFeed struct {
+	mu      sync.Mutex
+	changes []string
+}
+
+func (f *fakeChangesFeed) change(fileID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.changes = append(f.changes, fileID)
+}
+
+func (f *fakeChangesFeed) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/changes/startPageToken":
+		_ = json.NewEncoder(w).Encode(drive.StartPageToken{StartPageToken: strconv.Itoa(len(f.changes))})
+	case "/changes":
+		start, err := strconv.Atoi(r.URL.Query().Get("pageToken"))
+		if err != nil || start > len(f.changes) {
+			http.Error(w, `{"error":{"code":400,"message":"Invalid page token"}}`, http.StatusBadRequest)
+			return
+		}
+		end := start + 2
+		var response drive.ChangeList
+		if end >= len(f.changes) {
+			end = len(f.changes)
+			response.NewStartPageToken = strconv.Itoa(end)
+		} else {
+			response.NextPageToken = strconv.Itoa(end)
+		}
+		for _, id := range f.changes[start:end] {
+			response.Changes = append(response.Changes, &drive.Change{FileId: id})
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestDriveDetectChanges(t *testing.T) {
+	ctx := context.Background()
+	feed := &fakeChangesFeed{}
+	ts := httptest.NewServer(feed)
+	defer ts.Close()
+
+	service, err := drive.NewService(ctx, option.WithEndpoint(ts.URL+"/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("failed to create drive service: %v", err)
+	}
+	handler := &MultiAccountHandler{
+		multiClient: &MultiAccountClient{accountManager: &auth.AccountManager{}, clients: map[string]*Client{}},
+		handler:     NewHandler(&Client{service: service}),
+	}
+
+	detect := func(uri, cursor string) (bool, string) {
+		t.Helper()
+		changed, next, err := handler.DetectChanges(ctx, uri, cursor)
+		if err != nil {
+			t.Fatalf("DetectChanges(%s) failed: %v", uri, err)
+		}
+		return changed, next
+	}
+
+	_, recent := detect("drive://recent", "")
+	_, file := detect("drive://file/doc-1", "")
+
+	feed.change("other-1")
+	feed.change("other-2")
+	feed.change("other-3")
+
+	// Listings change with any file; a file resource only with its own file
+	changed, recent := detect("drive://recent", recent)
+	if !changed {
+		t.Error("expected drive://recent to change")
+	}
+	if recent != "3" {
+		t.Errorf("expected the cursor to follow all pages to 3, got %s", recent)
+	}
+	changed, file = detect("drive://file/doc-1", file)
+	if changed {
+		t.Error("expected drive://file/doc-1 not to change when other files do")
+	}
+
+	feed.change("doc-1")
+	if changed, _ := detect("drive://file/doc-1", file); !changed {
+		t.Error("expected drive://file/doc-1 to change")
+	}
+	if changed, _ := detect("drive://recent", "4"); changed {
+		t.Error("expected no change once caught up")
+	}
+
+	if _, _, err := handler.DetectChanges(ctx, "drive://shared", ""); err == nil {
+		t.Error("expected unknown drive resources not to be watchable")
+	}
+}
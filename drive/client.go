@@ -5,11 +5,10 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"log"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
 
 	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
 	"github.com/yuin/goldmark"
@@ -20,12 +19,25 @@ import (
 	"github.com/yuin/goldmark/parser"
 	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
 	"go.ngs.io/google-mcp-server/auth"
+	"go.ngs.io/google-mcp-server/logging"
+	"go.ngs.io/google-mcp-server/server"
 	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
 )
 
+var logger = logging.Logger("drive")
+
+// uploadChunkSize is the chunk size of resumable uploads. Media larger than
+// this is sent in chunks, and progress is reported after each one.
+const uploadChunkSize = 4 * 1024 * 1024
+
 // Client wraps the Google Drive API client
 type Client struct {
 	service *drive.Service
+
+	// httpClient fetches thumbnails, which are served outside the API but
+	// need the account's credentials
+	httpClient *http.Client
 }
 
 // NewClient creates a new Drive client
@@ -36,18 +48,14 @@ func NewClient(ctx context.Context, oauth *auth.OAuthClient) (*Client, error) {
 	}
 
 	return &Client{
-		service: service,
+		service:    service,
+		httpClient: oauth.GetHTTPClient(),
 	}, nil
 }
 
 // ListFiles lists files and folders
 func (c *Client) ListFiles(ctx context.Context, query string, pageSize int64, parentID string) ([]*drive.File, error) {
-	// Log the request for debugging
-	log.Printf("[Drive] ListFiles called with query=%q, pageSize=%d, parentID=%q", query, pageSize, parentID)
-
-	// Apply timeout to caller's context
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
+	logger.DebugContext(ctx, "listing files", "query", query, "page_size", pageSize, "parent_id", parentID)
 
 	call := c.service.Files.List().
 		Fields("files(id, name, mimeType, size, modifiedTime, parents, webViewLink, iconLink, thumbnailLink)")
@@ -67,7 +75,6 @@ func (c *Client) ListFiles(ctx context.Context, query string, pageSize int64, pa
 	}
 
 	if finalQuery != "" {
-		log.Printf("[Drive] Final query: %q", finalQuery)
 		call = call.Q(finalQuery)
 	}
 
@@ -79,17 +86,14 @@ func (c *Client) ListFiles(ctx context.Context, query string, pageSize int64, pa
 		call = call.PageSize(pageSize)
 	}
 
-	log.Printf("[Drive] Making API call with pageSize=%d", pageSize)
-
 	// Don't use Pages() method as it fetches ALL pages which can cause timeouts
 	// Instead, fetch only one page based on the specified pageSize
 	fileList, err := call.Context(ctx).Do()
 	if err != nil {
-		log.Printf("[Drive] API call failed: %v", err)
 		return nil, fmt.Errorf("failed to list files: %w", err)
 	}
 
-	log.Printf("[Drive] API call successful, got %d files", len(fileList.Files))
+	logger.DebugContext(ctx, "listed files", "query", finalQuery, "count", len(fileList.Files))
 	return fileList.Files, nil
 }
 
@@ -125,9 +129,10 @@ func (c *Client) SearchFiles(ctx context.Context, name, mimeType string, modifie
 }
 
 // GetFile gets file metadata
-func (c *Client) GetFile(fileID string) (*drive.File, error) {
+func (c *Client) GetFile(ctx context.Context, fileID string) (*drive.File, error) {
 	file, err := c.service.Files.Get(fileID).
 		Fields("id, name, mimeType, size, modifiedTime, parents, webViewLink, iconLink, thumbnailLink, permissions").
+		Context(ctx).
 		Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get file: %w", err)
@@ -143,7 +148,7 @@ func (c *Client) DownloadFile(ctx context.Context, fileID string, writer io.Writ
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to close download body: %v\n", err)
+			logger.WarnContext(ctx, "failed to close download body", "error", err)
 		}
 	}()
 
@@ -160,8 +165,44 @@ func (c *Client) DownloadFile(ctx context.Context, fileID string, writer io.Writ
 	return nil
 }
 
+// FetchThumbnail fetches the image behind a file's thumbnailLink and returns
+// it with its MIME type
+func (c *Client) FetchThumbnail(ctx context.Context, link string, maxSize int64) ([]byte, string, error) {
+	httpClient := c.httpClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid thumbnail link: %w", err)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch thumbnail: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.WarnContext(ctx, "failed to close thumbnail body", "error", err)
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to fetch thumbnail: %s", resp.Status)
+	}
+
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read thumbnail: %w", err)
+	}
+	if n > maxSize {
+		return nil, "", fmt.Errorf("thumbnail exceeded maximum size of %d bytes", maxSize)
+	}
+	return buf.Bytes(), resp.Header.Get("Content-Type"), nil
+}
+
 // UploadFile uploads a file
-func (c *Client) UploadFile(name string, mimeType string, reader io.Reader, parentID string) (*drive.File, error) {
+func (c *Client) UploadFile(ctx context.Context, name string, mimeType string, reader io.Reader, parentID string) (*drive.File, error) {
 	file := &drive.File{
 		Name:     name,
 		MimeType: mimeType,
@@ -173,10 +214,14 @@ func (c *Client) UploadFile(name string, mimeType string, reader io.Reader, pare
 
 	call := c.service.Files.Create(file)
 	if reader != nil {
-		call = call.Media(reader)
+		size := uploadSize(reader)
+		call = call.Media(reader, googleapi.ChunkSize(uploadChunkSize)).
+			ProgressUpdater(func(current, _ int64) {
+				server.ReportProgress(ctx, float64(current), float64(size), fmt.Sprintf("uploaded %d bytes of %s", current, name))
+			})
 	}
 
-	created, err := call.Do()
+	created, err := call.Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload file: %w", err)
 	}
@@ -184,8 +229,21 @@ func (c *Client) UploadFile(name string, mimeType string, reader io.Reader, pare
 	return created, nil
 }
 
+// uploadSize returns the number of bytes reader holds, or 0 if it is unknown
+func uploadSize(reader io.Reader) int64 {
+	switch r := reader.(type) {
+	case interface{ Size() int64 }:
+		return r.Size()
+	case *os.File:
+		if info, err := r.Stat(); err == nil {
+			return info.Size()
+		}
+	}
+	return 0
+}
+
 // UpdateFileMetadata updates file metadata
-func (c *Client) UpdateFileMetadata(fileID, name, description string) (*drive.File, error) {
+func (c *Client) UpdateFileMetadata(ctx context.Context, fileID, name, description string) (*drive.File, error) {
 	file := &drive.File{}
 	if name != "" {
 		file.Name = name
@@ -194,7 +252,7 @@ func (c *Client) UpdateFileMetadata(fileID, name, description string) (*drive.Fi
 		file.Description = description
 	}
 
-	updated, err := c.service.Files.Update(fileID, file).Do()
+	updated, err := c.service.Files.Update(fileID, file).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to update file metadata: %w", err)
 	}
@@ -203,7 +261,7 @@ func (c *Client) UpdateFileMetadata(fileID, name, description string) (*drive.Fi
 }
 
 // CreateFolder creates a folder
-func (c *Client) CreateFolder(name string, parentID string) (*drive.File, error) {
+func (c *Client) CreateFolder(ctx context.Context, name string, parentID string) (*drive.File, error) {
 	folder := &drive.File{
 		Name:     name,
 		MimeType: "application/vnd.google-apps.folder",
@@ -213,7 +271,7 @@ func (c *Client) CreateFolder(name string, parentID string) (*drive.File, error)
 		folder.Parents = []string{parentID}
 	}
 
-	created, err := c.service.Files.Create(folder).Do()
+	created, err := c.service.Files.Create(folder).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create folder: %w", err)
 	}
@@ -222,9 +280,9 @@ func (c *Client) CreateFolder(name string, parentID string) (*drive.File, error)
 }
 
 // MoveFile moves a file to a different folder
-func (c *Client) MoveFile(fileID, newParentID string) (*drive.File, error) {
+func (c *Client) MoveFile(ctx context.Context, fileID, newParentID string) (*drive.File, error) {
 	// Get current parents
-	file, err := c.GetFile(fileID)
+	file, err := c.GetFile(ctx, fileID)
 	if err != nil {
 		return nil, err
 	}
@@ -239,6 +297,7 @@ func (c *Client) MoveFile(fileID, newParentID string) (*drive.File, error) {
 		AddParents(newParentID).
 		RemoveParents(removeParents).
 		Fields("id, parents").
+		Context(ctx).
 		Do()
 
 	if err != nil {
@@ -249,13 +308,13 @@ func (c *Client) MoveFile(fileID, newParentID string) (*drive.File, error) {
 }
 
 // CopyFile copies a file
-func (c *Client) CopyFile(fileID, newName string) (*drive.File, error) {
+func (c *Client) CopyFile(ctx context.Context, fileID, newName string) (*drive.File, error) {
 	copy := &drive.File{}
 	if newName != "" {
 		copy.Name = newName
 	}
 
-	copied, err := c.service.Files.Copy(fileID, copy).Do()
+	copied, err := c.service.Files.Copy(fileID, copy).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to copy file: %w", err)
 	}
@@ -264,8 +323,8 @@ func (c *Client) CopyFile(fileID, newName string) (*drive.File, error) {
 }
 
 // DeleteFile deletes a file
-func (c *Client) DeleteFile(fileID string) error {
-	err := c.service.Files.Delete(fileID).Do()
+func (c *Client) DeleteFile(ctx context.Context, fileID string) error {
+	err := c.service.Files.Delete(fileID).Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("failed to delete file: %w", err)
 	}
@@ -273,8 +332,8 @@ func (c *Client) DeleteFile(fileID string) error {
 }
 
 // TrashFile moves a file to trash
-func (c *Client) TrashFile(fileID string) error {
-	_, err := c.service.Files.Update(fileID, &drive.File{Trashed: true}).Do()
+func (c *Client) TrashFile(ctx context.Context, fileID string) error {
+	_, err := c.service.Files.Update(fileID, &drive.File{Trashed: true}).Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("failed to trash file: %w", err)
 	}
@@ -282,8 +341,8 @@ func (c *Client) TrashFile(fileID string) error {
 }
 
 // RestoreFile restores a file from trash
-func (c *Client) RestoreFile(fileID string) error {
-	_, err := c.service.Files.Update(fileID, &drive.File{Trashed: false}).Do()
+func (c *Client) RestoreFile(ctx context.Context, fileID string) error {
+	_, err := c.service.Files.Update(fileID, &drive.File{Trashed: false}).Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("failed to restore file: %w", err)
 	}
@@ -291,7 +350,7 @@ func (c *Client) RestoreFile(fileID string) error {
 }
 
 // CreateShareLink creates a shareable link
-func (c *Client) CreateShareLink(fileID, role, permType string) (string, error) {
+func (c *Client) CreateShareLink(ctx context.Context, fileID, role, permType string) (string, error) {
 	// Default to "anyone" if not specified
 	if permType == "" {
 		permType = "anyone"
@@ -314,12 +373,12 @@ func (c *Client) CreateShareLink(fileID, role, permType string) (string, error)
 		Role: role,
 	}
 
-	_, err := c.service.Permissions.Create(fileID, permission).Do()
+	_, err := c.service.Permissions.Create(fileID, permission).Context(ctx).Do()
 	if err != nil {
 		return "", fmt.Errorf("failed to create share link: %w", err)
 	}
 
-	file, err := c.GetFile(fileID)
+	file, err := c.GetFile(ctx, fileID)
 	if err != nil {
 		return "", err
 	}
@@ -328,9 +387,10 @@ func (c *Client) CreateShareLink(fileID, role, permType string) (string, error)
 }
 
 // ListPermissions lists file permissions
-func (c *Client) ListPermissions(fileID string) ([]*drive.Permission, error) {
+func (c *Client) ListPermissions(ctx context.Context, fileID string) ([]*drive.Permission, error) {
 	permissions, err := c.service.Permissions.List(fileID).
 		Fields("permissions(id, type, role, emailAddress)").
+		Context(ctx).
 		Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list permissions: %w", err)
@@ -340,7 +400,7 @@ func (c *Client) ListPermissions(fileID string) ([]*drive.Permission, error) {
 }
 
 // CreatePermission creates a permission
-func (c *Client) CreatePermission(fileID, email, role string) (*drive.Permission, error) {
+func (c *Client) CreatePermission(ctx context.Context, fileID, email, role string) (*drive.Permission, error) {
 	permission := &drive.Permission{
 		Type:         "user",
 		Role:         role,
@@ -349,6 +409,7 @@ func (c *Client) CreatePermission(fileID, email, role string) (*drive.Permission
 
 	created, err := c.service.Permissions.Create(fileID, permission).
 		SendNotificationEmail(true).
+		Context(ctx).
 		Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create permission: %w", err)
@@ -358,8 +419,8 @@ func (c *Client) CreatePermission(fileID, email, role string) (*drive.Permission
 }
 
 // DeletePermission deletes a permission
-func (c *Client) DeletePermission(fileID, permissionID string) error {
-	err := c.service.Permissions.Delete(fileID, permissionID).Do()
+func (c *Client) DeletePermission(ctx context.Context, fileID, permissionID string) error {
+	err := c.service.Permissions.Delete(fileID, permissionID).Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("failed to delete permission: %w", err)
 	}
@@ -367,8 +428,8 @@ func (c *Client) DeletePermission(fileID, permissionID string) error {
 }
 
 // ExportFile exports a Google Workspace file
-func (c *Client) ExportFile(fileID, mimeType string) (io.ReadCloser, error) {
-	resp, err := c.service.Files.Export(fileID, mimeType).Download()
+func (c *Client) ExportFile(ctx context.Context, fileID, mimeType string) (io.ReadCloser, error) {
+	resp, err := c.service.Files.Export(fileID, mimeType).Context(ctx).Download()
 	if err != nil {
 		return nil, fmt.Errorf("failed to export file: %w", err)
 	}
@@ -376,7 +437,7 @@ func (c *Client) ExportFile(fileID, mimeType string) (io.ReadCloser, error) {
 }
 
 // UploadFileFromPath uploads a file from filesystem path
-func (c *Client) UploadFileFromPath(filePath string, parentID string) (*drive.File, error) {
+func (c *Client) UploadFileFromPath(ctx context.Context, filePath string, parentID string) (*drive.File, error) {
 	// Clean the path to prevent directory traversal
 	cleanPath := filepath.Clean(filePath)
 	file, err := os.Open(cleanPath)
@@ -393,7 +454,7 @@ func (c *Client) UploadFileFromPath(filePath string, parentID string) (*drive.Fi
 		return nil, fmt.Errorf("failed to stat file: %w", err)
 	}
 
-	return c.UploadFile(info.Name(), mimeType, file, parentID)
+	return c.UploadFile(ctx, info.Name(), mimeType, file, parentID)
 }
 
 // convertMarkdownToHTML converts markdown content to HTML using goldmark with all extensions
@@ -1,9 +1,11 @@
 package drive
 
 import (
+	"bytes"
 	"context"
 	"io"
 	"net/http"
+	"os"
 	"strings"
 	"testing"
 
@@ -84,6 +86,36 @@ func TestEscapeDriveQuery(t *testing.T) {
 	}
 }
 
+func TestUploadSize(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "upload")
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+	if _, err := file.WriteString("twelve bytes"); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		reader   io.Reader
+		expected int64
+	}{
+		{name: "bytes reader", reader: bytes.NewReader(make([]byte, 5)), expected: 5},
+		{name: "strings reader", reader: strings.NewReader("abc"), expected: 3},
+		{name: "file", reader: file, expected: 12},
+		{name: "unknown", reader: io.LimitReader(strings.NewReader("abc"), 2), expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := uploadSize(tt.reader); got != tt.expected {
+				t.Errorf("uploadSize() = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestConvertMarkdownToHTML(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,36 @@
+package drive
+
+import (
+	"context"
+
+	"go.ngs.io/google-mcp-server/server"
+)
+
+// maxFolderCompletions bounds the folders fetched for parent folder completion
+const maxFolderCompletions = 100
+
+// CompleteArgument suggests folders, matched by name, for parent folder arguments
+func (h *MultiAccountHandler) CompleteArgument(ctx context.Context, ref server.CompletionRef, argument string, arguments map[string]string) ([]server.CompletionCandidate, error) {
+	if argument != "parent_id" && argument != "new_parent_id" {
+		return nil, nil
+	}
+
+	client, _, err := h.clientForResource(ctx, arguments["account"])
+	if err != nil {
+		return nil, err
+	}
+
+	folders, err := client.ListFiles(ctx, "mimeType = 'application/vnd.google-apps.folder' and trashed = false", maxFolderCompletions, "")
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []server.CompletionCandidate{{Value: "root", Label: "My Drive"}}
+	for _, folder := range folders {
+		candidates = append(candidates, server.CompletionCandidate{
+			Value: folder.Id,
+			Label: folder.Name,
+		})
+	}
+	return candidates, nil
+}
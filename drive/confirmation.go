@@ -0,0 +1,51 @@
+package drive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ConfirmationMessage implements server.Confirmer for permanent deletions and
+// permission removals. File and grantee names are looked up so the user sees
+// what is affected; if the lookup fails the IDs are shown instead.
+func (h *MultiAccountHandler) ConfirmationMessage(ctx context.Context, name string, arguments json.RawMessage) (string, error) {
+	if name != "drive_file_delete" && name != "drive_permissions_delete" {
+		return "", nil
+	}
+
+	var args struct {
+		FileID       string `json:"file_id"`
+		PermissionID string `json:"permission_id"`
+		Account      string `json:"account"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	fileName := fmt.Sprintf("file %s", args.FileID)
+	var grantee string
+	if client, _, err := h.clientForResource(ctx, args.Account); err == nil {
+		if file, err := client.GetFile(ctx, args.FileID); err == nil {
+			fileName = fmt.Sprintf("%q (%s)", file.Name, file.MimeType)
+			for _, permission := range file.Permissions {
+				if permission.Id != args.PermissionID {
+					continue
+				}
+				grantee = permission.EmailAddress
+				if grantee == "" {
+					grantee = permission.Type
+				}
+				grantee = fmt.Sprintf("%s (%s)", grantee, permission.Role)
+			}
+		}
+	}
+
+	if name == "drive_file_delete" {
+		return fmt.Sprintf("Permanently delete %s from Google Drive? It will not go to the trash and cannot be restored.", fileName), nil
+	}
+	if grantee == "" {
+		grantee = fmt.Sprintf("permission %s", args.PermissionID)
+	}
+	return fmt.Sprintf("Remove access for %s to %s?", grantee, fileName), nil
+}
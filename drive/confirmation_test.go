@@ -0,0 +1,49 @@
+package drive
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"go.ngs.io/google-mcp-server/server"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+func TestFileDeleteAcceptsUserConfirmation(t *testing.T) {
+	var deleted atomic.Int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete && r.URL.Path == "/files/F1" {
+			deleted.Add(1)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer api.Close()
+	service, err := drive.NewService(context.Background(), option.WithEndpoint(api.URL+"/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("failed to create drive service: %v", err)
+	}
+	h := NewHandler(&Client{service: service})
+	arguments := json.RawMessage(`{"file_id":"F1"}`)
+
+	// Without confirm=true or the user's go-ahead nothing is deleted
+	if _, err := h.HandleToolCall(context.Background(), "drive_file_delete", arguments); err == nil {
+		t.Error("expected the deletion to need confirmation")
+	}
+	if deleted.Load() != 0 {
+		t.Fatalf("expected nothing to be deleted, got %d deletions", deleted.Load())
+	}
+
+	// The user's confirmation stands in for confirm=true
+	if _, err := h.HandleToolCall(server.WithUserConfirmed(context.Background()), "drive_file_delete", arguments); err != nil {
+		t.Fatalf("expected the confirmed deletion to succeed: %v", err)
+	}
+	if deleted.Load() != 1 {
+		t.Errorf("expected the file to be deleted once, got %d deletions", deleted.Load())
+	}
+}
@@ -0,0 +1,115 @@
+package drive
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.ngs.io/google-mcp-server/server"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+// fakeFiles serves file metadata and media for a few files, and the
+// thumbnail of the document
+func fakeFiles(t *testing.T) *httptest.Server {
+	t.Helper()
+	var ts *httptest.Server
+	media := map[string]string{
+		"notes": "plain text notes",
+		"photo": "\x89PNG\r\n\x1a\nimage",
+		"pdf":   "%PDF-1.7 binary\xff",
+	}
+	files := map[string]drive.File{
+		"notes": {Id: "notes", Name: "notes.txt", MimeType: "text/plain"},
+		"photo": {Id: "photo", Name: "photo.png", MimeType: "image/png"},
+		"pdf":   {Id: "pdf", Name: "report.pdf", MimeType: "application/pdf"},
+		"doc":   {Id: "doc", Name: "Plan", MimeType: "application/vnd.google-apps.document"},
+	}
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/thumbnails/doc" {
+			w.Header().Set("Content-Type", "image/jpeg")
+			_, _ = w.Write([]byte("\xff\xd8\xff thumbnail"))
+			return
+		}
+		id := strings.TrimPrefix(r.URL.Path, "/files/")
+		file, ok := files[id]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("alt") == "media" {
+			_, _ = w.Write([]byte(media[id]))
+			return
+		}
+		file.Size = int64(len(media[id]))
+		if id == "doc" {
+			file.ThumbnailLink = ts.URL + "/thumbnails/doc"
+		}
+		_ = json.NewEncoder(w).Encode(file)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestFileDownloadAndPreviewContent(t *testing.T) {
+	ctx := context.Background()
+	ts := fakeFiles(t)
+	service, err := drive.NewService(ctx, option.WithEndpoint(ts.URL+"/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("failed to create drive service: %v", err)
+	}
+	handler := NewHandler(&Client{service: service})
+
+	call := func(tool, fileID string) *server.ToolResult {
+		t.Helper()
+		result, err := handler.HandleToolCall(ctx, tool, json.RawMessage(`{"file_id":"`+fileID+`"}`))
+		if err != nil {
+			t.Fatalf("%s(%s) failed: %v", tool, fileID, err)
+		}
+		typed, ok := result.(*server.ToolResult)
+		if !ok || len(typed.Content) != 1 {
+			t.Fatalf("expected a tool result with one block from %s(%s), got %#v", tool, fileID, result)
+		}
+		return typed
+	}
+
+	// Text is embedded as text, anything else as a blob
+	notes := call("drive_file_download", "notes").Content[0].Resource
+	if notes == nil || notes.URI != "drive://file/notes" || notes.MimeType != "text/plain" || notes.Text != "plain text notes" {
+		t.Errorf("unexpected text download %+v", notes)
+	}
+	pdf := call("drive_file_download", "pdf")
+	if blob := pdf.Content[0].Resource; blob == nil || blob.MimeType != "application/pdf" || blob.Blob != base64.StdEncoding.EncodeToString([]byte("%PDF-1.7 binary\xff")) {
+		t.Errorf("unexpected binary download %+v", blob)
+	}
+	if pdf.Structured["name"] != "report.pdf" {
+		t.Errorf("expected the file name in the structured result, got %v", pdf.Structured)
+	}
+
+	// Images are previewed as themselves, other files by their thumbnail
+	photo := call("drive_file_preview", "photo").Content[0]
+	if photo.Type != server.ContentTypeImage || photo.MimeType != "image/png" {
+		t.Errorf("unexpected image preview %+v", photo)
+	}
+	thumbnail := call("drive_file_preview", "doc").Content[0]
+	if thumbnail.Type != server.ContentTypeImage || thumbnail.MimeType != "image/jpeg" {
+		t.Errorf("unexpected thumbnail preview %+v", thumbnail)
+	}
+	if _, err := handler.HandleToolCall(ctx, "drive_file_preview", json.RawMessage(`{"file_id":"pdf"}`)); err == nil {
+		t.Error("expected a preview of a file without thumbnail to fail")
+	}
+
+	// Multi-account results point their resources at the account
+	tagAccount(pdf, "user@example.com")
+	if uri := pdf.Content[0].Resource.URI; uri != "drive://file/pdf?account=user%40example.com" {
+		t.Errorf("expected the resource to name the account, got %s", uri)
+	}
+	if pdf.Structured["account"] != "user@example.com" {
+		t.Errorf("expected the account in the structured result, got %v", pdf.Structured)
+	}
+}
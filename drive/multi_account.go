@@ -29,12 +29,13 @@ func NewMultiAccountClient(ctx context.Context, accountManager *auth.AccountMana
 
 	// Initialize clients for all accounts
 	for email, oauthClient := range accountManager.GetAllOAuthClients() {
-		service, err := drive.NewService(ctx, option.WithHTTPClient(oauthClient.GetHTTPClient()))
+		httpClient := oauthClient.GetHTTPClient()
+		service, err := drive.NewService(ctx, option.WithHTTPClient(httpClient))
 		if err != nil {
-			fmt.Printf("Warning: failed to create drive service for %s: %v\n", email, err)
+			logger.Warn("failed to create drive service", "account", email, "error", err)
 			continue
 		}
-		mac.clients[email] = &Client{service: service}
+		mac.clients[email] = &Client{service: service, httpClient: httpClient}
 	}
 
 	return mac, nil
@@ -54,12 +55,13 @@ func (mac *MultiAccountClient) GetClientForContext(ctx context.Context, hint str
 		}
 
 		// Create client on demand if not exists
-		service, err := drive.NewService(ctx, option.WithHTTPClient(account.OAuthClient.GetHTTPClient()))
+		httpClient := account.OAuthClient.GetHTTPClient()
+		service, err := drive.NewService(ctx, option.WithHTTPClient(httpClient))
 		if err != nil {
 			return nil, "", fmt.Errorf("failed to create drive service: %w", err)
 		}
 
-		newClient := &Client{service: service}
+		newClient := &Client{service: service, httpClient: httpClient}
 		mac.mu.Lock()
 		mac.clients[account.Email] = newClient
 		mac.mu.Unlock()
@@ -96,7 +98,6 @@ func (mac *MultiAccountClient) GetClientForContext(ctx context.Context, hint str
 // SearchAcrossAccounts searches for files across all accounts
 func (mac *MultiAccountClient) SearchAcrossAccounts(ctx context.Context, query string, pageSize int64) (map[string][]*drive.File, error) {
 	results := make(map[string][]*drive.File)
-	var wg sync.WaitGroup
 	var mu sync.Mutex
 	errors := make([]error, 0)
 
@@ -107,28 +108,24 @@ func (mac *MultiAccountClient) SearchAcrossAccounts(ctx context.Context, query s
 	}
 	mac.mu.RUnlock()
 
-	for email, client := range clients {
-		wg.Add(1)
-		go func(email string, client *Client) {
-			defer wg.Done()
-
-			// SearchFiles expects (name, mimeType, modifiedAfter)
-			// For cross-account search, we'll use ListFiles with the query directly
-			files, err := client.ListFiles(ctx, query, pageSize, "")
-			if err != nil {
-				mu.Lock()
-				errors = append(errors, fmt.Errorf("%s: %w", email, err))
-				mu.Unlock()
-				return
-			}
+	progress := server.NewProgressCounter(ctx, len(clients))
+	auth.ForEachAccount(clients, func(email string, client *Client) {
+		defer progress.Step("searched " + email)
 
+		// SearchFiles expects (name, mimeType, modifiedAfter)
+		// For cross-account search, we'll use ListFiles with the query directly
+		files, err := client.ListFiles(ctx, query, pageSize, "")
+		if err != nil {
 			mu.Lock()
-			results[email] = files
+			errors = append(errors, fmt.Errorf("%s: %w", email, err))
 			mu.Unlock()
-		}(email, client)
-	}
+			return
+		}
 
-	wg.Wait()
+		mu.Lock()
+		results[email] = files
+		mu.Unlock()
+	})
 
 	// If all searches failed, return the first error
 	if len(errors) == len(clients) && len(errors) > 0 {
@@ -141,7 +138,6 @@ func (mac *MultiAccountClient) SearchAcrossAccounts(ctx context.Context, query s
 // ListFilesAcrossAccounts lists files from all accounts
 func (mac *MultiAccountClient) ListFilesAcrossAccounts(ctx context.Context, parentID string, pageSize int64) (map[string][]*drive.File, error) {
 	results := make(map[string][]*drive.File)
-	var wg sync.WaitGroup
 	var mu sync.Mutex
 	errors := make([]error, 0)
 
@@ -152,26 +148,22 @@ func (mac *MultiAccountClient) ListFilesAcrossAccounts(ctx context.Context, pare
 	}
 	mac.mu.RUnlock()
 
-	for email, client := range clients {
-		wg.Add(1)
-		go func(email string, client *Client) {
-			defer wg.Done()
-
-			files, err := client.ListFiles(ctx, "", pageSize, parentID)
-			if err != nil {
-				mu.Lock()
-				errors = append(errors, fmt.Errorf("%s: %w", email, err))
-				mu.Unlock()
-				return
-			}
+	progress := server.NewProgressCounter(ctx, len(clients))
+	auth.ForEachAccount(clients, func(email string, client *Client) {
+		defer progress.Step("listed " + email)
 
+		files, err := client.ListFiles(ctx, "", pageSize, parentID)
+		if err != nil {
 			mu.Lock()
-			results[email] = files
+			errors = append(errors, fmt.Errorf("%s: %w", email, err))
 			mu.Unlock()
-		}(email, client)
-	}
+			return
+		}
 
-	wg.Wait()
+		mu.Lock()
+		results[email] = files
+		mu.Unlock()
+	})
 
 	// If all searches failed, return the first error
 	if len(errors) == len(clients) && len(errors) > 0 {
@@ -193,7 +185,7 @@ func NewMultiAccountHandler(accountManager *auth.AccountManager, defaultClient *
 	multiClient, err := NewMultiAccountClient(ctx, accountManager)
 	if err != nil {
 		// Log error but continue with limited functionality
-		fmt.Printf("Warning: failed to initialize multi-account client: %v\n", err)
+		logger.Warn("failed to initialize multi-account client", "error", err)
 		multiClient = &MultiAccountClient{
 			accountManager: accountManager,
 			clients:        make(map[string]*Client),
@@ -212,150 +204,98 @@ func NewMultiAccountHandler(accountManager *auth.AccountManager, defaultClient *
 	}
 }
 
-// GetTools returns the available Drive tools with multi-account support
-func (h *MultiAccountHandler) GetTools() []server.Tool {
-	// Get original tools from handler
-	if h.handler != nil {
-		tools := h.handler.GetTools()
-
-		// Add account parameter to existing tools
-		for i := range tools {
-			if tools[i].InputSchema.Properties == nil {
-				tools[i].InputSchema.Properties = make(map[string]server.Property)
-			}
-			tools[i].InputSchema.Properties["account"] = server.Property{
-				Type:        "string",
-				Description: "Email address of the account to use (optional)",
-			}
-		}
+// filesListAllAccountsArgs are the arguments of drive_files_list_all_accounts
+type filesListAllAccountsArgs struct {
+	ParentID string `json:"parent_id" description:"Parent folder ID (optional, defaults to root)"`
+	PageSize int64  `json:"page_size" description:"Number of files per account (max 1000)"`
+}
 
-		// Add new multi-account specific tools
-		tools = append(tools, server.Tool{
+// ToolDefinitions declares the Drive tools with multi-account support
+func (h *MultiAccountHandler) ToolDefinitions() server.ToolSet {
+	return append(fileTools(h.clientForAccount),
+		server.NewTool(server.Tool{
 			Name:        "drive_files_list_all_accounts",
 			Description: "List files from all authenticated accounts",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"parent_id": {
-						Type:        "string",
-						Description: "Parent folder ID (optional, defaults to root)",
-					},
-					"page_size": {
-						Type:        "number",
-						Description: "Number of files per account (max 1000)",
-					},
-				},
-			},
-		})
-
-		return tools
-	}
+			Annotations: server.ReadOnlyAnnotations(),
+		}, h.handleFilesListAllAccounts),
+	)
+}
 
-	// Return empty if no handler
-	return []server.Tool{}
+// GetTools returns the available Drive tools with multi-account support
+func (h *MultiAccountHandler) GetTools() []server.Tool {
+	return h.ToolDefinitions().Tools()
 }
 
 // HandleToolCall handles a tool call for Drive service with multi-account support
 func (h *MultiAccountHandler) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
-	// Handle multi-account specific tools
-	if name == "drive_files_list_all_accounts" {
-		var args struct {
-			ParentID string  `json:"parent_id"`
-			PageSize float64 `json:"page_size"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-
-		pageSize := int64(args.PageSize)
-		if pageSize <= 0 {
-			pageSize = 100
-		}
-
-		// List files across all accounts
-		results, err := h.multiClient.ListFilesAcrossAccounts(ctx, args.ParentID, pageSize)
-		if err != nil {
-			return nil, err
-		}
+	return h.ToolDefinitions().Call(ctx, name, arguments)
+}
 
-		// Format results
-		formattedResults := make(map[string]interface{})
-		totalFiles := 0
-		for email, files := range results {
-			fileList := make([]map[string]interface{}, len(files))
-			for i, file := range files {
-				fileInfo := map[string]interface{}{
-					"id":           file.Id,
-					"name":         file.Name,
-					"mimeType":     file.MimeType,
-					"size":         file.Size,
-					"modifiedTime": file.ModifiedTime,
-				}
-				if file.WebViewLink != "" {
-					fileInfo["webViewLink"] = file.WebViewLink
-				}
-				if len(file.Parents) > 0 {
-					fileInfo["parents"] = file.Parents
-				}
-				if file.ThumbnailLink != "" {
-					fileInfo["thumbnailLink"] = file.ThumbnailLink
-				}
-				if file.IconLink != "" {
-					fileInfo["iconLink"] = file.IconLink
-				}
-				fileList[i] = fileInfo
-			}
-			formattedResults[email] = map[string]interface{}{
-				"files": fileList,
-				"count": len(files),
-			}
-			totalFiles += len(files)
+// clientForAccount returns the client of the named account, falling back to
+// the default client if the account cannot be resolved
+func (h *MultiAccountHandler) clientForAccount(ctx context.Context, account string) (*Client, string, error) {
+	client, accountUsed, err := h.multiClient.GetClientForContext(ctx, account)
+	if err != nil {
+		if h.handler == nil {
+			return nil, "", err
 		}
+		return h.handler.client, "", nil
+	}
+	return client, accountUsed, nil
+}
 
-		return map[string]interface{}{
-			"accounts":      formattedResults,
-			"total_count":   totalFiles,
-			"account_count": len(results),
-		}, nil
+// handleFilesListAllAccounts lists the files of every account
+func (h *MultiAccountHandler) handleFilesListAllAccounts(ctx context.Context, args filesListAllAccountsArgs) (interface{}, error) {
+	pageSize := args.PageSize
+	if pageSize <= 0 {
+		pageSize = 100
 	}
 
-	// For other tools, check if account parameter is provided
-	var accountHint string
-	if arguments != nil {
-		var args map[string]interface{}
-		if err := json.Unmarshal(arguments, &args); err == nil {
-			if account, ok := args["account"].(string); ok {
-				accountHint = account
-			}
-		}
+	// List files across all accounts
+	results, err := h.multiClient.ListFilesAcrossAccounts(ctx, args.ParentID, pageSize)
+	if err != nil {
+		return nil, err
 	}
 
-	// Try to get client for the specified account
-	if accountHint != "" || h.multiClient != nil {
-		client, accountUsed, err := h.multiClient.GetClientForContext(ctx, accountHint)
-		if err == nil {
-			// Create a temporary handler with the selected client
-			tempHandler := NewHandler(client)
-			result, err := tempHandler.HandleToolCall(ctx, name, arguments)
-			if err != nil {
-				return nil, err
+	// Format results
+	formattedResults := make(map[string]interface{})
+	totalFiles := 0
+	for email, files := range results {
+		fileList := make([]map[string]interface{}, len(files))
+		for i, file := range files {
+			fileInfo := map[string]interface{}{
+				"id":           file.Id,
+				"name":         file.Name,
+				"mimeType":     file.MimeType,
+				"size":         file.Size,
+				"modifiedTime": file.ModifiedTime,
 			}
-
-			// Add account information to result if it's a map
-			if resultMap, ok := result.(map[string]interface{}); ok {
-				resultMap["account"] = accountUsed
+			if file.WebViewLink != "" {
+				fileInfo["webViewLink"] = file.WebViewLink
 			}
-
-			return result, nil
+			if len(file.Parents) > 0 {
+				fileInfo["parents"] = file.Parents
+			}
+			if file.ThumbnailLink != "" {
+				fileInfo["thumbnailLink"] = file.ThumbnailLink
+			}
+			if file.IconLink != "" {
+				fileInfo["iconLink"] = file.IconLink
+			}
+			fileList[i] = fileInfo
 		}
+		formattedResults[email] = map[string]interface{}{
+			"files": fileList,
+			"count": len(files),
+		}
+		totalFiles += len(files)
 	}
 
-	// Fall back to original handler for backward compatibility
-	if h.handler != nil {
-		return h.handler.HandleToolCall(ctx, name, arguments)
-	}
-
-	return nil, fmt.Errorf("no handler available for tool: %s", name)
+	return map[string]interface{}{
+		"accounts":      formattedResults,
+		"total_count":   totalFiles,
+		"account_count": len(results),
+	}, nil
 }
 
 // GetResources returns the available Drive resources
@@ -111,3 +111,82 @@ func (h *Handler) getTrashedFiles(ctx context.Context) (interface{}, error) {
 		"count":    len(files),
 	}, nil
 }
+
+// fileURITemplate addresses a single Drive file by ID
+const fileURITemplate = "drive://file/{id}{?account}"
+
+// fileURI returns the drive://file URI of a file, naming the account when
+// one is given
+func fileURI(id, account string) string {
+	return fileURIPattern.Expand(map[string]string{"id": id, "account": account})
+}
+
+// tagAccount records in a tool result which account produced it, and points
+// its embedded file resources at that account
+func tagAccount(result *server.ToolResult, account string) {
+	if result.Structured != nil {
+		result.Structured["account"] = account
+	}
+	for _, content := range result.Content {
+		if content.Resource == nil {
+			continue
+		}
+		if variables, ok := fileURIPattern.Match(content.Resource.URI); ok && variables["account"] == "" {
+			content.Resource.URI = fileURI(variables["id"], account)
+		}
+	}
+}
+
+// GetResourceTemplates returns the parameterized Drive resources
+func (h *MultiAccountHandler) GetResourceTemplates() []server.ResourceTemplate {
+	return []server.ResourceTemplate{
+		{
+			URITemplate: fileURITemplate,
+			Name:        "Drive File",
+			Description: "Metadata of a Drive file by ID; add ?account=<email> to choose the account",
+			MimeType:    "application/json",
+		},
+	}
+}
+
+// HandleResourceTemplateCall reads a parameterized Drive resource
+func (h *MultiAccountHandler) HandleResourceTemplateCall(ctx context.Context, uri string, uriTemplate string, variables map[string]string) (interface{}, error) {
+	switch uriTemplate {
+	case fileURITemplate:
+		if err := validateID(variables["id"], "id"); err != nil {
+			return nil, err
+		}
+
+		client, account, err := h.clientForResource(ctx, variables["account"])
+		if err != nil {
+			return nil, err
+		}
+
+		file, err := client.GetFile(ctx, variables["id"])
+		if err != nil {
+			return nil, err
+		}
+
+		result := formatFile(file)
+		if account != "" {
+			result["account"] = account
+		}
+		return result, nil
+
+	default:
+		return nil, fmt.Errorf("unknown drive resource: %s", uri)
+	}
+}
+
+// clientForResource picks the client for a resource read, preferring the
+// account named in the URI and falling back to the default client
+func (h *MultiAccountHandler) clientForResource(ctx context.Context, account string) (*Client, string, error) {
+	client, accountUsed, err := h.multiClient.GetClientForContext(ctx, account)
+	if err == nil {
+		return client, accountUsed, nil
+	}
+	if account == "" && h.handler != nil {
+		return h.handler.client, "", nil
+	}
+	return nil, "", err
+}
@@ -7,7 +7,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"os"
+	"strings"
+	"unicode/utf8"
 
 	"go.ngs.io/google-mcp-server/server"
 )
@@ -28,6 +29,8 @@ const (
 	maxDownloadSize = 100 * 1024 * 1024
 	// maxUploadSize is the maximum allowed content size for uploads (50MB)
 	maxUploadSize = 50 * 1024 * 1024
+	// maxPreviewSize is the maximum size of an image returned as a preview (5MB)
+	maxPreviewSize = 5 * 1024 * 1024
 )
 
 // Handler implements the ServiceHandler interface for Drive
@@ -40,542 +43,285 @@ func NewHandler(client *Client) *Handler {
 	return &Handler{client: client}
 }
 
-// GetTools returns the available Drive tools
-func (h *Handler) GetTools() []server.Tool {
-	return []server.Tool{
-		{
+// clientResolver returns the client for the account named in the arguments
+// of a tool, and the account it belongs to. The account is empty when the
+// default client is used.
+type clientResolver func(ctx context.Context, account string) (*Client, string, error)
+
+// Arguments of the Drive tools. The input schemas are generated from these
+// structs, see server.SchemaOf.
+
+type accountArgs struct {
+	Account string `json:"account,omitempty" description:"Email address of the account to use (optional)"`
+}
+
+// accountName returns the account named in the arguments
+func (a accountArgs) accountName() string {
+	return a.Account
+}
+
+type filesListArgs struct {
+	ParentID string `json:"parent_id" description:"Parent folder ID (optional, defaults to root)"`
+	PageSize int64  `json:"page_size" description:"Number of files to return (max 1000)"`
+	accountArgs
+}
+
+type filesSearchArgs struct {
+	Name          string `json:"name" description:"File name to search for"`
+	MimeType      string `json:"mime_type" description:"MIME type to filter by"`
+	ModifiedAfter string `json:"modified_after" description:"Modified after date (RFC3339 format)"`
+	accountArgs
+}
+
+type fileDownloadArgs struct {
+	FileID string `json:"file_id" required:"true" description:"File ID to download"`
+	accountArgs
+}
+
+type filePreviewArgs struct {
+	FileID string `json:"file_id" required:"true" description:"File ID to preview"`
+	accountArgs
+}
+
+type fileUploadArgs struct {
+	Name     string `json:"name" required:"true" description:"File name"`
+	Content  string `json:"content" required:"true" description:"File content (base64 encoded for binary files)"`
+	MimeType string `json:"mime_type" description:"MIME type of the file"`
+	ParentID string `json:"parent_id" description:"Parent folder ID (optional)"`
+	accountArgs
+}
+
+type markdownUploadArgs struct {
+	Name     string `json:"name" required:"true" description:"Document name"`
+	Markdown string `json:"markdown" required:"true" description:"Markdown content to convert and upload (supports headers, lists, code blocks, etc.)"`
+	ParentID string `json:"parent_id" description:"Parent folder ID (optional)"`
+	accountArgs
+}
+
+type markdownReplaceArgs struct {
+	FileID   string `json:"file_id" required:"true" description:"Google Doc file ID to update"`
+	Markdown string `json:"markdown" required:"true" description:"Markdown content to convert and replace"`
+	accountArgs
+}
+
+type fileArgs struct {
+	FileID string `json:"file_id" required:"true" description:"File ID"`
+	accountArgs
+}
+
+type fileUpdateMetadataArgs struct {
+	FileID      string `json:"file_id" required:"true" description:"File ID"`
+	Name        string `json:"name" description:"New file name"`
+	Description string `json:"description" description:"New file description"`
+	accountArgs
+}
+
+type folderCreateArgs struct {
+	Name     string `json:"name" required:"true" description:"Folder name"`
+	ParentID string `json:"parent_id" description:"Parent folder ID (optional)"`
+	accountArgs
+}
+
+type fileMoveArgs struct {
+	FileID      string `json:"file_id" required:"true" description:"File ID to move"`
+	NewParentID string `json:"new_parent_id" required:"true" description:"New parent folder ID"`
+	accountArgs
+}
+
+type fileCopyArgs struct {
+	FileID  string `json:"file_id" required:"true" description:"File ID to copy"`
+	NewName string `json:"new_name" description:"Name for the copy"`
+	accountArgs
+}
+
+type fileDeleteArgs struct {
+	FileID  string `json:"file_id" required:"true" description:"File ID to delete"`
+	Confirm bool   `json:"confirm" description:"Set to true to confirm permanent deletion; not needed when the user confirms it when asked"`
+	accountArgs
+}
+
+type fileTrashArgs struct {
+	FileID string `json:"file_id" required:"true" description:"File ID to trash"`
+	accountArgs
+}
+
+type fileRestoreArgs struct {
+	FileID string `json:"file_id" required:"true" description:"File ID to restore"`
+	accountArgs
+}
+
+type sharedLinkCreateArgs struct {
+	FileID string `json:"file_id" required:"true" description:"File ID"`
+	Role   string `json:"role" required:"true" enum:"reader,writer,commenter" description:"Permission role (reader, writer, commenter)"`
+	Type   string `json:"type" enum:"anyone,user,group,domain" description:"Permission type. 'anyone' = public link (default), 'user'/'group'/'domain' = restricted"`
+	accountArgs
+}
+
+type permissionsCreateArgs struct {
+	FileID string `json:"file_id" required:"true" description:"File ID"`
+	Email  string `json:"email" required:"true" format:"email" description:"User email address"`
+	Role   string `json:"role" required:"true" enum:"reader,writer,commenter" description:"Permission role (reader, writer, commenter)"`
+	accountArgs
+}
+
+type permissionsDeleteArgs struct {
+	FileID       string `json:"file_id" required:"true" description:"File ID"`
+	PermissionID string `json:"permission_id" required:"true" description:"Permission ID to remove"`
+	accountArgs
+}
+
+// onAccount runs a tool of Handler with the client of the account named in
+// its arguments, and records the account in the result
+func onAccount[A interface{ accountName() string }](resolve clientResolver, handle func(*Handler, context.Context, A) (interface{}, error)) func(context.Context, A) (interface{}, error) {
+	return func(ctx context.Context, args A) (interface{}, error) {
+		client, accountUsed, err := resolve(ctx, args.accountName())
+		if err != nil {
+			return nil, err
+		}
+		result, err := handle(NewHandler(client), ctx, args)
+		if err != nil || accountUsed == "" {
+			return result, err
+		}
+
+		switch typed := result.(type) {
+		case map[string]interface{}:
+			typed["account"] = accountUsed
+		case *server.ToolResult:
+			tagAccount(typed, accountUsed)
+		}
+		return result, nil
+	}
+}
+
+// fileTools declares the tools that work on the files of one account,
+// resolving the account with resolve
+func fileTools(resolve clientResolver) server.ToolSet {
+	return server.ToolSet{
+		server.NewTool(server.Tool{
 			Name:        "drive_files_list",
 			Description: "List files and folders in Google Drive",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"parent_id": {
-						Type:        "string",
-						Description: "Parent folder ID (optional, defaults to root)",
-					},
-					"page_size": {
-						Type:        "number",
-						Description: "Number of files to return (max 1000)",
-					},
-				},
-			},
-		},
-		{
+			Annotations: server.ReadOnlyAnnotations(),
+		}, onAccount(resolve, (*Handler).handleFilesList)),
+		server.NewTool(server.Tool{
 			Name:        "drive_files_search",
 			Description: "Search for files in Google Drive",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"name": {
-						Type:        "string",
-						Description: "File name to search for",
-					},
-					"mime_type": {
-						Type:        "string",
-						Description: "MIME type to filter by",
-					},
-					"modified_after": {
-						Type:        "string",
-						Description: "Modified after date (RFC3339 format)",
-					},
-				},
-			},
-		},
-		{
+			Annotations: server.ReadOnlyAnnotations(),
+		}, onAccount(resolve, (*Handler).handleFilesSearch)),
+		server.NewTool(server.Tool{
 			Name:        "drive_file_download",
 			Description: "Download a file from Google Drive",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"file_id": {
-						Type:        "string",
-						Description: "File ID to download",
-					},
-				},
-				Required: []string{"file_id"},
-			},
-		},
-		{
+			Annotations: server.ReadOnlyAnnotations(),
+		}, onAccount(resolve, (*Handler).handleFileDownload)),
+		server.NewTool(server.Tool{
+			Name:        "drive_file_preview",
+			Description: "Show a file as an image: image files themselves, other files as their thumbnail",
+			Annotations: server.ReadOnlyAnnotations(),
+		}, onAccount(resolve, (*Handler).handleFilePreview)),
+		server.NewTool(server.Tool{
 			Name:        "drive_file_upload",
 			Description: "Upload a file to Google Drive",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"name": {
-						Type:        "string",
-						Description: "File name",
-					},
-					"content": {
-						Type:        "string",
-						Description: "File content (base64 encoded for binary files)",
-					},
-					"mime_type": {
-						Type:        "string",
-						Description: "MIME type of the file",
-					},
-					"parent_id": {
-						Type:        "string",
-						Description: "Parent folder ID (optional)",
-					},
-				},
-				Required: []string{"name", "content"},
-			},
-		},
-		{
+			Annotations: server.AdditiveAnnotations(),
+		}, onAccount(resolve, (*Handler).handleFileUpload)),
+		server.NewTool(server.Tool{
 			Name:        "drive_markdown_upload",
 			Description: "Upload Markdown content as a properly formatted Google Doc (RECOMMENDED for any Markdown/formatted text)",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"name": {
-						Type:        "string",
-						Description: "Document name",
-					},
-					"markdown": {
-						Type:        "string",
-						Description: "Markdown content to convert and upload (supports headers, lists, code blocks, etc.)",
-					},
-					"parent_id": {
-						Type:        "string",
-						Description: "Parent folder ID (optional)",
-					},
-				},
-				Required: []string{"name", "markdown"},
-			},
-		},
-		{
+			Annotations: server.AdditiveAnnotations(),
+		}, onAccount(resolve, (*Handler).handleMarkdownUpload)),
+		server.NewTool(server.Tool{
 			Name:        "drive_markdown_replace",
 			Description: "Replace existing Google Doc content with properly formatted Markdown (preserves formatting)",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"file_id": {
-						Type:        "string",
-						Description: "Google Doc file ID to update",
-					},
-					"markdown": {
-						Type:        "string",
-						Description: "Markdown content to convert and replace",
-					},
-				},
-				Required: []string{"file_id", "markdown"},
-			},
-		},
-		{
+			Annotations: server.DestructiveAnnotations(),
+		}, onAccount(resolve, (*Handler).handleMarkdownReplace)),
+		server.NewTool(server.Tool{
 			Name:        "drive_file_get_metadata",
 			Description: "Get metadata for a file",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"file_id": {
-						Type:        "string",
-						Description: "File ID",
-					},
-				},
-				Required: []string{"file_id"},
-			},
-		},
-		{
+			Annotations: server.ReadOnlyAnnotations(),
+		}, onAccount(resolve, (*Handler).handleFileGetMetadata)),
+		server.NewTool(server.Tool{
 			Name:        "drive_file_update_metadata",
 			Description: "Update file metadata",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"file_id": {
-						Type:        "string",
-						Description: "File ID",
-					},
-					"name": {
-						Type:        "string",
-						Description: "New file name",
-					},
-					"description": {
-						Type:        "string",
-						Description: "New file description",
-					},
-				},
-				Required: []string{"file_id"},
-			},
-		},
-		{
+			Annotations: server.DestructiveAnnotations(),
+		}, onAccount(resolve, (*Handler).handleFileUpdateMetadata)),
+		server.NewTool(server.Tool{
 			Name:        "drive_folder_create",
 			Description: "Create a new folder",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"name": {
-						Type:        "string",
-						Description: "Folder name",
-					},
-					"parent_id": {
-						Type:        "string",
-						Description: "Parent folder ID (optional)",
-					},
-				},
-				Required: []string{"name"},
-			},
-		},
-		{
+			Annotations: server.AdditiveAnnotations(),
+		}, onAccount(resolve, (*Handler).handleFolderCreate)),
+		server.NewTool(server.Tool{
 			Name:        "drive_file_move",
 			Description: "Move a file to another folder",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"file_id": {
-						Type:        "string",
-						Description: "File ID to move",
-					},
-					"new_parent_id": {
-						Type:        "string",
-						Description: "New parent folder ID",
-					},
-				},
-				Required: []string{"file_id", "new_parent_id"},
-			},
-		},
-		{
+			Annotations: server.IdempotentAnnotations(),
+		}, onAccount(resolve, (*Handler).handleFileMove)),
+		server.NewTool(server.Tool{
 			Name:        "drive_file_copy",
 			Description: "Copy a file",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"file_id": {
-						Type:        "string",
-						Description: "File ID to copy",
-					},
-					"new_name": {
-						Type:        "string",
-						Description: "Name for the copy",
-					},
-				},
-				Required: []string{"file_id"},
-			},
-		},
-		{
+			Annotations: server.AdditiveAnnotations(),
+		}, onAccount(resolve, (*Handler).handleFileCopy)),
+		server.NewTool(server.Tool{
 			Name:        "drive_file_delete",
-			Description: "Permanently delete a file. WARNING: This action is irreversible. You must set confirm=true to proceed.",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"file_id": {
-						Type:        "string",
-						Description: "File ID to delete",
-					},
-					"confirm": {
-						Type:        "boolean",
-						Description: "Must be set to true to confirm permanent deletion",
-					},
-				},
-				Required: []string{"file_id", "confirm"},
-			},
-		},
-		{
+			Description: "Permanently delete a file. WARNING: This action is irreversible. Set confirm=true to proceed, unless the client asks the user to confirm the deletion.",
+			Annotations: server.DestructiveAnnotations(),
+		}, onAccount(resolve, (*Handler).handleFileDelete)),
+		server.NewTool(server.Tool{
 			Name:        "drive_file_trash",
 			Description: "Move a file to trash",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"file_id": {
-						Type:        "string",
-						Description: "File ID to trash",
-					},
-				},
-				Required: []string{"file_id"},
-			},
-		},
-		{
+			Annotations: server.DestructiveAnnotations(),
+		}, onAccount(resolve, (*Handler).handleFileTrash)),
+		server.NewTool(server.Tool{
 			Name:        "drive_file_restore",
 			Description: "Restore a file from trash",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"file_id": {
-						Type:        "string",
-						Description: "File ID to restore",
-					},
-				},
-				Required: []string{"file_id"},
-			},
-		},
-		{
+			Annotations: server.IdempotentAnnotations(),
+		}, onAccount(resolve, (*Handler).handleFileRestore)),
+		server.NewTool(server.Tool{
 			Name:        "drive_shared_link_create",
 			Description: "Create a shareable link for a file. WARNING: Using type 'anyone' makes the file publicly accessible to anyone with the link.",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"file_id": {
-						Type:        "string",
-						Description: "File ID",
-					},
-					"role": {
-						Type:        "string",
-						Description: "Permission role (reader, writer, commenter)",
-						Enum:        []string{"reader", "writer", "commenter"},
-					},
-					"type": {
-						Type:        "string",
-						Description: "Permission type. 'anyone' = public link (default), 'user'/'group'/'domain' = restricted",
-						Enum:        []string{"anyone", "user", "group", "domain"},
-					},
-				},
-				Required: []string{"file_id", "role"},
-			},
-		},
-		{
+			Annotations: server.IdempotentAnnotations(),
+		}, onAccount(resolve, (*Handler).handleSharedLinkCreate)),
+		server.NewTool(server.Tool{
 			Name:        "drive_permissions_list",
 			Description: "List permissions for a file",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"file_id": {
-						Type:        "string",
-						Description: "File ID",
-					},
-				},
-				Required: []string{"file_id"},
-			},
-		},
-		{
+			Annotations: server.ReadOnlyAnnotations(),
+		}, onAccount(resolve, (*Handler).handlePermissionsList)),
+		server.NewTool(server.Tool{
 			Name:        "drive_permissions_create",
 			Description: "Grant permission to a user",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"file_id": {
-						Type:        "string",
-						Description: "File ID",
-					},
-					"email": {
-						Type:        "string",
-						Description: "User email address",
-					},
-					"role": {
-						Type:        "string",
-						Description: "Permission role (reader, writer, commenter)",
-						Enum:        []string{"reader", "writer", "commenter"},
-					},
-				},
-				Required: []string{"file_id", "email", "role"},
-			},
-		},
-		{
+			Annotations: server.AdditiveAnnotations(),
+		}, onAccount(resolve, (*Handler).handlePermissionsCreate)),
+		server.NewTool(server.Tool{
 			Name:        "drive_permissions_delete",
 			Description: "Remove a permission",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"file_id": {
-						Type:        "string",
-						Description: "File ID",
-					},
-					"permission_id": {
-						Type:        "string",
-						Description: "Permission ID to remove",
-					},
-				},
-				Required: []string{"file_id", "permission_id"},
-			},
-		},
+			Annotations: server.DestructiveAnnotations(),
+		}, onAccount(resolve, (*Handler).handlePermissionsDelete)),
 	}
 }
 
-// HandleToolCall handles a tool call for Drive service
-func (h *Handler) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
-	switch name {
-	case "drive_files_list":
-		var args struct {
-			ParentID string  `json:"parent_id"`
-			PageSize float64 `json:"page_size"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleFilesList(ctx, args.ParentID, int64(args.PageSize))
-
-	case "drive_files_search":
-		var args struct {
-			Name          string `json:"name"`
-			MimeType      string `json:"mime_type"`
-			ModifiedAfter string `json:"modified_after"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleFilesSearch(ctx, args.Name, args.MimeType, args.ModifiedAfter)
-
-	case "drive_file_download":
-		var args struct {
-			FileID string `json:"file_id"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleFileDownload(ctx, args.FileID)
-
-	case "drive_file_upload":
-		var args struct {
-			Name     string `json:"name"`
-			Content  string `json:"content"`
-			MimeType string `json:"mime_type"`
-			ParentID string `json:"parent_id"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleFileUpload(ctx, args.Name, args.Content, args.MimeType, args.ParentID)
-
-	case "drive_markdown_upload":
-		var args struct {
-			Name     string `json:"name"`
-			Markdown string `json:"markdown"`
-			ParentID string `json:"parent_id"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleMarkdownUpload(ctx, args.Name, args.Markdown, args.ParentID)
-
-	case "drive_markdown_replace":
-		var args struct {
-			FileID   string `json:"file_id"`
-			Markdown string `json:"markdown"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleMarkdownReplace(ctx, args.FileID, args.Markdown)
-
-	case "drive_file_get_metadata":
-		var args struct {
-			FileID string `json:"file_id"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleFileGetMetadata(ctx, args.FileID)
-
-	case "drive_file_update_metadata":
-		var args struct {
-			FileID      string `json:"file_id"`
-			Name        string `json:"name"`
-			Description string `json:"description"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleFileUpdateMetadata(ctx, args.FileID, args.Name, args.Description)
-
-	case "drive_folder_create":
-		var args struct {
-			Name     string `json:"name"`
-			ParentID string `json:"parent_id"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleFolderCreate(ctx, args.Name, args.ParentID)
-
-	case "drive_file_move":
-		var args struct {
-			FileID      string `json:"file_id"`
-			NewParentID string `json:"new_parent_id"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleFileMove(ctx, args.FileID, args.NewParentID)
-
-	case "drive_file_copy":
-		var args struct {
-			FileID  string `json:"file_id"`
-			NewName string `json:"new_name"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleFileCopy(ctx, args.FileID, args.NewName)
-
-	case "drive_file_delete":
-		var args struct {
-			FileID  string `json:"file_id"`
-			Confirm bool   `json:"confirm"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleFileDelete(ctx, args.FileID, args.Confirm)
-
-	case "drive_file_trash":
-		var args struct {
-			FileID string `json:"file_id"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleFileTrash(ctx, args.FileID)
-
-	case "drive_file_restore":
-		var args struct {
-			FileID string `json:"file_id"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleFileRestore(ctx, args.FileID)
-
-	case "drive_shared_link_create":
-		var args struct {
-			FileID string `json:"file_id"`
-			Role   string `json:"role"`
-			Type   string `json:"type"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleSharedLinkCreate(ctx, args.FileID, args.Role, args.Type)
-
-	case "drive_permissions_list":
-		var args struct {
-			FileID string `json:"file_id"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handlePermissionsList(ctx, args.FileID)
-
-	case "drive_permissions_create":
-		var args struct {
-			FileID string `json:"file_id"`
-			Email  string `json:"email"`
-			Role   string `json:"role"`
+// ToolDefinitions declares the Drive tools of the handler's client
+func (h *Handler) ToolDefinitions() server.ToolSet {
+	return fileTools(func(ctx context.Context, account string) (*Client, string, error) {
+		if account != "" {
+			return nil, "", fmt.Errorf("account %s is not available: this handler serves a single Drive", account)
 		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handlePermissionsCreate(ctx, args.FileID, args.Email, args.Role)
+		return h.client, "", nil
+	})
+}
 
-	case "drive_permissions_delete":
-		var args struct {
-			FileID       string `json:"file_id"`
-			PermissionID string `json:"permission_id"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handlePermissionsDelete(ctx, args.FileID, args.PermissionID)
+// GetTools returns the available Drive tools
+func (h *Handler) GetTools() []server.Tool {
+	return h.ToolDefinitions().Tools()
+}
 
-	default:
-		return nil, fmt.Errorf("unknown tool: %s", name)
-	}
+// HandleToolCall handles a tool call for Drive service
+func (h *Handler) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
+	return h.ToolDefinitions().Call(ctx, name, arguments)
 }
 
 // Tool handler implementations
-func (h *Handler) handleFilesList(ctx context.Context, parentID string, pageSize int64) (interface{}, error) {
-	if pageSize <= 0 {
-		pageSize = 100
+func (h *Handler) handleFilesList(ctx context.Context, args filesListArgs) (interface{}, error) {
+	if args.PageSize <= 0 {
+		args.PageSize = 100
 	}
 
-	files, err := h.client.ListFiles(ctx, "", pageSize, parentID)
+	files, err := h.client.ListFiles(ctx, "", args.PageSize, args.ParentID)
 	if err != nil {
 		return nil, err
 	}
@@ -585,8 +331,8 @@ func (h *Handler) handleFilesList(ctx context.Context, parentID string, pageSize
 	}, nil
 }
 
-func (h *Handler) handleFilesSearch(ctx context.Context, name, mimeType, modifiedAfter string) (interface{}, error) {
-	files, err := h.client.SearchFiles(ctx, name, mimeType, modifiedAfter)
+func (h *Handler) handleFilesSearch(ctx context.Context, args filesSearchArgs) (interface{}, error) {
+	files, err := h.client.SearchFiles(ctx, args.Name, args.MimeType, args.ModifiedAfter)
 	if err != nil {
 		return nil, err
 	}
@@ -596,13 +342,13 @@ func (h *Handler) handleFilesSearch(ctx context.Context, name, mimeType, modifie
 	}, nil
 }
 
-func (h *Handler) handleFileDownload(ctx context.Context, fileID string) (interface{}, error) {
-	if err := validateID(fileID, "file_id"); err != nil {
+func (h *Handler) handleFileDownload(ctx context.Context, args fileDownloadArgs) (interface{}, error) {
+	if err := validateID(args.FileID, "file_id"); err != nil {
 		return nil, err
 	}
 
 	// Check file size before downloading
-	fileMeta, err := h.client.GetFile(fileID)
+	fileMeta, err := h.client.GetFile(ctx, args.FileID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get file metadata: %w", err)
 	}
@@ -611,32 +357,96 @@ func (h *Handler) handleFileDownload(ctx context.Context, fileID string) (interf
 	}
 
 	var buf bytes.Buffer
-	err = h.client.DownloadFile(ctx, fileID, &buf, maxDownloadSize)
+	err = h.client.DownloadFile(ctx, args.FileID, &buf, maxDownloadSize)
 	if err != nil {
 		return nil, err
 	}
 
-	// Return base64 encoded content
-	return map[string]interface{}{
-		"file_id": fileID,
-		"content": base64.StdEncoding.EncodeToString(buf.Bytes()),
-		"size":    buf.Len(),
+	// Text files are embedded as text, anything else as a base64 blob
+	var contents server.ResourceContents
+	if isTextMimeType(fileMeta.MimeType) && utf8.Valid(buf.Bytes()) {
+		contents = server.TextResource(fileURI(args.FileID, ""), fileMeta.MimeType, buf.String())
+	} else {
+		contents = server.BlobResource(fileURI(args.FileID, ""), fileMeta.MimeType, buf.Bytes())
+	}
+
+	return &server.ToolResult{
+		Structured: map[string]interface{}{
+			"file_id":  args.FileID,
+			"name":     fileMeta.Name,
+			"mimeType": contents.MimeType,
+			"size":     buf.Len(),
+		},
+		Content: []server.Content{server.EmbeddedResource(contents)},
 	}, nil
 }
 
-func (h *Handler) handleFileUpload(ctx context.Context, name, content, mimeType, parentID string) (interface{}, error) {
+func (h *Handler) handleFilePreview(ctx context.Context, args filePreviewArgs) (interface{}, error) {
+	if err := validateID(args.FileID, "file_id"); err != nil {
+		return nil, err
+	}
+
+	fileMeta, err := h.client.GetFile(ctx, args.FileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file metadata: %w", err)
+	}
+
+	var image server.Content
+	switch {
+	case strings.HasPrefix(fileMeta.MimeType, "image/") && fileMeta.Size <= maxPreviewSize:
+		var buf bytes.Buffer
+		if err := h.client.DownloadFile(ctx, args.FileID, &buf, maxPreviewSize); err != nil {
+			return nil, err
+		}
+		image = server.ImageContent(buf.Bytes(), fileMeta.MimeType)
+
+	case fileMeta.ThumbnailLink != "":
+		data, mimeType, err := h.client.FetchThumbnail(ctx, fileMeta.ThumbnailLink, maxPreviewSize)
+		if err != nil {
+			return nil, err
+		}
+		image = server.ImageContent(data, mimeType)
+
+	default:
+		return nil, fmt.Errorf("no preview available for file %s (%s)", args.FileID, fileMeta.MimeType)
+	}
+
+	return &server.ToolResult{
+		Structured: map[string]interface{}{
+			"file_id":  args.FileID,
+			"name":     fileMeta.Name,
+			"mimeType": fileMeta.MimeType,
+		},
+		Content: []server.Content{image},
+	}, nil
+}
+
+// isTextMimeType reports whether files of a MIME type hold text
+func isTextMimeType(mimeType string) bool {
+	if strings.HasPrefix(mimeType, "text/") || strings.HasSuffix(mimeType, "+json") || strings.HasSuffix(mimeType, "+xml") {
+		return true
+	}
+	switch mimeType {
+	case "application/json", "application/xml", "application/javascript", "application/x-yaml":
+		return true
+	default:
+		return false
+	}
+}
+
+func (h *Handler) handleFileUpload(ctx context.Context, args fileUploadArgs) (interface{}, error) {
 	// Check encoded content size before decoding
-	if len(content) > maxUploadSize {
-		return nil, fmt.Errorf("content size %d bytes exceeds maximum upload size of %d bytes (50MB)", len(content), maxUploadSize)
+	if len(args.Content) > maxUploadSize {
+		return nil, fmt.Errorf("content size %d bytes exceeds maximum upload size of %d bytes (50MB)", len(args.Content), maxUploadSize)
 	}
 
 	// Decode base64 content if needed
 	var reader io.Reader
-	if content != "" {
-		decoded, err := base64.StdEncoding.DecodeString(content)
+	if args.Content != "" {
+		decoded, err := base64.StdEncoding.DecodeString(args.Content)
 		if err != nil {
 			// Try as plain text if base64 decode fails
-			reader = bytes.NewReader([]byte(content))
+			reader = bytes.NewReader([]byte(args.Content))
 		} else {
 			// Also check decoded size (base64 inflates by ~33%)
 			if len(decoded) > maxUploadSize {
@@ -646,11 +456,11 @@ func (h *Handler) handleFileUpload(ctx context.Context, name, content, mimeType,
 		}
 	}
 
-	if mimeType == "" {
-		mimeType = "text/plain"
+	if args.MimeType == "" {
+		args.MimeType = "text/plain"
 	}
 
-	file, err := h.client.UploadFile(name, mimeType, reader, parentID)
+	file, err := h.client.UploadFile(ctx, args.Name, args.MimeType, reader, args.ParentID)
 	if err != nil {
 		return nil, err
 	}
@@ -658,11 +468,11 @@ func (h *Handler) handleFileUpload(ctx context.Context, name, content, mimeType,
 	return formatFile(file), nil
 }
 
-func (h *Handler) handleFileGetMetadata(ctx context.Context, fileID string) (interface{}, error) {
-	if err := validateID(fileID, "file_id"); err != nil {
+func (h *Handler) handleFileGetMetadata(ctx context.Context, args fileArgs) (interface{}, error) {
+	if err := validateID(args.FileID, "file_id"); err != nil {
 		return nil, err
 	}
-	file, err := h.client.GetFile(fileID)
+	file, err := h.client.GetFile(ctx, args.FileID)
 	if err != nil {
 		return nil, err
 	}
@@ -670,11 +480,11 @@ func (h *Handler) handleFileGetMetadata(ctx context.Context, fileID string) (int
 	return formatFile(file), nil
 }
 
-func (h *Handler) handleFileUpdateMetadata(ctx context.Context, fileID, name, description string) (interface{}, error) {
-	if err := validateID(fileID, "file_id"); err != nil {
+func (h *Handler) handleFileUpdateMetadata(ctx context.Context, args fileUpdateMetadataArgs) (interface{}, error) {
+	if err := validateID(args.FileID, "file_id"); err != nil {
 		return nil, err
 	}
-	file, err := h.client.UpdateFileMetadata(fileID, name, description)
+	file, err := h.client.UpdateFileMetadata(ctx, args.FileID, args.Name, args.Description)
 	if err != nil {
 		return nil, err
 	}
@@ -682,8 +492,8 @@ func (h *Handler) handleFileUpdateMetadata(ctx context.Context, fileID, name, de
 	return formatFile(file), nil
 }
 
-func (h *Handler) handleFolderCreate(ctx context.Context, name, parentID string) (interface{}, error) {
-	folder, err := h.client.CreateFolder(name, parentID)
+func (h *Handler) handleFolderCreate(ctx context.Context, args folderCreateArgs) (interface{}, error) {
+	folder, err := h.client.CreateFolder(ctx, args.Name, args.ParentID)
 	if err != nil {
 		return nil, err
 	}
@@ -691,14 +501,14 @@ func (h *Handler) handleFolderCreate(ctx context.Context, name, parentID string)
 	return formatFile(folder), nil
 }
 
-func (h *Handler) handleFileMove(ctx context.Context, fileID, newParentID string) (interface{}, error) {
-	if err := validateID(fileID, "file_id"); err != nil {
+func (h *Handler) handleFileMove(ctx context.Context, args fileMoveArgs) (interface{}, error) {
+	if err := validateID(args.FileID, "file_id"); err != nil {
 		return nil, err
 	}
-	if err := validateID(newParentID, "new_parent_id"); err != nil {
+	if err := validateID(args.NewParentID, "new_parent_id"); err != nil {
 		return nil, err
 	}
-	file, err := h.client.MoveFile(fileID, newParentID)
+	file, err := h.client.MoveFile(ctx, args.FileID, args.NewParentID)
 	if err != nil {
 		return nil, err
 	}
@@ -706,11 +516,11 @@ func (h *Handler) handleFileMove(ctx context.Context, fileID, newParentID string
 	return formatFile(file), nil
 }
 
-func (h *Handler) handleFileCopy(ctx context.Context, fileID, newName string) (interface{}, error) {
-	if err := validateID(fileID, "file_id"); err != nil {
+func (h *Handler) handleFileCopy(ctx context.Context, args fileCopyArgs) (interface{}, error) {
+	if err := validateID(args.FileID, "file_id"); err != nil {
 		return nil, err
 	}
-	file, err := h.client.CopyFile(fileID, newName)
+	file, err := h.client.CopyFile(ctx, args.FileID, args.NewName)
 	if err != nil {
 		return nil, err
 	}
@@ -718,74 +528,74 @@ func (h *Handler) handleFileCopy(ctx context.Context, fileID, newName string) (i
 	return formatFile(file), nil
 }
 
-func (h *Handler) handleFileDelete(ctx context.Context, fileID string, confirm bool) (interface{}, error) {
-	if err := validateID(fileID, "file_id"); err != nil {
+func (h *Handler) handleFileDelete(ctx context.Context, args fileDeleteArgs) (interface{}, error) {
+	if err := validateID(args.FileID, "file_id"); err != nil {
 		return nil, err
 	}
-	if !confirm {
+	if !args.Confirm && !server.UserConfirmed(ctx) {
 		return nil, fmt.Errorf("permanent deletion requires confirm=true. This action is irreversible")
 	}
 
-	err := h.client.DeleteFile(fileID)
+	err := h.client.DeleteFile(ctx, args.FileID)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]string{"status": "deleted", "file_id": fileID}, nil
+	return map[string]string{"status": "deleted", "file_id": args.FileID}, nil
 }
 
-func (h *Handler) handleFileTrash(ctx context.Context, fileID string) (interface{}, error) {
-	if err := validateID(fileID, "file_id"); err != nil {
+func (h *Handler) handleFileTrash(ctx context.Context, args fileTrashArgs) (interface{}, error) {
+	if err := validateID(args.FileID, "file_id"); err != nil {
 		return nil, err
 	}
-	err := h.client.TrashFile(fileID)
+	err := h.client.TrashFile(ctx, args.FileID)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]string{"status": "trashed", "file_id": fileID}, nil
+	return map[string]string{"status": "trashed", "file_id": args.FileID}, nil
 }
 
-func (h *Handler) handleFileRestore(ctx context.Context, fileID string) (interface{}, error) {
-	if err := validateID(fileID, "file_id"); err != nil {
+func (h *Handler) handleFileRestore(ctx context.Context, args fileRestoreArgs) (interface{}, error) {
+	if err := validateID(args.FileID, "file_id"); err != nil {
 		return nil, err
 	}
-	err := h.client.RestoreFile(fileID)
+	err := h.client.RestoreFile(ctx, args.FileID)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]string{"status": "restored", "file_id": fileID}, nil
+	return map[string]string{"status": "restored", "file_id": args.FileID}, nil
 }
 
-func (h *Handler) handleSharedLinkCreate(ctx context.Context, fileID, role, permType string) (interface{}, error) {
-	if err := validateID(fileID, "file_id"); err != nil {
+func (h *Handler) handleSharedLinkCreate(ctx context.Context, args sharedLinkCreateArgs) (interface{}, error) {
+	if err := validateID(args.FileID, "file_id"); err != nil {
 		return nil, err
 	}
-	link, err := h.client.CreateShareLink(fileID, role, permType)
+	link, err := h.client.CreateShareLink(ctx, args.FileID, args.Role, args.Type)
 	if err != nil {
 		return nil, err
 	}
 
 	result := map[string]interface{}{
-		"file_id": fileID,
+		"file_id": args.FileID,
 		"link":    link,
-		"role":    role,
-		"type":    permType,
+		"role":    args.Role,
+		"type":    args.Type,
 	}
 
-	if permType == "" || permType == "anyone" {
+	if args.Type == "" || args.Type == "anyone" {
 		result["warning"] = "This file is now publicly accessible to anyone with the link"
 	}
 
 	return result, nil
 }
 
-func (h *Handler) handlePermissionsList(ctx context.Context, fileID string) (interface{}, error) {
-	if err := validateID(fileID, "file_id"); err != nil {
+func (h *Handler) handlePermissionsList(ctx context.Context, args fileArgs) (interface{}, error) {
+	if err := validateID(args.FileID, "file_id"); err != nil {
 		return nil, err
 	}
-	permissions, err := h.client.ListPermissions(fileID)
+	permissions, err := h.client.ListPermissions(ctx, args.FileID)
 	if err != nil {
 		return nil, err
 	}
@@ -803,11 +613,11 @@ func (h *Handler) handlePermissionsList(ctx context.Context, fileID string) (int
 	return result, nil
 }
 
-func (h *Handler) handlePermissionsCreate(ctx context.Context, fileID, email, role string) (interface{}, error) {
-	if err := validateID(fileID, "file_id"); err != nil {
+func (h *Handler) handlePermissionsCreate(ctx context.Context, args permissionsCreateArgs) (interface{}, error) {
+	if err := validateID(args.FileID, "file_id"); err != nil {
 		return nil, err
 	}
-	permission, err := h.client.CreatePermission(fileID, email, role)
+	permission, err := h.client.CreatePermission(ctx, args.FileID, args.Email, args.Role)
 	if err != nil {
 		return nil, err
 	}
@@ -820,19 +630,19 @@ func (h *Handler) handlePermissionsCreate(ctx context.Context, fileID, email, ro
 	}, nil
 }
 
-func (h *Handler) handlePermissionsDelete(ctx context.Context, fileID, permissionID string) (interface{}, error) {
-	if err := validateID(fileID, "file_id"); err != nil {
+func (h *Handler) handlePermissionsDelete(ctx context.Context, args permissionsDeleteArgs) (interface{}, error) {
+	if err := validateID(args.FileID, "file_id"); err != nil {
 		return nil, err
 	}
-	if err := validateID(permissionID, "permission_id"); err != nil {
+	if err := validateID(args.PermissionID, "permission_id"); err != nil {
 		return nil, err
 	}
-	err := h.client.DeletePermission(fileID, permissionID)
+	err := h.client.DeletePermission(ctx, args.FileID, args.PermissionID)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]string{"status": "deleted", "permission_id": permissionID}, nil
+	return map[string]string{"status": "deleted", "permission_id": args.PermissionID}, nil
 }
 
 // formatFile formats a drive file for response
@@ -840,11 +650,11 @@ func formatFile(file interface{}) map[string]interface{} {
 	data := make(map[string]interface{})
 	jsonData, err := json.Marshal(file)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to marshal file data: %v\n", err)
+		logger.Warn("failed to marshal file data", "error", err)
 		return data
 	}
 	if err := json.Unmarshal(jsonData, &data); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to unmarshal file data: %v\n", err)
+		logger.Warn("failed to unmarshal file data", "error", err)
 	}
 	return data
 }
@@ -854,17 +664,17 @@ func formatFiles(files interface{}) []map[string]interface{} {
 	var result []map[string]interface{}
 	jsonData, err := json.Marshal(files)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to marshal files data: %v\n", err)
+		logger.Warn("failed to marshal files data", "error", err)
 		return result
 	}
 	if err := json.Unmarshal(jsonData, &result); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to unmarshal files data: %v\n", err)
+		logger.Warn("failed to unmarshal files data", "error", err)
 	}
 	return result
 }
 
-func (h *Handler) handleMarkdownUpload(ctx context.Context, name, markdown, parentID string) (interface{}, error) {
-	file, err := h.client.UploadMarkdownAsDoc(ctx, name, markdown, parentID)
+func (h *Handler) handleMarkdownUpload(ctx context.Context, args markdownUploadArgs) (interface{}, error) {
+	file, err := h.client.UploadMarkdownAsDoc(ctx, args.Name, args.Markdown, args.ParentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload markdown as doc: %w", err)
 	}
@@ -878,8 +688,8 @@ func (h *Handler) handleMarkdownUpload(ctx context.Context, name, markdown, pare
 	}, nil
 }
 
-func (h *Handler) handleMarkdownReplace(ctx context.Context, fileID, markdown string) (interface{}, error) {
-	file, err := h.client.ReplaceDocWithMarkdown(ctx, fileID, markdown)
+func (h *Handler) handleMarkdownReplace(ctx context.Context, args markdownReplaceArgs) (interface{}, error) {
+	file, err := h.client.ReplaceDocWithMarkdown(ctx, args.FileID, args.Markdown)
 	if err != nil {
 		return nil, fmt.Errorf("failed to replace doc with markdown: %w", err)
 	}
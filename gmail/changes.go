@@ -0,0 +1,121 @@
+package gmail
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go.ngs.io/google-mcp-server/server"
+	"google.golang.org/api/googleapi"
+)
+
+// inboxURI is the resource that subscriptions watch through the history API
+const inboxURI = "gmail://inbox"
+
+// CurrentHistoryID returns the ID of the latest history record of the mailbox
+func (c *Client) CurrentHistoryID(ctx context.Context) (uint64, error) {
+	profile, err := c.service.Users.GetProfile("me").Context(ctx).Do()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get profile: %w", err)
+	}
+	return profile.HistoryId, nil
+}
+
+// InboxChangedSince reports whether messages were added to or removed from the
+// inbox, or relabelled there, after historyID. It also returns the history ID
+// to check from next time. Gmail keeps history for about a week; when
+// historyID is older than that the inbox is reported as changed.
+func (c *Client) InboxChangedSince(ctx context.Context, historyID uint64) (bool, uint64, error) {
+	// One record is enough to know the inbox changed, and the response
+	// carries the mailbox's current history ID either way
+	response, err := c.service.Users.History.List("me").
+		StartHistoryId(historyID).
+		LabelId("INBOX").
+		MaxResults(1).
+		Context(ctx).
+		Do()
+	if err != nil {
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+			latest, err := c.CurrentHistoryID(ctx)
+			if err != nil {
+				return false, 0, err
+			}
+			return true, latest, nil
+		}
+		return false, 0, fmt.Errorf("failed to list history: %w", err)
+	}
+
+	return len(response.History) > 0, response.HistoryId, nil
+}
+
+// DetectChanges implements server.ChangeDetector for the inbox resource. The
+// cursor is a JSON object with the last seen history ID of every account, so
+// the resource is reported as changed when any account's inbox changes or
+// an account is added or removed.
+func (h *MultiAccountHandler) DetectChanges(ctx context.Context, uri string, cursor string) (bool, string, error) {
+	if uri != inboxURI {
+		return false, "", server.ErrNotWatchable
+	}
+
+	clients := h.multiClient.allClients()
+	if len(clients) == 0 && h.client != nil {
+		clients = map[string]*Client{"default": h.client}
+	}
+
+	previous := make(map[string]uint64)
+	if cursor != "" {
+		if err := json.Unmarshal([]byte(cursor), &previous); err != nil {
+			return false, "", fmt.Errorf("invalid inbox cursor: %w", err)
+		}
+	}
+
+	next := make(map[string]uint64, len(clients))
+	changed := false
+	var firstErr error
+	for email, client := range clients {
+		since, known := previous[email]
+		var (
+			accountChanged bool
+			latest         uint64
+			err            error
+		)
+		if known {
+			accountChanged, latest, err = client.InboxChangedSince(ctx, since)
+		} else {
+			// A new account changes the resource unless this is the first check
+			latest, err = client.CurrentHistoryID(ctx)
+			accountChanged = cursor != ""
+		}
+		if err != nil {
+			// Keep the old position so the change is found next time
+			logger.WarnContext(ctx, "failed to check inbox for changes", "account", email, "error", err)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", email, err)
+			}
+			if known {
+				next[email] = since
+			}
+			continue
+		}
+		next[email] = latest
+		changed = changed || accountChanged
+	}
+	if firstErr != nil && len(next) == 0 {
+		return false, "", firstErr
+	}
+
+	for email := range previous {
+		if _, ok := clients[email]; !ok {
+			changed = true
+		}
+	}
+
+	encoded, err := json.Marshal(next)
+	if err != nil {
+		return false, "", err
+	}
+	return changed, string(encoded), nil
+}
@@ -0,0 +1,129 @@
+package gmail
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/gmail/v1"
+	"google.golang.org/api/option"
+)
+
+// fakeMailbox serves the profile and history endpoints of one mailbox. Each
+// delivered message adds a history record; history before expiredBefore is
+// answered with 404 like Gmail does for records it no longer keeps.
+type fakeMailbox struct {
+	mu            sync.Mutex
+	historyID     uint64
+	records       []uint64
+	expiredBefore uint64
+}
+
+func (f *fakeMailbox) deliver() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.historyID++
+	f.records = append(f.records, f.historyID)
+}
+
+func (f *fakeMailbox) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/gmail/v1/users/me/profile":
+		_ = json.NewEncoder(w).Encode(gmail.Profile{HistoryId: f.historyID})
+	case "/gmail/v1/users/me/history":
+		start, _ := strconv.ParseUint(r.URL.Query().Get("startHistoryId"), 10, 64)
+		if start < f.expiredBefore {
+			http.Error(w, `{"error":{"code":404,"message":"Requested entity was not found."}}`, http.StatusNotFound)
+			return
+		}
+		response := gmail.ListHistoryResponse{HistoryId: f.historyID}
+		for _, id := range f.records {
+			if id > start {
+				response.History = append(response.History, &gmail.History{Id: id})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newFakeMailboxClient(t *testing.T, mailbox *fakeMailbox) *Client {
+	t.Helper()
+	ts := httptest.NewServer(mailbox)
+	t.Cleanup(ts.Close)
+
+	service, err := gmail.NewService(context.Background(), option.WithEndpoint(ts.URL+"/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("failed to create gmail service: %v", err)
+	}
+	return &Client{service: service}
+}
+
+func TestInboxDetectChanges(t *testing.T) {
+	ctx := context.Background()
+	work := &fakeMailbox{historyID: 100}
+	personal := &fakeMailbox{historyID: 500}
+	handler := &MultiAccountHandler{multiClient: &MultiAccountClient{clients: map[string]*Client{
+		"work@example.com":     newFakeMailboxClient(t, work),
+		"personal@example.com": newFakeMailboxClient(t, personal),
+	}}}
+
+	changed, cursor, err := handler.DetectChanges(ctx, inboxURI, "")
+	if err != nil {
+		t.Fatalf("DetectChanges failed: %v", err)
+	}
+	if changed {
+		t.Error("expected the first check not to report a change")
+	}
+
+	changed, cursor, err = handler.DetectChanges(ctx, inboxURI, cursor)
+	if err != nil || changed {
+		t.Fatalf("expected no change without new mail, got changed=%v err=%v", changed, err)
+	}
+
+	personal.deliver()
+	changed, cursor, err = handler.DetectChanges(ctx, inboxURI, cursor)
+	if err != nil || !changed {
+		t.Fatalf("expected a change after new mail, got changed=%v err=%v", changed, err)
+	}
+	var positions map[string]uint64
+	if err := json.Unmarshal([]byte(cursor), &positions); err != nil {
+		t.Fatalf("invalid cursor %q: %v", cursor, err)
+	}
+	if positions["personal@example.com"] != 501 || positions["work@example.com"] != 100 {
+		t.Errorf("expected the cursor to advance per account, got %v", positions)
+	}
+
+	changed, cursor, err = handler.DetectChanges(ctx, inboxURI, cursor)
+	if err != nil || changed {
+		t.Fatalf("expected the change to be reported once, got changed=%v err=%v", changed, err)
+	}
+
+	// Expired history is treated as a change and the position is refreshed
+	work.mu.Lock()
+	work.historyID = 200
+	work.expiredBefore = 150
+	work.mu.Unlock()
+	changed, cursor, err = handler.DetectChanges(ctx, inboxURI, cursor)
+	if err != nil || !changed {
+		t.Fatalf("expected expired history to report a change, got changed=%v err=%v", changed, err)
+	}
+	if err := json.Unmarshal([]byte(cursor), &positions); err != nil {
+		t.Fatalf("invalid cursor %q: %v", cursor, err)
+	}
+	if positions["work@example.com"] != 200 {
+		t.Errorf("expected the expired position to be refreshed, got %v", positions)
+	}
+
+	if _, _, err := handler.DetectChanges(ctx, "gmail://message/1", ""); err == nil {
+		t.Error("expected messages not to be watchable")
+	}
+}
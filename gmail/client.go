@@ -5,9 +5,12 @@ import (
 	"fmt"
 
 	"go.ngs.io/google-mcp-server/auth"
+	"go.ngs.io/google-mcp-server/logging"
 	"google.golang.org/api/gmail/v1"
 )
 
+var logger = logging.Logger("gmail")
+
 // Client wraps the Google Gmail API client
 type Client struct {
 	service *gmail.Service
@@ -26,7 +29,7 @@ func NewClient(ctx context.Context, oauth *auth.OAuthClient) (*Client, error) {
 }
 
 // ListMessages lists messages
-func (c *Client) ListMessages(query string, maxResults int64) ([]*gmail.Message, error) {
+func (c *Client) ListMessages(ctx context.Context, query string, maxResults int64) ([]*gmail.Message, error) {
 	call := c.service.Users.Messages.List("me")
 	if query != "" {
 		call = call.Q(query)
@@ -35,7 +38,7 @@ func (c *Client) ListMessages(query string, maxResults int64) ([]*gmail.Message,
 		call = call.MaxResults(maxResults)
 	}
 
-	response, err := call.Do()
+	response, err := call.Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list messages: %w", err)
 	}
@@ -43,9 +46,18 @@ func (c *Client) ListMessages(query string, maxResults int64) ([]*gmail.Message,
 	return response.Messages, nil
 }
 
+// ListLabels lists the labels of the mailbox
+func (c *Client) ListLabels(ctx context.Context) ([]*gmail.Label, error) {
+	response, err := c.service.Users.Labels.List("me").Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list labels: %w", err)
+	}
+	return response.Labels, nil
+}
+
 // GetMessage gets a message by ID
-func (c *Client) GetMessage(messageID string) (*gmail.Message, error) {
-	message, err := c.service.Users.Messages.Get("me", messageID).Do()
+func (c *Client) GetMessage(ctx context.Context, messageID string) (*gmail.Message, error) {
+	message, err := c.service.Users.Messages.Get("me", messageID).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get message: %w", err)
 	}
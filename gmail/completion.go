@@ -0,0 +1,46 @@
+package gmail
+
+import (
+	"context"
+	"strings"
+
+	"go.ngs.io/google-mcp-server/server"
+)
+
+// CompleteArgument suggests label filters for search queries. Typing "label:"
+// in a query argument lists the mailbox labels, including user labels. Only
+// the last term of the query is completed, so earlier filters are kept.
+func (h *MultiAccountHandler) CompleteArgument(ctx context.Context, ref server.CompletionRef, argument string, arguments map[string]string) ([]server.CompletionCandidate, error) {
+	if argument != "query" {
+		return nil, nil
+	}
+
+	client, _, err := h.multiClient.GetClientForContext(ctx, arguments["account"])
+	if err != nil {
+		if h.client == nil || arguments["account"] != "" {
+			return nil, err
+		}
+		client = h.client
+	}
+
+	labels, err := client.ListLabels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]server.CompletionCandidate, 0, len(labels))
+	for _, label := range labels {
+		candidates = append(candidates, server.CompletionCandidate{
+			Value: "label:" + labelQueryName(label.Name),
+			Label: label.Name,
+			Term:  true,
+		})
+	}
+	return candidates, nil
+}
+
+// labelQueryName formats a label name for a search query. Gmail search
+// expects spaces and slashes in label names to be written as dashes.
+func labelQueryName(name string) string {
+	return strings.NewReplacer(" ", "-", "/", "-").Replace(name)
+}
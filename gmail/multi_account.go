@@ -31,7 +31,7 @@ func NewMultiAccountClient(ctx context.Context, accountManager *auth.AccountMana
 	for email, oauthClient := range accountManager.GetAllOAuthClients() {
 		service, err := gmail.NewService(ctx, option.WithHTTPClient(oauthClient.GetHTTPClient()))
 		if err != nil {
-			fmt.Printf("Warning: failed to create gmail service for %s: %v\n", email, err)
+			logger.Warn("failed to create gmail service", "account", email, "error", err)
 			continue
 		}
 		mac.clients[email] = &Client{service: service}
@@ -93,40 +93,40 @@ func (mac *MultiAccountClient) GetClientForContext(ctx context.Context, hint str
 	return nil, "", fmt.Errorf("please specify account: %s", strings.Join(accountList, ", "))
 }
 
+// allClients returns a snapshot of the clients of all accounts
+func (mac *MultiAccountClient) allClients() map[string]*Client {
+	mac.mu.RLock()
+	defer mac.mu.RUnlock()
+	clients := make(map[string]*Client, len(mac.clients))
+	for email, client := range mac.clients {
+		clients[email] = client
+	}
+	return clients
+}
+
 // SearchAcrossAccounts searches for messages across all accounts
 func (mac *MultiAccountClient) SearchAcrossAccounts(ctx context.Context, query string, maxResults int64) (map[string][]*gmail.Message, error) {
 	results := make(map[string][]*gmail.Message)
-	var wg sync.WaitGroup
 	var mu sync.Mutex
 	errors := make([]error, 0)
 
-	mac.mu.RLock()
-	clients := make(map[string]*Client)
-	for email, client := range mac.clients {
-		clients[email] = client
-	}
-	mac.mu.RUnlock()
-
-	for email, client := range clients {
-		wg.Add(1)
-		go func(email string, client *Client) {
-			defer wg.Done()
-
-			messages, err := client.ListMessages(query, maxResults)
-			if err != nil {
-				mu.Lock()
-				errors = append(errors, fmt.Errorf("%s: %w", email, err))
-				mu.Unlock()
-				return
-			}
+	clients := mac.allClients()
+	progress := server.NewProgressCounter(ctx, len(clients))
+	auth.ForEachAccount(clients, func(email string, client *Client) {
+		defer progress.Step("searched " + email)
 
+		messages, err := client.ListMessages(ctx, query, maxResults)
+		if err != nil {
 			mu.Lock()
-			results[email] = messages
+			errors = append(errors, fmt.Errorf("%s: %w", email, err))
 			mu.Unlock()
-		}(email, client)
-	}
+			return
+		}
 
-	wg.Wait()
+		mu.Lock()
+		results[email] = messages
+		mu.Unlock()
+	})
 
 	// If all searches failed, return the first error
 	if len(errors) == len(clients) && len(errors) > 0 {
@@ -148,7 +148,7 @@ func NewMultiAccountHandler(accountManager *auth.AccountManager, defaultClient *
 	multiClient, err := NewMultiAccountClient(ctx, accountManager)
 	if err != nil {
 		// Log error but continue with limited functionality
-		fmt.Printf("Warning: failed to initialize multi-account client: %v\n", err)
+		logger.Warn("failed to initialize multi-account client", "error", err)
 		multiClient = &MultiAccountClient{
 			accountManager: accountManager,
 			clients:        make(map[string]*Client),
@@ -161,99 +161,63 @@ func NewMultiAccountHandler(accountManager *auth.AccountManager, defaultClient *
 	}
 }
 
-// GetTools returns the available Gmail tools with multi-account support
-func (h *MultiAccountHandler) GetTools() []server.Tool {
-	return []server.Tool{
-		{
-			Name:        "gmail_messages_list",
-			Description: "List email messages",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"query": {
-						Type:        "string",
-						Description: "Search query (e.g., 'from:user@example.com')",
-					},
-					"max_results": {
-						Type:        "number",
-						Description: "Maximum number of results",
-					},
-					"account": {
-						Type:        "string",
-						Description: "Email address of the account to use (optional)",
-					},
-				},
-			},
-		},
-		{
-			Name:        "gmail_message_get",
-			Description: "Get email message details",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"message_id": {
-						Type:        "string",
-						Description: "Message ID",
-					},
-					"account": {
-						Type:        "string",
-						Description: "Email address of the account to use (optional)",
-					},
-				},
-				Required: []string{"message_id"},
-			},
-		},
-		{
+// searchAllAccountsArgs are the arguments of gmail_messages_list_all_accounts
+type searchAllAccountsArgs struct {
+	Query      string `json:"query" description:"Search query (e.g., 'is:unread')"`
+	MaxResults int64  `json:"max_results" minimum:"1" maximum:"500" description:"Maximum number of results per account"`
+}
+
+// ToolDefinitions declares the Gmail tools with multi-account support
+func (h *MultiAccountHandler) ToolDefinitions() server.ToolSet {
+	return append(messageTools(h.clientForAccount),
+		server.NewTool(server.Tool{
 			Name:        "gmail_messages_list_all_accounts",
 			Description: "List messages from all authenticated accounts",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"query": {
-						Type:        "string",
-						Description: "Search query (e.g., 'is:unread')",
-					},
-					"max_results": {
-						Type:        "number",
-						Description: "Maximum number of results per account",
-					},
-				},
-			},
-		},
-	}
+			Annotations: server.ReadOnlyAnnotations(),
+		}, h.handleSearchAllAccounts),
+	)
+}
+
+// GetTools returns the available Gmail tools with multi-account support
+func (h *MultiAccountHandler) GetTools() []server.Tool {
+	return h.ToolDefinitions().Tools()
 }
 
 // HandleToolCall handles a tool call for Gmail service with multi-account support
 func (h *MultiAccountHandler) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
-	switch name {
-	case "gmail_messages_list":
-		var args struct {
-			Query      string  `json:"query"`
-			MaxResults float64 `json:"max_results"`
-			Account    string  `json:"account"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
+	return h.ToolDefinitions().Call(ctx, name, arguments)
+}
 
-		// Try to get client for specified account
-		client, accountUsed, err := h.multiClient.GetClientForContext(ctx, args.Account)
-		if err != nil {
-			// Fall back to default client if available
-			if h.client != nil {
-				client = h.client
-				accountUsed = "default"
-			} else {
-				return nil, err
-			}
+// clientForAccount returns the client of the named account, falling back to
+// the default client if the account cannot be resolved
+func (h *MultiAccountHandler) clientForAccount(ctx context.Context, account string) (*Client, string, error) {
+	client, accountUsed, err := h.multiClient.GetClientForContext(ctx, account)
+	if err != nil {
+		if h.client == nil {
+			return nil, "", err
 		}
+		return h.client, "default", nil
+	}
+	return client, accountUsed, nil
+}
 
-		messages, err := client.ListMessages(args.Query, int64(args.MaxResults))
-		if err != nil {
-			return nil, err
-		}
+func (h *MultiAccountHandler) handleSearchAllAccounts(ctx context.Context, args searchAllAccountsArgs) (interface{}, error) {
+	// Default query to inbox if not specified
+	query := args.Query
+	if query == "" {
+		query = "in:inbox"
+	}
 
-		// Format messages for response
+	// Search across all accounts
+	results, err := h.multiClient.SearchAcrossAccounts(ctx, query, args.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	// Format results
+	formattedResults := make(map[string]interface{})
+	totalMessages := 0
+	for email, messages := range results {
 		messageList := make([]map[string]interface{}, len(messages))
 		for i, msg := range messages {
 			messageList[i] = map[string]interface{}{
@@ -261,125 +225,25 @@ func (h *MultiAccountHandler) HandleToolCall(ctx context.Context, name string, a
 				"threadId": msg.ThreadId,
 			}
 		}
-		return map[string]interface{}{
+		formattedResults[email] = map[string]interface{}{
 			"messages": messageList,
-			"account":  accountUsed,
-		}, nil
-
-	case "gmail_message_get":
-		var args struct {
-			MessageID string `json:"message_id"`
-			Account   string `json:"account"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-
-		// Try to get client for specified account
-		client, accountUsed, err := h.multiClient.GetClientForContext(ctx, args.Account)
-		if err != nil {
-			// Fall back to default client if available
-			if h.client != nil {
-				client = h.client
-				accountUsed = "default"
-			} else {
-				return nil, err
-			}
-		}
-
-		message, err := client.GetMessage(args.MessageID)
-		if err != nil {
-			return nil, err
-		}
-
-		// Format message for response
-		result := map[string]interface{}{
-			"id":           message.Id,
-			"threadId":     message.ThreadId,
-			"labelIds":     message.LabelIds,
-			"snippet":      message.Snippet,
-			"historyId":    message.HistoryId,
-			"internalDate": message.InternalDate,
-			"sizeEstimate": message.SizeEstimate,
-			"account":      accountUsed,
+			"count":    len(messages),
 		}
-
-		// Extract headers for easier access
-		if message.Payload != nil && message.Payload.Headers != nil {
-			headers := make(map[string]string)
-			for _, header := range message.Payload.Headers {
-				headers[header.Name] = header.Value
-			}
-			result["headers"] = headers
-
-			// Add body if available
-			if message.Payload.Body != nil && message.Payload.Body.Data != "" {
-				result["body"] = message.Payload.Body.Data
-			}
-		}
-
-		return result, nil
-
-	case "gmail_messages_list_all_accounts":
-		var args struct {
-			Query      string  `json:"query"`
-			MaxResults float64 `json:"max_results"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-
-		// Default query to inbox if not specified
-		query := args.Query
-		if query == "" {
-			query = "in:inbox"
-		}
-
-		// Search across all accounts
-		results, err := h.multiClient.SearchAcrossAccounts(ctx, query, int64(args.MaxResults))
-		if err != nil {
-			return nil, err
-		}
-
-		// Format results
-		formattedResults := make(map[string]interface{})
-		totalMessages := 0
-		for email, messages := range results {
-			messageList := make([]map[string]interface{}, len(messages))
-			for i, msg := range messages {
-				messageList[i] = map[string]interface{}{
-					"id":       msg.Id,
-					"threadId": msg.ThreadId,
-				}
-			}
-			formattedResults[email] = map[string]interface{}{
-				"messages": messageList,
-				"count":    len(messages),
-			}
-			totalMessages += len(messages)
-		}
-
-		return map[string]interface{}{
-			"accounts":      formattedResults,
-			"total_count":   totalMessages,
-			"account_count": len(results),
-		}, nil
-
-	default:
-		// Fall back to original handler for backward compatibility
-		if h.client != nil {
-			handler := &Handler{client: h.client}
-			return handler.HandleToolCall(ctx, name, arguments)
-		}
-		return nil, fmt.Errorf("unknown tool: %s", name)
+		totalMessages += len(messages)
 	}
+
+	return map[string]interface{}{
+		"accounts":      formattedResults,
+		"total_count":   totalMessages,
+		"account_count": len(results),
+	}, nil
 }
 
 // GetResources returns the available Gmail resources
 func (h *MultiAccountHandler) GetResources() []server.Resource {
 	return []server.Resource{
 		{
-			URI:         "gmail://inbox",
+			URI:         inboxURI,
 			Name:        "Inbox",
 			Description: "Gmail inbox messages",
 			MimeType:    "application/json",
@@ -389,13 +253,13 @@ func (h *MultiAccountHandler) GetResources() []server.Resource {
 
 // HandleResourceCall handles a resource call for Gmail service
 func (h *MultiAccountHandler) HandleResourceCall(ctx context.Context, uri string) (interface{}, error) {
-	if uri == "gmail://inbox" {
+	if uri == inboxURI {
 		// List inbox messages from all accounts
 		results, err := h.multiClient.SearchAcrossAccounts(ctx, "in:inbox", 20)
 		if err != nil {
 			// Fall back to default client if available
 			if h.client != nil {
-				messages, err := h.client.ListMessages("in:inbox", 20)
+				messages, err := h.client.ListMessages(ctx, "in:inbox", 20)
 				if err != nil {
 					return nil, err
 				}
@@ -425,3 +289,74 @@ func (h *MultiAccountHandler) HandleResourceCall(ctx context.Context, uri string
 	}
 	return nil, fmt.Errorf("unknown resource: %s", uri)
 }
+
+// formatMessage converts a message into the response shape shared by
+// gmail_message_get and the message resource template
+func formatMessage(message *gmail.Message, accountUsed string) map[string]interface{} {
+	result := map[string]interface{}{
+		"id":           message.Id,
+		"threadId":     message.ThreadId,
+		"labelIds":     message.LabelIds,
+		"snippet":      message.Snippet,
+		"historyId":    message.HistoryId,
+		"internalDate": message.InternalDate,
+		"sizeEstimate": message.SizeEstimate,
+		"account":      accountUsed,
+	}
+
+	// Extract headers for easier access
+	if message.Payload != nil && message.Payload.Headers != nil {
+		headers := make(map[string]string)
+		for _, header := range message.Payload.Headers {
+			headers[header.Name] = header.Value
+		}
+		result["headers"] = headers
+
+		// Add body if available
+		if message.Payload.Body != nil && message.Payload.Body.Data != "" {
+			result["body"] = message.Payload.Body.Data
+		}
+	}
+
+	return result
+}
+
+// messageURITemplate addresses a single message by ID
+const messageURITemplate = "gmail://message/{id}{?account}"
+
+// GetResourceTemplates returns the parameterized Gmail resources
+func (h *MultiAccountHandler) GetResourceTemplates() []server.ResourceTemplate {
+	return []server.ResourceTemplate{
+		{
+			URITemplate: messageURITemplate,
+			Name:        "Gmail Message",
+			Description: "A Gmail message by ID with headers and body; add ?account=<email> to choose the mailbox",
+			MimeType:    "application/json",
+		},
+	}
+}
+
+// HandleResourceTemplateCall reads a parameterized Gmail resource
+func (h *MultiAccountHandler) HandleResourceTemplateCall(ctx context.Context, uri string, uriTemplate string, variables map[string]string) (interface{}, error) {
+	switch uriTemplate {
+	case messageURITemplate:
+		client, accountUsed, err := h.multiClient.GetClientForContext(ctx, variables["account"])
+		if err != nil {
+			// Only fall back to the default client when no account was named
+			if h.client == nil || variables["account"] != "" {
+				return nil, err
+			}
+			client = h.client
+			accountUsed = "default"
+		}
+
+		message, err := client.GetMessage(ctx, variables["id"])
+		if err != nil {
+			return nil, err
+		}
+		return formatMessage(message, accountUsed), nil
+
+	default:
+		return nil, fmt.Errorf("unknown resource: %s", uri)
+	}
+}
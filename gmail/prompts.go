@@ -0,0 +1,70 @@
+package gmail
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.ngs.io/google-mcp-server/server"
+)
+
+// GetPrompts returns the Gmail prompt templates
+func (h *MultiAccountHandler) GetPrompts() []server.Prompt {
+	return []server.Prompt{
+		{
+			Name:        "gmail_triage_inbox",
+			Description: "Triage my inbox: group unread mail by urgency and suggest next actions",
+			Arguments: []server.PromptArgument{
+				{
+					Name:        "account",
+					Description: "Email address of the account to triage (defaults to all accounts)",
+				},
+				{
+					Name:        "query",
+					Description: "Gmail search query selecting the messages to triage (default: is:unread in:inbox)",
+				},
+				{
+					Name:        "max_messages",
+					Description: "Maximum number of messages to review (default: 25)",
+				},
+			},
+		},
+	}
+}
+
+// GetPrompt renders a Gmail prompt template
+func (h *MultiAccountHandler) GetPrompt(ctx context.Context, name string, arguments map[string]string) (*server.PromptResult, error) {
+	switch name {
+	case "gmail_triage_inbox":
+		return triageInboxPrompt(arguments), nil
+	default:
+		return nil, fmt.Errorf("unknown prompt: %s", name)
+	}
+}
+
+// triageInboxPrompt builds the instructions for an inbox triage session
+func triageInboxPrompt(arguments map[string]string) *server.PromptResult {
+	query := strings.TrimSpace(arguments["query"])
+	if query == "" {
+		query = "is:unread in:inbox"
+	}
+	maxMessages := strings.TrimSpace(arguments["max_messages"])
+	if maxMessages == "" {
+		maxMessages = "25"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Please triage my inbox.\n\n")
+	if account := strings.TrimSpace(arguments["account"]); account != "" {
+		fmt.Fprintf(&sb, "1. Call gmail_messages_list with query %q, max_results %s and account %q.\n", query, maxMessages, account)
+	} else {
+		fmt.Fprintf(&sb, "1. Call gmail_messages_list_all_accounts with query %q and max_results %s.\n", query, maxMessages)
+	}
+	sb.WriteString("2. For messages whose subject and snippet are not enough to judge, call gmail_message_get.\n")
+	sb.WriteString("3. Group the messages into: Needs reply today, Needs action this week, FYI / read later, and Likely noise (newsletters, notifications, promotions).\n")
+	sb.WriteString("4. For each message list the sender, subject and a one-line summary; for anything needing a reply, draft a short suggested response.\n")
+	sb.WriteString("5. Finish with the three most important things I should do next.\n\n")
+	sb.WriteString("Do not modify, archive or delete any messages.")
+
+	return server.UserPrompt("Triage unread email and suggest next actions", sb.String())
+}
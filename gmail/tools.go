@@ -18,115 +18,117 @@ func NewHandler(client *Client) *Handler {
 	return &Handler{client: client}
 }
 
-// GetTools returns the available Gmail tools
-func (h *Handler) GetTools() []server.Tool {
-	return []server.Tool{
-		{
-			Name:        "gmail_messages_list",
-			Description: "List email messages",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"query": {
-						Type:        "string",
-						Description: "Search query (e.g., 'from:user@example.com')",
-					},
-					"max_results": {
-						Type:        "number",
-						Description: "Maximum number of results",
-					},
-				},
-			},
-		},
-		{
-			Name:        "gmail_message_get",
-			Description: "Get email message details",
-			InputSchema: server.InputSchema{
-				Type: "object",
+// messagesListOutputSchema describes the result of gmail_messages_list
+var messagesListOutputSchema = &server.OutputSchema{
+	Type: "object",
+	Properties: map[string]server.Property{
+		"messages": {
+			Type:        "array",
+			Description: "Matching messages, newest first",
+			Items: &server.Property{
+				Type:        "object",
+				Description: "Message reference; fetch details with gmail_message_get",
 				Properties: map[string]server.Property{
-					"message_id": {
-						Type:        "string",
-						Description: "Message ID",
-					},
+					"id":       {Type: "string", Description: "Message ID"},
+					"threadId": {Type: "string", Description: "ID of the thread the message belongs to"},
 				},
-				Required: []string{"message_id"},
 			},
 		},
-	}
+		"account": {Type: "string", Description: "Account that was searched"},
+	},
+	Required: []string{"messages"},
 }
 
-// HandleToolCall handles a tool call for Gmail service
-func (h *Handler) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
-	switch name {
-	case "gmail_messages_list":
-		var args struct {
-			Query      string  `json:"query"`
-			MaxResults float64 `json:"max_results"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		messages, err := h.client.ListMessages(args.Query, int64(args.MaxResults))
-		if err != nil {
-			return nil, err
-		}
+// clientResolver returns the client for the account named in the arguments
+// of a tool, and the account it belongs to
+type clientResolver func(ctx context.Context, account string) (*Client, string, error)
 
-		// Format messages for response
-		messageList := make([]map[string]interface{}, len(messages))
-		for i, msg := range messages {
-			messageList[i] = map[string]interface{}{
-				"id":       msg.Id,
-				"threadId": msg.ThreadId,
-			}
-		}
-		return map[string]interface{}{
-			"messages": messageList,
-		}, nil
+// Arguments of the Gmail tools. The input schemas are generated from these
+// structs, see server.SchemaOf.
 
-	case "gmail_message_get":
-		var args struct {
-			MessageID string `json:"message_id"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		message, err := h.client.GetMessage(args.MessageID)
-		if err != nil {
-			return nil, err
-		}
+type listMessagesArgs struct {
+	Query      string `json:"query" description:"Search query (e.g., 'from:user@example.com')"`
+	MaxResults int64  `json:"max_results" minimum:"1" maximum:"500" description:"Maximum number of results"`
+	Account    string `json:"account,omitempty" description:"Email address of the account to use (optional)"`
+}
 
-		// Format message for response
-		result := map[string]interface{}{
-			"id":           message.Id,
-			"threadId":     message.ThreadId,
-			"labelIds":     message.LabelIds,
-			"snippet":      message.Snippet,
-			"historyId":    message.HistoryId,
-			"internalDate": message.InternalDate,
-			"sizeEstimate": message.SizeEstimate,
-		}
+type getMessageArgs struct {
+	MessageID string `json:"message_id" required:"true" description:"Message ID"`
+	Account   string `json:"account,omitempty" description:"Email address of the account to use (optional)"`
+}
 
-		// Extract headers for easier access
-		if message.Payload != nil && message.Payload.Headers != nil {
-			headers := make(map[string]string)
-			for _, header := range message.Payload.Headers {
-				headers[header.Name] = header.Value
+// messageTools declares the tools that read one mailbox, resolving the
+// mailbox with resolve
+func messageTools(resolve clientResolver) server.ToolSet {
+	return server.ToolSet{
+		server.NewTool(server.Tool{
+			Name:         "gmail_messages_list",
+			Description:  "List email messages",
+			Annotations:  server.ReadOnlyAnnotations(),
+			OutputSchema: messagesListOutputSchema,
+		}, func(ctx context.Context, args listMessagesArgs) (interface{}, error) {
+			client, accountUsed, err := resolve(ctx, args.Account)
+			if err != nil {
+				return nil, err
 			}
-			result["headers"] = headers
 
-			// Add body if available
-			if message.Payload.Body != nil && message.Payload.Body.Data != "" {
-				result["body"] = message.Payload.Body.Data
+			messages, err := client.ListMessages(ctx, args.Query, args.MaxResults)
+			if err != nil {
+				return nil, err
 			}
-		}
 
-		return result, nil
+			// Format messages for response
+			messageList := make([]map[string]interface{}, len(messages))
+			for i, msg := range messages {
+				messageList[i] = map[string]interface{}{
+					"id":       msg.Id,
+					"threadId": msg.ThreadId,
+				}
+			}
+			return map[string]interface{}{
+				"messages": messageList,
+				"account":  accountUsed,
+			}, nil
+		}),
+		server.NewTool(server.Tool{
+			Name:        "gmail_message_get",
+			Description: "Get email message details",
+			Annotations: server.ReadOnlyAnnotations(),
+		}, func(ctx context.Context, args getMessageArgs) (interface{}, error) {
+			client, accountUsed, err := resolve(ctx, args.Account)
+			if err != nil {
+				return nil, err
+			}
 
-	default:
-		return nil, fmt.Errorf("unknown tool: %s", name)
+			message, err := client.GetMessage(ctx, args.MessageID)
+			if err != nil {
+				return nil, err
+			}
+			return formatMessage(message, accountUsed), nil
+		}),
 	}
 }
 
+// ToolDefinitions declares the Gmail tools of the handler's client
+func (h *Handler) ToolDefinitions() server.ToolSet {
+	return messageTools(func(ctx context.Context, account string) (*Client, string, error) {
+		if account != "" {
+			return nil, "", fmt.Errorf("account %s is not available: this handler serves a single mailbox", account)
+		}
+		return h.client, "default", nil
+	})
+}
+
+// GetTools returns the available Gmail tools
+func (h *Handler) GetTools() []server.Tool {
+	return h.ToolDefinitions().Tools()
+}
+
+// HandleToolCall handles a tool call for Gmail service
+func (h *Handler) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
+	return h.ToolDefinitions().Call(ctx, name, arguments)
+}
+
 // GetResources returns the available Gmail resources
 func (h *Handler) GetResources() []server.Resource {
 	return []server.Resource{
@@ -142,7 +144,7 @@ func (h *Handler) GetResources() []server.Resource {
 // HandleResourceCall handles a resource call for Gmail service
 func (h *Handler) HandleResourceCall(ctx context.Context, uri string) (interface{}, error) {
 	if uri == "gmail://inbox" {
-		messages, err := h.client.ListMessages("in:inbox", 20)
+		messages, err := h.client.ListMessages(ctx, "in:inbox", 20)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,127 @@
+// Package mcptest is a client of the Streamable HTTP transport of the MCP
+// server for tests: it initializes sessions, calls tools and reads the
+// messages the server sends on a session's SSE stream.
+package mcptest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// SessionHeader is the header carrying the session ID
+const SessionHeader = "Mcp-Session-Id"
+
+// Post sends a JSON-RPC message to an MCP endpoint and returns the response.
+// An empty sessionID sends the message outside a session.
+func Post(t testing.TB, endpoint, sessionID, accept, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", accept)
+	if sessionID != "" {
+		req.Header.Set(SessionHeader, sessionID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return resp
+}
+
+// Initialize performs the initialize handshake requesting the given protocol
+// version and declaring the given client capabilities, and returns the
+// session ID
+func Initialize(t testing.TB, endpoint, version, capabilities string) string {
+	t.Helper()
+	resp := Post(t, endpoint, "", "application/json",
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"`+version+`","capabilities":`+capabilities+`,"clientInfo":{"name":"test","version":"1"}}}`)
+	_ = resp.Body.Close()
+	sessionID := resp.Header.Get(SessionHeader)
+	if sessionID == "" {
+		t.Fatal("expected session ID header on initialize response")
+	}
+	return sessionID
+}
+
+// CallTool calls a tool with the given tools/call params and returns the
+// text of the result and whether it is an error
+func CallTool(t testing.TB, endpoint, sessionID, params string) (string, bool) {
+	t.Helper()
+	resp := Post(t, endpoint, sessionID, "application/json", `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":`+params+`}`)
+	defer func() { _ = resp.Body.Close() }()
+	var msg struct {
+		Result struct {
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+			IsError bool `json:"isError"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil || len(msg.Result.Content) == 0 {
+		t.Fatalf("failed to decode tools/call response: %v", err)
+	}
+	return msg.Result.Content[0].Text, msg.Result.IsError
+}
+
+// Listen opens the standalone SSE stream of a session and sends every
+// message with the given method, such as a server request, to the returned
+// channel. The stream is closed when the test ends.
+func Listen(t testing.TB, endpoint, sessionID, method string) <-chan json.RawMessage {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set(SessionHeader, sessionID)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to open SSE stream: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for the SSE stream, got %d", resp.StatusCode)
+	}
+
+	received := make(chan json.RawMessage, 10)
+	go func() {
+		defer close(received)
+		defer func() { _ = resp.Body.Close() }()
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			data := []byte(strings.TrimPrefix(line, "data: "))
+			var msg struct {
+				Method string `json:"method"`
+			}
+			if json.Unmarshal(data, &msg) == nil && msg.Method == method {
+				received <- json.RawMessage(data)
+			}
+		}
+	}()
+	return received
+}
+
+// Reply answers a request the server sent, such as one received from
+// Listen, with the given JSON result
+func Reply(t testing.TB, endpoint, sessionID string, request json.RawMessage, result string) {
+	t.Helper()
+	var msg struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal(request, &msg); err != nil {
+		t.Fatalf("failed to decode server request: %v", err)
+	}
+	resp := Post(t, endpoint, sessionID, "application/json",
+		`{"jsonrpc":"2.0","id":`+string(msg.ID)+`,"result":`+result+`}`)
+	_ = resp.Body.Close()
+}
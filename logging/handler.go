@@ -0,0 +1,137 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// handler writes records to stderr and forwards them to the sink, each with
+// its own minimum level
+type handler struct {
+	state  *state
+	attrs  []slog.Attr // bound with Logger.With, kept for the sink
+	groups []string
+}
+
+// Enabled reports whether either destination wants records of this level
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	if level >= h.state.level.Level() {
+		return true
+	}
+	h.state.mu.RLock()
+	defer h.state.mu.RUnlock()
+	return h.state.sink != nil && level >= h.state.sinkLevel.Level()
+}
+
+// Handle writes the record, with context attributes added, to each
+// destination whose level it meets
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	ctxAttrs := attrsFromContext(ctx)
+
+	var err error
+	if r.Level >= h.state.level.Level() {
+		out := r.Clone()
+		out.AddAttrs(ctxAttrs...)
+		h.state.mu.RLock()
+		output := h.state.output
+		h.state.mu.RUnlock()
+		err = h.withOutput(output).Handle(ctx, out)
+	}
+
+	h.state.mu.RLock()
+	sink := h.state.sink
+	h.state.mu.RUnlock()
+	if sink != nil && r.Level >= h.state.sinkLevel.Level() {
+		sink(ctx, h.entry(r, ctxAttrs))
+	}
+
+	return err
+}
+
+// withOutput applies the bound attributes and groups to the output handler
+func (h *handler) withOutput(output slog.Handler) slog.Handler {
+	if len(h.attrs) > 0 {
+		output = output.WithAttrs(h.attrs)
+	}
+	for _, group := range h.groups {
+		output = output.WithGroup(group)
+	}
+	return output
+}
+
+// entry flattens a record into the form handed to the sink. Grouped
+// attributes are keyed by their dotted path.
+func (h *handler) entry(r slog.Record, ctxAttrs []slog.Attr) Entry {
+	entry := Entry{
+		Level:   r.Level,
+		Message: r.Message,
+		Attrs:   make(map[string]any),
+	}
+
+	add := func(prefix string, a slog.Attr) {
+		addAttr(entry.Attrs, prefix, a)
+	}
+	for _, a := range h.attrs {
+		if a.Key == loggerKey && len(h.groups) == 0 {
+			entry.Logger = a.Value.String()
+			continue
+		}
+		add("", a)
+	}
+	for _, a := range ctxAttrs {
+		add("", a)
+	}
+	prefix := ""
+	for _, group := range h.groups {
+		prefix += group + "."
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		add(prefix, a)
+		return true
+	})
+
+	return entry
+}
+
+// addAttr stores a resolved attribute, expanding groups. Errors and durations
+// are rendered as text so they survive JSON encoding.
+func addAttr(attrs map[string]any, prefix string, a slog.Attr) {
+	value := a.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		for _, ga := range value.Group() {
+			addAttr(attrs, prefix+a.Key+".", ga)
+		}
+		return
+	}
+	if a.Key == "" {
+		return
+	}
+	switch v := value.Any().(type) {
+	case error:
+		attrs[prefix+a.Key] = v.Error()
+	case slog.Level:
+		attrs[prefix+a.Key] = LevelName(v)
+	case time.Duration:
+		attrs[prefix+a.Key] = v.String()
+	default:
+		attrs[prefix+a.Key] = v
+	}
+}
+
+// WithAttrs returns a handler with additional bound attributes
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return &h2
+}
+
+// WithGroup returns a handler that nests subsequent attributes in a group
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(append([]string{}, h.groups...), name)
+	return &h2
+}
@@ -0,0 +1,188 @@
+// Package logging provides the leveled, structured logger shared by all
+// packages of the server.
+//
+// Records are written to stderr, never stdout, because stdout carries the
+// protocol stream of the stdio transport. A sink can additionally receive
+// records so the server can forward them to clients as MCP log messages.
+// The sink has its own minimum level, which clients change with
+// logging/setLevel independently of the level configured for stderr.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Log levels, following the RFC 5424 severities used by MCP. Debug, info,
+// warning and error are slog's own levels; the others sit between and above them.
+const (
+	LevelDebug     = slog.LevelDebug
+	LevelInfo      = slog.LevelInfo
+	LevelNotice    = slog.Level(2)
+	LevelWarning   = slog.LevelWarn
+	LevelError     = slog.LevelError
+	LevelCritical  = slog.Level(12)
+	LevelAlert     = slog.Level(16)
+	LevelEmergency = slog.Level(20)
+)
+
+// levelNames maps levels to their MCP names, most severe last
+var levelNames = []struct {
+	level slog.Level
+	name  string
+}{
+	{LevelDebug, "debug"},
+	{LevelInfo, "info"},
+	{LevelNotice, "notice"},
+	{LevelWarning, "warning"},
+	{LevelError, "error"},
+	{LevelCritical, "critical"},
+	{LevelAlert, "alert"},
+	{LevelEmergency, "emergency"},
+}
+
+// ParseLevel parses an MCP level name. "warn" is accepted as an alias of
+// "warning" and matching is case-insensitive.
+func ParseLevel(name string) (slog.Level, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "warn" {
+		name = "warning"
+	}
+	for _, l := range levelNames {
+		if l.name == name {
+			return l.level, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q", name)
+}
+
+// LevelName returns the MCP name of a level, rounding down to the nearest
+// named level
+func LevelName(level slog.Level) string {
+	name := levelNames[0].name
+	for _, l := range levelNames {
+		if level >= l.level {
+			name = l.name
+		}
+	}
+	return name
+}
+
+// Entry is a log record as delivered to a sink
+type Entry struct {
+	Level   slog.Level
+	Logger  string
+	Message string
+	Attrs   map[string]any
+}
+
+// Sink receives log records at or above the sink level. It must not log
+// through this package, or records would loop back into it.
+type Sink func(ctx context.Context, entry Entry)
+
+// loggerKey is the attribute holding the logger name set by Logger
+const loggerKey = "logger"
+
+// state is shared by every logger so level and sink changes apply to loggers
+// that were created earlier
+type state struct {
+	level     slog.LevelVar
+	sinkLevel slog.LevelVar
+
+	mu     sync.RWMutex
+	output slog.Handler
+	sink   Sink
+}
+
+var shared = newState(os.Stderr)
+
+func newState(w io.Writer) *state {
+	st := &state{}
+	st.level.Set(LevelInfo)
+	st.output = slog.NewTextHandler(w, &slog.HandlerOptions{
+		Level:       slog.LevelDebug, // filtering happens in handler.Handle
+		ReplaceAttr: replaceLevel,
+	})
+	return st
+}
+
+// replaceLevel prints the MCP level names instead of slog's DEBUG+2 style
+func replaceLevel(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.LevelKey && len(groups) == 0 {
+		if level, ok := a.Value.Any().(slog.Level); ok {
+			a.Value = slog.StringValue(strings.ToUpper(LevelName(level)))
+		}
+	}
+	return a
+}
+
+// Setup sets the stderr level from the configured level name and routes the
+// standard library logger through this package
+func Setup(level string) error {
+	l, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	shared.level.Set(l)
+	slog.SetDefault(slog.New(&handler{state: shared}))
+	return nil
+}
+
+// SetLevel changes the minimum level written to stderr
+func SetLevel(level slog.Level) {
+	shared.level.Set(level)
+}
+
+// SetSink installs the sink that receives records at or above level.
+// A nil sink disables forwarding.
+func SetSink(sink Sink, level slog.Level) {
+	shared.mu.Lock()
+	defer shared.mu.Unlock()
+	shared.sink = sink
+	shared.sinkLevel.Set(level)
+}
+
+// SetSinkLevel changes the minimum level delivered to the sink
+func SetSinkLevel(level slog.Level) {
+	shared.sinkLevel.Set(level)
+}
+
+// Logger returns a logger whose records carry the given name, typically the
+// package or service that logs
+func Logger(name string) *slog.Logger {
+	return slog.New(&handler{state: shared}).With(loggerKey, name)
+}
+
+// contextKey is the key for attributes attached to a context
+type contextKey struct{}
+
+// WithAttrs returns a context whose log records carry the given key/value
+// pairs, e.g. the tool and account of the request being handled
+func WithAttrs(ctx context.Context, args ...any) context.Context {
+	var attrs []slog.Attr
+	if existing, ok := ctx.Value(contextKey{}).([]slog.Attr); ok {
+		attrs = append(attrs, existing...)
+	}
+	r := slog.NewRecord(time.Time{}, 0, "", 0)
+	r.Add(args...)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return context.WithValue(ctx, contextKey{}, attrs)
+}
+
+// attrsFromContext returns the attributes attached with WithAttrs
+func attrsFromContext(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(contextKey{}).([]slog.Attr)
+	return attrs
+}
@@ -0,0 +1,131 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    slog.Level
+		wantErr bool
+	}{
+		{"debug", LevelDebug, false},
+		{"INFO", LevelInfo, false},
+		{"notice", LevelNotice, false},
+		{"warn", LevelWarning, false},
+		{"warning", LevelWarning, false},
+		{" error ", LevelError, false},
+		{"critical", LevelCritical, false},
+		{"alert", LevelAlert, false},
+		{"emergency", LevelEmergency, false},
+		{"verbose", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLevel(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLevelName(t *testing.T) {
+	tests := map[slog.Level]string{
+		LevelDebug:         "debug",
+		LevelDebug - 4:     "debug",
+		LevelInfo:          "info",
+		LevelNotice:        "notice",
+		LevelNotice + 1:    "notice",
+		LevelWarning:       "warning",
+		LevelError:         "error",
+		LevelCritical:      "critical",
+		LevelEmergency:     "emergency",
+		LevelEmergency + 8: "emergency",
+	}
+	for level, want := range tests {
+		if got := LevelName(level); got != want {
+			t.Errorf("LevelName(%d) = %q, want %q", level, got, want)
+		}
+	}
+}
+
+func TestHandlerLevels(t *testing.T) {
+	var buf bytes.Buffer
+	st := newState(&buf)
+	st.level.Set(LevelWarning)
+
+	var entries []Entry
+	st.sink = func(ctx context.Context, entry Entry) { entries = append(entries, entry) }
+	st.sinkLevel.Set(LevelDebug)
+
+	logger := slog.New(&handler{state: st}).With(loggerKey, "drive")
+	logger.Debug("listing files", "count", 3)
+	logger.Log(context.Background(), LevelNotice, "quota low")
+	logger.Warn("request failed", "error", errors.New("boom"))
+
+	out := buf.String()
+	if strings.Contains(out, "listing files") || strings.Contains(out, "quota low") {
+		t.Errorf("expected records below the stderr level to be dropped, got %q", out)
+	}
+	if !strings.Contains(out, "level=WARNING") || !strings.Contains(out, "logger=drive") || !strings.Contains(out, "error=boom") {
+		t.Errorf("unexpected stderr output %q", out)
+	}
+
+	if len(entries) != 3 {
+		t.Fatalf("expected all records at the sink, got %d", len(entries))
+	}
+	if entries[0].Logger != "drive" || entries[0].Attrs["count"] != int64(3) {
+		t.Errorf("unexpected sink entry %+v", entries[0])
+	}
+	if _, ok := entries[0].Attrs[loggerKey]; ok {
+		t.Error("expected logger name to be moved out of the attributes")
+	}
+	if entries[2].Attrs["error"] != "boom" {
+		t.Errorf("expected errors to be rendered as their message, got %+v", entries[2].Attrs)
+	}
+
+	// Raising the sink level filters the sink independently of stderr
+	st.sinkLevel.Set(LevelError)
+	entries = nil
+	logger.Warn("still on stderr")
+	if len(entries) != 0 {
+		t.Errorf("expected no sink entries below the sink level, got %+v", entries)
+	}
+	if !strings.Contains(buf.String(), "still on stderr") {
+		t.Error("expected record on stderr")
+	}
+}
+
+func TestWithAttrsContext(t *testing.T) {
+	var buf bytes.Buffer
+	st := newState(&buf)
+
+	var got Entry
+	st.sink = func(ctx context.Context, entry Entry) { got = entry }
+
+	ctx := WithAttrs(context.Background(), "tool", "drive_files_list")
+	ctx = WithAttrs(ctx, "account", "me@example.com")
+
+	logger := slog.New(&handler{state: st}).With(loggerKey, "drive").WithGroup("req")
+	logger.InfoContext(ctx, "calling API", "page", 2)
+
+	if got.Attrs["tool"] != "drive_files_list" || got.Attrs["account"] != "me@example.com" {
+		t.Errorf("expected context attributes on the entry, got %+v", got.Attrs)
+	}
+	if got.Attrs["req.page"] != int64(2) {
+		t.Errorf("expected grouped attribute keyed by its path, got %+v", got.Attrs)
+	}
+	if !strings.Contains(buf.String(), "tool=drive_files_list") {
+		t.Errorf("expected context attributes on stderr, got %q", buf.String())
+	}
+}
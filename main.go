@@ -2,10 +2,14 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"go.ngs.io/google-mcp-server/accounts"
@@ -15,78 +19,308 @@ import (
 	"go.ngs.io/google-mcp-server/docs"
 	"go.ngs.io/google-mcp-server/drive"
 	"go.ngs.io/google-mcp-server/gmail"
+	"go.ngs.io/google-mcp-server/logging"
 	"go.ngs.io/google-mcp-server/server"
 	"go.ngs.io/google-mcp-server/sheets"
 	"go.ngs.io/google-mcp-server/slides"
 	"go.ngs.io/google-mcp-server/tasks"
 )
 
+var logger = logging.Logger("main")
+
 func main() {
-	// Set up logging immediately with no buffering
-	log.SetOutput(os.Stderr)
-	log.SetFlags(0) // Remove flags for cleaner MCP output
+	// Parse command line flags
+	showVersion := flag.Bool("version", false, "print version and exit")
+	flag.BoolVar(showVersion, "v", false, "print version and exit (shorthand)")
+	transport := flag.String("transport", "", "transport to serve: stdio or http (overrides config)")
+	httpAddr := flag.String("http-addr", "", "listen address for the http transport (overrides config)")
+	verify := flag.Bool("verify-audit", false, "verify the hash chain of the audit log files given as arguments, oldest first, and exit")
+	flag.Parse()
 
-	// Check for version flag
-	if len(os.Args) > 1 && (os.Args[1] == "--version" || os.Args[1] == "-v") {
+	if *showVersion {
 		fmt.Println("google-mcp-server v0.1.0")
 		os.Exit(0)
 	}
+	if *verify {
+		if err := verifyAudit(flag.Args()); err != nil {
+			fatal("audit log verification failed", err)
+		}
+		os.Exit(0)
+	}
 
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		fatal("failed to load configuration", err)
+	}
+
+	// Logs go to stderr at the configured level; stdout is the protocol stream
+	if err := logging.Setup(cfg.Global.LogLevel); err != nil {
+		fatal("invalid log level", err)
+	}
+
+	// Command line flags take precedence over config files and environment
+	if *transport != "" {
+		if *transport != config.TransportStdio && *transport != config.TransportHTTP {
+			fatal("unsupported transport", fmt.Errorf("%q (expected %q or %q)", *transport, config.TransportStdio, config.TransportHTTP))
+		}
+		cfg.Global.Transport = *transport
+	}
+	if *httpAddr != "" {
+		cfg.Global.HTTPAddr = *httpAddr
 	}
-	// Configuration loaded successfully
+
+	// Every API client sends its requests through the shared transport,
+	// which holds back the writes of dry runs, answers repeated reads from
+	// the cache, retries failed requests and keeps each account within its
+	// quotas. Retries are rate limited like first attempts; cache hits do
+	// not count against the quota. Every attempt that reaches the network
+	// is counted in the metrics.
+	limiter := auth.NewRateLimiter(cfg.Global.RateLimits)
+	var apiTransport http.RoundTripper = auth.NewRetryTransport(auth.NewRateLimitTransport(auth.NewMetricsTransport(http.DefaultTransport), limiter), retryPolicy(cfg))
+	var cache *auth.ResponseCache
+	if !cfg.Global.Cache.Disabled {
+		cache = auth.NewResponseCache(auth.NewLRUStore(cfg.Global.Cache.MaxEntries), cacheTTLs(cfg))
+		apiTransport = auth.NewCacheTransport(apiTransport, cache)
+	}
+	auth.SetTransport(auth.NewDryRunTransport(apiTransport))
 
 	// Initialize account manager for multi-account support
 	ctx := context.Background()
 	accountManager, err := auth.NewAccountManager(ctx, cfg.OAuth)
 	if err != nil {
-		log.Fatalf("Failed to initialize account manager: %v", err)
+		fatal("failed to initialize account manager", err)
 	}
-	log.Printf("[INFO] Account manager initialized with %d accounts\n", len(accountManager.ListAccounts()))
+	logger.Info("account manager initialized", "accounts", len(accountManager.ListAccounts()))
 
 	// For backward compatibility, create a default OAuth client
 	oauthClient, err := auth.NewOAuthClient(ctx, cfg.OAuth)
 	if err != nil {
 		// Don't fail if no default client - multi-account mode
-		log.Printf("[INFO] No default OAuth client, using multi-account mode\n")
+		logger.Info("no default OAuth client, using multi-account mode")
 		oauthClient = nil
 	}
 
 	// Initialize MCP server
 	mcpServer := server.NewMCPServer(cfg)
+	if err := mcpServer.EnableAudit(cfg.Global.Audit); err != nil {
+		fatal("failed to open audit log", err)
+	}
+	if err := mcpServer.EnableMetrics(cfg.Global.MetricsAddr); err != nil {
+		fatal("failed to start metrics endpoint", err)
+	}
+	mcpServer.SetAccountLister(func() []string {
+		var emails []string
+		for _, account := range accountManager.ListAccounts() {
+			emails = append(emails, account.Email)
+		}
+		return emails
+	})
 
-	// Register services before starting the server
-	log.Println("[INFO] Starting service registration...")
-	if err := registerServices(ctx, mcpServer, accountManager, oauthClient, cfg); err != nil {
-		log.Printf("[WARNING] Some services failed to register: %v", err)
-	} else {
-		log.Println("[INFO] All services registered successfully")
+	// Register account management service
+	accountsHandler := accounts.NewHandler(accountManager, limiter)
+	if cache != nil {
+		accountsHandler.SetCache(cache)
 	}
+	mcpServer.RegisterService("accounts", accountsHandler)
+	mcpServer.RegisterCompleter("account", accountsHandler.CompleteAccounts)
+
+	// The tool policy can be inspected whatever it allows
+	mcpServer.RegisterService("server", server.NewPolicyService(mcpServer))
+
+	// Register services before starting the server. They are registered
+	// again when accounts gain scopes or the configuration is reloaded.
+	logger.Info("registering services")
+	registry := newServiceRegistry(mcpServer, accountManager, oauthClient, cfg)
+	registry.sync(ctx)
+	accountManager.OnAccountsChanged(func() {
+		registry.sync(context.Background())
+	})
+
+	// Shut down gracefully on SIGINT/SIGTERM
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		logger.Info("shutting down", "signal", sig.String())
+		if err := mcpServer.Stop(); err != nil {
+			logger.Warn("error during shutdown", "error", err)
+		}
+	}()
+
+	// Reload the services section and the tool policy of the configuration
+	// on SIGHUP
+	reloads := make(chan os.Signal, 1)
+	signal.Notify(reloads, syscall.SIGHUP)
+	go func() {
+		for range reloads {
+			reloaded, err := config.Load()
+			if err != nil {
+				logger.Warn("failed to reload configuration", "error", err)
+				continue
+			}
+			logger.Info("configuration reloaded")
+			mcpServer.SetToolPolicy(reloaded)
+			registry.reload(context.Background(), reloaded)
+		}
+	}()
 
 	// Start the server (blocks until shutdown)
 	if err := mcpServer.Start(); err != nil {
-		log.Fatalf("Server error: %v", err)
+		fatal("server error", err)
 	}
 }
 
-func registerServices(ctx context.Context, srv *server.MCPServer, accountManager *auth.AccountManager, oauth *auth.OAuthClient, cfg *config.Config) error {
-	// Register account management service
-	accountsHandler := accounts.NewHandler(accountManager)
-	srv.RegisterService("accounts", accountsHandler)
-	log.Println("[DEBUG] Accounts service registered")
+// retryPolicy maps the retry settings of the configuration to the policy of
+// the shared transport. Drive has its own limit, as uploads and exports are
+// more likely to hit transient errors than the other APIs.
+func retryPolicy(cfg *config.Config) auth.RetryPolicy {
+	policy := auth.RetryPolicy{
+		MaxRetries: cfg.Global.RetryCount,
+		BaseDelay:  time.Duration(cfg.Global.RetryDelay) * time.Millisecond,
+	}
+	if cfg.Services.Drive.MaxRetries > 0 {
+		policy.ServiceRetries = map[string]int{"drive": cfg.Services.Drive.MaxRetries}
+	}
+	return policy
+}
+
+// cacheTTLs converts the cache TTLs of the configuration from seconds
+func cacheTTLs(cfg *config.Config) map[string]time.Duration {
+	ttls := make(map[string]time.Duration, len(cfg.Global.Cache.TTLs))
+	for endpoint, seconds := range cfg.Global.Cache.TTLs {
+		ttls[endpoint] = time.Duration(seconds) * time.Second
+	}
+	return ttls
+}
+
+// serviceNames lists the Google services in registration order
+var serviceNames = []string{"calendar", "drive", "gmail", "sheets", "docs", "slides", "tasks"}
+
+// serviceRegistry keeps the registered services in line with the
+// configuration and with the scopes granted to the accounts. Services are
+// registered when they are enabled and an account can use them, and
+// unregistered otherwise, so clients are told when the tool list changes.
+type serviceRegistry struct {
+	srv            *server.MCPServer
+	accountManager *auth.AccountManager
+	oauth          *auth.OAuthClient
+
+	mu         sync.Mutex
+	services   config.ServicesConfig
+	registered map[string]bool
+}
+
+func newServiceRegistry(srv *server.MCPServer, accountManager *auth.AccountManager, oauth *auth.OAuthClient, cfg *config.Config) *serviceRegistry {
+	return &serviceRegistry{
+		srv:            srv,
+		accountManager: accountManager,
+		oauth:          oauth,
+		services:       cfg.Services,
+		registered:     make(map[string]bool),
+	}
+}
+
+// reload applies the services section of a reloaded configuration
+func (r *serviceRegistry) reload(ctx context.Context, cfg *config.Config) {
+	r.mu.Lock()
+	r.services = cfg.Services
+	r.mu.Unlock()
+	r.sync(ctx)
+}
 
-	// Use a short timeout for service initialization to prevent blocking
-	initTimeout := 5 * time.Second
+// sync registers the services that should be offered and unregisters the
+// ones that should not
+func (r *serviceRegistry) sync(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	granted, scopesKnown := r.grantedServices(ctx)
+	initialized := false
+	for _, name := range serviceNames {
+		wanted := serviceEnabled(r.services, name) && (!scopesKnown || granted[name])
+		switch {
+		case wanted && !r.registered[name]:
+			if initialized {
+				// Add delay between service initializations to avoid conflicts
+				time.Sleep(serviceDelay)
+			}
+			initialized = true
+			handler, err := r.newServiceHandler(ctx, name)
+			if err != nil {
+				// Continue without the service
+				logger.Warn("failed to initialize client", "service", name, "error", err)
+				continue
+			}
+			r.srv.RegisterService(name, handler)
+			r.registered[name] = true
+			logger.Info("service registered", "service", name)
 
-	// Add delay between service initializations to avoid conflicts
-	serviceDelay := 100 * time.Millisecond
+		case !wanted && r.registered[name]:
+			r.srv.UnregisterService(name)
+			delete(r.registered, name)
+			logger.Info("service unregistered", "service", name)
 
-	// Initialize and register Calendar service with multi-account support
-	if cfg.Services.Calendar.Enabled {
-		log.Println("[DEBUG] Initializing Calendar service...")
+		case !wanted && serviceEnabled(r.services, name):
+			logger.Debug("service not offered until an account grants its scopes", "service", name)
+		}
+	}
+}
+
+// grantedServices returns the services an account has the scopes for. The
+// second result is false when that is unknown, in which case every enabled
+// service is offered and calls report missing scopes themselves.
+func (r *serviceRegistry) grantedServices(ctx context.Context) (map[string]bool, bool) {
+	if r.oauth != nil {
+		// The default client was authorized with the configured scopes
+		return nil, false
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, initTimeout)
+	defer cancel()
+	granted, err := r.accountManager.GrantedServices(checkCtx)
+	if err != nil {
+		logger.Warn("could not check granted scopes, offering all enabled services", "error", err)
+		return nil, false
+	}
+	return granted, true
+}
+
+// serviceEnabled reports whether a service is enabled in the configuration
+func serviceEnabled(services config.ServicesConfig, name string) bool {
+	switch name {
+	case "calendar":
+		return services.Calendar.Enabled
+	case "drive":
+		return services.Drive.Enabled
+	case "gmail":
+		return services.Gmail.Enabled
+	case "sheets":
+		return services.Sheets.Enabled
+	case "docs":
+		return services.Docs.Enabled
+	case "slides":
+		return services.Slides.Enabled
+	case "tasks":
+		return services.Tasks.Enabled
+	default:
+		return false
+	}
+}
+
+// Use a short timeout for service initialization to prevent blocking
+const initTimeout = 5 * time.Second
+
+// serviceDelay separates service initializations
+const serviceDelay = 100 * time.Millisecond
+
+// newServiceHandler creates the handler of a Google service. Multi-account
+// services fall back to the default client when it is available.
+func (r *serviceRegistry) newServiceHandler(ctx context.Context, name string) (server.ServiceHandler, error) {
+	accountManager, oauth := r.accountManager, r.oauth
+
+	switch name {
+	case "calendar":
 		var calendarClient *calendar.Client
 		if oauth != nil {
 			initCtx, cancel := context.WithTimeout(ctx, initTimeout)
@@ -94,21 +328,13 @@ func registerServices(ctx context.Context, srv *server.MCPServer, accountManager
 			calendarClient, err = calendar.NewClient(initCtx, oauth)
 			cancel()
 			if err != nil {
-				log.Printf("[WARNING] Failed to initialize default Calendar client: %v\n", err)
+				logger.Warn("failed to initialize default client", "service", "calendar", "error", err)
 				calendarClient = nil
 			}
 		}
-		// Use multi-account handler
-		calendarHandler := calendar.NewMultiAccountHandler(accountManager, calendarClient)
-		srv.RegisterService("calendar", calendarHandler)
-		log.Println("[DEBUG] Calendar service registered with multi-account support")
-		// Add delay before next service
-		time.Sleep(serviceDelay)
-	}
+		return calendar.NewMultiAccountHandler(accountManager, calendarClient), nil
 
-	// Initialize and register Drive service with multi-account support
-	if cfg.Services.Drive.Enabled {
-		log.Println("[DEBUG] Initializing Drive service...")
+	case "drive":
 		var driveClient *drive.Client
 		if oauth != nil {
 			initCtx, cancel := context.WithTimeout(ctx, initTimeout)
@@ -116,21 +342,13 @@ func registerServices(ctx context.Context, srv *server.MCPServer, accountManager
 			driveClient, err = drive.NewClient(initCtx, oauth)
 			cancel()
 			if err != nil {
-				log.Printf("[WARNING] Failed to initialize default Drive client: %v\n", err)
+				logger.Warn("failed to initialize default client", "service", "drive", "error", err)
 				driveClient = nil
 			}
 		}
-		// Use multi-account handler
-		driveHandler := drive.NewMultiAccountHandler(accountManager, driveClient)
-		srv.RegisterService("drive", driveHandler)
-		log.Println("[DEBUG] Drive service registered with multi-account support")
-		// Add delay before next service
-		time.Sleep(serviceDelay)
-	}
+		return drive.NewMultiAccountHandler(accountManager, driveClient), nil
 
-	// Initialize and register Gmail service with multi-account support
-	if cfg.Services.Gmail.Enabled {
-		log.Println("[DEBUG] Initializing Gmail service...")
+	case "gmail":
 		var gmailClient *gmail.Client
 		if oauth != nil {
 			initCtx, cancel := context.WithTimeout(ctx, initTimeout)
@@ -138,88 +356,34 @@ func registerServices(ctx context.Context, srv *server.MCPServer, accountManager
 			gmailClient, err = gmail.NewClient(initCtx, oauth)
 			cancel()
 			if err != nil {
-				log.Printf("[WARNING] Failed to initialize default Gmail client: %v\n", err)
+				logger.Warn("failed to initialize default client", "service", "gmail", "error", err)
 				gmailClient = nil
 			}
 		}
-		// Use multi-account handler
-		gmailHandler := gmail.NewMultiAccountHandler(accountManager, gmailClient)
-		srv.RegisterService("gmail", gmailHandler)
-		log.Println("[DEBUG] Gmail service registered with multi-account support")
-		// Add delay before next service
-		time.Sleep(serviceDelay)
-	}
+		return gmail.NewMultiAccountHandler(accountManager, gmailClient), nil
 
-	// Initialize and register Sheets service
-	if cfg.Services.Sheets.Enabled {
-		// Initialize Sheets service
+	case "sheets":
 		initCtx, cancel := context.WithTimeout(ctx, initTimeout)
+		defer cancel()
 		sheetsClient, err := sheets.NewClient(initCtx, oauth)
-		cancel()
 		if err != nil {
-			// Failed to initialize Sheets client, continue without it
-		} else {
-			sheetsHandler := sheets.NewHandler(sheetsClient)
-			srv.RegisterService("sheets", sheetsHandler)
-			// Sheets service registered
+			return nil, err
 		}
-		// Add delay before next service
-		time.Sleep(serviceDelay)
-	}
+		return sheets.NewHandler(sheetsClient), nil
 
-	// Initialize and register Docs service
-	if cfg.Services.Docs.Enabled {
-		// Initialize Docs service
+	case "docs":
 		initCtx, cancel := context.WithTimeout(ctx, initTimeout)
+		defer cancel()
 		docsClient, err := docs.NewClient(initCtx, oauth)
-		cancel()
 		if err != nil {
-			// Failed to initialize Docs client, continue without it
-		} else {
-			docsHandler := docs.NewHandler(docsClient)
-			srv.RegisterService("docs", docsHandler)
-			// Docs service registered
+			return nil, err
 		}
-		// Add delay before next service
-		time.Sleep(serviceDelay)
-	}
-
-	// Initialize and register Slides service with multi-account support
-	if cfg.Services.Slides.Enabled {
-		log.Println("[DEBUG] Initializing Slides service...")
-		// Create service and multi-account service
-		slidesService := slides.NewService(accountManager)
-		slidesMultiAccount := slides.NewMultiAccountService(accountManager)
-
-		// Combine tools from both services
-		allTools := append(slidesService.GetTools(), slidesMultiAccount.GetTools()...)
-
-		// Create a combined handler wrapper
-		slidesHandler := server.NewCombinedHandler(
-			allTools,
-			func(ctx context.Context, name string, args json.RawMessage) (interface{}, error) {
-				// Try regular service first
-				result, err := slidesService.HandleToolCall(ctx, name, args)
-				if err == nil {
-					return result, nil
-				}
-				// Check if it's an "unknown tool" error
-				if err.Error() == fmt.Sprintf("unknown tool: %s", name) {
-					// Fall back to multi-account service
-					return slidesMultiAccount.HandleToolCall(ctx, name, args)
-				}
-				// For other errors, return the original error
-				return nil, err
-			},
-		)
-
-		srv.RegisterService("slides", slidesHandler)
-		log.Println("[DEBUG] Slides service registered with multi-account support")
-	}
-
-	// Initialize and register Tasks service with multi-account support
-	if cfg.Services.Tasks.Enabled {
-		log.Println("[DEBUG] Initializing Tasks service...")
+		return docs.NewHandler(docsClient), nil
+
+	case "slides":
+		return slides.NewHandler(accountManager), nil
+
+	case "tasks":
 		var tasksClient *tasks.Client
 		if oauth != nil {
 			initCtx, cancel := context.WithTimeout(ctx, initTimeout)
@@ -227,21 +391,42 @@ func registerServices(ctx context.Context, srv *server.MCPServer, accountManager
 			tasksClient, err = tasks.NewClient(initCtx, oauth)
 			cancel()
 			if err != nil {
-				log.Printf("[WARNING] Failed to initialize default Tasks client: %v\n", err)
+				logger.Warn("failed to initialize default client", "service", "tasks", "error", err)
 				tasksClient = nil
 			}
 		}
-		// Use multi-account handler
-		tasksHandler := tasks.NewMultiAccountHandler(accountManager, tasksClient)
-		srv.RegisterService("tasks", tasksHandler)
-		log.Println("[DEBUG] Tasks service registered with multi-account support")
-		// Add delay before next service
-		time.Sleep(serviceDelay)
+		return tasks.NewMultiAccountHandler(accountManager, tasksClient), nil
+
+	default:
+		return nil, fmt.Errorf("unknown service %q", name)
 	}
+}
 
+// verifyAudit checks that the audit log files at paths, given from the
+// oldest rotated file to the current one, form an unbroken hash chain
+func verifyAudit(paths []string) error {
+	if len(paths) == 0 {
+		return errors.New("no audit log files given")
+	}
+	prevHash := ""
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		var n int
+		prevHash, n, err = server.VerifyAuditLog(file, prevHash)
+		_ = file.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		fmt.Printf("%s: %d records ok\n", path, n)
+	}
 	return nil
 }
 
-func init() {
-	// Logging is now set up in main()
+// fatal logs an error that prevents the server from running and exits
+func fatal(msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
 }
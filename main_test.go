@@ -2,6 +2,18 @@ package main
 
 import (
 	"testing"
+
+	"go.ngs.io/google-mcp-server/accounts"
+	"go.ngs.io/google-mcp-server/auth"
+	"go.ngs.io/google-mcp-server/calendar"
+	"go.ngs.io/google-mcp-server/config"
+	"go.ngs.io/google-mcp-server/docs"
+	"go.ngs.io/google-mcp-server/drive"
+	"go.ngs.io/google-mcp-server/gmail"
+	"go.ngs.io/google-mcp-server/server"
+	"go.ngs.io/google-mcp-server/sheets"
+	"go.ngs.io/google-mcp-server/slides"
+	"go.ngs.io/google-mcp-server/tasks"
 )
 
 func TestInit(t *testing.T) {
@@ -9,3 +21,61 @@ func TestInit(t *testing.T) {
 	// This is a placeholder test
 	t.Log("Main package initialized successfully")
 }
+
+// testServices builds the services the way newServiceHandler registers
+// them, without accounts or default clients, enough to list their tools
+func testServices() map[string]interface{ GetTools() []server.Tool } {
+	accountManager := &auth.AccountManager{}
+	return map[string]interface{ GetTools() []server.Tool }{
+		"accounts": accounts.NewHandler(accountManager, nil),
+		"calendar": calendar.NewMultiAccountHandler(accountManager, nil),
+		"docs":     docs.NewHandler(nil),
+		"drive":    drive.NewMultiAccountHandler(accountManager, nil),
+		"gmail":    gmail.NewMultiAccountHandler(accountManager, nil),
+		"sheets":   sheets.NewHandler(nil),
+		"slides":   slides.NewHandler(accountManager),
+		"tasks":    tasks.NewMultiAccountHandler(accountManager, nil),
+		"server":   server.NewPolicyService(server.NewMCPServer(&config.Config{})),
+	}
+}
+
+func TestAllToolsAnnotated(t *testing.T) {
+	for name, service := range testServices() {
+		for _, tool := range service.GetTools() {
+			a := tool.Annotations
+			if a == nil || a.ReadOnlyHint == nil || a.DestructiveHint == nil || a.IdempotentHint == nil || a.OpenWorldHint == nil {
+				t.Errorf("%s: tool %s is missing annotations", name, tool.Name)
+				continue
+			}
+			if *a.ReadOnlyHint && *a.DestructiveHint {
+				t.Errorf("%s: tool %s cannot be both read-only and destructive", name, tool.Name)
+			}
+			if tool.OutputSchema != nil && tool.OutputSchema.Type != "object" {
+				t.Errorf("%s: output schema of %s must describe an object", name, tool.Name)
+			}
+		}
+	}
+}
+
+// TestToolSchemasMatchArguments fails when a tool advertises a schema that
+// differs from the struct its arguments are decoded into
+func TestToolSchemasMatchArguments(t *testing.T) {
+	for name, service := range testServices() {
+		definer, ok := service.(server.ToolDefiner)
+		if !ok {
+			t.Errorf("%s: tools are not declared as a ToolSet", name)
+			continue
+		}
+		definitions := definer.ToolDefinitions()
+		for _, tool := range service.GetTools() {
+			definition, ok := definitions.Lookup(tool.Name)
+			if !ok {
+				t.Errorf("%s: tool %s has no definition", name, tool.Name)
+				continue
+			}
+			if err := server.CheckSchema(tool.InputSchema, definition.ArgumentType()); err != nil {
+				t.Errorf("%s: tool %s: %v", name, tool.Name, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,377 @@
+// Package metrics provides the counters, gauges and histograms the server
+// exposes to Prometheus, and the handler serving them in the Prometheus
+// text exposition format.
+//
+// The packages that update a metric declare it as a package variable
+// created with NewCounterVec, NewGauge or NewHistogramVec, which register
+// it in the Default registry. Updates are cheap and safe for concurrent
+// use, so metrics are kept whether or not an endpoint serves them.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.ngs.io/google-mcp-server/logging"
+)
+
+var logger = logging.Logger("metrics")
+
+// contentType is the media type of the Prometheus text format
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// metric is a family of samples sharing a name
+type metric interface {
+	describe() desc
+	writeSamples(w *bufio.Writer)
+}
+
+// desc is what a family of samples is called and what it measures
+type desc struct {
+	name   string
+	help   string
+	kind   string // "counter", "gauge" or "histogram"
+	labels []string
+}
+
+// Registry is a set of metrics with distinct names
+type Registry struct {
+	mu      sync.Mutex
+	metrics map[string]metric
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]metric)}
+}
+
+// Default is the registry of the package-level constructors, served by
+// Handler
+var Default = NewRegistry()
+
+// register adds m, panicking when the name is taken as two metrics of the
+// same name would be indistinguishable
+func (r *Registry) register(m metric) {
+	name := m.describe().name
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.metrics[name]; exists {
+		panic(fmt.Sprintf("metrics: %s is already registered", name))
+	}
+	r.metrics[name] = m
+}
+
+// WriteTo writes the metrics in the text format, ordered by name
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	metrics := make([]metric, 0, len(r.metrics))
+	for _, m := range r.metrics {
+		metrics = append(metrics, m)
+	}
+	r.mu.Unlock()
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].describe().name < metrics[j].describe().name
+	})
+
+	counter := &countingWriter{w: w}
+	buf := bufio.NewWriter(counter)
+	for _, m := range metrics {
+		d := m.describe()
+		fmt.Fprintf(buf, "# HELP %s %s\n", d.name, escapeHelp(d.help))
+		fmt.Fprintf(buf, "# TYPE %s %s\n", d.name, d.kind)
+		m.writeSamples(buf)
+	}
+	err := buf.Flush()
+	return counter.n, err
+}
+
+// Handler serves the metrics of r
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		if _, err := r.WriteTo(w); err != nil {
+			logger.Debug("failed to write metrics", "error", err)
+		}
+	})
+}
+
+// Handler serves the metrics of the Default registry
+func Handler() http.Handler {
+	return Default.Handler()
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// seriesKey joins label values into a map key. The separator cannot
+// appear in valid UTF-8.
+func seriesKey(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+// checkLabels panics when a metric is updated with the wrong number of
+// label values, which is a programming error
+func checkLabels(d desc, values []string) {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", d.name, len(d.labels), len(values)))
+	}
+}
+
+// labelPairs formats label names and values as {a="1",b="2"}, followed by
+// extra pairs already formatted, such as le="0.5"
+func labelPairs(names, values []string, extra ...string) string {
+	if len(names) == 0 && len(extra) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(names)+len(extra))
+	for i, name := range names {
+		pairs = append(pairs, name+`="`+escapeLabelValue(values[i])+`"`)
+	}
+	pairs = append(pairs, extra...)
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string       { return helpEscaper.Replace(s) }
+func escapeLabelValue(s string) string { return labelEscaper.Replace(s) }
+
+// formatValue formats a sample value the way Prometheus parses it
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// sortedSeries returns the keys of a series map in order, so that scrapes
+// list samples consistently
+func sortedSeries[T any](series map[string]T) []string {
+	keys := make([]string, 0, len(series))
+	for key := range series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CounterVec is a family of counters, one per combination of label values
+type CounterVec struct {
+	desc desc
+
+	mu     sync.Mutex
+	values map[string]float64
+	labels map[string][]string
+}
+
+// NewCounterVec creates a counter family in r. Counter names end in
+// _total by convention.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{
+		desc:   desc{name: name, help: help, kind: "counter", labels: labels},
+		values: make(map[string]float64),
+		labels: make(map[string][]string),
+	}
+	r.register(c)
+	return c
+}
+
+// NewCounterVec creates a counter family in the Default registry
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return Default.NewCounterVec(name, help, labels...)
+}
+
+// Inc adds 1 to the counter of the label values
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds delta, which must not be negative, to the counter of the label
+// values
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	checkLabels(c.desc, labelValues)
+	if delta < 0 {
+		panic(fmt.Sprintf("metrics: counter %s cannot decrease", c.desc.name))
+	}
+	key := seriesKey(labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.labels[key]; !ok {
+		c.labels[key] = append([]string(nil), labelValues...)
+	}
+	c.values[key] += delta
+}
+
+// Value returns the counter of the label values
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[seriesKey(labelValues)]
+}
+
+func (c *CounterVec) describe() desc { return c.desc }
+
+func (c *CounterVec) writeSamples(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range sortedSeries(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.desc.name, labelPairs(c.desc.labels, c.labels[key]), formatValue(c.values[key]))
+	}
+}
+
+// Gauge is a value that goes up and down
+type Gauge struct {
+	desc desc
+
+	mu    sync.Mutex
+	value float64
+}
+
+// NewGauge creates a gauge in r
+func (r *Registry) NewGauge(name, help string) *Gauge {
+	g := &Gauge{desc: desc{name: name, help: help, kind: "gauge"}}
+	r.register(g)
+	return g
+}
+
+// NewGauge creates a gauge in the Default registry
+func NewGauge(name, help string) *Gauge {
+	return Default.NewGauge(name, help)
+}
+
+// Add adds delta, which may be negative, to the gauge
+func (g *Gauge) Add(delta float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.value += delta
+}
+
+// Value returns the gauge
+func (g *Gauge) Value() float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.value
+}
+
+func (g *Gauge) describe() desc { return g.desc }
+
+func (g *Gauge) writeSamples(w *bufio.Writer) {
+	fmt.Fprintf(w, "%s %s\n", g.desc.name, formatValue(g.Value()))
+}
+
+// HistogramVec is a family of histograms, one per combination of label
+// values, sharing the same buckets
+type HistogramVec struct {
+	desc    desc
+	buckets []float64 // upper bounds, ascending, without +Inf
+
+	mu     sync.Mutex
+	series map[string]*histogram
+}
+
+// histogram holds the observations of one combination of label values
+type histogram struct {
+	labels []string
+	counts []uint64 // per bucket, not cumulative; the last is +Inf
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec creates a histogram family in r with the given bucket
+// upper bounds, which must be ascending. The +Inf bucket is implied.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] <= buckets[i-1] {
+			panic(fmt.Sprintf("metrics: buckets of %s are not ascending", name))
+		}
+	}
+	h := &HistogramVec{
+		desc:    desc{name: name, help: help, kind: "histogram", labels: labels},
+		buckets: append([]float64(nil), buckets...),
+		series:  make(map[string]*histogram),
+	}
+	r.register(h)
+	return h
+}
+
+// NewHistogramVec creates a histogram family in the Default registry
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	return Default.NewHistogramVec(name, help, buckets, labels...)
+}
+
+// Observe adds v to the histogram of the label values
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	checkLabels(h.desc, labelValues)
+	bucket := sort.SearchFloat64s(h.buckets, v)
+	key := seriesKey(labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogram{labels: append([]string(nil), labelValues...), counts: make([]uint64, len(h.buckets)+1)}
+		h.series[key] = s
+	}
+	s.counts[bucket]++
+	s.count++
+	s.sum += v
+}
+
+// Count returns the number of observations of the label values
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s, ok := h.series[seriesKey(labelValues)]; ok {
+		return s.count
+	}
+	return 0
+}
+
+func (h *HistogramVec) describe() desc { return h.desc }
+
+func (h *HistogramVec) writeSamples(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range sortedSeries(h.series) {
+		s := h.series[key]
+		var cumulative uint64
+		for i, count := range s.counts {
+			cumulative += count
+			le := "+Inf"
+			if i < len(h.buckets) {
+				le = formatValue(h.buckets[i])
+			}
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.desc.name, labelPairs(h.desc.labels, s.labels, `le="`+le+`"`), cumulative)
+		}
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.desc.name, labelPairs(h.desc.labels, s.labels), formatValue(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.desc.name, labelPairs(h.desc.labels, s.labels), s.count)
+	}
+}
@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWritesTextFormat(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounterVec("requests_total", "Requests sent.", "service", "code")
+	inFlight := r.NewGauge("in_flight", "Requests running.")
+	latency := r.NewHistogramVec("latency_seconds", "Request latency.", []float64{0.1, 1}, "tool")
+
+	requests.Inc("drive", "200")
+	requests.Inc("drive", "200")
+	requests.Add(3, "gmail", `say "hi"`)
+	inFlight.Add(2)
+	inFlight.Add(-1)
+	latency.Observe(0.05, "a")
+	latency.Observe(0.1, "a")
+	latency.Observe(5, "a")
+
+	var out strings.Builder
+	if _, err := r.WriteTo(&out); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP in_flight Requests running.
+# TYPE in_flight gauge
+in_flight 1
+# HELP latency_seconds Request latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{tool="a",le="0.1"} 2
+latency_seconds_bucket{tool="a",le="1"} 2
+latency_seconds_bucket{tool="a",le="+Inf"} 3
+latency_seconds_sum{tool="a"} 5.15
+latency_seconds_count{tool="a"} 3
+# HELP requests_total Requests sent.
+# TYPE requests_total counter
+requests_total{service="drive",code="200"} 2
+requests_total{service="gmail",code="say \"hi\""} 3
+`
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	if requests.Value("drive", "200") != 2 || latency.Count("a") != 3 || latency.Count("b") != 0 {
+		t.Error("unexpected values")
+	}
+}
+
+func TestRegistryRejectsDuplicateNames(t *testing.T) {
+	r := NewRegistry()
+	r.NewGauge("up", "")
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a name twice to panic")
+		}
+	}()
+	r.NewCounterVec("up", "")
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	r.NewGauge("up", "Up.").Add(1)
+	ts := httptest.NewServer(r.Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != contentType {
+		t.Errorf("unexpected response %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	resp, err = http.Post(ts.URL, "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("expected POST to be refused, got %d", resp.StatusCode)
+	}
+}
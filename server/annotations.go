@@ -0,0 +1,87 @@
+package server
+
+// ToolAnnotations describe how a tool behaves so clients can decide, for
+// example, whether to ask the user before calling it. They are hints: clients
+// must not rely on them for security decisions.
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    *bool  `json:"readOnlyHint,omitempty"`
+	DestructiveHint *bool  `json:"destructiveHint,omitempty"`
+	IdempotentHint  *bool  `json:"idempotentHint,omitempty"`
+	OpenWorldHint   *bool  `json:"openWorldHint,omitempty"`
+}
+
+// newAnnotations sets every hint explicitly instead of relying on the
+// defaults of the specification, which assume the worst case
+func newAnnotations(readOnly, destructive, idempotent bool) *ToolAnnotations {
+	return &ToolAnnotations{
+		ReadOnlyHint:    &readOnly,
+		DestructiveHint: &destructive,
+		IdempotentHint:  &idempotent,
+		OpenWorldHint:   boolPtr(true),
+	}
+}
+
+// ReadOnlyAnnotations annotate a tool that only reads data
+func ReadOnlyAnnotations() *ToolAnnotations {
+	return newAnnotations(true, false, true)
+}
+
+// AdditiveAnnotations annotate a tool that creates data without changing
+// existing data. Calling it twice creates two items.
+func AdditiveAnnotations() *ToolAnnotations {
+	return newAnnotations(false, false, false)
+}
+
+// IdempotentAnnotations annotate a tool that changes existing data without
+// losing any of it, e.g. moving a file or completing a task. Repeating the
+// call has no further effect.
+func IdempotentAnnotations() *ToolAnnotations {
+	return newAnnotations(false, false, true)
+}
+
+// DestructiveAnnotations annotate a tool that overwrites or deletes data.
+// Repeating the call has no further effect.
+func DestructiveAnnotations() *ToolAnnotations {
+	return newAnnotations(false, true, true)
+}
+
+// Local marks the tool as working on data kept by this server only, such as
+// stored account tokens, rather than on a Google service
+func (a *ToolAnnotations) Local() *ToolAnnotations {
+	a.OpenWorldHint = boolPtr(false)
+	return a
+}
+
+// NotIdempotent marks a tool whose every call has an effect, e.g. appending
+// text
+func (a *ToolAnnotations) NotIdempotent() *ToolAnnotations {
+	a.IdempotentHint = boolPtr(false)
+	return a
+}
+
+// IsReadOnly reports whether the tool is annotated as read-only. Tools
+// without annotations are assumed to write.
+func (a *ToolAnnotations) IsReadOnly() bool {
+	return a != nil && a.ReadOnlyHint != nil && *a.ReadOnlyHint
+}
+
+// IsLocal reports whether the tool is annotated as working on data kept by
+// this server only
+func (a *ToolAnnotations) IsLocal() bool {
+	return a != nil && a.OpenWorldHint != nil && !*a.OpenWorldHint
+}
+
+// IsDestructive reports whether the tool may overwrite or delete data.
+// Following the specification, tools that write are assumed destructive
+// unless annotated otherwise.
+func (a *ToolAnnotations) IsDestructive() bool {
+	if a.IsReadOnly() {
+		return false
+	}
+	return a == nil || a.DestructiveHint == nil || *a.DestructiveHint
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestToolAnnotations(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations *ToolAnnotations
+		expected    string
+	}{
+		{
+			name:        "read-only",
+			annotations: ReadOnlyAnnotations(),
+			expected:    `{"readOnlyHint":true,"destructiveHint":false,"idempotentHint":true,"openWorldHint":true}`,
+		},
+		{
+			name:        "additive",
+			annotations: AdditiveAnnotations(),
+			expected:    `{"readOnlyHint":false,"destructiveHint":false,"idempotentHint":false,"openWorldHint":true}`,
+		},
+		{
+			name:        "destructive and local",
+			annotations: DestructiveAnnotations().Local(),
+			expected:    `{"readOnlyHint":false,"destructiveHint":true,"idempotentHint":true,"openWorldHint":false}`,
+		},
+		{
+			name:        "destructive, not idempotent",
+			annotations: DestructiveAnnotations().NotIdempotent(),
+			expected:    `{"readOnlyHint":false,"destructiveHint":true,"idempotentHint":false,"openWorldHint":true}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.annotations)
+			if err != nil {
+				t.Fatalf("failed to marshal annotations: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("got %s, want %s", data, tt.expected)
+			}
+		})
+	}
+
+	if !ReadOnlyAnnotations().IsReadOnly() || IdempotentAnnotations().IsReadOnly() {
+		t.Error("IsReadOnly does not follow readOnlyHint")
+	}
+	if !DestructiveAnnotations().Local().IsLocal() || DestructiveAnnotations().IsLocal() {
+		t.Error("IsLocal does not follow openWorldHint")
+	}
+	var missing *ToolAnnotations
+	if missing.IsReadOnly() {
+		t.Error("tools without annotations must not be treated as read-only")
+	}
+	if missing.IsLocal() {
+		t.Error("tools without annotations must not be treated as local")
+	}
+}
+
+func TestStructuredContent(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{text: `{"echo":"x"}`, expected: `{"echo":"x"}`},
+		{text: "  {\"a\":1}\n", expected: `{"a":1}`},
+		{text: `[1,2]`, expected: ""},
+		{text: `{"broken":`, expected: ""},
+		{text: "plain text", expected: ""},
+	}
+
+	for _, tt := range tests {
+		if got := string(structuredContent(tt.text)); got != tt.expected {
+			t.Errorf("structuredContent(%q) = %q, want %q", tt.text, got, tt.expected)
+		}
+	}
+}
+
+func TestToolsListIncludesAnnotations(t *testing.T) {
+	srv, _ := newTestHTTPServer(t)
+	srv.RegisterService("annotated", annotatedService{})
+
+	data, err := json.Marshal(srv.tools)
+	if err != nil {
+		t.Fatalf("failed to marshal tools: %v", err)
+	}
+	for _, want := range []string{`"annotations":{"readOnlyHint":true`, `"outputSchema":{"type":"object"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %s in tools list %s", want, data)
+		}
+	}
+}
+
+// annotatedService has a read-only tool with an output schema
+type annotatedService struct {
+	stubService
+}
+
+func (annotatedService) GetTools() []Tool {
+	return []Tool{{
+		Name:         "stub_annotated",
+		Description:  "Annotated",
+		InputSchema:  InputSchema{Type: "object"},
+		OutputSchema: &OutputSchema{Type: "object", Properties: map[string]Property{"echo": {Type: "string", Description: "Echoed arguments"}}},
+		Annotations:  ReadOnlyAnnotations(),
+	}}
+}
@@ -0,0 +1,482 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.ngs.io/google-mcp-server/auth"
+	"go.ngs.io/google-mcp-server/config"
+)
+
+// Outcomes of an audited tool call
+const (
+	AuditSuccess   = "success"
+	AuditError     = "error"
+	AuditCancelled = "cancelled"
+)
+
+// AuditRecord is one line of the audit log
+type AuditRecord struct {
+	Time        time.Time       `json:"time"`
+	Session     string          `json:"session,omitempty"`
+	Account     string          `json:"account,omitempty"`
+	Service     string          `json:"service"`
+	Tool        string          `json:"tool"`
+	Arguments   json.RawMessage `json:"arguments,omitempty"` // redacted, see redactArguments
+	ResourceIDs []string        `json:"resource_ids,omitempty"`
+	Outcome     string          `json:"outcome"`
+	Category    ErrorCategory   `json:"category,omitempty"`
+	Error       string          `json:"error,omitempty"`
+
+	// PrevHash is the Hash of the record before this one, across rotated
+	// files; Hash is the SHA-256 of this record with Hash left empty.
+	// Changing, removing or reordering records breaks the chain.
+	PrevHash string `json:"prev_hash"`
+	Hash     string `json:"hash,omitempty"`
+}
+
+// digest computes the hash of the record
+func (r AuditRecord) digest() (string, error) {
+	r.Hash = ""
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// auditLog appends records to a JSONL file, rotating it by size and,
+// optionally, by day
+type auditLog struct {
+	path        string
+	maxSize     int64
+	rotateDaily bool
+	now         func() time.Time
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	day      string // UTC date of the current file
+	lastHash string
+}
+
+// openAuditLog opens the log at cfg.Path, continuing the hash chain of the
+// records already in it
+func openAuditLog(cfg config.AuditConfig) (*auditLog, error) {
+	maxSize := cfg.MaxSizeMB
+	if maxSize <= 0 {
+		maxSize = config.DefaultAuditMaxSizeMB
+	}
+	path := cfg.Path
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(home, path[2:])
+	}
+	l := &auditLog{
+		path:        path,
+		maxSize:     int64(maxSize) << 20,
+		rotateDaily: cfg.RotateDaily,
+		now:         time.Now,
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	lastHash, err := lastAuditHash(l.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log %s: %w", l.path, err)
+	}
+	l.lastHash = lastHash
+	if err := l.openFile(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// openFile opens the current file for appending
+func (l *auditLog) openFile() error {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	l.file = file
+	l.size = info.Size()
+	l.day = l.now().UTC().Format(time.DateOnly)
+	if l.size > 0 {
+		l.day = info.ModTime().UTC().Format(time.DateOnly)
+	}
+	return nil
+}
+
+// lastAuditHash returns the hash of the last record in the file at path,
+// or "" when there is none
+func lastAuditHash(path string) (string, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = file.Close() }()
+
+	// Records are small, so the last one is within the tail of the file
+	const tail = 1 << 20
+	if info, err := file.Stat(); err == nil && info.Size() > tail {
+		if _, err := file.Seek(-tail, io.SeekEnd); err != nil {
+			return "", err
+		}
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+	lines := bytes.Split(bytes.TrimRight(data, "\n"), []byte("\n"))
+	last := lines[len(lines)-1]
+	if len(last) == 0 {
+		return "", nil
+	}
+	var record AuditRecord
+	if err := json.Unmarshal(last, &record); err != nil {
+		return "", fmt.Errorf("last record is malformed: %w", err)
+	}
+	return record.Hash, nil
+}
+
+// append chains the record to the previous one and writes it
+func (l *auditLog) append(record AuditRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return errors.New("audit log is closed")
+	}
+
+	record.PrevHash = l.lastHash
+	hash, err := record.digest()
+	if err != nil {
+		return err
+	}
+	record.Hash = hash
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if err := l.rotateIfNeeded(int64(len(line)), record.Time); err != nil {
+		return err
+	}
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	l.lastHash = hash
+	return nil
+}
+
+// rotateIfNeeded moves the current file aside when the next record would
+// make it too large, or when a new day has started
+func (l *auditLog) rotateIfNeeded(next int64, at time.Time) error {
+	day := at.UTC().Format(time.DateOnly)
+	full := l.size > 0 && l.size+next > l.maxSize
+	newDay := l.rotateDaily && l.size > 0 && day != l.day
+	if !full && !newDay {
+		return nil
+	}
+
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit log: %w", err)
+	}
+	l.file = nil
+	if err := os.Rename(l.path, l.rotatedPath(at)); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+	return l.openFile()
+}
+
+// rotatedPath names the file the current one is renamed to, such as
+// audit-20240501T120000Z.jsonl for audit.jsonl
+func (l *auditLog) rotatedPath(at time.Time) string {
+	ext := filepath.Ext(l.path)
+	base := strings.TrimSuffix(l.path, ext) + "-" + at.UTC().Format("20060102T150405Z")
+	path := base + ext
+	for i := 1; ; i++ {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return path
+		}
+		path = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+}
+
+// close closes the current file
+func (l *auditLog) close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// VerifyAuditLog checks the hash chain of the records read from r, which
+// must follow the record whose hash is prevHash. With an empty prevHash the
+// chain may start anywhere, so that a log whose oldest files were removed
+// can still be verified. It returns the hash of the last record, to verify
+// the next rotated file with, and the number of records read.
+func VerifyAuditLog(r io.Reader, prevHash string) (string, int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+	count := 0
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		count++
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return prevHash, count, fmt.Errorf("record %d is malformed: %w", count, err)
+		}
+		if record.PrevHash != prevHash && (count > 1 || prevHash != "") {
+			return prevHash, count, fmt.Errorf("record %d does not follow the previous record: the chain was broken", count)
+		}
+		hash, err := record.digest()
+		if err != nil {
+			return prevHash, count, err
+		}
+		if hash != record.Hash {
+			return prevHash, count, fmt.Errorf("record %d was modified: its hash does not match its contents", count)
+		}
+		prevHash = record.Hash
+	}
+	return prevHash, count, scanner.Err()
+}
+
+// EnableAudit starts recording calls to tools that are not read-only in
+// the audit log configured by cfg. It fails when the log cannot be opened,
+// so that the server does not run unaudited by accident.
+func (s *MCPServer) EnableAudit(cfg config.AuditConfig) error {
+	if cfg.Path == "" {
+		return nil
+	}
+	log, err := openAuditLog(cfg)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.audit = log
+	s.mu.Unlock()
+	return nil
+}
+
+// auditToolCall records the call of a tool that is not read-only. Failures
+// to write are logged; they do not change the result of the call.
+func (h *Handler) auditToolCall(ctx context.Context, entry toolEntry, name, account string, arguments json.RawMessage, result interface{}, err error) {
+	h.server.mu.RLock()
+	log := h.server.audit
+	h.server.mu.RUnlock()
+	if log == nil || entry.tool.Annotations.IsReadOnly() {
+		return
+	}
+
+	record := AuditRecord{
+		Time:        log.now().UTC(),
+		Session:     h.session.ID(),
+		Account:     account,
+		Service:     entry.service,
+		Tool:        name,
+		Arguments:   redactArguments(arguments),
+		ResourceIDs: resourceIDs(arguments, result),
+		Outcome:     AuditSuccess,
+	}
+	switch {
+	case err == nil:
+	case requestCancelled(ctx):
+		record.Outcome = AuditCancelled
+	default:
+		record.Outcome = AuditError
+		record.Category = classifyError(err)
+		record.Error = sanitizeErrorMessage(err.Error())
+	}
+
+	if err := log.append(record); err != nil {
+		logger.ErrorContext(ctx, "failed to write audit record", "error", err)
+	}
+}
+
+// usedAccount names the account a tool call acted for: the account its
+// requests were made for, which the service may have picked without an
+// account argument, or the accounts joined by commas when it used several.
+// Calls that made no request fall back to the account argument.
+func usedAccount(use *auth.AccountUse, argument string) string {
+	if accounts := use.Accounts(); len(accounts) > 0 {
+		return strings.Join(accounts, ",")
+	}
+	return argument
+}
+
+// maxAuditedString is the length above which argument values are replaced
+// by their size and digest
+const maxAuditedString = 256
+
+// contentKeys name arguments holding document content, and secretKeys
+// arguments holding credentials. The audit log identifies their values by
+// digest instead of recording them.
+var (
+	contentKeys = []string{"body", "content", "markdown", "html", "text", "data", "values", "notes", "description"}
+	secretKeys  = []string{"password", "secret", "token", "credential"}
+)
+
+// isRedactedKey reports whether the value of key is never recorded
+func isRedactedKey(key string) bool {
+	lower := strings.ToLower(key)
+	for _, secret := range secretKeys {
+		if strings.Contains(lower, secret) {
+			return true
+		}
+	}
+	for _, content := range contentKeys {
+		if lower == content || strings.HasSuffix(lower, "_"+content) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactArguments returns the arguments with content, secrets and long
+// values replaced by a digest. The digest proves which value was sent
+// without disclosing it.
+func redactArguments(arguments json.RawMessage) json.RawMessage {
+	if len(arguments) == 0 {
+		return nil
+	}
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(arguments))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return json.RawMessage(fmt.Sprintf("%q", redactedValue(arguments)))
+	}
+	data, err := json.Marshal(redactValue("", value))
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// redactValue redacts the value of the argument named key
+func redactValue(key string, value interface{}) interface{} {
+	if isRedactedKey(key) {
+		data, _ := json.Marshal(value)
+		return redactedValue(data)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = redactValue(k, item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = redactValue(key, item)
+		}
+		return out
+	case string:
+		if len(v) > maxAuditedString {
+			return redactedValue([]byte(v))
+		}
+		return sanitizeErrorMessage(v)
+	}
+	return value
+}
+
+// redactedValue describes a value that is not recorded
+func redactedValue(data []byte) string {
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("[redacted %d bytes sha256:%s]", len(data), hex.EncodeToString(sum[:8]))
+}
+
+// maxResourceIDs bounds the IDs recorded for one call
+const maxResourceIDs = 20
+
+// resourceIDs collects the IDs a call names in its arguments and returns
+// in its result: values of keys such as "id", "file_id" or "spreadsheetId",
+// at the top level and one object down
+func resourceIDs(arguments json.RawMessage, result interface{}) []string {
+	seen := map[string]bool{}
+	var ids []string
+	collect := func(data []byte) {
+		var object map[string]interface{}
+		if json.Unmarshal(data, &object) != nil {
+			return
+		}
+		addIDs(object, 1, seen, &ids)
+	}
+
+	collect(arguments)
+	if result != nil {
+		switch r := result.(type) {
+		case *Content, Content, []Content:
+			// Rich content carries no IDs worth recording
+		default:
+			if data, err := json.Marshal(r); err == nil {
+				collect(data)
+			}
+		}
+	}
+
+	sort.Strings(ids)
+	if len(ids) > maxResourceIDs {
+		ids = ids[:maxResourceIDs]
+	}
+	return ids
+}
+
+// addIDs adds the ID values of object, descending depth more levels
+func addIDs(object map[string]interface{}, depth int, seen map[string]bool, ids *[]string) {
+	for key, value := range object {
+		switch v := value.(type) {
+		case string:
+			if isIDKey(key) && v != "" && !seen[v] {
+				seen[v] = true
+				*ids = append(*ids, v)
+			}
+		case map[string]interface{}:
+			if depth > 0 {
+				addIDs(v, depth-1, seen, ids)
+			}
+		}
+	}
+}
+
+// isIDKey reports whether a key names the ID of a resource
+func isIDKey(key string) bool {
+	return key == "id" || strings.HasSuffix(key, "_id") || (strings.HasSuffix(key, "Id") && key != "requestId")
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+var (
+	// errRequestCancelled is the cause of requests cancelled with notifications/cancelled
+	errRequestCancelled = errors.New("request cancelled by client")
+
+	// errClientGone is the cause of requests whose HTTP connection was closed
+	// before they were answered
+	errClientGone = errors.New("client disconnected")
+
+	// errSessionClosed is the cause of requests still running when their session ends
+	errSessionClosed = errors.New("session closed")
+)
+
+// handlerFunc is the signature shared by the request handlers
+type handlerFunc func(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request)
+
+// goCancellable runs a request handler in its own goroutine with a context
+// the client can cancel. jsonrpc2 dispatches messages one at a time, so a
+// long tool call handled inline would keep the cancellation from being read,
+// and every other request of the session would wait behind it.
+//
+// At most GlobalConfig.MaxConcurrency handlers run at once; the others wait
+// for a slot of the server's worker pool. The request is tracked before it
+// waits, so a cancellation reaches it while it is queued too. Cheap requests
+// such as tools/list and all notifications stay inline in Handle, which keeps
+// them in the order the client sent them and off the queue.
+func (h *Handler) goCancellable(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request, handle handlerFunc) {
+	if req.Notif {
+		return
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	id := req.ID.String()
+	h.session.trackRequest(id, cancel)
+
+	go func() {
+		defer cancel(nil)
+		defer h.session.untrackRequest(id)
+
+		if err := h.server.workers.acquire(ctx); err != nil {
+			if requestCancelled(ctx) {
+				return
+			}
+			if err := conn.ReplyWithError(ctx, req.ID, &jsonrpc2.Error{
+				Code:    jsonrpc2.CodeInternalError,
+				Message: fmt.Sprintf("request abandoned while queued: %v", err),
+			}); err != nil {
+				logger.Error("failed to send reply", "error", err)
+			}
+			return
+		}
+		ctx, release := h.server.workers.withWorkerSlot(ctx)
+		defer release()
+
+		handle(ctx, conn, req)
+	}()
+}
+
+// handleCancelled cancels an in-flight request of the session. Requests that
+// already finished or are unknown are ignored, as the notification may race
+// with the response.
+func (h *Handler) handleCancelled(ctx context.Context, req *jsonrpc2.Request) {
+	if req.Params == nil {
+		return
+	}
+
+	var params struct {
+		RequestID jsonrpc2.ID `json:"requestId"`
+		Reason    string      `json:"reason,omitempty"`
+	}
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		logger.DebugContext(ctx, "ignoring malformed cancellation", "error", err)
+		return
+	}
+
+	id := params.RequestID.String()
+	if h.session.cancelRequest(id, errRequestCancelled) {
+		logger.InfoContext(ctx, "request cancelled", "request", id, "reason", params.Reason)
+	}
+}
+
+// requestCancelled reports whether the request was cancelled by the client
+// or its connection went away, in which case no response is sent
+func requestCancelled(ctx context.Context) bool {
+	if ctx.Err() == nil {
+		return false
+	}
+	cause := context.Cause(ctx)
+	return errors.Is(cause, errRequestCancelled) || errors.Is(cause, errClientGone) || errors.Is(cause, errSessionClosed)
+}
+
+// trackRequest records the cancel function of an in-flight request
+func (s *Session) trackRequest(id string, cancel context.CancelCauseFunc) {
+	s.inflightMu.Lock()
+	defer s.inflightMu.Unlock()
+	if s.inflight == nil {
+		s.inflight = make(map[string]context.CancelCauseFunc)
+	}
+	s.inflight[id] = cancel
+}
+
+// untrackRequest forgets a finished request
+func (s *Session) untrackRequest(id string) {
+	s.inflightMu.Lock()
+	defer s.inflightMu.Unlock()
+	delete(s.inflight, id)
+}
+
+// cancelRequest cancels an in-flight request with the given cause and
+// reports whether it was found
+func (s *Session) cancelRequest(id string, cause error) bool {
+	s.inflightMu.Lock()
+	cancel, ok := s.inflight[id]
+	s.inflightMu.Unlock()
+	if ok {
+		cancel(cause)
+	}
+	return ok
+}
+
+// cancelAll cancels every in-flight request of the session
+func (s *Session) cancelAll(cause error) {
+	s.inflightMu.Lock()
+	cancels := make([]context.CancelCauseFunc, 0, len(s.inflight))
+	for _, cancel := range s.inflight {
+		cancels = append(cancels, cancel)
+	}
+	s.inflightMu.Unlock()
+
+	for _, cancel := range cancels {
+		cancel(cause)
+	}
+}
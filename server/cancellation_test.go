@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"go.ngs.io/google-mcp-server/config"
+	"go.ngs.io/google-mcp-server/internal/mcptest"
+)
+
+// blockingService has a tool that runs until its request is cancelled
+type blockingService struct {
+	stubService
+	started chan struct{}
+	causes  chan error
+}
+
+func (blockingService) GetTools() []Tool {
+	return []Tool{{Name: "stub_block", Description: "Blocks", InputSchema: InputSchema{Type: "object"}}}
+}
+
+func (s blockingService) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
+	close(s.started)
+	<-ctx.Done()
+	s.causes <- context.Cause(ctx)
+	return nil, ctx.Err()
+}
+
+func TestCancelledToolCallIsNotAnswered(t *testing.T) {
+	service := blockingService{started: make(chan struct{}), causes: make(chan error, 1)}
+	srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP}})
+	srv.RegisterService("stub", service)
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+	sessionID := initializeSession(t, endpoint)
+
+	// The call blocks, so it is posted from its own goroutine
+	callCtx, stopCall := context.WithCancel(context.Background())
+	defer stopCall()
+	answered := make(chan *http.Response, 1)
+	go func() {
+		req, _ := http.NewRequestWithContext(callCtx, http.MethodPost, endpoint,
+			strings.NewReader(`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"stub_block","arguments":{}}}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set(sessionHeader, sessionID)
+		resp, err := http.DefaultClient.Do(req)
+		if err == nil {
+			answered <- resp
+		}
+	}()
+
+	select {
+	case <-service.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tool was not called")
+	}
+
+	// The cancellation is read while the tool is still running
+	resp := mcptest.Post(t, endpoint, sessionID, "application/json",
+		`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7,"reason":"user aborted"}}`)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202 for the cancellation, got %d", resp.StatusCode)
+	}
+
+	select {
+	case cause := <-service.causes:
+		if !errors.Is(cause, errRequestCancelled) {
+			t.Errorf("expected the tool context to be cancelled by the client, got %v", cause)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("tool context was not cancelled")
+	}
+
+	select {
+	case resp := <-answered:
+		_ = resp.Body.Close()
+		t.Fatal("expected no response to a cancelled request")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestCancellationOfUnknownRequestIsIgnored(t *testing.T) {
+	_, ts := newTestHTTPServer(t)
+	endpoint := ts.URL + httpEndpointPath
+	sessionID := initializeSession(t, endpoint)
+
+	resp := mcptest.Post(t, endpoint, sessionID, "application/json",
+		`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"never-sent"}}`)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202 for the cancellation, got %d", resp.StatusCode)
+	}
+
+	// The session keeps serving requests
+	resp = mcptest.Post(t, endpoint, sessionID, "application/json", `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for tools/list, got %d", resp.StatusCode)
+	}
+}
@@ -3,27 +3,36 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 )
 
 // CombinedHandler combines multiple service handlers
 type CombinedHandler struct {
-	tools      []Tool
-	resources  []Resource
-	handleFunc func(ctx context.Context, name string, args json.RawMessage) (interface{}, error)
+	definitions ToolSet
+	resources   []Resource
+	prompts     PromptProvider
 }
 
-// NewCombinedHandler creates a new combined handler
-func NewCombinedHandler(tools []Tool, handleFunc func(ctx context.Context, name string, args json.RawMessage) (interface{}, error)) *CombinedHandler {
+// NewCombinedHandler creates a handler serving the tools of all sets
+func NewCombinedHandler(sets ...ToolSet) *CombinedHandler {
+	var definitions ToolSet
+	for _, set := range sets {
+		definitions = append(definitions, set...)
+	}
 	return &CombinedHandler{
-		tools:      tools,
-		resources:  []Resource{},
-		handleFunc: handleFunc,
+		definitions: definitions,
+		resources:   []Resource{},
 	}
 }
 
+// ToolDefinitions returns the tools of all combined sets
+func (h *CombinedHandler) ToolDefinitions() ToolSet {
+	return h.definitions
+}
+
 // GetTools returns all tools from combined services
 func (h *CombinedHandler) GetTools() []Tool {
-	return h.tools
+	return h.definitions.Tools()
 }
 
 // GetResources returns all resources from combined services
@@ -33,7 +42,7 @@ func (h *CombinedHandler) GetResources() []Resource {
 
 // HandleToolCall delegates to the appropriate service handler
 func (h *CombinedHandler) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
-	return h.handleFunc(ctx, name, arguments)
+	return h.definitions.Call(ctx, name, arguments)
 }
 
 // HandleResourceCall delegates to the appropriate service handler
@@ -41,3 +50,24 @@ func (h *CombinedHandler) HandleResourceCall(ctx context.Context, uri string) (i
 	// Not implemented for slides
 	return nil, nil
 }
+
+// SetPromptProvider makes the combined handler serve the prompts of provider
+func (h *CombinedHandler) SetPromptProvider(provider PromptProvider) {
+	h.prompts = provider
+}
+
+// GetPrompts returns the prompts of the configured prompt provider, if any
+func (h *CombinedHandler) GetPrompts() []Prompt {
+	if h.prompts == nil {
+		return nil
+	}
+	return h.prompts.GetPrompts()
+}
+
+// GetPrompt delegates prompt rendering to the configured prompt provider
+func (h *CombinedHandler) GetPrompt(ctx context.Context, name string, arguments map[string]string) (*PromptResult, error) {
+	if h.prompts == nil {
+		return nil, fmt.Errorf("unknown prompt: %s", name)
+	}
+	return h.prompts.GetPrompt(ctx, name, arguments)
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Completion reference types accepted by completion/complete. "ref/tool" is an
+// extension of this server so clients can complete tool arguments as well.
+const (
+	RefPrompt   = "ref/prompt"
+	RefResource = "ref/resource"
+	RefTool     = "ref/tool"
+)
+
+// completionCacheTTL is how long completion candidates are reused. It keeps
+// typing responsive without letting new calendars or labels go unnoticed.
+const completionCacheTTL = 30 * time.Second
+
+// maxCompletionValues is the maximum number of values in one completion result
+const maxCompletionValues = 100
+
+// CompletionRef identifies the prompt, resource template or tool whose
+// argument is being completed
+type CompletionRef struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	URI  string `json:"uri,omitempty"`
+}
+
+// CompletionCandidate is a possible argument value. Label is a human readable
+// name (a calendar or folder title) that the typed text is matched against in
+// addition to Value.
+type CompletionCandidate struct {
+	Value string
+	Label string
+
+	// Term makes Value complete the last space-separated term of the typed
+	// text, such as one filter of a search query, keeping the text before it
+	Term bool
+}
+
+// CompletionProvider is implemented by services that can suggest argument
+// values. It is optional; the server asks the service that owns the referenced
+// prompt, resource template or tool. Returning a nil slice means the argument
+// is not handled, so the server falls back to its registered completers.
+//
+// Providers return every candidate for the argument; the server caches them and
+// filters by what the user has typed so far.
+type CompletionProvider interface {
+	CompleteArgument(ctx context.Context, ref CompletionRef, argument string, arguments map[string]string) ([]CompletionCandidate, error)
+}
+
+// CompleterFunc returns the candidates for an argument shared by many services
+type CompleterFunc func(ctx context.Context, arguments map[string]string) ([]CompletionCandidate, error)
+
+// completionCache holds candidate lists for a short time
+type completionCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]completionCacheEntry
+}
+
+type completionCacheEntry struct {
+	candidates []CompletionCandidate
+	expires    time.Time
+}
+
+func newCompletionCache(ttl time.Duration) *completionCache {
+	return &completionCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]completionCacheEntry),
+	}
+}
+
+// get returns the cached candidates for key if they have not expired
+func (c *completionCache) get(key string) ([]CompletionCandidate, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || c.now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.candidates, true
+}
+
+// put stores candidates under key and drops expired entries
+func (c *completionCache) put(key string, candidates []CompletionCandidate) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for k, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = completionCacheEntry{candidates: candidates, expires: now.Add(c.ttl)}
+}
+
+// completionCacheKey identifies a candidate list. Other argument values are
+// part of the key because they can change the result (e.g. the account).
+func completionCacheKey(ref CompletionRef, argument string, arguments map[string]string) string {
+	var sb strings.Builder
+	sb.WriteString(ref.Type)
+	sb.WriteString("\x00")
+	sb.WriteString(ref.Name)
+	sb.WriteString(ref.URI)
+	sb.WriteString("\x00")
+	sb.WriteString(argument)
+
+	names := make([]string, 0, len(arguments))
+	for name := range arguments {
+		if name != argument {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sb.WriteString("\x00")
+		sb.WriteString(name)
+		sb.WriteString("=")
+		sb.WriteString(arguments[name])
+	}
+	return sb.String()
+}
+
+// filterCompletions returns the values of the candidates matching the typed
+// text, case-insensitively against the value or label. Term candidates are
+// matched against the last term only and returned with the text before it.
+// Prefix matches come first; duplicates are removed.
+func filterCompletions(candidates []CompletionCandidate, typed string) []string {
+	head, last := splitLastTerm(typed)
+
+	var prefix, contains []string
+	seen := make(map[string]bool, len(candidates))
+	for _, c := range candidates {
+		match, completed := typed, c.Value
+		if c.Term {
+			match, completed = last, head+c.Value
+		}
+		if seen[completed] {
+			continue
+		}
+		match = strings.ToLower(match)
+		value := strings.ToLower(c.Value)
+		label := strings.ToLower(c.Label)
+		switch {
+		case strings.HasPrefix(value, match) || (label != "" && strings.HasPrefix(label, match)):
+			prefix = append(prefix, completed)
+		case strings.Contains(value, match) || strings.Contains(label, match):
+			contains = append(contains, completed)
+		default:
+			continue
+		}
+		seen[completed] = true
+	}
+	return append(prefix, contains...)
+}
+
+// splitLastTerm splits typed text before its last space-separated term
+func splitLastTerm(typed string) (head, last string) {
+	i := strings.LastIndexByte(typed, ' ')
+	return typed[:i+1], typed[i+1:]
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.ngs.io/google-mcp-server/config"
+	"go.ngs.io/google-mcp-server/internal/mcptest"
+)
+
+func TestFilterCompletions(t *testing.T) {
+	candidates := []CompletionCandidate{
+		{Value: "abc123", Label: "Team Projects"},
+		{Value: "primary", Label: "Primary calendar"},
+		{Value: "xyz789", Label: "Projects archive"},
+		{Value: "primary", Label: "duplicate"},
+	}
+
+	tests := []struct {
+		typed string
+		want  []string
+	}{
+		{"", []string{"abc123", "primary", "xyz789"}},
+		{"pro", []string{"xyz789", "abc123"}},
+		{"PRI", []string{"primary"}},
+		{"abc", []string{"abc123"}},
+		{"nothing", nil},
+	}
+	for _, tt := range tests {
+		if got := filterCompletions(candidates, tt.typed); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("filterCompletions(%q) = %v, want %v", tt.typed, got, tt.want)
+		}
+	}
+}
+
+func TestFilterCompletionsOfTerms(t *testing.T) {
+	candidates := []CompletionCandidate{
+		{Value: "label:work", Label: "Work", Term: true},
+		{Value: "label:personal", Label: "Personal", Term: true},
+	}
+
+	tests := []struct {
+		typed string
+		want  []string
+	}{
+		{"", []string{"label:work", "label:personal"}},
+		{"label:wo", []string{"label:work"}},
+		{"from:bob label:wo", []string{"from:bob label:work"}},
+		{"from:bob per", []string{"from:bob label:personal"}},
+		{"from:bob", nil},
+	}
+	for _, tt := range tests {
+		if got := filterCompletions(candidates, tt.typed); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("filterCompletions(%q) = %v, want %v", tt.typed, got, tt.want)
+		}
+	}
+}
+
+func TestCompletionCacheExpires(t *testing.T) {
+	now := time.Now()
+	cache := newCompletionCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	cache.put("k", []CompletionCandidate{{Value: "a"}})
+	if got, ok := cache.get("k"); !ok || len(got) != 1 {
+		t.Fatalf("expected cached entry, got %v, %v", got, ok)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := cache.get("k"); ok {
+		t.Error("expected entry to expire after the TTL")
+	}
+}
+
+func TestCompletionCacheKeyIncludesContext(t *testing.T) {
+	ref := CompletionRef{Type: RefTool, Name: "calendar_events_list"}
+	a := completionCacheKey(ref, "calendar_id", map[string]string{"account": "a@example.com", "calendar_id": "x"})
+	b := completionCacheKey(ref, "calendar_id", map[string]string{"account": "b@example.com"})
+	c := completionCacheKey(ref, "calendar_id", map[string]string{"account": "a@example.com", "calendar_id": "y"})
+	if a == b {
+		t.Error("expected different accounts to use different cache keys")
+	}
+	if a != c {
+		t.Error("expected the value being completed to be left out of the cache key")
+	}
+}
+
+// completingService completes the "folder" argument and counts lookups
+type completingService struct {
+	stubService
+	calls int
+}
+
+func (s *completingService) CompleteArgument(ctx context.Context, ref CompletionRef, argument string, arguments map[string]string) ([]CompletionCandidate, error) {
+	if argument != "folder" {
+		return nil, nil
+	}
+	s.calls++
+	return []CompletionCandidate{
+		{Value: "f1", Label: "Reports"},
+		{Value: "f2", Label: "Receipts"},
+		{Value: "f3", Label: "Archive"},
+	}, nil
+}
+
+func TestHandleCompletion(t *testing.T) {
+	service := &completingService{}
+	srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP}})
+	srv.RegisterService("stub", service)
+	srv.RegisterCompleter("account", func(ctx context.Context, arguments map[string]string) ([]CompletionCandidate, error) {
+		return []CompletionCandidate{{Value: "me@example.com"}, {Value: "work@example.com"}}, nil
+	})
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+	sessionID := initializeSession(t, endpoint)
+
+	complete := func(id int, ref, argument, value string) []string {
+		t.Helper()
+		body := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"completion/complete","params":{"ref":%s,"argument":{"name":%q,"value":%q}}}`,
+			id, ref, argument, value)
+		resp := mcptest.Post(t, endpoint, sessionID, "application/json", body)
+		defer func() { _ = resp.Body.Close() }()
+		var result struct {
+			Result struct {
+				Completion struct {
+					Values []string `json:"values"`
+					Total  int      `json:"total"`
+				} `json:"completion"`
+			} `json:"result"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode completion response: %v", err)
+		}
+		return result.Result.Completion.Values
+	}
+
+	toolRef := `{"type":"ref/tool","name":"stub_echo"}`
+	if got := complete(2, toolRef, "folder", "re"); !reflect.DeepEqual(got, []string{"f1", "f2"}) {
+		t.Errorf("unexpected folder completions: %v", got)
+	}
+	if got := complete(3, toolRef, "folder", "arc"); !reflect.DeepEqual(got, []string{"f3"}) {
+		t.Errorf("unexpected folder completions: %v", got)
+	}
+	if service.calls != 1 {
+		t.Errorf("expected candidates to be cached between keystrokes, got %d lookups", service.calls)
+	}
+
+	// Arguments the service does not handle fall back to registered completers
+	if got := complete(4, toolRef, "account", "wo"); !reflect.DeepEqual(got, []string{"work@example.com"}) {
+		t.Errorf("unexpected account completions: %v", got)
+	}
+
+	// Unknown references still get an empty list
+	if got := complete(5, `{"type":"ref/prompt","name":"missing"}`, "other", ""); len(got) != 0 {
+		t.Errorf("expected no completions, got %v", got)
+	}
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sourcegraph/jsonrpc2"
+	"go.ngs.io/google-mcp-server/config"
+)
+
+// elicitationTimeout bounds how long a tool call waits for the user to answer
+// a confirmation request
+const elicitationTimeout = 5 * time.Minute
+
+// Errors returned to the model when a destructive call does not run
+var (
+	ErrNotConfirmed            = errors.New("the user did not confirm the operation")
+	ErrConfirmationUnavailable = errors.New("the operation needs the user's confirmation, which this client cannot ask for")
+	ErrConfirmationUnreachable = errors.New("the operation needs the user's confirmation, but the client has no open stream to receive the question on")
+)
+
+// Confirmer is implemented by services that describe their destructive
+// calls better than the default confirmation message can. Every destructive
+// tool asks the user to confirm; ConfirmationMessage only words the question,
+// e.g. naming the file rather than its ID, and returns an empty string to
+// use the default message.
+type Confirmer interface {
+	ConfirmationMessage(ctx context.Context, name string, arguments json.RawMessage) (string, error)
+}
+
+// confirmedContextKey marks the context of a tool call the user confirmed
+type confirmedContextKey struct{}
+
+// UserConfirmed reports whether the user confirmed the current tool call
+// through elicitation. Tools that ask the model for an explicit confirm
+// argument can accept the user's answer instead.
+func UserConfirmed(ctx context.Context) bool {
+	confirmed, _ := ctx.Value(confirmedContextKey{}).(bool)
+	return confirmed
+}
+
+// WithUserConfirmed returns a copy of ctx for a tool call the user
+// confirmed, as the server passes to the tool once the user accepts
+func WithUserConfirmed(ctx context.Context) context.Context {
+	return context.WithValue(ctx, confirmedContextKey{}, true)
+}
+
+// elicitationParams are the params of an elicitation/create request
+type elicitationParams struct {
+	Message         string          `json:"message"`
+	RequestedSchema json.RawMessage `json:"requestedSchema"`
+}
+
+// elicitationResult is the client's answer to an elicitation/create request
+type elicitationResult struct {
+	Action  string          `json:"action"` // accept, decline or cancel
+	Content json.RawMessage `json:"content,omitempty"`
+}
+
+// confirmationSchema asks for a single checkbox
+var confirmationSchema = json.RawMessage(`{"type":"object","properties":{"confirm":{"type":"boolean","title":"Confirm","description":"Check to go ahead"}},"required":["confirm"]}`)
+
+// confirmToolCall asks the user to confirm a call to a destructive tool and
+// returns the context to run it with. The service words the question if it
+// is a Confirmer. When the client cannot ask, the configured fallback
+// decides.
+func (h *Handler) confirmToolCall(ctx context.Context, conn *jsonrpc2.Conn, entry toolEntry, name string, arguments json.RawMessage) (context.Context, error) {
+	if !entry.tool.Annotations.IsDestructive() {
+		return ctx, nil
+	}
+	var message string
+	if confirmer, ok := entry.handler.(Confirmer); ok {
+		var err error
+		if message, err = confirmer.ConfirmationMessage(ctx, name, arguments); err != nil {
+			return ctx, err
+		}
+	}
+	if message == "" {
+		message = defaultConfirmationMessage(entry.tool, arguments)
+	}
+
+	if !h.session.Supports(FeatureElicitation) {
+		if h.server.config.Global.ConfirmationFallback == config.ConfirmationDeny {
+			logger.InfoContext(ctx, "refusing call that needs confirmation", "reason", "elicitation unsupported")
+			return ctx, NewToolError(CategoryDenied, ErrConfirmationUnavailable)
+		}
+		return ctx, nil
+	}
+
+	// A question sent without a stream to carry it would be dropped, and
+	// the call would wait for an answer until the timeout
+	if !h.session.canSendRequests() {
+		logger.InfoContext(ctx, "refusing call that needs confirmation", "reason", "no open stream")
+		return ctx, NewToolError(CategoryDenied, ErrConfirmationUnreachable)
+	}
+
+	elicitCtx, cancel := context.WithTimeout(ctx, elicitationTimeout)
+	defer cancel()
+	var result elicitationResult
+	err := conn.Call(elicitCtx, "elicitation/create", elicitationParams{Message: message, RequestedSchema: confirmationSchema}, &result)
+	if err != nil {
+		return ctx, fmt.Errorf("failed to ask for confirmation: %w", err)
+	}
+
+	var answer struct {
+		Confirm bool `json:"confirm"`
+	}
+	if result.Action == "accept" && len(result.Content) > 0 {
+		if err := json.Unmarshal(result.Content, &answer); err != nil {
+			return ctx, fmt.Errorf("invalid confirmation answer: %w", err)
+		}
+	}
+	if !answer.Confirm {
+		logger.InfoContext(ctx, "call not confirmed", "action", result.Action)
+		return ctx, NewToolError(CategoryDenied, ErrNotConfirmed)
+	}
+	return WithUserConfirmed(ctx), nil
+}
+
+// maxConfirmedArgument is the longest argument value quoted in a default
+// confirmation message
+const maxConfirmedArgument = 80
+
+// defaultConfirmationMessage asks about a destructive call in general
+// terms, naming the tool and listing the arguments it was called with
+func defaultConfirmationMessage(tool Tool, arguments json.RawMessage) string {
+	title := tool.Name
+	if tool.Annotations != nil && tool.Annotations.Title != "" {
+		title = fmt.Sprintf("%s (%s)", tool.Annotations.Title, tool.Name)
+	}
+	message := fmt.Sprintf("Allow %s to run? It may overwrite or delete data that cannot be restored.", title)
+
+	var args map[string]json.RawMessage
+	if json.Unmarshal(arguments, &args) != nil || len(args) == 0 {
+		return message
+	}
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		value := string(args[name])
+		var str string
+		if json.Unmarshal(args[name], &str) == nil {
+			value = str
+		}
+		value = sanitizeErrorMessage(value)
+		if runes := []rune(value); len(runes) > maxConfirmedArgument {
+			value = string(runes[:maxConfirmedArgument]) + "…"
+		}
+		pairs = append(pairs, name+"="+value)
+	}
+	return message + " Arguments: " + strings.Join(pairs, ", ")
+}
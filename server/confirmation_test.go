@@ -0,0 +1,246 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.ngs.io/google-mcp-server/config"
+	"go.ngs.io/google-mcp-server/internal/mcptest"
+)
+
+// confirmingService has a destructive tool that needs confirmation and
+// records whether it ran and whether the user confirmed it
+type confirmingService struct {
+	stubService
+	calls     atomic.Int32
+	confirmed atomic.Bool
+}
+
+func (s *confirmingService) GetTools() []Tool {
+	return []Tool{{Name: "stub_destroy", InputSchema: InputSchema{Type: "object"}, Annotations: DestructiveAnnotations()}}
+}
+
+func (s *confirmingService) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
+	s.calls.Add(1)
+	s.confirmed.Store(UserConfirmed(ctx))
+	return "destroyed", nil
+}
+
+func (s *confirmingService) ConfirmationMessage(ctx context.Context, name string, arguments json.RawMessage) (string, error) {
+	return "Destroy everything?", nil
+}
+
+// callDestroy calls stub_destroy and returns the error category of the
+// result, or an empty string if the call succeeded
+func callDestroy(t *testing.T, endpoint, sessionID string) ErrorCategory {
+	t.Helper()
+	resp := mcptest.Post(t, endpoint, sessionID, "application/json",
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"stub_destroy","arguments":{}}}`)
+	defer func() { _ = resp.Body.Close() }()
+	var msg struct {
+		Result struct {
+			Content []Content `json:"content"`
+			IsError bool      `json:"isError"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+		t.Fatalf("failed to decode tools/call response: %v", err)
+	}
+	if !msg.Result.IsError {
+		return ""
+	}
+	var body struct {
+		Error toolErrorDetail `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(msg.Result.Content[0].Text), &body); err != nil {
+		t.Fatalf("error content is not JSON: %v", err)
+	}
+	return body.Error.Category
+}
+
+func TestElicitationConfirmsDestructiveCalls(t *testing.T) {
+	service := &confirmingService{}
+	srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP}})
+	srv.RegisterService("stub", service)
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+	sessionID := mcptest.Initialize(t, endpoint, ProtocolVersion20250618, `{"elicitation":{}}`)
+	requests := mcptest.Listen(t, endpoint, sessionID, "elicitation/create")
+
+	// answer replies to the next elicitation request with the given result
+	answer := func(result string) {
+		select {
+		case msg := <-requests:
+			var request struct {
+				Params elicitationParams `json:"params"`
+			}
+			if err := json.Unmarshal(msg, &request); err != nil {
+				t.Errorf("failed to decode elicitation request: %v", err)
+				return
+			}
+			if request.Params.Message != "Destroy everything?" {
+				t.Errorf("expected the confirmation message, got %q", request.Params.Message)
+			}
+			mcptest.Reply(t, endpoint, sessionID, msg, result)
+		case <-time.After(5 * time.Second):
+			t.Error("expected an elicitation request")
+		}
+	}
+
+	go answer(`{"action":"accept","content":{"confirm":true}}`)
+	if category := callDestroy(t, endpoint, sessionID); category != "" {
+		t.Fatalf("expected the confirmed call to succeed, got %s", category)
+	}
+	if service.calls.Load() != 1 || !service.confirmed.Load() {
+		t.Errorf("expected the tool to run once as confirmed, got %d calls (confirmed=%v)", service.calls.Load(), service.confirmed.Load())
+	}
+
+	for _, result := range []string{`{"action":"decline"}`, `{"action":"accept","content":{"confirm":false}}`} {
+		go answer(result)
+		if category := callDestroy(t, endpoint, sessionID); category != CategoryDenied {
+			t.Errorf("expected %s to deny the call, got %q", result, category)
+		}
+	}
+	if service.calls.Load() != 1 {
+		t.Errorf("expected unconfirmed calls not to run, got %d calls", service.calls.Load())
+	}
+}
+
+func TestConfirmationFallback(t *testing.T) {
+	for _, fallback := range []string{config.ConfirmationAllow, config.ConfirmationDeny} {
+		t.Run(fallback, func(t *testing.T) {
+			service := &confirmingService{}
+			srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP, ConfirmationFallback: fallback}})
+			srv.RegisterService("stub", service)
+			ts := newTestServerFor(t, srv)
+			endpoint := ts.URL + httpEndpointPath
+
+			// Without the elicitation capability the client cannot be asked
+			sessionID := mcptest.Initialize(t, endpoint, ProtocolVersion20250618, `{}`)
+			category := callDestroy(t, endpoint, sessionID)
+
+			switch fallback {
+			case config.ConfirmationAllow:
+				if category != "" || service.calls.Load() != 1 || service.confirmed.Load() {
+					t.Errorf("expected the call to run unconfirmed, got category %q and %d calls", category, service.calls.Load())
+				}
+			case config.ConfirmationDeny:
+				if category != CategoryDenied || service.calls.Load() != 0 {
+					t.Errorf("expected the call to be denied, got category %q and %d calls", category, service.calls.Load())
+				}
+			}
+		})
+	}
+}
+
+func TestConfirmationMessageErrorsFailTheCall(t *testing.T) {
+	srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP}})
+	srv.RegisterService("stub", &failingConfirmer{})
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+	sessionID := mcptest.Initialize(t, endpoint, ProtocolVersion20250618, `{"elicitation":{}}`)
+
+	if category := callDestroy(t, endpoint, sessionID); category != CategoryInvalidArgument {
+		t.Errorf("expected the invalid arguments to be reported, got %q", category)
+	}
+}
+
+// failingConfirmer cannot describe its calls
+type failingConfirmer struct {
+	confirmingService
+}
+
+func (*failingConfirmer) ConfirmationMessage(ctx context.Context, name string, arguments json.RawMessage) (string, error) {
+	return "", NewToolError(CategoryInvalidArgument, errors.New("unknown file"))
+}
+
+// overwritingService has a destructive and an idempotent tool and words no
+// confirmation of its own
+type overwritingService struct {
+	stubService
+	calls *atomic.Int32
+}
+
+func (overwritingService) GetTools() []Tool {
+	return []Tool{
+		{Name: "stub_overwrite", InputSchema: InputSchema{Type: "object"}, Annotations: DestructiveAnnotations()},
+		{Name: "stub_move", InputSchema: InputSchema{Type: "object"}, Annotations: IdempotentAnnotations()},
+	}
+}
+
+func (s overwritingService) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
+	s.calls.Add(1)
+	return "done", nil
+}
+
+func TestDestructiveToolsAskByDefault(t *testing.T) {
+	service := overwritingService{calls: &atomic.Int32{}}
+	srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP}})
+	srv.RegisterService("stub", service)
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+	sessionID := mcptest.Initialize(t, endpoint, ProtocolVersion20250618, `{"elicitation":{}}`)
+	requests := mcptest.Listen(t, endpoint, sessionID, "elicitation/create")
+
+	go func() {
+		select {
+		case msg := <-requests:
+			var request struct {
+				Params elicitationParams `json:"params"`
+			}
+			_ = json.Unmarshal(msg, &request)
+			if !strings.Contains(request.Params.Message, "stub_overwrite") || !strings.Contains(request.Params.Message, "file_id=abc") {
+				t.Errorf("expected the default message to name the tool and its arguments, got %q", request.Params.Message)
+			}
+			mcptest.Reply(t, endpoint, sessionID, msg, `{"action":"decline"}`)
+		case <-time.After(5 * time.Second):
+			t.Error("expected an elicitation request")
+		}
+	}()
+	if _, isError := mcptest.CallTool(t, endpoint, sessionID, `{"name":"stub_overwrite","arguments":{"file_id":"abc"}}`); !isError {
+		t.Error("expected the declined call to fail")
+	}
+
+	// Tools that do not destroy data run without asking
+	if text, isError := mcptest.CallTool(t, endpoint, sessionID, `{"name":"stub_move","arguments":{}}`); isError {
+		t.Errorf("expected the idempotent call to run, got %s", text)
+	}
+	if service.calls.Load() != 1 {
+		t.Errorf("expected only the idempotent call to run, got %d calls", service.calls.Load())
+	}
+}
+
+func TestConfirmationWithoutOpenStreamFailsFast(t *testing.T) {
+	service := &confirmingService{}
+	srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP}})
+	srv.RegisterService("stub", service)
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+
+	// The client can answer elicitations but has no stream open to get them
+	sessionID := mcptest.Initialize(t, endpoint, ProtocolVersion20250618, `{"elicitation":{}}`)
+	start := time.Now()
+	if category := callDestroy(t, endpoint, sessionID); category != CategoryDenied {
+		t.Errorf("expected the call to be denied, got %q", category)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the call to fail at once, took %s", elapsed)
+	}
+	if service.calls.Load() != 0 {
+		t.Errorf("expected the tool not to run, got %d calls", service.calls.Load())
+	}
+}
+
+func TestUserConfirmed(t *testing.T) {
+	if UserConfirmed(context.Background()) {
+		t.Error("expected a call to be unconfirmed by default")
+	}
+	if !UserConfirmed(WithUserConfirmed(context.Background())) {
+		t.Error("expected the context of an accepted call to be confirmed")
+	}
+}
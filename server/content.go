@@ -0,0 +1,128 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Content types of tool result blocks
+const (
+	ContentTypeText     = "text"
+	ContentTypeImage    = "image"
+	ContentTypeResource = "resource"
+)
+
+// Content is a block of a tool result: text, a base64 image, or an embedded
+// resource
+type Content struct {
+	Type     string            `json:"type"`
+	Text     string            `json:"text,omitempty"`
+	Data     string            `json:"data,omitempty"`
+	MimeType string            `json:"mimeType,omitempty"`
+	Resource *ResourceContents `json:"resource,omitempty"`
+}
+
+// ResourceContents are the contents of a resource, as read with
+// resources/read or embedded in a tool result. Text resources set Text and
+// binary ones set Blob to the base64 encoded data.
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+// TextContent returns a text block
+func TextContent(text string) Content {
+	return Content{Type: ContentTypeText, Text: text}
+}
+
+// ImageContent returns an image block. The MIME type is sniffed from data
+// when mimeType is empty.
+func ImageContent(data []byte, mimeType string) Content {
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	return Content{Type: ContentTypeImage, Data: base64.StdEncoding.EncodeToString(data), MimeType: mimeType}
+}
+
+// EmbeddedResource returns a block carrying a resource, so clients can tell
+// which URI the data belongs to and read it again later
+func EmbeddedResource(contents ResourceContents) Content {
+	return Content{Type: ContentTypeResource, Resource: &contents}
+}
+
+// TextResource returns the contents of a text resource
+func TextResource(uri, mimeType, text string) ResourceContents {
+	return ResourceContents{URI: uri, MimeType: mimeType, Text: text}
+}
+
+// BlobResource returns the contents of a binary resource. The MIME type is
+// sniffed from data when mimeType is empty.
+func BlobResource(uri, mimeType string, data []byte) ResourceContents {
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	return ResourceContents{URI: uri, MimeType: mimeType, Blob: base64.StdEncoding.EncodeToString(data)}
+}
+
+// JSONResource returns the contents of a resource whose text is v as JSON
+func JSONResource(uri string, v interface{}) (ResourceContents, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ResourceContents{}, fmt.Errorf("failed to encode resource %s: %w", uri, err)
+	}
+	return TextResource(uri, "application/json", string(data)), nil
+}
+
+// ToolResult is a tool result made of typed content blocks. Services return
+// it from HandleToolCall instead of a value to be sent as JSON text when the
+// result holds images or resources. Structured, if set, is sent as
+// structuredContent and as a leading text block for clients that only read
+// text.
+type ToolResult struct {
+	Content    []Content
+	Structured map[string]interface{}
+}
+
+// ResourceResult is the result of reading a resource made of one or more
+// contents. HandleResourceCall and HandleResourceTemplateCall return it when
+// the resource is binary or not JSON; contents without a URI get the URI
+// that was read.
+type ResourceResult struct {
+	Contents []ResourceContents
+}
+
+// resourceContents converts what a service returned for a resource read into
+// contents. Strings are plain text, byte slices binary, and any other value
+// is sent as JSON.
+func resourceContents(uri string, result interface{}) ([]ResourceContents, error) {
+	switch v := result.(type) {
+	case *ResourceResult:
+		contents := make([]ResourceContents, len(v.Contents))
+		for i, c := range v.Contents {
+			if c.URI == "" {
+				c.URI = uri
+			}
+			contents[i] = c
+		}
+		return contents, nil
+	case ResourceContents:
+		if v.URI == "" {
+			v.URI = uri
+		}
+		return []ResourceContents{v}, nil
+	case string:
+		return []ResourceContents{TextResource(uri, "text/plain", v)}, nil
+	case []byte:
+		return []ResourceContents{BlobResource(uri, "", v)}, nil
+	default:
+		contents, err := JSONResource(uri, v)
+		if err != nil {
+			return nil, err
+		}
+		return []ResourceContents{contents}, nil
+	}
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"go.ngs.io/google-mcp-server/config"
+	"go.ngs.io/google-mcp-server/internal/mcptest"
+)
+
+func TestResourceContents(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n")
+
+	tests := []struct {
+		name     string
+		result   interface{}
+		mimeType string
+		text     string
+		blob     string
+	}{
+		{"string", "hello", "text/plain", "hello", ""},
+		{"object", map[string]int{"count": 2}, "application/json", `{"count":2}`, ""},
+		{"bytes", png, "image/png", "", "iVBORw0KGgo="},
+		{"typed", &ResourceResult{Contents: []ResourceContents{TextResource("", "text/markdown", "# Notes")}}, "text/markdown", "# Notes", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contents, err := resourceContents("test://resource", tt.result)
+			if err != nil {
+				t.Fatalf("resourceContents failed: %v", err)
+			}
+			if len(contents) != 1 {
+				t.Fatalf("expected one content, got %d", len(contents))
+			}
+			got := contents[0]
+			if got.URI != "test://resource" || got.MimeType != tt.mimeType || got.Text != tt.text || got.Blob != tt.blob {
+				t.Errorf("unexpected contents %+v", got)
+			}
+		})
+	}
+}
+
+func TestNewCallToolResult(t *testing.T) {
+	result := newCallToolResult(&ToolResult{
+		Structured: map[string]interface{}{"file_id": "abc"},
+		Content:    []Content{EmbeddedResource(BlobResource("drive://file/abc", "application/pdf", []byte("%PDF")))},
+	})
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("failed to encode result: %v", err)
+	}
+	want := `{"content":[{"type":"text","text":"{\"file_id\":\"abc\"}"},` +
+		`{"type":"resource","resource":{"uri":"drive://file/abc","mimeType":"application/pdf","blob":"JVBERg=="}}],` +
+		`"structuredContent":{"file_id":"abc"}}`
+	if string(data) != want {
+		t.Errorf("unexpected result\n got %s\nwant %s", data, want)
+	}
+
+	if result := newCallToolResult("plain"); len(result.Content) != 1 || result.Content[0].Text != "plain" || result.StructuredContent != nil {
+		t.Errorf("expected a string to become a single text block, got %+v", result)
+	}
+}
+
+// imageService returns typed content from its tool and resource
+type imageService struct {
+	stubService
+}
+
+func (imageService) GetTools() []Tool {
+	return []Tool{{Name: "stub_image", InputSchema: InputSchema{Type: "object"}}}
+}
+
+func (imageService) GetResources() []Resource {
+	return []Resource{{URI: "stub://report", Name: "Report", MimeType: "application/json"}}
+}
+
+func (imageService) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
+	return &ToolResult{Content: []Content{ImageContent([]byte("GIF89a"), "")}}, nil
+}
+
+func (imageService) HandleResourceCall(ctx context.Context, uri string) (interface{}, error) {
+	return map[string]interface{}{"pages": 3}, nil
+}
+
+func TestTypedContentOverHTTP(t *testing.T) {
+	srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP}})
+	srv.RegisterService("stub", imageService{})
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+	sessionID := initializeSession(t, endpoint)
+
+	resp := mcptest.Post(t, endpoint, sessionID, "application/json",
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"stub_image","arguments":{}}}`)
+	var call struct {
+		Result struct {
+			Content []Content `json:"content"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&call); err != nil {
+		t.Fatalf("failed to decode tools/call response: %v", err)
+	}
+	_ = resp.Body.Close()
+	if len(call.Result.Content) != 1 || call.Result.Content[0].Type != ContentTypeImage || call.Result.Content[0].MimeType != "image/gif" {
+		t.Errorf("expected a single image/gif block, got %+v", call.Result.Content)
+	}
+
+	resp = mcptest.Post(t, endpoint, sessionID, "application/json",
+		`{"jsonrpc":"2.0","id":3,"method":"resources/read","params":{"uri":"stub://report"}}`)
+	var read struct {
+		Result struct {
+			Contents []ResourceContents `json:"contents"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&read); err != nil {
+		t.Fatalf("failed to decode resources/read response: %v", err)
+	}
+	_ = resp.Body.Close()
+	if len(read.Result.Contents) != 1 || read.Result.Contents[0].MimeType != "application/json" || read.Result.Contents[0].Text != `{"pages":3}` {
+		t.Errorf("expected the resource as JSON, got %+v", read.Result.Contents)
+	}
+}
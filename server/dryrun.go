@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.ngs.io/google-mcp-server/auth"
+)
+
+// dryRunArgument is the argument asking a tool that changes data to return
+// the API requests it would send instead of sending them
+const dryRunArgument = "dry_run"
+
+// withDryRunArgument adds the dry_run argument to the schema of a tool
+// that changes data through a Google API. Local tools, such as removing a
+// stored account, change files the dry run cannot hold back.
+func withDryRunArgument(tool Tool) Tool {
+	if tool.Annotations.IsReadOnly() || tool.Annotations.IsLocal() {
+		return tool
+	}
+	if _, exists := tool.InputSchema.Properties[dryRunArgument]; exists {
+		return tool
+	}
+	tool.InputSchema = copySchema(tool.InputSchema)
+	tool.InputSchema.Properties[dryRunArgument] = Property{
+		Type:        "boolean",
+		Description: "Return the Google API requests the tool would send, without sending them",
+	}
+	return tool
+}
+
+// takeDryRun reports whether a call asks for a dry run, and returns its
+// arguments without dry_run so that services never see it
+func takeDryRun(arguments json.RawMessage) (bool, json.RawMessage) {
+	var args map[string]json.RawMessage
+	if len(arguments) == 0 || json.Unmarshal(arguments, &args) != nil {
+		return false, arguments
+	}
+	raw, ok := args[dryRunArgument]
+	if !ok {
+		return false, arguments
+	}
+	delete(args, dryRunArgument)
+	stripped, err := json.Marshal(args)
+	if err != nil {
+		return false, arguments
+	}
+	var dryRun bool
+	_ = json.Unmarshal(raw, &dryRun)
+	return dryRun, stripped
+}
+
+// isDryRun reports whether a call of tool runs as a dry run, because the
+// server runs every call so or because the call asked for it
+func (s *MCPServer) isDryRun(tool Tool, requested bool) bool {
+	if tool.Annotations.IsReadOnly() {
+		return false
+	}
+	return requested || s.config.Global.DryRun
+}
+
+// dryRunUnsupported is the error of a dry run of a local tool, which is
+// refused rather than run for real
+func dryRunUnsupported(tool string) error {
+	return NewToolError(CategoryInvalidArgument,
+		fmt.Errorf("%s changes data kept by this server, not by Google, and cannot run as a dry run", tool))
+}
+
+// dryRunResult is the result of a tool call made as a dry run
+type dryRunResult struct {
+	DryRun   bool                  `json:"dry_run"`
+	Requests []auth.PlannedRequest `json:"requests"`
+	Note     string                `json:"note"`
+
+	// Result is what the tool returned when it finished without making
+	// a request that changes data
+	Result interface{} `json:"result,omitempty"`
+}
+
+// newDryRunResult reports the requests a dry run held back. A tool stops
+// at the first one, as it fails without the response, so later requests
+// that depend on it are not known.
+func newDryRunResult(requests []auth.PlannedRequest, result interface{}) dryRunResult {
+	if requests == nil {
+		requests = []auth.PlannedRequest{}
+	}
+	r := dryRunResult{DryRun: true, Requests: requests}
+	if len(requests) == 0 {
+		r.Note = "Nothing was sent. The tool made no request that changes data."
+		r.Result = result
+	} else {
+		r.Note = "Nothing was sent. The tool stopped at its first request that changes data; requests that would depend on its response are not shown."
+	}
+	return r
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"go.ngs.io/google-mcp-server/auth"
+	"go.ngs.io/google-mcp-server/config"
+	"go.ngs.io/google-mcp-server/internal/mcptest"
+)
+
+// writingService has a tool that creates an event through a dry run
+// transport, and counts the requests that reach the network
+type writingService struct {
+	stubService
+	sent      *atomic.Int32
+	arguments *atomic.Value
+}
+
+func (writingService) GetTools() []Tool {
+	return []Tool{
+		{Name: "stub_create", Description: "Creates an event", InputSchema: InputSchema{Type: "object"}, Annotations: AdditiveAnnotations()},
+		{Name: "stub_read", Description: "Reads", InputSchema: InputSchema{Type: "object"}, Annotations: ReadOnlyAnnotations()},
+		{Name: "stub_forget", Description: "Deletes a stored token", InputSchema: InputSchema{Type: "object"}, Annotations: DestructiveAnnotations().Local()},
+	}
+}
+
+func (s writingService) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
+	s.arguments.Store(string(arguments))
+	if name == "stub_forget" {
+		s.sent.Add(1)
+		return "forgotten", nil
+	}
+	client := &http.Client{Transport: auth.NewDryRunTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		s.sent.Add(1)
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	}))}
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "https://www.googleapis.com/calendar/v3/calendars/primary/events",
+		strings.NewReader(`{"summary":"Standup"}`))
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	return map[string]string{"id": "evt1"}, nil
+}
+
+// roundTripFunc lets a function stand in for the network
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestDryRun(t *testing.T) {
+	service := writingService{sent: &atomic.Int32{}, arguments: &atomic.Value{}}
+	cfg := &config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP}}
+	srv := NewMCPServer(cfg)
+	srv.RegisterService("stub", service)
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+	sessionID := initializeSession(t, endpoint)
+
+	// Only tools that change data take dry_run
+	if _, ok := srv.toolMap["stub_create"].tool.InputSchema.Properties[dryRunArgument]; !ok {
+		t.Error("expected stub_create to declare dry_run")
+	}
+	if _, ok := srv.toolMap["stub_read"].tool.InputSchema.Properties[dryRunArgument]; ok {
+		t.Error("expected stub_read not to declare dry_run")
+	}
+	if _, ok := srv.toolMap["stub_forget"].tool.InputSchema.Properties[dryRunArgument]; ok {
+		t.Error("expected the local stub_forget not to declare dry_run")
+	}
+
+	text, isError := mcptest.CallTool(t, endpoint, sessionID, `{"name":"stub_create","arguments":{"title":"x","dry_run":true}}`)
+	var result struct {
+		DryRun   bool                  `json:"dry_run"`
+		Requests []auth.PlannedRequest `json:"requests"`
+	}
+	if isError || json.Unmarshal([]byte(text), &result) != nil {
+		t.Fatalf("expected a dry run result, got %s", text)
+	}
+	if !result.DryRun || len(result.Requests) != 1 || string(result.Requests[0].Body) != `{"summary":"Standup"}` {
+		t.Errorf("expected the planned request, got %s", text)
+	}
+	if service.sent.Load() != 0 {
+		t.Errorf("expected nothing to be sent, got %d requests", service.sent.Load())
+	}
+	if got := service.arguments.Load(); got != `{"title":"x"}` {
+		t.Errorf("expected dry_run to be removed from the arguments, got %v", got)
+	}
+
+	// Without dry_run the request is sent
+	if text, isError := mcptest.CallTool(t, endpoint, sessionID, `{"name":"stub_create","arguments":{"dry_run":false}}`); isError || !strings.Contains(text, "evt1") {
+		t.Errorf("expected the tool to run, got %s", text)
+	}
+	if service.sent.Load() != 1 {
+		t.Errorf("expected the request to be sent, got %d requests", service.sent.Load())
+	}
+
+	// The server can run every call as a dry run
+	cfg.Global.DryRun = true
+	if text, _ := mcptest.CallTool(t, endpoint, sessionID, `{"name":"stub_create","arguments":{}}`); !strings.Contains(text, `"dry_run":true`) {
+		t.Errorf("expected a dry run result, got %s", text)
+	}
+	if service.sent.Load() != 1 {
+		t.Errorf("expected nothing more to be sent, got %d requests", service.sent.Load())
+	}
+}
+
+func TestDryRunRefusesLocalTools(t *testing.T) {
+	service := writingService{sent: &atomic.Int32{}, arguments: &atomic.Value{}}
+	cfg := &config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP}}
+	srv := NewMCPServer(cfg)
+	srv.RegisterService("stub", service)
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+	sessionID := initializeSession(t, endpoint)
+
+	// A local tool changes data that no dry run can hold back, so it
+	// refuses to run rather than run for real
+	text, isError := mcptest.CallTool(t, endpoint, sessionID, `{"name":"stub_forget","arguments":{"dry_run":true}}`)
+	if !isError || !strings.Contains(text, string(CategoryInvalidArgument)) {
+		t.Errorf("expected the dry run to be refused, got %s", text)
+	}
+	cfg.Global.DryRun = true
+	if text, isError := mcptest.CallTool(t, endpoint, sessionID, `{"name":"stub_forget","arguments":{}}`); !isError {
+		t.Errorf("expected the server-wide dry run to be refused, got %s", text)
+	}
+	if service.sent.Load() != 0 {
+		t.Errorf("expected the local tool not to run, got %d calls", service.sent.Load())
+	}
+}
+
+func TestDryRunReportsErrorsBeforeTheFirstWrite(t *testing.T) {
+	srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP}})
+	srv.RegisterService("stub", stubService{})
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+	sessionID := initializeSession(t, endpoint)
+
+	text, isError := mcptest.CallTool(t, endpoint, sessionID, `{"name":"stub_fail","arguments":{"dry_run":true}}`)
+	if !isError || !strings.Contains(text, "not_found") {
+		t.Errorf("expected the tool's error, got %s", text)
+	}
+}
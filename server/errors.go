@@ -0,0 +1,208 @@
+package server
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"go.ngs.io/google-mcp-server/auth"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/googleapi"
+)
+
+// ErrorCategory classifies a tool failure so the client can decide how to react
+type ErrorCategory string
+
+// Error categories reported in tool error results
+const (
+	CategoryAuth            ErrorCategory = "auth"
+	CategoryNotFound        ErrorCategory = "not_found"
+	CategoryQuota           ErrorCategory = "quota"
+	CategoryInvalidArgument ErrorCategory = "invalid_argument"
+	CategoryUpstream        ErrorCategory = "upstream"
+	CategoryInternal        ErrorCategory = "internal"
+	// CategoryDenied means the call did not run because the user declined
+	// it or policy forbids it; retrying will not help
+	CategoryDenied ErrorCategory = "denied"
+	// CategoryTimeout means the call ran past its deadline; it may still
+	// have taken effect
+	CategoryTimeout ErrorCategory = "timeout"
+)
+
+// ToolError attaches an explicit category to an error returned by a tool handler.
+// Handlers only need it when the category cannot be inferred from the error itself.
+type ToolError struct {
+	Category ErrorCategory
+	Err      error
+}
+
+// NewToolError wraps err with the given category
+func NewToolError(category ErrorCategory, err error) *ToolError {
+	return &ToolError{Category: category, Err: err}
+}
+
+// Error returns the message of the wrapped error, or the category when
+// there is none
+func (e *ToolError) Error() string {
+	if e.Err == nil {
+		return string(e.Category)
+	}
+	return e.Err.Error()
+}
+
+func (e *ToolError) Unwrap() error {
+	return e.Err
+}
+
+// quotaReasons are googleapi error reasons that indicate rate or quota exhaustion
+var quotaReasons = map[string]bool{
+	"rateLimitExceeded":     true,
+	"userRateLimitExceeded": true,
+	"quotaExceeded":         true,
+	"dailyLimitExceeded":    true,
+	"limitExceeded":         true,
+}
+
+// classifyError determines the category of a tool failure
+func classifyError(err error) ErrorCategory {
+	var toolErr *ToolError
+	if errors.As(err, &toolErr) {
+		return toolErr.Category
+	}
+
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return CategoryInvalidArgument
+	}
+
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) {
+		return CategoryTimeout
+	}
+
+	var scopeErr *auth.ScopeError
+	if errors.As(err, &scopeErr) {
+		return CategoryAuth
+	}
+
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) {
+		return CategoryAuth
+	}
+
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return classifyAPIError(apiErr)
+	}
+
+	if auth.IsAPIDisabledError(err) {
+		return CategoryAuth
+	}
+
+	// Many errors are flattened with %v along the way, so fall back to the message
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "invalid argument"),
+		strings.Contains(msg, "invalid parameter"),
+		strings.Contains(msg, " is required"),
+		strings.Contains(msg, " are required"),
+		strings.Contains(msg, "must be "),
+		strings.Contains(msg, "unknown tool"):
+		return CategoryInvalidArgument
+	case strings.Contains(msg, "no authenticated accounts"),
+		strings.Contains(msg, "multiple accounts available"),
+		strings.Contains(msg, "insufficient permission"),
+		strings.Contains(msg, "invalid_grant"),
+		strings.Contains(msg, "unauthorized"):
+		return CategoryAuth
+	case strings.Contains(msg, "ratelimitexceeded"),
+		strings.Contains(msg, "quota"):
+		return CategoryQuota
+	case strings.Contains(msg, "not found"),
+		strings.Contains(msg, "notfound"):
+		return CategoryNotFound
+	case strings.Contains(msg, "googleapi:"):
+		return CategoryUpstream
+	}
+
+	return CategoryInternal
+}
+
+// classifyAPIError maps a Google API error to a category using its HTTP status and reasons
+func classifyAPIError(apiErr *googleapi.Error) ErrorCategory {
+	for _, item := range apiErr.Errors {
+		if quotaReasons[item.Reason] {
+			return CategoryQuota
+		}
+	}
+
+	switch {
+	case apiErr.Code == 400, apiErr.Code == 409, apiErr.Code == 412, apiErr.Code == 422:
+		return CategoryInvalidArgument
+	case apiErr.Code == 401, apiErr.Code == 403:
+		return CategoryAuth
+	case apiErr.Code == 404, apiErr.Code == 410:
+		return CategoryNotFound
+	case apiErr.Code == 429:
+		return CategoryQuota
+	}
+	return CategoryUpstream
+}
+
+// secretPatterns match credentials that must never reach the client or logs
+var secretPatterns = []struct {
+	re          *regexp.Regexp
+	replacement string
+}{
+	// key=value pairs in URLs, form bodies and messages
+	{regexp.MustCompile(`(?i)\b(access_token|refresh_token|id_token|client_secret|code|key)=[^&\s"']+`), "${1}=[REDACTED]"},
+	// JSON fields
+	{regexp.MustCompile(`(?i)("(?:access_token|refresh_token|id_token|client_secret)"\s*:\s*)"[^"]*"`), `${1}"[REDACTED]"`},
+	// Authorization headers
+	{regexp.MustCompile(`(?i)\b(bearer)\s+[A-Za-z0-9\-_.~+/]+=*`), "${1} [REDACTED]"},
+	// Google OAuth access tokens, refresh tokens, client secrets and API keys
+	{regexp.MustCompile(`ya29\.[A-Za-z0-9\-_.]+`), "[REDACTED]"},
+	{regexp.MustCompile(`\b1//[A-Za-z0-9\-_]{10,}`), "[REDACTED]"},
+	{regexp.MustCompile(`GOCSPX-[A-Za-z0-9\-_]+`), "[REDACTED]"},
+	{regexp.MustCompile(`AIza[A-Za-z0-9\-_]{35}`), "[REDACTED]"},
+}
+
+// sanitizeErrorMessage redacts secrets and tokens from an error message
+func sanitizeErrorMessage(msg string) string {
+	for _, pattern := range secretPatterns {
+		msg = pattern.re.ReplaceAllString(msg, pattern.replacement)
+	}
+	return msg
+}
+
+// toolErrorDetail is the structured error body returned in a failed tool result
+type toolErrorDetail struct {
+	Category ErrorCategory `json:"category"`
+	Message  string        `json:"message"`
+	Tool     string        `json:"tool"`
+	Account  string        `json:"account,omitempty"`
+	Fields   []FieldError  `json:"fields,omitempty"` // arguments that failed validation
+}
+
+// newToolErrorDetail classifies err and builds a sanitized, actionable description.
+// Remediation text for scope, disabled-API and permission failures is added via
+// auth.HandleServiceError so the model can tell the user how to fix the problem.
+func newToolErrorDetail(err error, tool, service, account string) toolErrorDetail {
+	category := classifyError(err)
+
+	if category == CategoryAuth {
+		err = auth.HandleServiceError(err, service, account)
+	}
+
+	detail := toolErrorDetail{
+		Category: category,
+		Message:  sanitizeErrorMessage(err.Error()),
+		Tool:     tool,
+		Account:  account,
+	}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		detail.Fields = validationErr.Fields
+	}
+	return detail
+}
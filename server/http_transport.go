@@ -0,0 +1,657 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+const (
+	// httpEndpointPath is the single MCP endpoint served by the HTTP transport
+	httpEndpointPath = "/mcp"
+
+	// sessionHeader carries the session ID between client and server
+	sessionHeader = "Mcp-Session-Id"
+
+	// shutdownTimeout bounds how long Stop waits for in-flight HTTP requests
+	shutdownTimeout = 10 * time.Second
+
+	// sinkBufferSize is the number of outbound messages buffered per HTTP response
+	sinkBufferSize = 16
+)
+
+// startHTTP serves the Streamable HTTP transport until Stop is called.
+// Without a bearer token it only serves loopback clients: it refuses to
+// listen on other addresses or to answer for allowed hosts.
+func (s *MCPServer) startHTTP(addr string) error {
+	if s.config.Global.HTTPAuthToken == "" {
+		if !isLoopbackAddr(addr) {
+			return fmt.Errorf("http transport on %s needs http_auth_token, as it is reachable beyond loopback", addr)
+		}
+		if len(s.config.Global.AllowedHosts) > 0 {
+			return fmt.Errorf("http transport with allowed_hosts needs http_auth_token")
+		}
+	}
+
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           s.HTTPHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	s.mu.Lock()
+	s.httpServer = httpServer
+	s.mu.Unlock()
+
+	logger.Info("MCP server listening", "url", "http://"+addr+httpEndpointPath)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("http transport failed: %w", err)
+	}
+	return nil
+}
+
+// HTTPHandler returns the handler that serves the Streamable HTTP transport
+// at /mcp, for serving the server from another HTTP server
+func (s *MCPServer) HTTPHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(httpEndpointPath, func(w http.ResponseWriter, r *http.Request) {
+		// Reject requests for other hosts and from other origins to prevent
+		// DNS rebinding attacks
+		if !s.isAllowedRequest(r) {
+			http.Error(w, "host or origin not allowed", http.StatusForbidden)
+			return
+		}
+		if !s.isAuthorized(r) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "missing or invalid bearer token", http.StatusUnauthorized)
+			return
+		}
+		if !checkProtocolHeader(w, r) {
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPost:
+			s.handleHTTPPost(w, r)
+		case http.MethodGet:
+			s.handleHTTPGet(w, r)
+		case http.MethodDelete:
+			s.handleHTTPDelete(w, r)
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	return mux
+}
+
+// handleHTTPPost delivers client messages to the session and returns the
+// responses either as a JSON body or as an SSE stream
+func (s *MCPServer) handleHTTPPost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxMessageSize))
+	if err != nil {
+		http.Error(w, "failed to read request body", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	messages, batch, err := splitMessages(body)
+	if err != nil {
+		http.Error(w, "invalid JSON-RPC message", http.StatusBadRequest)
+		return
+	}
+
+	// Collect the IDs of requests that expect a response
+	var requestIDs []string
+	var requests []jsonrpc2.ID
+	initialize := false
+	for _, msg := range messages {
+		var envelope struct {
+			ID     *jsonrpc2.ID `json:"id"`
+			Method string       `json:"method"`
+		}
+		if err := json.Unmarshal(msg, &envelope); err != nil {
+			http.Error(w, "invalid JSON-RPC message", http.StatusBadRequest)
+			return
+		}
+		if envelope.Method != "" && envelope.ID != nil {
+			requestIDs = append(requestIDs, envelope.ID.String())
+			requests = append(requests, *envelope.ID)
+		}
+		if envelope.Method == "initialize" {
+			initialize = true
+		}
+	}
+
+	var session *Session
+	if id := r.Header.Get(sessionHeader); id != "" {
+		existing, ok := s.getSession(id)
+		if !ok || existing.stream == nil {
+			http.Error(w, "session not found", http.StatusNotFound)
+			return
+		}
+		session = existing
+	} else {
+		if !initialize {
+			http.Error(w, "missing "+sessionHeader+" header", http.StatusBadRequest)
+			return
+		}
+		session, err = s.newHTTPSession()
+		if err != nil {
+			http.Error(w, "failed to create session", http.StatusInternalServerError)
+			return
+		}
+	}
+	w.Header().Set(sessionHeader, session.id)
+
+	// Notifications and responses are accepted without a body
+	if len(requestIDs) == 0 {
+		for _, msg := range messages {
+			if !session.stream.push(r.Context(), msg) {
+				http.Error(w, "session closed", http.StatusNotFound)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	useSSE := acceptsEventStream(r)
+	sink := newHTTPSink(useSSE)
+	defer sink.release()
+	// A request ID already awaiting its response cannot be told apart from
+	// it, so the request is refused rather than taking over its response
+	if i := session.stream.register(requestIDs, sink); i >= 0 {
+		writeJSONRPCError(w, http.StatusBadRequest, requests[i], &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidRequest,
+			Message: fmt.Sprintf("request ID %s is already in use", requestIDs[i]),
+		})
+		return
+	}
+	defer session.stream.unregister(requestIDs)
+
+	// Requests still running when the client hangs up have nobody to answer
+	defer func() {
+		if r.Context().Err() != nil {
+			for _, id := range requestIDs {
+				session.cancelRequest(id, errClientGone)
+			}
+		}
+	}()
+
+	for _, msg := range messages {
+		if !session.stream.push(r.Context(), msg) {
+			http.Error(w, "session closed", http.StatusNotFound)
+			return
+		}
+	}
+
+	if useSSE {
+		s.writeSSEResponse(w, r, session, sink, len(requestIDs))
+		return
+	}
+
+	responses := make([]json.RawMessage, 0, len(requestIDs))
+	for len(responses) < len(requestIDs) {
+		select {
+		case msg := <-sink.messages:
+			if isResponse(msg) {
+				responses = append(responses, msg)
+			}
+		case <-r.Context().Done():
+			return
+		case <-session.stream.done:
+			http.Error(w, "session closed", http.StatusNotFound)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if batch {
+		_ = json.NewEncoder(w).Encode(responses)
+		return
+	}
+	_, _ = w.Write(responses[0])
+}
+
+// writeSSEResponse streams server messages related to a POST until every
+// request in it has been answered
+func (s *MCPServer) writeSSEResponse(w http.ResponseWriter, r *http.Request, session *Session, sink *httpSink, expected int) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	answered := 0
+	for answered < expected {
+		select {
+		case msg := <-sink.messages:
+			if err := writeSSEEvent(w, msg); err != nil {
+				return
+			}
+			flusher.Flush()
+			if isResponse(msg) {
+				answered++
+			}
+		case <-r.Context().Done():
+			return
+		case <-session.stream.done:
+			return
+		}
+	}
+}
+
+// handleHTTPGet opens a standalone SSE stream for server-initiated messages
+func (s *MCPServer) handleHTTPGet(w http.ResponseWriter, r *http.Request) {
+	if !acceptsEventStream(r) {
+		http.Error(w, "client must accept text/event-stream", http.StatusNotAcceptable)
+		return
+	}
+
+	session, ok := s.getSession(r.Header.Get(sessionHeader))
+	if !ok || session.stream == nil {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	sink := newHTTPSink(true)
+	defer sink.release()
+	if !session.stream.setListener(sink) {
+		http.Error(w, "stream already open for session", http.StatusConflict)
+		return
+	}
+	defer session.stream.clearListener(sink)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set(sessionHeader, session.id)
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case msg := <-sink.messages:
+			if err := writeSSEEvent(w, msg); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		case <-session.stream.done:
+			return
+		}
+	}
+}
+
+// handleHTTPDelete terminates a session at the client's request
+func (s *MCPServer) handleHTTPDelete(w http.ResponseWriter, r *http.Request) {
+	session, ok := s.getSession(r.Header.Get(sessionHeader))
+	if !ok || session.stream == nil {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+
+	s.removeSession(session.id)
+	if err := session.close(); err != nil {
+		logger.Warn("failed to close session", "session", session.id, "error", err)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// newHTTPSession creates a session backed by an in-memory message stream
+// and serves it with the shared JSON-RPC handler
+func (s *MCPServer) newHTTPSession() (*Session, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &Session{
+		id:     id,
+		stream: newHTTPStream(),
+	}
+	session.conn = jsonrpc2.NewConn(
+		context.Background(),
+		session.stream,
+		&Handler{server: s, session: session},
+	)
+	s.addSession(session)
+
+	go func() {
+		<-session.conn.DisconnectNotify()
+		s.removeSession(session.id)
+	}()
+
+	return session, nil
+}
+
+// httpStream implements jsonrpc2.ObjectStream on top of HTTP requests.
+// Inbound messages arrive from POST bodies; outbound responses are routed
+// back to the POST that carried the request, and everything else goes to
+// the standalone GET stream or any POST that is currently streaming.
+type httpStream struct {
+	incoming  chan json.RawMessage
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mu       sync.Mutex
+	pending  map[string]*httpSink
+	listener *httpSink
+}
+
+// newHTTPStream creates an empty stream
+func newHTTPStream() *httpStream {
+	return &httpStream{
+		incoming: make(chan json.RawMessage),
+		done:     make(chan struct{}),
+		pending:  make(map[string]*httpSink),
+	}
+}
+
+// ReadObject blocks until a client message arrives or the stream closes
+func (h *httpStream) ReadObject(v interface{}) error {
+	select {
+	case msg := <-h.incoming:
+		return json.Unmarshal(msg, v)
+	case <-h.done:
+		return io.EOF
+	}
+}
+
+// WriteObject routes an outbound message to the HTTP response waiting for it
+func (h *httpStream) WriteObject(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var envelope struct {
+		ID     *jsonrpc2.ID `json:"id"`
+		Method string       `json:"method"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	var sink *httpSink
+	if envelope.Method == "" && envelope.ID != nil {
+		sink = h.pending[envelope.ID.String()]
+	} else if h.listener != nil {
+		sink = h.listener
+	} else {
+		for _, pending := range h.pending {
+			if pending.stream {
+				sink = pending
+				break
+			}
+		}
+	}
+	h.mu.Unlock()
+
+	// Without a connected receiver the message is dropped, which matches
+	// the behaviour of an SSE stream the client never opened
+	if sink != nil {
+		sink.deliver(data, h.done)
+	}
+	return nil
+}
+
+// canSendRequests reports whether a request from the server would reach
+// the client now: it needs the standalone GET stream or a POST response
+// streamed as SSE, the receivers WriteObject routes requests to
+func (h *httpStream) canSendRequests() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.listener != nil {
+		return true
+	}
+	for _, pending := range h.pending {
+		if pending.stream {
+			return true
+		}
+	}
+	return false
+}
+
+// Close closes the stream and unblocks the reader
+func (h *httpStream) Close() error {
+	h.closeOnce.Do(func() {
+		close(h.done)
+	})
+	return nil
+}
+
+// push hands a client message to the JSON-RPC reader
+func (h *httpStream) push(ctx context.Context, msg json.RawMessage) bool {
+	select {
+	case h.incoming <- msg:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-h.done:
+		return false
+	}
+}
+
+// register routes responses for the given request IDs to sink. If one of
+// them is already pending, or repeated in ids, nothing is registered and
+// its index is returned; otherwise register returns -1.
+func (h *httpStream) register(ids []string, sink *httpSink) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, id := range ids {
+		if _, exists := h.pending[id]; exists || slices.Contains(ids[:i], id) {
+			return i
+		}
+	}
+	for _, id := range ids {
+		h.pending[id] = sink
+	}
+	return -1
+}
+
+// unregister stops routing responses for the given request IDs
+func (h *httpStream) unregister(ids []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, id := range ids {
+		delete(h.pending, id)
+	}
+}
+
+// setListener installs the standalone GET stream; only one may be open
+func (h *httpStream) setListener(sink *httpSink) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.listener != nil {
+		return false
+	}
+	h.listener = sink
+	return true
+}
+
+// clearListener removes the standalone GET stream
+func (h *httpStream) clearListener(sink *httpSink) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.listener == sink {
+		h.listener = nil
+	}
+}
+
+// httpSink receives outbound messages for one HTTP response
+type httpSink struct {
+	messages chan json.RawMessage
+	gone     chan struct{}
+	stream   bool
+}
+
+// newHTTPSink creates a sink; stream marks sinks that accept notifications
+func newHTTPSink(stream bool) *httpSink {
+	return &httpSink{
+		messages: make(chan json.RawMessage, sinkBufferSize),
+		gone:     make(chan struct{}),
+		stream:   stream,
+	}
+}
+
+// deliver sends a message unless the response or session has gone away
+func (k *httpSink) deliver(msg json.RawMessage, done <-chan struct{}) {
+	select {
+	case k.messages <- msg:
+	case <-k.gone:
+	case <-done:
+	}
+}
+
+// release marks the HTTP response as finished
+func (k *httpSink) release() {
+	close(k.gone)
+}
+
+// splitMessages parses a POST body holding a single message or a batch
+func splitMessages(body []byte) ([]json.RawMessage, bool, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil, false, fmt.Errorf("empty body")
+	}
+
+	if trimmed[0] == '[' {
+		var messages []json.RawMessage
+		if err := json.Unmarshal(trimmed, &messages); err != nil {
+			return nil, false, err
+		}
+		if len(messages) == 0 {
+			return nil, false, fmt.Errorf("empty batch")
+		}
+		return messages, true, nil
+	}
+
+	if !json.Valid(trimmed) {
+		return nil, false, fmt.Errorf("invalid JSON")
+	}
+	return []json.RawMessage{trimmed}, false, nil
+}
+
+// isResponse reports whether msg is a JSON-RPC response rather than a
+// request or notification
+func isResponse(msg json.RawMessage) bool {
+	var envelope struct {
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(msg, &envelope); err != nil {
+		return false
+	}
+	return envelope.Method == ""
+}
+
+// writeJSONRPCError answers a POST with a JSON-RPC error response for id
+func writeJSONRPCError(w http.ResponseWriter, status int, id jsonrpc2.ID, rpcErr *jsonrpc2.Error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(&jsonrpc2.Response{ID: id, Error: rpcErr})
+}
+
+// writeSSEEvent writes a single message event
+func writeSSEEvent(w io.Writer, msg json.RawMessage) error {
+	_, err := fmt.Fprintf(w, "event: message\ndata: %s\n\n", msg)
+	return err
+}
+
+// acceptsEventStream reports whether the client can read an SSE response
+func acceptsEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// isAllowedRequest accepts requests addressed to an allowed host, coming
+// from an allowed origin or from a non-browser client that sends no Origin.
+// A page that rebinds its own name to this server sends that name in both
+// Host and Origin, so neither may be trusted just because they agree.
+func (s *MCPServer) isAllowedRequest(r *http.Request) bool {
+	if !s.isAllowedHost(r.Host) {
+		return false
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return s.isAllowedHost(u.Host)
+}
+
+// isAllowedHost reports whether host, with or without a port, is a
+// loopback name or address or one of the configured allowed hosts
+func (s *MCPServer) isAllowedHost(host string) bool {
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+	host = strings.ToLower(strings.Trim(host, "[]"))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return true
+	}
+	for _, allowed := range s.config.Global.AllowedHosts {
+		if strings.EqualFold(host, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAuthorized reports whether r carries the configured bearer token.
+// Without a configured token every request is authorized.
+func (s *MCPServer) isAuthorized(r *http.Request) bool {
+	token := s.config.Global.HTTPAuthToken
+	if token == "" {
+		return true
+	}
+	scheme, credentials, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(credentials), []byte(token)) == 1
+}
+
+// isLoopbackAddr reports whether a listen address only accepts connections
+// from this machine. An empty host listens on every interface.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,446 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/jsonrpc2"
+	"go.ngs.io/google-mcp-server/config"
+	"go.ngs.io/google-mcp-server/internal/mcptest"
+)
+
+// stubService is a minimal ServiceHandler used by transport tests
+type stubService struct{}
+
+func (stubService) GetTools() []Tool {
+	return []Tool{
+		{Name: "stub_echo", Description: "Echo", InputSchema: InputSchema{Type: "object"}},
+		{Name: "stub_fail", Description: "Always fails", InputSchema: InputSchema{Type: "object"}},
+	}
+}
+
+func (stubService) GetResources() []Resource { return nil }
+
+func (stubService) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
+	if name == "stub_fail" {
+		return nil, fmt.Errorf("file not found (token ya29.a0AfH6SMBexample)")
+	}
+	return map[string]interface{}{"echo": string(arguments)}, nil
+}
+
+func (stubService) HandleResourceCall(ctx context.Context, uri string) (interface{}, error) {
+	return nil, nil
+}
+
+func newTestHTTPServer(t *testing.T) (*MCPServer, *httptest.Server) {
+	t.Helper()
+	srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP}})
+	srv.RegisterService("stub", stubService{})
+	return srv, newTestServerFor(t, srv)
+}
+
+// newTestServerFor serves srv over an httptest server that is shut down with the test
+func newTestServerFor(t *testing.T, srv *MCPServer) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(srv.HTTPHandler())
+	t.Cleanup(func() {
+		_ = srv.Stop()
+		ts.Close()
+	})
+	return ts
+}
+
+// initializeSession performs the initialize handshake at the latest protocol
+// version and returns the session ID
+func initializeSession(t *testing.T, endpoint string) string {
+	t.Helper()
+	return mcptest.Initialize(t, endpoint, LatestProtocolVersion, `{}`)
+}
+
+func TestHTTPTransportSessionLifecycle(t *testing.T) {
+	_, ts := newTestHTTPServer(t)
+	endpoint := ts.URL + httpEndpointPath
+
+	// Requests other than initialize need a session
+	resp := mcptest.Post(t, endpoint, "", "application/json", `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 without session, got %d", resp.StatusCode)
+	}
+
+	// Initialize creates a session
+	resp = mcptest.Post(t, endpoint, "", "application/json",
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`)
+	sessionID := resp.Header.Get(sessionHeader)
+	var initResult struct {
+		Result struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&initResult); err != nil {
+		t.Fatalf("failed to decode initialize response: %v", err)
+	}
+	_ = resp.Body.Close()
+	if sessionID == "" {
+		t.Fatal("expected session ID header on initialize response")
+	}
+	if initResult.Result.ProtocolVersion == "" {
+		t.Error("expected protocol version in initialize result")
+	}
+
+	// Notifications are accepted without a body
+	resp = mcptest.Post(t, endpoint, sessionID, "application/json", `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202 for notification, got %d", resp.StatusCode)
+	}
+
+	// Requests on the session are dispatched through the shared handler
+	resp = mcptest.Post(t, endpoint, sessionID, "application/json", `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	var listResult struct {
+		ID     int `json:"id"`
+		Result struct {
+			Tools []Tool `json:"tools"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&listResult); err != nil {
+		t.Fatalf("failed to decode tools/list response: %v", err)
+	}
+	_ = resp.Body.Close()
+	if listResult.ID != 2 || len(listResult.Result.Tools) != 2 || listResult.Result.Tools[0].Name != "stub_echo" {
+		t.Errorf("unexpected tools/list response: %+v", listResult)
+	}
+
+	// Unknown sessions are rejected
+	resp = mcptest.Post(t, endpoint, "does-not-exist", "application/json", `{"jsonrpc":"2.0","id":3,"method":"tools/list"}`)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown session, got %d", resp.StatusCode)
+	}
+
+	// DELETE terminates the session
+	req, _ := http.NewRequest(http.MethodDelete, endpoint, nil)
+	req.Header.Set(sessionHeader, sessionID)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for delete, got %d", resp.StatusCode)
+	}
+
+	resp = mcptest.Post(t, endpoint, sessionID, "application/json", `{"jsonrpc":"2.0","id":4,"method":"tools/list"}`)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 after delete, got %d", resp.StatusCode)
+	}
+}
+
+func TestHTTPTransportSSEResponse(t *testing.T) {
+	_, ts := newTestHTTPServer(t)
+	endpoint := ts.URL + httpEndpointPath
+
+	sessionID := initializeSession(t, endpoint)
+
+	resp := mcptest.Post(t, endpoint, sessionID, "application/json, text/event-stream",
+		`{"jsonrpc":"2.0","id":"call-1","method":"tools/call","params":{"name":"stub_echo","arguments":{"x":1}}}`)
+	defer func() { _ = resp.Body.Close() }()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", ct)
+	}
+
+	var data string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "data: ") {
+			data = strings.TrimPrefix(line, "data: ")
+			break
+		}
+	}
+
+	var msg struct {
+		ID     string `json:"id"`
+		Result struct {
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+			StructuredContent map[string]interface{} `json:"structuredContent"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(data), &msg); err != nil {
+		t.Fatalf("failed to decode SSE event %q: %v", data, err)
+	}
+	if msg.ID != "call-1" || len(msg.Result.Content) != 1 || !strings.Contains(msg.Result.Content[0].Text, "echo") {
+		t.Errorf("unexpected SSE response: %+v", msg)
+	}
+	if _, ok := msg.Result.StructuredContent["echo"]; !ok {
+		t.Errorf("expected the object result as structuredContent, got %+v", msg.Result.StructuredContent)
+	}
+}
+
+func TestHTTPTransportRejectsForeignOrigin(t *testing.T) {
+	_, ts := newTestHTTPServer(t)
+
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+httpEndpointPath, strings.NewReader(`{}`))
+	req.Header.Set("Origin", "https://evil.example.com")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected 403 for foreign origin, got %d", resp.StatusCode)
+	}
+}
+
+func TestHTTPTransportRejectsReboundHost(t *testing.T) {
+	srv, ts := newTestHTTPServer(t)
+	send := func(host, origin string) int {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+httpEndpointPath, strings.NewReader(`{}`))
+		req.Host = host
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// A page on evil.example rebound to the loopback address names itself
+	// in both headers
+	if code := send("evil.example", "http://evil.example"); code != http.StatusForbidden {
+		t.Errorf("expected 403 when Host and Origin are both evil.example, got %d", code)
+	}
+	if code := send("evil.example:8765", ""); code != http.StatusForbidden {
+		t.Errorf("expected 403 for a foreign Host without Origin, got %d", code)
+	}
+	if code := send("localhost:8765", "http://localhost:3000"); code == http.StatusForbidden {
+		t.Error("expected loopback hosts and origins to be allowed")
+	}
+
+	// Hosts can be allowed by configuration
+	srv.config.Global.AllowedHosts = []string{"mcp.internal"}
+	if code := send("mcp.internal:8765", "https://MCP.internal"); code == http.StatusForbidden {
+		t.Error("expected a configured host to be allowed")
+	}
+}
+
+func TestHTTPTransportRequiresBearerToken(t *testing.T) {
+	srv, ts := newTestHTTPServer(t)
+	srv.config.Global.HTTPAuthToken = "s3cret"
+	send := func(authorization string) int {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+httpEndpointPath,
+			strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	for _, authorization := range []string{"", "Bearer wrong", "Basic s3cret", "s3cret"} {
+		if code := send(authorization); code != http.StatusUnauthorized {
+			t.Errorf("expected 401 for Authorization %q, got %d", authorization, code)
+		}
+	}
+	if code := send("Bearer s3cret"); code != http.StatusOK {
+		t.Errorf("expected the token to be accepted, got %d", code)
+	}
+}
+
+func TestHTTPTransportNeedsTokenBeyondLoopback(t *testing.T) {
+	for _, tc := range []struct {
+		addr  string
+		hosts []string
+	}{
+		{addr: "0.0.0.0:0"},
+		{addr: ":0"},
+		{addr: "192.0.2.1:0"},
+		{addr: "127.0.0.1:0", hosts: []string{"mcp.internal"}},
+	} {
+		srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP, AllowedHosts: tc.hosts}})
+		if err := srv.startHTTP(tc.addr); err == nil || !strings.Contains(err.Error(), "http_auth_token") {
+			t.Errorf("%s with allowed hosts %v: expected the transport to need a token, got %v", tc.addr, tc.hosts, err)
+		}
+	}
+
+	for addr, want := range map[string]bool{
+		"127.0.0.1:8765": true,
+		"[::1]:8765":     true,
+		"localhost:8765": true,
+		"0.0.0.0:8765":   false,
+		":8765":          false,
+		"mcp.internal:0": false,
+	} {
+		if got := isLoopbackAddr(addr); got != want {
+			t.Errorf("isLoopbackAddr(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestHTTPTransportRejectsPendingRequestID(t *testing.T) {
+	service := blockingService{started: make(chan struct{}), causes: make(chan error, 1)}
+	srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP}})
+	srv.RegisterService("stub", service)
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+	sessionID := initializeSession(t, endpoint)
+
+	// Request 7 stays pending while the tool blocks
+	callCtx, stopCall := context.WithCancel(context.Background())
+	defer stopCall()
+	go func() {
+		req, _ := http.NewRequestWithContext(callCtx, http.MethodPost, endpoint,
+			strings.NewReader(`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"stub_block","arguments":{}}}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set(sessionHeader, sessionID)
+		if resp, err := http.DefaultClient.Do(req); err == nil {
+			_ = resp.Body.Close()
+		}
+	}()
+	select {
+	case <-service.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tool was not called")
+	}
+
+	for _, body := range []string{
+		`{"jsonrpc":"2.0","id":7,"method":"tools/list"}`,
+		`[{"jsonrpc":"2.0","id":8,"method":"tools/list"},{"jsonrpc":"2.0","id":8,"method":"tools/list"}]`,
+	} {
+		resp := mcptest.Post(t, endpoint, sessionID, "application/json", body)
+		var msg struct {
+			ID    json.RawMessage `json:"id"`
+			Error *struct {
+				Code int `json:"code"`
+			} `json:"error"`
+		}
+		err := json.NewDecoder(resp.Body).Decode(&msg)
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected 400 for a request ID in use, got %d", body, resp.StatusCode)
+		}
+		if err != nil || msg.Error == nil || msg.Error.Code != jsonrpc2.CodeInvalidRequest {
+			t.Errorf("%s: expected an invalid request error, got %+v (%v)", body, msg, err)
+		}
+	}
+
+	// Once the pending request ends, its ID can be used again
+	stopCall()
+	select {
+	case <-service.causes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tool context was not cancelled")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp := mcptest.Post(t, endpoint, sessionID, "application/json", `{"jsonrpc":"2.0","id":7,"method":"tools/list"}`)
+		_ = resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected request ID 7 to be free once its request ended, got %d", resp.StatusCode)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// templateService serves a single parameterized resource
+type templateService struct {
+	stubService
+}
+
+func (templateService) GetResourceTemplates() []ResourceTemplate {
+	return []ResourceTemplate{
+		{URITemplate: "stub://item/{id}{?account}", Name: "Stub Item", MimeType: "application/json"},
+		{URITemplate: "stub://{broken", Name: "Broken"},
+	}
+}
+
+func (templateService) HandleResourceTemplateCall(ctx context.Context, uri string, uriTemplate string, variables map[string]string) (interface{}, error) {
+	return map[string]interface{}{"template": uriTemplate, "id": variables["id"], "account": variables["account"]}, nil
+}
+
+func TestResourceTemplates(t *testing.T) {
+	srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP}})
+	srv.RegisterService("stub", templateService{})
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+
+	sessionID := initializeSession(t, endpoint)
+
+	resp := mcptest.Post(t, endpoint, sessionID, "application/json", `{"jsonrpc":"2.0","id":2,"method":"resources/templates/list"}`)
+	var listResult struct {
+		Result struct {
+			ResourceTemplates []ResourceTemplate `json:"resourceTemplates"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&listResult); err != nil {
+		t.Fatalf("failed to decode templates/list response: %v", err)
+	}
+	_ = resp.Body.Close()
+	if len(listResult.Result.ResourceTemplates) != 1 || listResult.Result.ResourceTemplates[0].URITemplate != "stub://item/{id}{?account}" {
+		t.Fatalf("expected only the valid template to be listed, got %+v", listResult.Result.ResourceTemplates)
+	}
+
+	resp = mcptest.Post(t, endpoint, sessionID, "application/json",
+		`{"jsonrpc":"2.0","id":3,"method":"resources/read","params":{"uri":"stub://item/42?account=me%40example.com"}}`)
+	var readResult struct {
+		Result struct {
+			Contents []struct {
+				URI  string `json:"uri"`
+				Text string `json:"text"`
+			} `json:"contents"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&readResult); err != nil {
+		t.Fatalf("failed to decode resources/read response: %v", err)
+	}
+	_ = resp.Body.Close()
+	if len(readResult.Result.Contents) != 1 {
+		t.Fatalf("expected one content entry, got %+v", readResult.Result)
+	}
+	text := readResult.Result.Contents[0].Text
+	for _, want := range []string{"stub://item/{id}{?account}", "42", "me@example.com"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in resource content %q", want, text)
+		}
+	}
+
+	resp = mcptest.Post(t, endpoint, sessionID, "application/json",
+		`{"jsonrpc":"2.0","id":4,"method":"resources/read","params":{"uri":"stub://other/42"}}`)
+	var errResult struct {
+		Error *struct {
+			Code int `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&errResult); err != nil {
+		t.Fatalf("failed to decode resources/read response: %v", err)
+	}
+	_ = resp.Body.Close()
+	if errResult.Error == nil {
+		t.Error("expected an error for a URI matching no resource or template")
+	}
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/sourcegraph/jsonrpc2"
+	"go.ngs.io/google-mcp-server/logging"
+)
+
+var logger = logging.Logger("server")
+
+// levelOff is above every log level, so nothing is forwarded
+const levelOff = logging.LevelEmergency + 1
+
+// logMessageParams are the params of a notifications/message notification
+type logMessageParams struct {
+	Level  string                 `json:"level"`
+	Logger string                 `json:"logger,omitempty"`
+	Data   map[string]interface{} `json:"data"`
+}
+
+// forwardLog is the logging sink that sends records to clients. Records
+// logged while serving a request go to that request's session only. Others
+// may describe any client's calls, so they go only to stdio sessions, which
+// have the process to themselves, and only once the client has asked for
+// log messages with logging/setLevel. HTTP sessions never receive them.
+func (s *MCPServer) forwardLog(ctx context.Context, entry logging.Entry) {
+	var sessions []*Session
+	if session, ok := SessionFromContext(ctx); ok {
+		sessions = []*Session{session}
+	} else {
+		for _, session := range s.allSessions() {
+			if session.stream == nil && session.logRequested.Load() {
+				sessions = append(sessions, session)
+			}
+		}
+	}
+	if len(sessions) == 0 {
+		return
+	}
+
+	params := logMessageParams{
+		Level:  logging.LevelName(entry.Level),
+		Logger: entry.Logger,
+		Data:   make(map[string]interface{}, len(entry.Attrs)+1),
+	}
+	for key, value := range entry.Attrs {
+		if str, ok := value.(string); ok {
+			value = sanitizeErrorMessage(str)
+		}
+		params.Data[key] = value
+	}
+	params.Data["message"] = sanitizeErrorMessage(entry.Message)
+
+	for _, session := range sessions {
+		// Clients may not be sent notifications before initialize
+		if session.conn == nil || session.ProtocolVersion() == "" || entry.Level < session.logLevel.Level() {
+			continue
+		}
+		// Errors are dropped: logging them would come straight back here
+		_ = session.conn.Notify(context.Background(), "notifications/message", params)
+	}
+}
+
+// updateSinkLevel lowers the sink level to what the most verbose session
+// wants, so records nobody asked for are not built. Callers must hold
+// s.sessionsMu.
+func (s *MCPServer) updateSinkLevel() {
+	level := levelOff
+	for _, session := range s.sessions {
+		if l := session.logLevel.Level(); l < level {
+			level = l
+		}
+	}
+	logging.SetSinkLevel(level)
+}
+
+// handleSetLevel sets the minimum level of log messages sent to the session
+func (h *Handler) handleSetLevel(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	var params struct {
+		Level string `json:"level"`
+	}
+
+	if req.Params == nil {
+		if err := conn.ReplyWithError(ctx, req.ID, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidParams,
+			Message: "missing parameters",
+		}); err != nil {
+			logger.Error("failed to send reply", "error", err)
+		}
+		return
+	}
+
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		if err := conn.ReplyWithError(ctx, req.ID, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidParams,
+			Message: "invalid parameters",
+		}); err != nil {
+			logger.Error("failed to send reply", "error", err)
+		}
+		return
+	}
+
+	level, err := logging.ParseLevel(params.Level)
+	if err != nil {
+		if err := conn.ReplyWithError(ctx, req.ID, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidParams,
+			Message: err.Error(),
+		}); err != nil {
+			logger.Error("failed to send reply", "error", err)
+		}
+		return
+	}
+
+	h.session.logLevel.Set(level)
+	h.session.logRequested.Store(true)
+	h.server.sessionsMu.Lock()
+	h.server.updateSinkLevel()
+	h.server.sessionsMu.Unlock()
+
+	logger.DebugContext(ctx, "client log level changed", "level", logging.LevelName(level))
+
+	if err := conn.Reply(ctx, req.ID, struct{}{}); err != nil {
+		logger.Error("failed to send reply", "error", err)
+	}
+}
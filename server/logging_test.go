@@ -0,0 +1,192 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/jsonrpc2"
+	"go.ngs.io/google-mcp-server/config"
+	"go.ngs.io/google-mcp-server/internal/mcptest"
+	"go.ngs.io/google-mcp-server/logging"
+)
+
+// loggingService has a tool that logs a warning while it runs
+type loggingService struct {
+	stubService
+}
+
+func (loggingService) GetTools() []Tool {
+	return []Tool{{Name: "stub_log", Description: "Logs", InputSchema: InputSchema{Type: "object"}}}
+}
+
+func (loggingService) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
+	logging.Logger("stub").WarnContext(ctx, "quota nearly exhausted (token ya29.a0secret)")
+	return "ok", nil
+}
+
+// callAndCollect calls a tool over SSE and returns the notifications/message
+// params received before the response
+func callAndCollect(t *testing.T, endpoint, sessionID string) []logMessageParams {
+	t.Helper()
+	resp := mcptest.Post(t, endpoint, sessionID, "application/json, text/event-stream",
+		`{"jsonrpc":"2.0","id":10,"method":"tools/call","params":{"name":"stub_log","arguments":{"account":"me@example.com"}}}`)
+	defer func() { _ = resp.Body.Close() }()
+
+	var messages []logMessageParams
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		var msg struct {
+			ID     *json.RawMessage `json:"id"`
+			Method string           `json:"method"`
+			Params logMessageParams `json:"params"`
+		}
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &msg); err != nil {
+			t.Fatalf("failed to decode SSE event: %v", err)
+		}
+		if msg.Method == "notifications/message" {
+			messages = append(messages, msg.Params)
+		}
+		if msg.ID != nil {
+			break
+		}
+	}
+	return messages
+}
+
+func setLevel(t *testing.T, endpoint, sessionID, level string) int {
+	t.Helper()
+	resp := mcptest.Post(t, endpoint, sessionID, "application/json",
+		`{"jsonrpc":"2.0","id":5,"method":"logging/setLevel","params":{"level":"`+level+`"}}`)
+	defer func() { _ = resp.Body.Close() }()
+	var result struct {
+		Error *struct {
+			Code int `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode setLevel response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status %d", resp.StatusCode)
+	}
+	if result.Error != nil {
+		return result.Error.Code
+	}
+	return 0
+}
+
+func TestLogMessagesForwardedToSession(t *testing.T) {
+	srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP, LogLevel: "error"}})
+	srv.RegisterService("stub", loggingService{})
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+	sessionID := initializeSession(t, endpoint)
+
+	// The configured level applies until the client chooses its own
+	if messages := callAndCollect(t, endpoint, sessionID); len(messages) != 0 {
+		t.Fatalf("expected no messages below the configured level, got %+v", messages)
+	}
+
+	if code := setLevel(t, endpoint, sessionID, "warning"); code != 0 {
+		t.Fatalf("setLevel failed with code %d", code)
+	}
+
+	messages := callAndCollect(t, endpoint, sessionID)
+	if len(messages) != 1 {
+		t.Fatalf("expected one log message, got %+v", messages)
+	}
+	msg := messages[0]
+	if msg.Level != "warning" || msg.Logger != "stub" {
+		t.Errorf("unexpected level or logger: %+v", msg)
+	}
+	if msg.Data["tool"] != "stub_log" || msg.Data["service"] != "stub" || msg.Data["account"] != "me@example.com" {
+		t.Errorf("expected tool context in the message data, got %+v", msg.Data)
+	}
+	text, _ := msg.Data["message"].(string)
+	if !strings.Contains(text, "quota nearly exhausted") || strings.Contains(text, "a0secret") {
+		t.Errorf("expected a sanitized message, got %q", text)
+	}
+
+	if code := setLevel(t, endpoint, sessionID, "loud"); code == 0 {
+		t.Error("expected an error for an unknown level")
+	}
+}
+
+// pipeSession returns a session whose messages can be read from the
+// returned scanner, one per line
+func pipeSession(t *testing.T, srv *MCPServer) (*Session, *bufio.Scanner) {
+	t.Helper()
+	serverSide, clientSide := net.Pipe()
+	t.Cleanup(func() { _ = clientSide.Close() })
+	id, _ := newSessionID()
+	session := &Session{id: id}
+	session.conn = jsonrpc2.NewConn(context.Background(), NewNewlineDelimitedStream(serverSide, serverSide), &Handler{server: srv, session: session})
+	t.Cleanup(func() { _ = session.close() })
+	return session, bufio.NewScanner(clientSide)
+}
+
+func TestLogMessagesWithoutSession(t *testing.T) {
+	srv := NewMCPServer(&config.Config{})
+	stdio, stdioMessages := pipeSession(t, srv)
+	uninitialized, _ := pipeSession(t, srv)
+	httpSession, _ := pipeSession(t, srv)
+	httpSession.stream = &httpStream{}
+	for _, session := range []*Session{stdio, uninitialized, httpSession} {
+		session.logRequested.Store(true)
+		if session != uninitialized {
+			session.initialize(LatestProtocolVersion, ClientInfo{}, ClientCapabilities{})
+		}
+	}
+
+	received := make(chan string, 1)
+	go func() {
+		if stdioMessages.Scan() {
+			received <- stdioMessages.Text()
+		}
+	}()
+	for _, session := range []*Session{stdio, uninitialized, httpSession} {
+		srv.sessionsMu.Lock()
+		srv.sessions[session.id] = session
+		srv.sessionsMu.Unlock()
+	}
+
+	// Writes to a pipe nobody reads block, so a record sent to the wrong
+	// session shows up as a forwardLog that does not return
+	forward := func(entry logging.Entry) {
+		t.Helper()
+		done := make(chan struct{})
+		go func() {
+			srv.forwardLog(context.Background(), entry)
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("expected the record to be sent only to the stdio session")
+		}
+	}
+
+	forward(logging.Entry{Level: logging.LevelWarning, Logger: "stub", Message: "token refreshed for me@example.com"})
+	select {
+	case msg := <-received:
+		if !strings.Contains(msg, "notifications/message") {
+			t.Errorf("expected a log message, got %s", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the stdio session to receive the record")
+	}
+
+	// A stdio client that has not asked for log messages receives none
+	stdio.logRequested.Store(false)
+	forward(logging.Entry{Level: logging.LevelError, Message: "background failure"})
+}
@@ -4,24 +4,80 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"net/http"
 	"os"
+	"reflect"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/sourcegraph/jsonrpc2"
+	"go.ngs.io/google-mcp-server/auth"
 	"go.ngs.io/google-mcp-server/config"
+	"go.ngs.io/google-mcp-server/logging"
 )
 
 // MCPServer represents the MCP server
 type MCPServer struct {
-	config    *config.Config
-	services  map[string]ServiceHandler
-	toolMap   map[string]ServiceHandler // O(1) tool name → service lookup
-	conn      *jsonrpc2.Conn
-	mu        sync.RWMutex
-	tools     []Tool
-	resources []Resource
+	config     *config.Config
+	services   map[string]ServiceHandler
+	toolMap    map[string]toolEntry // O(1) tool name → service lookup
+	httpServer *http.Server
+	mu         sync.RWMutex
+	tools      []Tool
+	resources  []Resource
+	prompts    []Prompt
+	promptMap  map[string]PromptProvider // prompt name → provider
+
+	resourceServices map[string]string // resource URI → service
+
+	// pageSize is the number of items per page of tools/list and resources/list
+	pageSize int
+
+	// serviceOrder lists service names in registration order, which is the
+	// order of their tools, resources and templates
+	serviceOrder []string
+
+	// configPrompts are the prompts defined in the config file; they take
+	// precedence over service prompts
+	configPrompts PromptProvider
+
+	resourceTemplates []ResourceTemplate
+	templateRoutes    []templateRoute // matched in registration order
+
+	completers  map[string]CompleterFunc // argument name → fallback completer
+	completions *completionCache
+
+	subscriptions *subscriptionManager
+
+	// workers bounds the requests handled concurrently, see goCancellable
+	workers *workerPool
+
+	// audit records the calls of tools that change data, see EnableAudit
+	audit *auditLog
+
+	// metricsServer serves the Prometheus metrics, see EnableMetrics
+	metricsServer *http.Server
+
+	// listAccounts lists the accounts the metrics may name, see
+	// SetAccountLister
+	listAccounts func() []string
+
+	// policy decides which tools of the registered services are offered;
+	// withheldTools are the ones it refuses, so that calls to them can be
+	// told apart from calls to tools that do not exist
+	policy        toolPolicy
+	withheldTools map[string]withheldTool
+
+	// Sessions have their own lock because log forwarding reads them while
+	// s.mu may already be held by the code that logs
+	sessions   map[string]*Session
+	sessionsMu sync.RWMutex
+	logLevel   slog.Level // default level of log messages sent to clients
 }
 
 // ServiceHandler represents a service that provides tools and resources
@@ -34,9 +90,11 @@ type ServiceHandler interface {
 
 // Tool represents an MCP tool
 type Tool struct {
-	Name        string      `json:"name"`
-	Description string      `json:"description"`
-	InputSchema InputSchema `json:"inputSchema"`
+	Name         string           `json:"name"`
+	Description  string           `json:"description"`
+	InputSchema  InputSchema      `json:"inputSchema"`
+	OutputSchema *OutputSchema    `json:"outputSchema,omitempty"`
+	Annotations  *ToolAnnotations `json:"annotations,omitempty"`
 }
 
 // InputSchema represents the JSON schema for tool input
@@ -46,12 +104,15 @@ type InputSchema struct {
 	Required   []string            `json:"required,omitempty"`
 }
 
-// Property represents a property in the input schema
-type Property struct {
-	Type        string    `json:"type"`
-	Description string    `json:"description"`
-	Items       *Property `json:"items,omitempty"`
-	Enum        []string  `json:"enum,omitempty"`
+// OutputSchema is the JSON schema of the structured result of a tool.
+// Tools that declare one must return a JSON object matching it.
+type OutputSchema = InputSchema
+
+// toolEntry records which registered service provides a tool
+type toolEntry struct {
+	service string
+	handler ServiceHandler
+	tool    Tool
 }
 
 // Resource represents an MCP resource
@@ -62,70 +123,318 @@ type Resource struct {
 	MimeType    string `json:"mimeType,omitempty"`
 }
 
+// ResourceTemplate describes a parameterized resource using an RFC 6570 URI template
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceTemplateHandler is implemented by services that serve parameterized
+// resources. It is optional; RegisterService routes any resources/read whose
+// URI matches one of the declared templates to HandleResourceTemplateCall with
+// the template that matched and the variables extracted from the URI.
+type ResourceTemplateHandler interface {
+	GetResourceTemplates() []ResourceTemplate
+	HandleResourceTemplateCall(ctx context.Context, uri string, uriTemplate string, variables map[string]string) (interface{}, error)
+}
+
+// templateRoute routes URIs matching a compiled template to its handler
+type templateRoute struct {
+	template *URITemplate
+	handler  ResourceTemplateHandler
+}
+
 // maxMessageSize is the maximum allowed size for a single JSON-RPC message (10MB)
 const maxMessageSize = 10 * 1024 * 1024
 
 // NewMCPServer creates a new MCP server
 func NewMCPServer(cfg *config.Config) *MCPServer {
-	return &MCPServer{
+	s := &MCPServer{
 		config:    cfg,
 		services:  make(map[string]ServiceHandler),
-		toolMap:   make(map[string]ServiceHandler),
+		toolMap:   make(map[string]toolEntry),
+		sessions:  make(map[string]*Session),
 		tools:     []Tool{},
 		resources: []Resource{},
+		prompts:   []Prompt{},
+		promptMap: make(map[string]PromptProvider),
+
+		resourceServices: make(map[string]string),
+		withheldTools:    make(map[string]withheldTool),
+		policy:           toolPolicy{global: cfg.Global.ToolPolicy, services: cfg.Services},
+		pageSize:         defaultPageSize,
+
+		completers:  make(map[string]CompleterFunc),
+		completions: newCompletionCache(completionCacheTTL),
+	}
+
+	pollInterval := cfg.Global.ResourcePollInterval
+	if pollInterval <= 0 {
+		pollInterval = config.DefaultResourcePollInterval
 	}
+	s.subscriptions = newSubscriptionManager(time.Duration(pollInterval) * time.Second)
+
+	concurrency := cfg.Global.MaxConcurrency
+	if concurrency <= 0 {
+		concurrency = config.DefaultMaxConcurrency
+	}
+	s.workers = newWorkerPool(concurrency)
+
+	// Clients receive log messages at the configured level until they ask
+	// for another one with logging/setLevel
+	s.logLevel = logging.LevelInfo
+	if level, err := logging.ParseLevel(cfg.Global.LogLevel); err == nil {
+		s.logLevel = level
+	}
+	logging.SetSink(s.forwardLog, levelOff)
+
+	// User-defined prompts from the config file
+	if len(cfg.Prompts) > 0 {
+		provider, err := newConfigPromptProvider(cfg.Prompts)
+		if err != nil {
+			logger.Warn("ignoring configured prompts", "error", err)
+		} else {
+			s.configPrompts = provider
+			s.addPrompts(provider)
+		}
+	}
+
+	return s
 }
 
-// RegisterService registers a service handler
+// RegisterService registers a service handler. Registering a name again
+// replaces the earlier handler. Services may be registered while clients are
+// connected; they are told to fetch the tool list again.
 func (s *MCPServer) RegisterService(name string, handler ServiceHandler) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	if _, exists := s.services[name]; exists {
-		fmt.Fprintf(os.Stderr, "Warning: service %q already registered, overwriting\n", name)
+		logger.Warn("service already registered, overwriting", "service", name)
+	} else {
+		s.serviceOrder = append(s.serviceOrder, name)
 	}
-
 	s.services[name] = handler
+	changed := s.rebuildLocked()
+	s.mu.Unlock()
 
-	// Add tools from the service and build tool-to-service map for O(1) lookup
-	tools := handler.GetTools()
-	s.tools = append(s.tools, tools...)
-	for _, tool := range tools {
-		s.toolMap[tool.Name] = handler
+	if changed {
+		s.notifyToolsChanged()
 	}
+}
 
-	// Add resources from the service
-	resources := handler.GetResources()
-	s.resources = append(s.resources, resources...)
+// UnregisterService removes a service with its tools, resources, prompts and
+// resource templates, and reports whether it was registered
+func (s *MCPServer) UnregisterService(name string) bool {
+	s.mu.Lock()
+	if _, exists := s.services[name]; !exists {
+		s.mu.Unlock()
+		return false
+	}
+	delete(s.services, name)
+	for i, registered := range s.serviceOrder {
+		if registered == name {
+			s.serviceOrder = append(s.serviceOrder[:i:i], s.serviceOrder[i+1:]...)
+			break
+		}
+	}
+	changed := s.rebuildLocked()
+	s.mu.Unlock()
+
+	if changed {
+		s.notifyToolsChanged()
+	}
+	return true
 }
 
-// Start starts the MCP server
+// rebuildLocked recomputes the tools, resources, prompts and resource
+// templates from the registered services, in registration order, and reports
+// whether the tool list changed. The lists are replaced rather than modified,
+// so handlers may keep using a list read before the rebuild. Callers hold s.mu.
+func (s *MCPServer) rebuildLocked() bool {
+	previous := s.tools
+
+	s.tools = []Tool{}
+	s.toolMap = make(map[string]toolEntry)
+	s.withheldTools = make(map[string]withheldTool)
+	s.resources = []Resource{}
+	s.resourceServices = make(map[string]string)
+	s.prompts = []Prompt{}
+	s.promptMap = make(map[string]PromptProvider)
+	s.resourceTemplates = nil
+	s.templateRoutes = nil
+
+	if s.configPrompts != nil {
+		s.addPrompts(s.configPrompts)
+	}
+
+	for _, name := range s.serviceOrder {
+		handler := s.services[name]
+
+		// Add the tools the policy allows and build tool-to-service map for O(1) lookup
+		for _, tool := range handler.GetTools() {
+			if reason := s.policy.check(name, tool); reason != "" {
+				s.withheldTools[tool.Name] = withheldTool{service: name, reason: reason}
+				continue
+			}
+			tool = withDryRunArgument(tool)
+			s.tools = append(s.tools, tool)
+			s.toolMap[tool.Name] = toolEntry{service: name, handler: handler, tool: tool}
+		}
+
+		// Add resources from the service
+		resources := handler.GetResources()
+		s.resources = append(s.resources, resources...)
+		for _, resource := range resources {
+			s.resourceServices[resource.URI] = name
+		}
+
+		// Add prompts if the service contributes any
+		if provider, ok := handler.(PromptProvider); ok {
+			s.addPrompts(provider)
+		}
+
+		// Add resource templates if the service serves parameterized resources
+		if templateHandler, ok := handler.(ResourceTemplateHandler); ok {
+			for _, rt := range templateHandler.GetResourceTemplates() {
+				compiled, err := ParseURITemplate(rt.URITemplate)
+				if err != nil {
+					logger.Warn("skipping invalid resource template", "service", name, "error", err)
+					continue
+				}
+				s.resourceTemplates = append(s.resourceTemplates, rt)
+				s.templateRoutes = append(s.templateRoutes, templateRoute{template: compiled, handler: templateHandler})
+			}
+		}
+	}
+
+	return !reflect.DeepEqual(previous, s.tools)
+}
+
+// notifyToolsChanged tells every initialized session that the tool list
+// changed, so clients call tools/list again
+func (s *MCPServer) notifyToolsChanged() {
+	for _, session := range s.allSessions() {
+		if session.ProtocolVersion() == "" {
+			continue
+		}
+		if err := session.conn.Notify(context.Background(), "notifications/tools/list_changed", nil); err != nil {
+			logger.Debug("failed to send tool list change", "session", session.ID(), "error", err)
+		}
+	}
+}
+
+// RegisterCompleter registers a completer for an argument that appears across
+// services, such as "account". It is used when the service owning the
+// reference does not complete the argument itself.
+func (s *MCPServer) RegisterCompleter(argument string, completer CompleterFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.completers[argument] = completer
+}
+
+// addPrompts registers the prompts of a provider. Prompts defined in the
+// config file take precedence over service prompts with the same name.
+// Callers must hold s.mu or be constructing the server.
+func (s *MCPServer) addPrompts(provider PromptProvider) {
+	for _, prompt := range provider.GetPrompts() {
+		if existing, exists := s.promptMap[prompt.Name]; exists {
+			if _, userDefined := existing.(*configPromptProvider); userDefined {
+				continue
+			}
+			logger.Warn("prompt already registered, overwriting", "prompt", prompt.Name)
+			for i := range s.prompts {
+				if s.prompts[i].Name == prompt.Name {
+					s.prompts[i] = prompt
+				}
+			}
+		} else {
+			s.prompts = append(s.prompts, prompt)
+		}
+		s.promptMap[prompt.Name] = provider
+	}
+}
+
+// Start starts the MCP server on the configured transport and blocks
+// until the client disconnects or Stop is called
 func (s *MCPServer) Start() error {
-	// Create JSON-RPC connection using stdio
-	handler := &Handler{server: s}
+	switch s.config.Global.Transport {
+	case config.TransportHTTP:
+		addr := s.config.Global.HTTPAddr
+		if addr == "" {
+			addr = config.DefaultHTTPAddr
+		}
+		return s.startHTTP(addr)
+	default:
+		return s.startStdio()
+	}
+}
+
+// startStdio serves a single session over stdin/stdout
+func (s *MCPServer) startStdio() error {
+	id, err := newSessionID()
+	if err != nil {
+		return err
+	}
+	session := &Session{id: id}
 
 	// Create a newline-delimited JSON stream for MCP
 	stream := NewNewlineDelimitedStream(os.Stdin, os.Stdout)
 
-	conn := jsonrpc2.NewConn(
+	session.conn = jsonrpc2.NewConn(
 		context.Background(),
 		stream,
-		handler,
+		&Handler{server: s, session: session},
 	)
-
-	s.conn = conn
+	s.addSession(session)
+	defer s.removeSession(session.id)
 
 	// Wait for connection to close
-	<-conn.DisconnectNotify()
+	<-session.conn.DisconnectNotify()
 	return nil
 }
 
-// Stop gracefully shuts down the MCP server
+// Stop gracefully shuts down the MCP server: open sessions are closed,
+// then the HTTP listener (if any) drains in-flight requests
 func (s *MCPServer) Stop() error {
-	if s.conn != nil {
-		return s.conn.Close()
+	sessions := s.allSessions()
+	s.subscriptions.close()
+
+	s.mu.Lock()
+	httpServer := s.httpServer
+	metricsServer := s.metricsServer
+	audit := s.audit
+	s.mu.Unlock()
+
+	var firstErr error
+	for _, session := range sessions {
+		if err := session.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
 	}
-	return nil
+
+	if httpServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := httpServer.Shutdown(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	// Scrapes are short; the metrics endpoint is closed without waiting
+	if metricsServer != nil {
+		if err := metricsServer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if audit != nil {
+		if err := audit.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
 }
 
 // NewlineDelimitedStream implements jsonrpc2.ObjectStream for newline-delimited JSON
@@ -183,48 +492,63 @@ func (s *NewlineDelimitedStream) Close() error {
 	return nil
 }
 
-// Handler handles JSON-RPC requests
+// Handler handles JSON-RPC requests for one session
 type Handler struct {
-	server *MCPServer
+	server  *MCPServer
+	session *Session
 }
 
 func (h *Handler) Handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	ctx = withSession(ctx, h.session)
+
 	switch req.Method {
 	case "initialize":
 		h.handleInitialize(ctx, conn, req)
-	case "initialized":
+	case "initialized", "notifications/initialized":
 		// Client confirms initialization
 	case "tools/list":
 		h.handleToolsList(ctx, conn, req)
 	case "tools/call":
-		h.handleToolCall(ctx, conn, req)
+		h.goCancellable(ctx, conn, req, h.handleToolCall)
 	case "resources/list":
 		h.handleResourcesList(ctx, conn, req)
 	case "resources/read":
-		h.handleResourceRead(ctx, conn, req)
+		h.goCancellable(ctx, conn, req, h.handleResourceRead)
+	case "resources/subscribe":
+		h.goCancellable(ctx, conn, req, h.handleResourceSubscribe)
+	case "resources/unsubscribe":
+		h.handleResourceUnsubscribe(ctx, conn, req)
+	case "resources/templates/list":
+		h.handleResourceTemplatesList(ctx, conn, req)
+	case "prompts/list":
+		h.handlePromptsList(ctx, conn, req)
+	case "prompts/get":
+		h.goCancellable(ctx, conn, req, h.handlePromptGet)
+	case "logging/setLevel":
+		h.handleSetLevel(ctx, conn, req)
 	case "completion/complete":
-		h.handleCompletion(ctx, conn, req)
+		h.goCancellable(ctx, conn, req, h.handleCompletion)
+	case "notifications/cancelled":
+		h.handleCancelled(ctx, req)
 	default:
+		// Unknown notifications are ignored; they must never be answered
+		if req.Notif {
+			return
+		}
 		if err := conn.ReplyWithError(ctx, req.ID, &jsonrpc2.Error{
 			Code:    jsonrpc2.CodeMethodNotFound,
 			Message: fmt.Sprintf("method not found: %s", req.Method),
 		}); err != nil {
-			fmt.Fprintf(os.Stderr, "Error sending reply: %v\n", err)
+			logger.Error("failed to send reply", "error", err)
 		}
 	}
 }
 
 func (h *Handler) handleInitialize(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
 	var params struct {
-		ProtocolVersion string `json:"protocolVersion"`
-		Capabilities    struct {
-			Roots    interface{} `json:"roots,omitempty"`
-			Sampling interface{} `json:"sampling,omitempty"`
-		} `json:"capabilities"`
-		ClientInfo struct {
-			Name    string `json:"name"`
-			Version string `json:"version"`
-		} `json:"clientInfo"`
+		ProtocolVersion string             `json:"protocolVersion"`
+		Capabilities    ClientCapabilities `json:"capabilities"`
+		ClientInfo      ClientInfo         `json:"clientInfo"`
 	}
 
 	if req.Params == nil {
@@ -232,7 +556,7 @@ func (h *Handler) handleInitialize(ctx context.Context, conn *jsonrpc2.Conn, req
 			Code:    jsonrpc2.CodeInvalidParams,
 			Message: "missing parameters",
 		}); err != nil {
-			fmt.Fprintf(os.Stderr, "Error sending reply: %v\n", err)
+			logger.Error("failed to send reply", "error", err)
 		}
 		return
 	}
@@ -242,25 +566,32 @@ func (h *Handler) handleInitialize(ctx context.Context, conn *jsonrpc2.Conn, req
 			Code:    jsonrpc2.CodeInvalidParams,
 			Message: "invalid parameters",
 		}); err != nil {
-			fmt.Fprintf(os.Stderr, "Error sending reply: %v\n", err)
+			logger.Error("failed to send reply", "error", err)
 		}
 		return
 	}
 
+	version := negotiateProtocolVersion(params.ProtocolVersion)
+	h.session.initialize(version, params.ClientInfo, params.Capabilities)
+	logger.InfoContext(ctx, "client initialized", "client", params.ClientInfo.Name, "client_version", params.ClientInfo.Version,
+		"requested_protocol", params.ProtocolVersion, "protocol", version)
+
 	response := struct {
 		ProtocolVersion string `json:"protocolVersion"`
 		Capabilities    struct {
-			Tools     interface{} `json:"tools,omitempty"`
-			Resources interface{} `json:"resources,omitempty"`
-			Prompts   interface{} `json:"prompts,omitempty"`
-			Logging   interface{} `json:"logging,omitempty"`
+			Tools        interface{} `json:"tools,omitempty"`
+			Resources    interface{} `json:"resources,omitempty"`
+			Prompts      interface{} `json:"prompts,omitempty"`
+			Completions  interface{} `json:"completions,omitempty"`
+			Logging      interface{} `json:"logging,omitempty"`
+			Experimental interface{} `json:"experimental,omitempty"`
 		} `json:"capabilities"`
 		ServerInfo struct {
 			Name    string `json:"name"`
 			Version string `json:"version"`
 		} `json:"serverInfo"`
 	}{
-		ProtocolVersion: "2024-11-05",
+		ProtocolVersion: version,
 		ServerInfo: struct {
 			Name    string `json:"name"`
 			Version string `json:"version"`
@@ -271,27 +602,54 @@ func (h *Handler) handleInitialize(ctx context.Context, conn *jsonrpc2.Conn, req
 	}
 
 	// Set capabilities
-	response.Capabilities.Tools = struct{}{}
-	response.Capabilities.Resources = struct{}{}
+	response.Capabilities.Tools = struct {
+		ListChanged bool `json:"listChanged"`
+	}{ListChanged: true}
+	response.Capabilities.Resources = struct {
+		Subscribe bool `json:"subscribe"`
+	}{Subscribe: true}
+	response.Capabilities.Prompts = struct{}{}
+	response.Capabilities.Logging = struct{}{}
+	if h.session.Supports(FeatureCompletions) {
+		response.Capabilities.Completions = struct{}{}
+	}
+	// tools/list and resources/list accept a "services" filter
+	response.Capabilities.Experimental = map[string]interface{}{"serviceFilter": struct{}{}}
 
 	if err := conn.Reply(ctx, req.ID, response); err != nil {
-		fmt.Fprintf(os.Stderr, "Error sending reply: %v\n", err)
+		logger.Error("failed to send reply", "error", err)
 	}
 }
 
 func (h *Handler) handleToolsList(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	cursor, err := parseListParams(req.Params)
+	if err != nil {
+		h.replyListError(ctx, conn, req, err)
+		return
+	}
+
 	h.server.mu.RLock()
 	tools := h.server.tools
+	toolMap := h.server.toolMap
 	h.server.mu.RUnlock()
 
+	tools = filterByService(tools, func(tool Tool) string { return toolMap[tool.Name].service }, cursor.Services)
+	page, next, err := paginate(tools, func(tool Tool) string { return tool.Name }, cursor, h.server.pageSize)
+	if err != nil {
+		h.replyListError(ctx, conn, req, err)
+		return
+	}
+
 	response := struct {
-		Tools []Tool `json:"tools"`
+		Tools      []Tool `json:"tools"`
+		NextCursor string `json:"nextCursor,omitempty"`
 	}{
-		Tools: tools,
+		Tools:      toolsForSession(h.session, page),
+		NextCursor: next,
 	}
 
 	if err := conn.Reply(ctx, req.ID, response); err != nil {
-		fmt.Fprintf(os.Stderr, "Error sending reply: %v\n", err)
+		logger.Error("failed to send reply", "error", err)
 	}
 }
 
@@ -299,6 +657,7 @@ func (h *Handler) handleToolCall(ctx context.Context, conn *jsonrpc2.Conn, req *
 	var params struct {
 		Name      string          `json:"name"`
 		Arguments json.RawMessage `json:"arguments"`
+		Meta      *requestMeta    `json:"_meta,omitempty"`
 	}
 
 	if req.Params == nil {
@@ -306,7 +665,7 @@ func (h *Handler) handleToolCall(ctx context.Context, conn *jsonrpc2.Conn, req *
 			Code:    jsonrpc2.CodeInvalidParams,
 			Message: "missing parameters",
 		}); err != nil {
-			fmt.Fprintf(os.Stderr, "Error sending reply: %v\n", err)
+			logger.Error("failed to send reply", "error", err)
 		}
 		return
 	}
@@ -316,39 +675,161 @@ func (h *Handler) handleToolCall(ctx context.Context, conn *jsonrpc2.Conn, req *
 			Code:    jsonrpc2.CodeInvalidParams,
 			Message: "invalid parameters",
 		}); err != nil {
-			fmt.Fprintf(os.Stderr, "Error sending reply: %v\n", err)
+			logger.Error("failed to send reply", "error", err)
 		}
 		return
 	}
 
 	// Find the appropriate service handler via O(1) map lookup
 	h.server.mu.RLock()
-	handler, exists := h.server.toolMap[params.Name]
+	entry, exists := h.server.toolMap[params.Name]
+	withheld, isWithheld := h.server.withheldTools[params.Name]
 	h.server.mu.RUnlock()
 
+	// Tools withheld by the policy are refused even if the client calls
+	// them without listing them first
+	if isWithheld {
+		detail := newToolErrorDetail(policyError(params.Name, withheld), params.Name, withheld.service, accountArgument(params.Arguments))
+		h.server.countToolError(detail)
+		logger.WarnContext(ctx, "tool call refused by policy", "tool", params.Name, "reason", withheld.reason)
+		if err := conn.Reply(ctx, req.ID, toolErrorResult(detail)); err != nil {
+			logger.Error("failed to send reply", "error", err)
+		}
+		return
+	}
+
 	if !exists {
 		if err := conn.ReplyWithError(ctx, req.ID, &jsonrpc2.Error{
 			Code:    jsonrpc2.CodeMethodNotFound,
 			Message: fmt.Sprintf("tool not found: %s", params.Name),
 		}); err != nil {
-			fmt.Fprintf(os.Stderr, "Error sending reply: %v\n", err)
+			logger.Error("failed to send reply", "error", err)
 		}
 		return
 	}
 
-	// Call the tool
-	result, err := handler.HandleToolCall(ctx, params.Name, params.Arguments)
+	// Records logged while the tool runs identify the call
+	account := accountArgument(params.Arguments)
+	ctx = logging.WithAttrs(ctx, "service", entry.service, "tool", params.Name)
+	if account != "" {
+		ctx = logging.WithAttrs(ctx, "account", account)
+	}
+
+	// Reject malformed arguments before they reach the service
+	arguments, err := validateArguments(entry.tool.InputSchema, params.Arguments)
 	if err != nil {
-		// Log full error to stderr, return generic message to client
-		fmt.Fprintf(os.Stderr, "Error in tool %s: %v\n", params.Name, err)
-		if err := conn.ReplyWithError(ctx, req.ID, &jsonrpc2.Error{
-			Code:    jsonrpc2.CodeInternalError,
-			Message: "internal error",
-		}); err != nil {
-			fmt.Fprintf(os.Stderr, "Error sending reply: %v\n", err)
+		detail := newToolErrorDetail(err, params.Name, entry.service, account)
+		h.server.countToolError(detail)
+		logger.InfoContext(ctx, "invalid tool arguments", "error", detail.Message)
+		if err := conn.Reply(ctx, req.ID, toolErrorResult(detail)); err != nil {
+			logger.Error("failed to send reply", "error", err)
 		}
 		return
 	}
+	requested, arguments := takeDryRun(arguments)
+	params.Arguments = arguments
+
+	// A dry run changes nothing, so it needs no confirmation. Local tools
+	// would change data regardless, so they refuse to run as one.
+	var plan *auth.DryRun
+	if h.server.isDryRun(entry.tool, requested) {
+		if entry.tool.Annotations.IsLocal() {
+			err = dryRunUnsupported(params.Name)
+		} else {
+			ctx, plan = auth.WithDryRun(ctx)
+		}
+	} else {
+		// Destructive calls may need the user's go-ahead first
+		ctx, err = h.confirmToolCall(ctx, conn, entry, params.Name, params.Arguments)
+	}
+	if err != nil {
+		if requestCancelled(ctx) {
+			logger.InfoContext(ctx, "tool call abandoned", "cause", context.Cause(ctx))
+			return
+		}
+		detail := newToolErrorDetail(err, params.Name, entry.service, account)
+		h.server.countToolError(detail)
+		logger.WarnContext(ctx, "tool call refused", "category", detail.Category, "error", detail.Message)
+		if err := conn.Reply(ctx, req.ID, toolErrorResult(detail)); err != nil {
+			logger.Error("failed to send reply", "error", err)
+		}
+		return
+	}
+
+	// Call the tool, recording the accounts its requests are made for
+	logger.DebugContext(ctx, "calling tool")
+	ctx, use := auth.WithAccountUse(ctx)
+	started := time.Now()
+	result, err := h.callTool(withProgress(ctx, conn, params.Meta), entry, params.Name, params.Arguments)
+	toolCallDuration.Observe(time.Since(started).Seconds(), params.Name)
+	if plan == nil {
+		h.auditToolCall(ctx, entry, params.Name, usedAccount(use, account), params.Arguments, result, err)
+	}
+	if requestCancelled(ctx) {
+		logger.InfoContext(ctx, "tool call abandoned", "cause", context.Cause(ctx))
+		return
+	}
+
+	// A dry run ends with the first request that changes data. Errors
+	// before it, such as invalid arguments or missing scopes, are reported
+	// as usual.
+	if plan != nil && (err == nil || len(plan.Requests()) > 0) {
+		response := newCallToolResult(newDryRunResult(plan.Requests(), result))
+		response.StructuredContent = nil
+		if err := conn.Reply(ctx, req.ID, response); err != nil {
+			logger.Error("failed to send reply", "error", err)
+		}
+		return
+	}
+
+	if err != nil {
+		// Tool failures are reported in the result so the model can see and act on them
+		detail := newToolErrorDetail(err, params.Name, entry.service, usedAccount(use, account))
+		h.server.countToolError(detail)
+		logger.WarnContext(ctx, "tool call failed", "category", detail.Category, "error", detail.Message)
+		if err := conn.Reply(ctx, req.ID, toolErrorResult(detail)); err != nil {
+			logger.Error("failed to send reply", "error", err)
+		}
+		return
+	}
+
+	response := newCallToolResult(result)
+
+	// Structured results are part of the protocol since 2025-06-18
+	if entry.tool.OutputSchema != nil && response.StructuredContent == nil {
+		logger.WarnContext(ctx, "tool declares an output schema but returned no JSON object")
+	}
+	if !h.session.Supports(FeatureStructuredOutput) {
+		response.StructuredContent = nil
+	}
+
+	if err := conn.Reply(ctx, req.ID, response); err != nil {
+		logger.Error("failed to send reply", "error", err)
+	}
+}
+
+// callToolResult is the result of a tools/call request
+type callToolResult struct {
+	Content           []Content       `json:"content"`
+	StructuredContent json.RawMessage `json:"structuredContent,omitempty"`
+	IsError           bool            `json:"isError,omitempty"`
+}
+
+// newCallToolResult builds the result of a tool from what its handler
+// returned. A ToolResult keeps its content blocks; any other value becomes a
+// single text block, sent as JSON unless it is a string.
+func newCallToolResult(result interface{}) callToolResult {
+	if typed, ok := result.(*ToolResult); ok {
+		response := callToolResult{Content: []Content{}}
+		if typed.Structured != nil {
+			if data, err := json.Marshal(typed.Structured); err == nil {
+				response.Content = append(response.Content, TextContent(string(data)))
+				response.StructuredContent = data
+			}
+		}
+		response.Content = append(response.Content, typed.Content...)
+		return response
+	}
 
 	// Check if result is already a JSON string
 	var responseText string
@@ -367,48 +848,137 @@ func (h *Handler) handleToolCall(ctx context.Context, conn *jsonrpc2.Conn, req *
 		}
 	}
 
+	return callToolResult{
+		Content:           []Content{TextContent(responseText)},
+		StructuredContent: structuredContent(responseText),
+	}
+}
+
+// structuredContent returns a tool result as structured content if it is a
+// JSON object. The text block carries the same JSON for clients that only
+// read content.
+func structuredContent(text string) json.RawMessage {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "{") || !json.Valid([]byte(trimmed)) {
+		return nil
+	}
+	return json.RawMessage(trimmed)
+}
+
+// toolErrorResult wraps a tool failure in a result with isError set
+func toolErrorResult(detail toolErrorDetail) callToolResult {
+	body, err := json.Marshal(struct {
+		Error toolErrorDetail `json:"error"`
+	}{Error: detail})
+	if err != nil {
+		body = []byte(detail.Message)
+	}
+
+	return callToolResult{
+		Content: []Content{TextContent(string(body))},
+		IsError: true,
+	}
+}
+
+// accountArgument extracts the optional "account" argument shared by multi-account tools
+func accountArgument(arguments json.RawMessage) string {
+	var args struct {
+		Account string `json:"account"`
+	}
+	if len(arguments) == 0 || json.Unmarshal(arguments, &args) != nil {
+		return ""
+	}
+	return args.Account
+}
+
+func (h *Handler) handleResourcesList(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	cursor, err := parseListParams(req.Params)
+	if err != nil {
+		h.replyListError(ctx, conn, req, err)
+		return
+	}
+
+	h.server.mu.RLock()
+	resources := h.server.resources
+	resourceServices := h.server.resourceServices
+	h.server.mu.RUnlock()
+
+	resources = filterByService(resources, func(resource Resource) string { return resourceServices[resource.URI] }, cursor.Services)
+	page, next, err := paginate(resources, func(resource Resource) string { return resource.URI }, cursor, h.server.pageSize)
+	if err != nil {
+		h.replyListError(ctx, conn, req, err)
+		return
+	}
+
 	response := struct {
-		Content []struct {
-			Type string      `json:"type"`
-			Text string      `json:"text,omitempty"`
-			Data interface{} `json:"data,omitempty"`
-		} `json:"content"`
-		IsError bool `json:"isError,omitempty"`
+		Resources  []Resource `json:"resources"`
+		NextCursor string     `json:"nextCursor,omitempty"`
 	}{
-		Content: []struct {
-			Type string      `json:"type"`
-			Text string      `json:"text,omitempty"`
-			Data interface{} `json:"data,omitempty"`
-		}{
-			{
-				Type: "text",
-				Text: responseText,
-			},
-		},
-		IsError: false,
+		Resources:  page,
+		NextCursor: next,
 	}
 
 	if err := conn.Reply(ctx, req.ID, response); err != nil {
-		fmt.Fprintf(os.Stderr, "Error sending reply: %v\n", err)
+		logger.Error("failed to send reply", "error", err)
 	}
 }
 
-func (h *Handler) handleResourcesList(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+// replyListError answers a list request whose params or cursor are invalid
+func (h *Handler) replyListError(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request, err error) {
+	message := "invalid parameters"
+	if errors.Is(err, errInvalidCursor) {
+		message = "invalid cursor"
+	}
+	if err := conn.ReplyWithError(ctx, req.ID, &jsonrpc2.Error{
+		Code:    jsonrpc2.CodeInvalidParams,
+		Message: message,
+	}); err != nil {
+		logger.Error("failed to send reply", "error", err)
+	}
+}
+
+func (h *Handler) handleResourceTemplatesList(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
 	h.server.mu.RLock()
-	resources := h.server.resources
+	templates := h.server.resourceTemplates
 	h.server.mu.RUnlock()
 
+	if templates == nil {
+		templates = []ResourceTemplate{}
+	}
+
 	response := struct {
-		Resources []Resource `json:"resources"`
+		ResourceTemplates []ResourceTemplate `json:"resourceTemplates"`
 	}{
-		Resources: resources,
+		ResourceTemplates: templates,
 	}
 
 	if err := conn.Reply(ctx, req.ID, response); err != nil {
-		fmt.Fprintf(os.Stderr, "Error sending reply: %v\n", err)
+		logger.Error("failed to send reply", "error", err)
 	}
 }
 
+// resolveResource finds the service serving uri. Static resources are matched
+// first; otherwise the first matching template route is returned with the
+// variables extracted from uri. Both results are nil if nothing matches.
+func (s *MCPServer) resolveResource(uri string) (ServiceHandler, *templateRoute, map[string]string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, service := range s.services {
+		for _, resource := range service.GetResources() {
+			if resource.URI == uri {
+				return service, nil, nil
+			}
+		}
+	}
+	for i := range s.templateRoutes {
+		if vars, ok := s.templateRoutes[i].template.Match(uri); ok {
+			return nil, &s.templateRoutes[i], vars
+		}
+	}
+	return nil, nil, nil
+}
+
 func (h *Handler) handleResourceRead(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
 	var params struct {
 		URI string `json:"uri"`
@@ -419,7 +989,7 @@ func (h *Handler) handleResourceRead(ctx context.Context, conn *jsonrpc2.Conn, r
 			Code:    jsonrpc2.CodeInvalidParams,
 			Message: "missing parameters",
 		}); err != nil {
-			fmt.Fprintf(os.Stderr, "Error sending reply: %v\n", err)
+			logger.Error("failed to send reply", "error", err)
 		}
 		return
 	}
@@ -429,88 +999,184 @@ func (h *Handler) handleResourceRead(ctx context.Context, conn *jsonrpc2.Conn, r
 			Code:    jsonrpc2.CodeInvalidParams,
 			Message: "invalid parameters",
 		}); err != nil {
-			fmt.Fprintf(os.Stderr, "Error sending reply: %v\n", err)
+			logger.Error("failed to send reply", "error", err)
 		}
 		return
 	}
 
-	// Find the appropriate service handler
-	h.server.mu.RLock()
-	var handler ServiceHandler
-	for _, service := range h.server.services {
-		resources := service.GetResources()
-		for _, resource := range resources {
-			if resource.URI == params.URI {
-				handler = service
-				break
-			}
-		}
-		if handler != nil {
-			break
-		}
-	}
-	h.server.mu.RUnlock()
+	// Find the appropriate service handler: static resources first, then templates
+	handler, route, variables := h.server.resolveResource(params.URI)
 
-	if handler == nil {
+	if handler == nil && route == nil {
 		if err := conn.ReplyWithError(ctx, req.ID, &jsonrpc2.Error{
 			Code:    jsonrpc2.CodeMethodNotFound,
 			Message: fmt.Sprintf("resource not found: %s", params.URI),
 		}); err != nil {
-			fmt.Fprintf(os.Stderr, "Error sending reply: %v\n", err)
+			logger.Error("failed to send reply", "error", err)
 		}
 		return
 	}
 
 	// Read the resource
-	result, err := handler.HandleResourceCall(ctx, params.URI)
+	var result interface{}
+	var err error
+	if route != nil {
+		result, err = route.handler.HandleResourceTemplateCall(ctx, params.URI, route.template.String(), variables)
+	} else {
+		result, err = handler.HandleResourceCall(ctx, params.URI)
+	}
+	if requestCancelled(ctx) {
+		logger.InfoContext(ctx, "resource read abandoned", "uri", params.URI, "cause", context.Cause(ctx))
+		return
+	}
 	if err != nil {
-		// Log full error to stderr, return generic message to client
-		fmt.Fprintf(os.Stderr, "Error reading resource %s: %v\n", params.URI, err)
+		// Resources have no isError result, so report a sanitized JSON-RPC error
+		category := classifyError(err)
+		message := sanitizeErrorMessage(err.Error())
+		logger.WarnContext(ctx, "resource read failed", "uri", params.URI, "category", category, "error", message)
+		rpcErr := &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInternalError,
+			Message: message,
+		}
+		rpcErr.SetError(map[string]interface{}{"category": category, "uri": params.URI})
+		if err := conn.ReplyWithError(ctx, req.ID, rpcErr); err != nil {
+			logger.Error("failed to send reply", "error", err)
+		}
+		return
+	}
+
+	contents, err := resourceContents(params.URI, result)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to encode resource", "uri", params.URI, "error", err)
 		if err := conn.ReplyWithError(ctx, req.ID, &jsonrpc2.Error{
 			Code:    jsonrpc2.CodeInternalError,
-			Message: "internal error",
+			Message: fmt.Sprintf("failed to encode resource: %s", params.URI),
 		}); err != nil {
-			fmt.Fprintf(os.Stderr, "Error sending reply: %v\n", err)
+			logger.Error("failed to send reply", "error", err)
 		}
 		return
 	}
 
 	response := struct {
-		Contents []struct {
-			URI      string `json:"uri"`
-			MimeType string `json:"mimeType,omitempty"`
-			Text     string `json:"text,omitempty"`
-		} `json:"contents"`
+		Contents []ResourceContents `json:"contents"`
 	}{
-		Contents: []struct {
-			URI      string `json:"uri"`
-			MimeType string `json:"mimeType,omitempty"`
-			Text     string `json:"text,omitempty"`
-		}{
-			{
-				URI:      params.URI,
-				MimeType: "text/plain",
-				Text:     fmt.Sprintf("%v", result),
-			},
-		},
+		Contents: contents,
+	}
+
+	if err := conn.Reply(ctx, req.ID, response); err != nil {
+		logger.Error("failed to send reply", "error", err)
+	}
+}
+
+func (h *Handler) handlePromptsList(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	h.server.mu.RLock()
+	prompts := h.server.prompts
+	h.server.mu.RUnlock()
+
+	response := struct {
+		Prompts []Prompt `json:"prompts"`
+	}{
+		Prompts: prompts,
 	}
 
 	if err := conn.Reply(ctx, req.ID, response); err != nil {
-		fmt.Fprintf(os.Stderr, "Error sending reply: %v\n", err)
+		logger.Error("failed to send reply", "error", err)
+	}
+}
+
+func (h *Handler) handlePromptGet(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	var params struct {
+		Name      string            `json:"name"`
+		Arguments map[string]string `json:"arguments"`
+	}
+
+	if req.Params == nil {
+		if err := conn.ReplyWithError(ctx, req.ID, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidParams,
+			Message: "missing parameters",
+		}); err != nil {
+			logger.Error("failed to send reply", "error", err)
+		}
+		return
+	}
+
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		if err := conn.ReplyWithError(ctx, req.ID, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidParams,
+			Message: "invalid parameters",
+		}); err != nil {
+			logger.Error("failed to send reply", "error", err)
+		}
+		return
+	}
+
+	h.server.mu.RLock()
+	provider, exists := h.server.promptMap[params.Name]
+	var prompt Prompt
+	for _, p := range h.server.prompts {
+		if p.Name == params.Name {
+			prompt = p
+			break
+		}
+	}
+	h.server.mu.RUnlock()
+
+	if !exists {
+		if err := conn.ReplyWithError(ctx, req.ID, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidParams,
+			Message: fmt.Sprintf("prompt not found: %s", params.Name),
+		}); err != nil {
+			logger.Error("failed to send reply", "error", err)
+		}
+		return
+	}
+
+	if missing := missingPromptArguments(prompt, params.Arguments); len(missing) > 0 {
+		if err := conn.ReplyWithError(ctx, req.ID, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidParams,
+			Message: fmt.Sprintf("missing required arguments: %s", strings.Join(missing, ", ")),
+		}); err != nil {
+			logger.Error("failed to send reply", "error", err)
+		}
+		return
+	}
+
+	if params.Arguments == nil {
+		params.Arguments = map[string]string{}
+	}
+
+	result, err := provider.GetPrompt(ctx, params.Name, params.Arguments)
+	if requestCancelled(ctx) {
+		logger.InfoContext(ctx, "prompt rendering abandoned", "prompt", params.Name, "cause", context.Cause(ctx))
+		return
+	}
+	if err != nil {
+		message := sanitizeErrorMessage(err.Error())
+		logger.WarnContext(ctx, "prompt rendering failed", "prompt", params.Name, "error", message)
+		if err := conn.ReplyWithError(ctx, req.ID, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInternalError,
+			Message: message,
+		}); err != nil {
+			logger.Error("failed to send reply", "error", err)
+		}
+		return
+	}
+
+	if err := conn.Reply(ctx, req.ID, result); err != nil {
+		logger.Error("failed to send reply", "error", err)
 	}
 }
 
 func (h *Handler) handleCompletion(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
 	var params struct {
-		Ref struct {
-			Type string `json:"type"`
-			Name string `json:"name,omitempty"`
-			URI  string `json:"uri,omitempty"`
-		} `json:"ref"`
+		Ref      CompletionRef `json:"ref"`
 		Argument struct {
 			Name  string `json:"name"`
 			Value string `json:"value"`
 		} `json:"argument"`
+		Context struct {
+			Arguments map[string]string `json:"arguments,omitempty"`
+		} `json:"context"`
 	}
 
 	if req.Params == nil {
@@ -518,35 +1184,110 @@ func (h *Handler) handleCompletion(ctx context.Context, conn *jsonrpc2.Conn, req
 			Code:    jsonrpc2.CodeInvalidParams,
 			Message: "missing parameters",
 		}); err != nil {
-			fmt.Fprintf(os.Stderr, "Error sending reply: %v\n", err)
+			logger.Error("failed to send reply", "error", err)
 		}
 		return
 	}
 
-	if err := json.Unmarshal(*req.Params, &params); err != nil {
+	if err := json.Unmarshal(*req.Params, &params); err != nil || params.Argument.Name == "" {
 		if err := conn.ReplyWithError(ctx, req.ID, &jsonrpc2.Error{
 			Code:    jsonrpc2.CodeInvalidParams,
 			Message: "invalid parameters",
 		}); err != nil {
-			fmt.Fprintf(os.Stderr, "Error sending reply: %v\n", err)
+			logger.Error("failed to send reply", "error", err)
 		}
 		return
 	}
 
-	// For now, return empty completions
+	candidates, err := h.server.completionCandidates(ctx, params.Ref, params.Argument.Name, params.Context.Arguments)
+	if requestCancelled(ctx) {
+		return
+	}
+	if err != nil {
+		// A failed lookup should not interrupt typing; offer nothing instead
+		logger.WarnContext(ctx, "completion lookup failed", "argument", params.Argument.Name, "error", sanitizeErrorMessage(err.Error()))
+	}
+
+	values := filterCompletions(candidates, params.Argument.Value)
+	total := len(values)
+	if total > maxCompletionValues {
+		values = values[:maxCompletionValues]
+	}
+
+	type completion struct {
+		Values  []string `json:"values"`
+		Total   int      `json:"total"`
+		HasMore bool     `json:"hasMore"`
+	}
 	response := struct {
-		Completion struct {
-			Values []string `json:"values"`
-		} `json:"completion"`
+		Completion completion `json:"completion"`
 	}{
-		Completion: struct {
-			Values []string `json:"values"`
-		}{
-			Values: []string{},
+		Completion: completion{
+			Values:  append([]string{}, values...),
+			Total:   total,
+			HasMore: total > len(values),
 		},
 	}
 
 	if err := conn.Reply(ctx, req.ID, response); err != nil {
-		fmt.Fprintf(os.Stderr, "Error sending reply: %v\n", err)
+		logger.Error("failed to send reply", "error", err)
 	}
 }
+
+// completionCandidates returns the candidates for an argument, asking the
+// service that owns ref first and the registered completers second. Results
+// are cached for a short time.
+func (s *MCPServer) completionCandidates(ctx context.Context, ref CompletionRef, argument string, arguments map[string]string) ([]CompletionCandidate, error) {
+	key := completionCacheKey(ref, argument, arguments)
+	if candidates, ok := s.completions.get(key); ok {
+		return candidates, nil
+	}
+
+	s.mu.RLock()
+	provider := s.completionProvider(ref)
+	completer := s.completers[argument]
+	s.mu.RUnlock()
+
+	var candidates []CompletionCandidate
+	if provider != nil {
+		var err error
+		candidates, err = provider.CompleteArgument(ctx, ref, argument, arguments)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if candidates == nil && completer != nil {
+		var err error
+		candidates, err = completer(ctx, arguments)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.completions.put(key, candidates)
+	return candidates, nil
+}
+
+// completionProvider returns the completion provider owning ref, or nil.
+// Callers must hold s.mu.
+func (s *MCPServer) completionProvider(ref CompletionRef) CompletionProvider {
+	var owner interface{}
+	switch ref.Type {
+	case RefPrompt:
+		owner = s.promptMap[ref.Name]
+	case RefResource:
+		for _, route := range s.templateRoutes {
+			if route.template.String() == ref.URI {
+				owner = route.handler
+				break
+			}
+		}
+	case RefTool:
+		if entry, ok := s.toolMap[ref.Name]; ok {
+			owner = entry.handler
+		}
+	}
+
+	provider, _ := owner.(CompletionProvider)
+	return provider
+}
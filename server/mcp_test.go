@@ -1,9 +1,13 @@
 package server
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
 	"go.ngs.io/google-mcp-server/config"
+	"go.ngs.io/google-mcp-server/internal/mcptest"
 )
 
 func TestNewMCPServer(t *testing.T) {
@@ -84,3 +88,89 @@ func TestResource(t *testing.T) {
 		t.Errorf("Expected MIME type to be 'application/json', got %s", resource.MimeType)
 	}
 }
+
+// laterService is registered after clients have connected
+type laterService struct {
+	stubService
+}
+
+func (laterService) GetTools() []Tool {
+	return []Tool{{Name: "later_tool", Description: "Registered at runtime", InputSchema: InputSchema{Type: "object"}}}
+}
+
+func TestRuntimeServiceRegistration(t *testing.T) {
+	srv, ts := newTestHTTPServer(t)
+	endpoint := ts.URL + httpEndpointPath
+
+	resp := mcptest.Post(t, endpoint, "", "application/json",
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`)
+	var initResult struct {
+		Result struct {
+			Capabilities struct {
+				Tools struct {
+					ListChanged bool `json:"listChanged"`
+				} `json:"tools"`
+			} `json:"capabilities"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&initResult); err != nil {
+		t.Fatalf("failed to decode initialize response: %v", err)
+	}
+	_ = resp.Body.Close()
+	if !initResult.Result.Capabilities.Tools.ListChanged {
+		t.Error("expected the tools capability to advertise listChanged")
+	}
+	sessionID := resp.Header.Get(sessionHeader)
+	changes := listenFor(t, endpoint, sessionID, "notifications/tools/list_changed")
+
+	listTools := func() string {
+		t.Helper()
+		resp := mcptest.Post(t, endpoint, sessionID, "application/json", `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+		defer func() { _ = resp.Body.Close() }()
+		var list struct {
+			Result json.RawMessage `json:"result"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+			t.Fatalf("failed to decode tools/list response: %v", err)
+		}
+		return string(list.Result)
+	}
+	expectChange := func(want bool) {
+		t.Helper()
+		select {
+		case <-changes:
+			if !want {
+				t.Error("expected no tools/list_changed notification")
+			}
+		case <-time.After(200 * time.Millisecond):
+			if want {
+				t.Error("expected a tools/list_changed notification")
+			}
+		}
+	}
+
+	srv.RegisterService("later", laterService{})
+	expectChange(true)
+	if tools := listTools(); !strings.Contains(tools, "later_tool") || !strings.Contains(tools, "stub_echo") {
+		t.Errorf("expected both services' tools, got %s", tools)
+	}
+
+	// Registering the same tools again is not a change
+	srv.RegisterService("later", laterService{})
+	expectChange(false)
+
+	if !srv.UnregisterService("later") {
+		t.Fatal("expected the service to be unregistered")
+	}
+	expectChange(true)
+	if tools := listTools(); strings.Contains(tools, "later_tool") {
+		t.Errorf("expected later_tool to be gone, got %s", tools)
+	}
+	if _, ok := srv.toolMap["later_tool"]; ok {
+		t.Error("expected later_tool to be removed from the tool map")
+	}
+
+	if srv.UnregisterService("later") {
+		t.Error("expected unregistering an unknown service to report false")
+	}
+}
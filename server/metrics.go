@@ -0,0 +1,104 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"go.ngs.io/google-mcp-server/metrics"
+)
+
+// metricsPath is where the metrics endpoint serves the Prometheus metrics
+const metricsPath = "/metrics"
+
+// toolCallBuckets are the upper bounds, in seconds, of the tool latency
+// histogram. Tools range from cached lookups to uploads that run for
+// minutes, up to the default timeout.
+var toolCallBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+var (
+	toolCallDuration = metrics.NewHistogramVec("google_mcp_tool_call_duration_seconds",
+		"Time taken by tool calls, from the start of the tool to its result, by tool.",
+		toolCallBuckets, "tool")
+
+	toolErrors = metrics.NewCounterVec("google_mcp_tool_errors_total",
+		"Tool calls answered with an error, by error category and account.",
+		"category", "account")
+
+	requestsInFlight = metrics.NewGauge("google_mcp_requests_in_flight",
+		"Requests being handled across all sessions, not counting those waiting for a worker.")
+)
+
+// countToolError counts a tool call answered with an error against the
+// account its requests went through. Calls that made no request and name
+// no account use "default", calls that used several accounts "multiple",
+// and calls naming an account the server does not know "unknown", so that
+// clients cannot add label values without bound.
+func (s *MCPServer) countToolError(detail toolErrorDetail) {
+	account := detail.Account
+	switch {
+	case account == "":
+		account = "default"
+	case strings.Contains(account, ","):
+		account = "multiple"
+	case account != "default" && !s.isKnownAccount(account):
+		account = "unknown"
+	}
+	toolErrors.Inc(string(detail.Category), account)
+}
+
+// SetAccountLister sets the function listing the accounts the server knows,
+// the only ones the tool error metric names
+func (s *MCPServer) SetAccountLister(list func() []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listAccounts = list
+}
+
+func (s *MCPServer) isKnownAccount(account string) bool {
+	s.mu.RLock()
+	list := s.listAccounts
+	s.mu.RUnlock()
+	if list == nil {
+		return false
+	}
+	return slices.Contains(list(), account)
+}
+
+// EnableMetrics serves the Prometheus metrics at /metrics on addr until
+// Stop is called. An empty addr leaves the endpoint off. It fails when addr
+// cannot be listened on, rather than leaving the failure to the first
+// scrape.
+func (s *MCPServer) EnableMetrics(addr string) error {
+	if addr == "" {
+		return nil
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for metrics: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, metrics.Handler())
+	metricsServer := &http.Server{
+		Addr:              listener.Addr().String(),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	s.mu.Lock()
+	s.metricsServer = metricsServer
+	s.mu.Unlock()
+
+	logger.Info("metrics listening", "url", "http://"+metricsServer.Addr+metricsPath)
+	go func() {
+		if err := metricsServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("metrics endpoint failed", "error", err)
+		}
+	}()
+	return nil
+}
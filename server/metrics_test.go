@@ -0,0 +1,105 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.ngs.io/google-mcp-server/config"
+	"go.ngs.io/google-mcp-server/internal/mcptest"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP}})
+	srv.RegisterService("stub", stubService{})
+	if err := srv.EnableMetrics("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = srv.Stop() }()
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+	sessionID := initializeSession(t, endpoint)
+
+	calls := toolCallDuration.Count("stub_echo")
+	failures := toolErrors.Value(string(CategoryNotFound), "default")
+	mcptest.CallTool(t, endpoint, sessionID, `{"name":"stub_echo","arguments":{}}`)
+	mcptest.CallTool(t, endpoint, sessionID, `{"name":"stub_fail","arguments":{}}`)
+	if got := toolCallDuration.Count("stub_echo") - calls; got != 1 {
+		t.Errorf("expected 1 observed stub_echo call, got %d", got)
+	}
+	if got := toolErrors.Value(string(CategoryNotFound), "default") - failures; got != 1 {
+		t.Errorf("expected 1 not_found error of the default account, got %v", got)
+	}
+
+	// Only accounts the server knows are named
+	srv.SetAccountLister(func() []string { return []string{"alice@example.com"} })
+	known := toolErrors.Value(string(CategoryNotFound), "alice@example.com")
+	unknown := toolErrors.Value(string(CategoryNotFound), "unknown")
+	mcptest.CallTool(t, endpoint, sessionID, `{"name":"stub_fail","arguments":{"account":"alice@example.com"}}`)
+	mcptest.CallTool(t, endpoint, sessionID, `{"name":"stub_fail","arguments":{"account":"mallory-1@example.com"}}`)
+	mcptest.CallTool(t, endpoint, sessionID, `{"name":"stub_fail","arguments":{"account":"mallory-2@example.com"}}`)
+	if got := toolErrors.Value(string(CategoryNotFound), "alice@example.com") - known; got != 1 {
+		t.Errorf("expected 1 not_found error of alice@example.com, got %v", got)
+	}
+	if got := toolErrors.Value(string(CategoryNotFound), "unknown") - unknown; got != 2 {
+		t.Errorf("expected 2 not_found errors of unknown accounts, got %v", got)
+	}
+	if got := toolErrors.Value(string(CategoryNotFound), "mallory-1@example.com"); got != 0 {
+		t.Errorf("expected no series for an account the server does not know, got %v", got)
+	}
+
+	srv.mu.RLock()
+	addr := srv.metricsServer.Addr
+	srv.mu.RUnlock()
+	resp, err := http.Get("http://" + addr + metricsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(resp.Body)
+	for _, want := range []string{
+		`google_mcp_tool_call_duration_seconds_count{tool="stub_echo"}`,
+		`google_mcp_tool_errors_total{category="not_found",account="default"}`,
+		"# TYPE google_mcp_requests_in_flight gauge",
+		"# TYPE google_mcp_api_requests_total counter",
+		"# TYPE google_mcp_token_refreshes_total counter",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("expected the metrics to contain %s, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestMetricsEndpointIsOffByDefault(t *testing.T) {
+	srv := NewMCPServer(&config.Config{})
+	if err := srv.EnableMetrics(""); err != nil {
+		t.Fatal(err)
+	}
+	if srv.metricsServer != nil {
+		t.Error("expected no metrics endpoint without an address")
+	}
+	if err := srv.EnableMetrics("256.0.0.1:http"); err == nil {
+		t.Error("expected an invalid address to fail")
+	}
+}
+
+func TestToolErrorsCountTheAccountUsed(t *testing.T) {
+	srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP}})
+	srv.RegisterService("stub", defaultAccountStubService{})
+	srv.SetAccountLister(func() []string { return []string{"bob@example.com"} })
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+	sessionID := initializeSession(t, endpoint)
+
+	// The call names no account, but its requests went through bob's
+	used := toolErrors.Value(string(CategoryNotFound), "bob@example.com")
+	failures := toolErrors.Value(string(CategoryNotFound), "default")
+	mcptest.CallTool(t, endpoint, sessionID, `{"name":"stub_fail","arguments":{}}`)
+	if got := toolErrors.Value(string(CategoryNotFound), "bob@example.com") - used; got != 1 {
+		t.Errorf("expected 1 not_found error of bob@example.com, got %v", got)
+	}
+	if got := toolErrors.Value(string(CategoryNotFound), "default") - failures; got != 0 {
+		t.Errorf("expected no not_found error of the default account, got %v", got)
+	}
+}
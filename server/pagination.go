@@ -0,0 +1,109 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// defaultPageSize is the number of items per page of tools/list and
+// resources/list
+const defaultPageSize = 50
+
+// errInvalidCursor is returned for cursors the server did not issue, or whose
+// item is no longer listed
+var errInvalidCursor = errors.New("invalid cursor")
+
+// listParams are the params of a paginated list request. Services is an
+// extension of this server: it limits the list to the tools or resources of
+// the named services, e.g. ["gmail", "calendar"].
+type listParams struct {
+	Cursor   string   `json:"cursor,omitempty"`
+	Services []string `json:"services,omitempty"`
+}
+
+// listCursor is what the opaque cursors handed to clients encode. It names
+// the last item of the previous page rather than an offset, so a page is not
+// skipped or repeated when services are registered between requests, and it
+// carries the service filter so later pages are filtered like the first.
+type listCursor struct {
+	After    string   `json:"after"`
+	Services []string `json:"services,omitempty"`
+}
+
+func (c listCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// parseListParams returns the position and filter of a list request. A
+// cursor takes precedence over the services in the params.
+func parseListParams(raw *json.RawMessage) (listCursor, error) {
+	var params listParams
+	if raw != nil {
+		if err := json.Unmarshal(*raw, &params); err != nil {
+			return listCursor{}, err
+		}
+	}
+	if params.Cursor == "" {
+		return listCursor{Services: params.Services}, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(params.Cursor)
+	if err != nil {
+		return listCursor{}, errInvalidCursor
+	}
+	var cursor listCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.After == "" {
+		return listCursor{}, errInvalidCursor
+	}
+	return cursor, nil
+}
+
+// filterByService keeps the items owned by one of services; all items are
+// kept when services is empty
+func filterByService[T any](items []T, serviceOf func(T) string, services []string) []T {
+	if len(services) == 0 {
+		return items
+	}
+	wanted := make(map[string]bool, len(services))
+	for _, service := range services {
+		wanted[service] = true
+	}
+	filtered := []T{}
+	for _, item := range items {
+		if wanted[serviceOf(item)] {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+// paginate returns the page of items that follows cursor and the cursor of
+// the next page, which is empty on the last page. key identifies an item.
+func paginate[T any](items []T, key func(T) string, cursor listCursor, pageSize int) ([]T, string, error) {
+	start := 0
+	if cursor.After != "" {
+		start = -1
+		for i, item := range items {
+			if key(item) == cursor.After {
+				start = i + 1
+				break
+			}
+		}
+		if start < 0 {
+			return nil, "", errInvalidCursor
+		}
+	}
+
+	end := len(items)
+	if pageSize > 0 && start+pageSize < end {
+		end = start + pageSize
+	}
+	page := items[start:end]
+	if end == len(items) {
+		return page, "", nil
+	}
+	next := listCursor{After: key(items[end-1]), Services: cursor.Services}
+	return page, next.encode(), nil
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"go.ngs.io/google-mcp-server/config"
+	"go.ngs.io/google-mcp-server/internal/mcptest"
+)
+
+// numberedService has count tools and resources named after the service
+type numberedService struct {
+	stubService
+	name  string
+	count int
+}
+
+func (s numberedService) GetTools() []Tool {
+	var tools []Tool
+	for i := 0; i < s.count; i++ {
+		tools = append(tools, Tool{Name: fmt.Sprintf("%s_tool_%d", s.name, i), InputSchema: InputSchema{Type: "object"}})
+	}
+	return tools
+}
+
+func (s numberedService) GetResources() []Resource {
+	var resources []Resource
+	for i := 0; i < s.count; i++ {
+		resources = append(resources, Resource{URI: fmt.Sprintf("%s://item/%d", s.name, i), Name: strconv.Itoa(i)})
+	}
+	return resources
+}
+
+func TestPaginate(t *testing.T) {
+	items := []string{"a", "b", "c", "d", "e"}
+	key := func(item string) string { return item }
+
+	var pages [][]string
+	cursor := listCursor{}
+	for {
+		page, next, err := paginate(items, key, cursor, 2)
+		if err != nil {
+			t.Fatalf("paginate failed: %v", err)
+		}
+		pages = append(pages, page)
+		if next == "" {
+			break
+		}
+		raw := json.RawMessage(`{"cursor":"` + next + `"}`)
+		if cursor, err = parseListParams(&raw); err != nil {
+			t.Fatalf("failed to parse cursor %q: %v", next, err)
+		}
+	}
+	if got := fmt.Sprint(pages); got != "[[a b] [c d] [e]]" {
+		t.Errorf("unexpected pages %s", got)
+	}
+
+	// A cursor pointing at an item that is gone is rejected
+	if _, _, err := paginate([]string{"a", "c"}, key, listCursor{After: "b"}, 2); err != errInvalidCursor {
+		t.Errorf("expected errInvalidCursor for a removed item, got %v", err)
+	}
+
+	for _, params := range []string{`{"cursor":"not base64!"}`, `{"cursor":"e30"}`} {
+		raw := json.RawMessage(params)
+		if _, err := parseListParams(&raw); err != errInvalidCursor {
+			t.Errorf("expected errInvalidCursor for %s, got %v", params, err)
+		}
+	}
+}
+
+func TestListPaginationAndServiceFilter(t *testing.T) {
+	srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP}})
+	srv.pageSize = 2
+	srv.RegisterService("gmail", numberedService{name: "gmail", count: 3})
+	srv.RegisterService("calendar", numberedService{name: "calendar", count: 2})
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+	sessionID := initializeSession(t, endpoint)
+
+	type listResult struct {
+		Result struct {
+			Tools []struct {
+				Name string `json:"name"`
+			} `json:"tools"`
+			Resources []struct {
+				URI string `json:"uri"`
+			} `json:"resources"`
+			NextCursor string `json:"nextCursor"`
+		} `json:"result"`
+		Error *struct {
+			Code int `json:"code"`
+		} `json:"error"`
+	}
+	list := func(method, params string) listResult {
+		t.Helper()
+		resp := mcptest.Post(t, endpoint, sessionID, "application/json",
+			`{"jsonrpc":"2.0","id":3,"method":"`+method+`","params":`+params+`}`)
+		defer func() { _ = resp.Body.Close() }()
+		var result listResult
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode %s response: %v", method, err)
+		}
+		return result
+	}
+	// listAll follows the cursors and returns the names or URIs of all pages
+	listAll := func(method, params string) []string {
+		t.Helper()
+		var items []string
+		for {
+			result := list(method, params)
+			if result.Error != nil {
+				t.Fatalf("%s failed with code %d", method, result.Error.Code)
+			}
+			for _, tool := range result.Result.Tools {
+				items = append(items, tool.Name)
+			}
+			for _, resource := range result.Result.Resources {
+				items = append(items, resource.URI)
+			}
+			if result.Result.NextCursor == "" {
+				return items
+			}
+			params = `{"cursor":"` + result.Result.NextCursor + `"}`
+		}
+	}
+
+	tools := listAll("tools/list", `{}`)
+	if got := fmt.Sprint(tools); got != "[gmail_tool_0 gmail_tool_1 gmail_tool_2 calendar_tool_0 calendar_tool_1]" {
+		t.Errorf("unexpected tools %s", got)
+	}
+	tools = listAll("tools/list", `{"services":["calendar"]}`)
+	if got := fmt.Sprint(tools); got != "[calendar_tool_0 calendar_tool_1]" {
+		t.Errorf("unexpected calendar tools %s", got)
+	}
+
+	// The filter of the first page applies to the following ones
+	first := list("resources/list", `{"services":["gmail"]}`)
+	if len(first.Result.Resources) != 2 || first.Result.NextCursor == "" {
+		t.Fatalf("expected a first page of 2 gmail resources and a cursor, got %+v", first.Result)
+	}
+	rest := list("resources/list", `{"cursor":"`+first.Result.NextCursor+`"}`)
+	if len(rest.Result.Resources) != 1 || rest.Result.Resources[0].URI != "gmail://item/2" || rest.Result.NextCursor != "" {
+		t.Errorf("expected the last gmail resource on the second page, got %+v", rest.Result)
+	}
+
+	if result := list("tools/list", `{"cursor":"bogus"}`); result.Error == nil || result.Error.Code != -32602 {
+		t.Errorf("expected an invalid cursor to be rejected with -32602, got %+v", result.Error)
+	}
+}
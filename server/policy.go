@@ -0,0 +1,170 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"go.ngs.io/google-mcp-server/config"
+)
+
+// PolicyToolName is the tool reporting the tool policy. It is offered
+// whatever the policy says, so that a restricted client can still find out
+// why the tools it expects are missing.
+const PolicyToolName = "server_tool_policy"
+
+// toolPolicy is the tool policy of the configuration: the global policy and
+// the policy of each service section
+type toolPolicy struct {
+	global   config.ToolPolicy
+	services config.ServicesConfig
+}
+
+// check reports why a tool of service is withheld, or "" when it is offered
+func (p toolPolicy) check(service string, tool Tool) string {
+	if tool.Name == PolicyToolName {
+		return ""
+	}
+	readOnly := tool.Annotations.IsReadOnly()
+	if reason := p.global.Check(tool.Name, readOnly); reason != "" {
+		return reason
+	}
+	if reason := p.services.Policy(service).Check(tool.Name, readOnly); reason != "" {
+		return service + " policy: " + reason
+	}
+	return ""
+}
+
+// withheldTool is a tool of a registered service that the policy withholds
+type withheldTool struct {
+	service string
+	reason  string
+}
+
+// SetToolPolicy replaces the tool policy with the one of cfg, updating the
+// tools offered by the registered services
+func (s *MCPServer) SetToolPolicy(cfg *config.Config) {
+	s.mu.Lock()
+	s.policy = toolPolicy{global: cfg.Global.ToolPolicy, services: cfg.Services}
+	changed := s.rebuildLocked()
+	s.mu.Unlock()
+
+	if changed {
+		s.notifyToolsChanged()
+	}
+}
+
+// policyError builds the error returned for a call of a withheld tool
+func policyError(name string, withheld withheldTool) error {
+	return NewToolError(CategoryDenied, fmt.Errorf("tool %s is not allowed by the tool policy (%s); call %s for details", name, withheld.reason, PolicyToolName))
+}
+
+// PolicyService offers the tool reporting the effective tool policy
+type PolicyService struct {
+	server *MCPServer
+}
+
+// NewPolicyService creates the service reporting the tool policy of srv
+func NewPolicyService(srv *MCPServer) *PolicyService {
+	return &PolicyService{server: srv}
+}
+
+type policyArgs struct {
+	Service string `json:"service,omitempty" description:"Only report the tools of this service (optional)"`
+}
+
+// ToolDefinitions declares the policy tool together with its handler
+func (p *PolicyService) ToolDefinitions() ToolSet {
+	return ToolSet{
+		NewTool(Tool{
+			Name:        PolicyToolName,
+			Description: "Show the tool policy in effect: read-only mode, the allowed and denied tool patterns, and which tools are offered or withheld and why",
+			Annotations: ReadOnlyAnnotations().Local(),
+		}, p.handlePolicy),
+	}
+}
+
+// GetTools returns the policy tool
+func (p *PolicyService) GetTools() []Tool {
+	return p.ToolDefinitions().Tools()
+}
+
+// GetResources returns no resources
+func (p *PolicyService) GetResources() []Resource {
+	return nil
+}
+
+// HandleToolCall handles a tool call
+func (p *PolicyService) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
+	return p.ToolDefinitions().Call(ctx, name, arguments)
+}
+
+// HandleResourceCall handles a resource call
+func (p *PolicyService) HandleResourceCall(ctx context.Context, uri string) (interface{}, error) {
+	return nil, fmt.Errorf("unknown resource: %s", uri)
+}
+
+// servicePolicyReport is the policy of one registered service and its
+// effect on the service's tools
+type servicePolicyReport struct {
+	Service  string             `json:"service"`
+	Policy   config.ToolPolicy  `json:"policy"`
+	Offered  []string           `json:"offered"`
+	Withheld []withheldToolInfo `json:"withheld,omitempty"`
+}
+
+type withheldToolInfo struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+func (p *PolicyService) handlePolicy(ctx context.Context, args policyArgs) (interface{}, error) {
+	s := p.server
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reports := make(map[string]*servicePolicyReport)
+	var services []string
+	report := func(service string) *servicePolicyReport {
+		r, ok := reports[service]
+		if !ok {
+			r = &servicePolicyReport{Service: service, Policy: s.policy.services.Policy(service), Offered: []string{}}
+			reports[service] = r
+			services = append(services, service)
+		}
+		return r
+	}
+
+	for _, service := range s.serviceOrder {
+		if args.Service == "" || args.Service == service {
+			report(service)
+		}
+	}
+	if args.Service != "" && len(services) == 0 {
+		return nil, fmt.Errorf("service %q is not registered", args.Service)
+	}
+
+	for _, tool := range s.tools {
+		entry := s.toolMap[tool.Name]
+		if r, ok := reports[entry.service]; ok {
+			r.Offered = append(r.Offered, tool.Name)
+		}
+	}
+	for name, withheld := range s.withheldTools {
+		if r, ok := reports[withheld.service]; ok {
+			r.Withheld = append(r.Withheld, withheldToolInfo{Name: name, Reason: withheld.reason})
+		}
+	}
+
+	result := struct {
+		Global   config.ToolPolicy      `json:"global"`
+		Services []*servicePolicyReport `json:"services"`
+	}{Global: s.policy.global}
+	for _, service := range services {
+		r := reports[service]
+		sort.Slice(r.Withheld, func(i, j int) bool { return r.Withheld[i].Name < r.Withheld[j].Name })
+		result.Services = append(result.Services, r)
+	}
+	return result, nil
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go.ngs.io/google-mcp-server/config"
+	"go.ngs.io/google-mcp-server/internal/mcptest"
+)
+
+func toolNames(tools []Tool) string {
+	names := make([]string, len(tools))
+	for i, tool := range tools {
+		names[i] = tool.Name
+	}
+	return strings.Join(names, ",")
+}
+
+func TestToolPolicyFiltersTools(t *testing.T) {
+	cfg := &config.Config{Global: config.GlobalConfig{ToolPolicy: config.ToolPolicy{DenyTools: []string{"*_fail"}}}}
+	srv := NewMCPServer(cfg)
+	srv.RegisterService("stub", readOnlyStubService{})
+	srv.RegisterService("server", NewPolicyService(srv))
+
+	if got := toolNames(srv.tools); got != "stub_echo,stub_read,server_tool_policy" {
+		t.Errorf("expected stub_fail to be withheld, got %s", got)
+	}
+
+	// A service can be made read-only on its own; the policy tool stays
+	// available whatever the policy says
+	cfg.Services.Accounts.ReadOnly = true
+	cfg.Global.AllowTools = []string{"stub_*"}
+	srv.SetToolPolicy(cfg)
+	if got := toolNames(srv.tools); got != "stub_echo,stub_read,server_tool_policy" {
+		t.Errorf("expected the accounts policy not to affect stub, got %s", got)
+	}
+
+	cfg.Global.ReadOnly = true
+	srv.SetToolPolicy(cfg)
+	if got := toolNames(srv.tools); got != "stub_read,server_tool_policy" {
+		t.Errorf("expected only read-only tools, got %s", got)
+	}
+	if withheld := srv.withheldTools["stub_echo"]; withheld.service != "stub" || !strings.Contains(withheld.reason, "read-only") {
+		t.Errorf("expected stub_echo to be withheld by read-only mode, got %+v", withheld)
+	}
+}
+
+func TestWithheldToolCallsAreRefused(t *testing.T) {
+	srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{
+		Transport:  config.TransportHTTP,
+		ToolPolicy: config.ToolPolicy{ReadOnly: true},
+	}})
+	srv.RegisterService("stub", readOnlyStubService{})
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+	sessionID := initializeSession(t, endpoint)
+
+	resp := mcptest.Post(t, endpoint, sessionID, "application/json",
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"stub_echo","arguments":{}}}`)
+	defer func() { _ = resp.Body.Close() }()
+	var msg struct {
+		Result struct {
+			Content []Content `json:"content"`
+			IsError bool      `json:"isError"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+		t.Fatalf("failed to decode tools/call response: %v", err)
+	}
+
+	var body struct {
+		Error toolErrorDetail `json:"error"`
+	}
+	if !msg.Result.IsError || json.Unmarshal([]byte(msg.Result.Content[0].Text), &body) != nil {
+		t.Fatalf("expected an error result, got %+v", msg.Result)
+	}
+	if body.Error.Category != CategoryDenied || !strings.Contains(body.Error.Message, PolicyToolName) {
+		t.Errorf("expected the call to be denied by policy, got %+v", body.Error)
+	}
+}
+
+func TestPolicyToolReportsWithheldTools(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Services.Gmail.AllowTools = []string{"stub_read"}
+	srv := NewMCPServer(cfg)
+	srv.RegisterService("gmail", readOnlyStubService{})
+	policy := NewPolicyService(srv)
+	srv.RegisterService("server", policy)
+
+	result, err := policy.HandleToolCall(context.Background(), PolicyToolName, json.RawMessage(`{"service":"gmail"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(result)
+	var report struct {
+		Services []struct {
+			Service  string   `json:"service"`
+			Offered  []string `json:"offered"`
+			Withheld []struct {
+				Name   string `json:"name"`
+				Reason string `json:"reason"`
+			} `json:"withheld"`
+		} `json:"services"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Services) != 1 || report.Services[0].Service != "gmail" {
+		t.Fatalf("expected a report of gmail only, got %s", data)
+	}
+	gmail := report.Services[0]
+	if strings.Join(gmail.Offered, ",") != "stub_read" || len(gmail.Withheld) != 2 || gmail.Withheld[0].Name != "stub_echo" ||
+		!strings.Contains(gmail.Withheld[0].Reason, "gmail policy") {
+		t.Errorf("unexpected report %s", data)
+	}
+
+	if _, err := policy.HandleToolCall(context.Background(), PolicyToolName, json.RawMessage(`{"service":"drive"}`)); err == nil {
+		t.Error("expected an error for a service that is not registered")
+	}
+}
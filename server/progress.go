@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// progressParams are the params of a notifications/progress notification
+type progressParams struct {
+	ProgressToken json.RawMessage `json:"progressToken"`
+	Progress      float64         `json:"progress"`
+	Total         float64         `json:"total,omitempty"`
+	Message       string          `json:"message,omitempty"`
+}
+
+// requestMeta is the _meta object clients may attach to request params
+type requestMeta struct {
+	ProgressToken json.RawMessage `json:"progressToken,omitempty"`
+}
+
+// progressReporter sends progress notifications for one request
+type progressReporter struct {
+	conn  *jsonrpc2.Conn
+	token json.RawMessage
+
+	mu   sync.Mutex
+	last float64
+	sent bool
+}
+
+// progressContextKey is the context key for the progress reporter of a request
+type progressContextKey struct{}
+
+// withProgress returns a context through which handlers report progress.
+// Without a token the client did not ask for progress and ctx is returned as is.
+func withProgress(ctx context.Context, conn *jsonrpc2.Conn, meta *requestMeta) context.Context {
+	if meta == nil || len(meta.ProgressToken) == 0 || string(meta.ProgressToken) == "null" {
+		return ctx
+	}
+	return context.WithValue(ctx, progressContextKey{}, &progressReporter{conn: conn, token: meta.ProgressToken})
+}
+
+// ReportProgress notifies the client how far the current request has got.
+// total is omitted when it is zero or less, e.g. when the size of an upload is
+// unknown. It does nothing unless the client sent a progress token with the
+// request, and updates that do not increase progress are dropped, so callers
+// working in parallel may report out of order.
+func ReportProgress(ctx context.Context, progress, total float64, message string) {
+	reporter, ok := ctx.Value(progressContextKey{}).(*progressReporter)
+	if !ok || ctx.Err() != nil {
+		return
+	}
+
+	reporter.mu.Lock()
+	defer reporter.mu.Unlock()
+	if reporter.sent && progress <= reporter.last {
+		return
+	}
+	reporter.last = progress
+	reporter.sent = true
+
+	params := progressParams{
+		ProgressToken: reporter.token,
+		Progress:      progress,
+		Message:       message,
+	}
+	if total > 0 {
+		params.Total = total
+	}
+	if session, ok := SessionFromContext(ctx); ok && !session.Supports(FeatureProgressMessages) {
+		params.Message = ""
+	}
+	// Sent while holding the lock so notifications leave in the order checked
+	if err := reporter.conn.Notify(ctx, "notifications/progress", params); err != nil {
+		logger.DebugContext(ctx, "failed to send progress notification", "error", err)
+	}
+}
+
+// ProgressCounter reports the progress of a known number of steps, such as
+// one search per account, that may complete concurrently
+type ProgressCounter struct {
+	ctx   context.Context
+	total int
+	done  atomic.Int64
+}
+
+// NewProgressCounter returns a counter for total steps of the request in ctx
+func NewProgressCounter(ctx context.Context, total int) *ProgressCounter {
+	return &ProgressCounter{ctx: ctx, total: total}
+}
+
+// Step marks one step as complete and reports it with message
+func (p *ProgressCounter) Step(message string) {
+	done := p.done.Add(1)
+	ReportProgress(p.ctx, float64(done), float64(p.total), message)
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go.ngs.io/google-mcp-server/config"
+	"go.ngs.io/google-mcp-server/internal/mcptest"
+)
+
+// progressService has a tool that reports progress in steps, including a
+// repeated step that must not be sent twice
+type progressService struct {
+	stubService
+}
+
+func (progressService) GetTools() []Tool {
+	return []Tool{{Name: "stub_progress", Description: "Reports progress", InputSchema: InputSchema{Type: "object"}}}
+}
+
+func (progressService) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
+	ReportProgress(ctx, 1, 2, "first half")
+	ReportProgress(ctx, 1, 2, "first half again")
+	counter := NewProgressCounter(ctx, 2)
+	counter.Step("one")
+	counter.Step("two")
+	return "done", nil
+}
+
+// collectProgress calls stub_progress over SSE with the given params and
+// returns the progress notifications received before the response
+func collectProgress(t *testing.T, endpoint, sessionID, params string) []progressParams {
+	t.Helper()
+	resp := mcptest.Post(t, endpoint, sessionID, "application/json, text/event-stream",
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":`+params+`}`)
+	defer func() { _ = resp.Body.Close() }()
+
+	var notifications []progressParams
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		var msg struct {
+			ID     *json.RawMessage `json:"id"`
+			Method string           `json:"method"`
+			Params progressParams   `json:"params"`
+		}
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &msg); err != nil {
+			t.Fatalf("failed to decode SSE event: %v", err)
+		}
+		if msg.Method == "notifications/progress" {
+			notifications = append(notifications, msg.Params)
+		}
+		if msg.ID != nil {
+			break
+		}
+	}
+	return notifications
+}
+
+func TestProgressNotifications(t *testing.T) {
+	srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP}})
+	srv.RegisterService("stub", progressService{})
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+	sessionID := initializeSession(t, endpoint)
+
+	// Without a token the client did not ask for progress
+	if got := collectProgress(t, endpoint, sessionID, `{"name":"stub_progress","arguments":{}}`); len(got) != 0 {
+		t.Fatalf("expected no progress without a token, got %+v", got)
+	}
+
+	got := collectProgress(t, endpoint, sessionID, `{"name":"stub_progress","arguments":{},"_meta":{"progressToken":"upload-1"}}`)
+	if len(got) != 2 {
+		t.Fatalf("expected two progress notifications, got %+v", got)
+	}
+	if string(got[0].ProgressToken) != `"upload-1"` || got[0].Progress != 1 || got[0].Total != 2 || got[0].Message != "first half" {
+		t.Errorf("unexpected first notification: %+v", got[0])
+	}
+	if got[1].Progress != 2 || got[1].Message != "two" {
+		t.Errorf("expected progress to increase to 2, got %+v", got[1])
+	}
+
+	// Numeric tokens are echoed unchanged
+	got = collectProgress(t, endpoint, sessionID, `{"name":"stub_progress","arguments":{},"_meta":{"progressToken":42}}`)
+	if len(got) == 0 || string(got[0].ProgressToken) != "42" {
+		t.Errorf("expected the numeric token to be echoed, got %+v", got)
+	}
+}
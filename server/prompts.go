@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"go.ngs.io/google-mcp-server/config"
+)
+
+// Prompt describes a prompt template offered to clients
+type Prompt struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+}
+
+// PromptArgument describes an argument accepted by a prompt
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// PromptMessage is a single message in a rendered prompt
+type PromptMessage struct {
+	Role    string        `json:"role"`
+	Content PromptContent `json:"content"`
+}
+
+// PromptContent is the content of a prompt message
+type PromptContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// PromptResult is the result of a prompts/get request
+type PromptResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []PromptMessage `json:"messages"`
+}
+
+// PromptProvider is implemented by services that contribute prompt templates.
+// It is optional; RegisterService picks it up when a ServiceHandler implements it.
+type PromptProvider interface {
+	GetPrompts() []Prompt
+	GetPrompt(ctx context.Context, name string, arguments map[string]string) (*PromptResult, error)
+}
+
+// UserPrompt builds a single-message prompt result spoken by the user
+func UserPrompt(description, text string) *PromptResult {
+	return &PromptResult{
+		Description: description,
+		Messages: []PromptMessage{
+			{
+				Role:    "user",
+				Content: PromptContent{Type: "text", Text: text},
+			},
+		},
+	}
+}
+
+// missingPromptArguments returns the required arguments absent from arguments
+func missingPromptArguments(prompt Prompt, arguments map[string]string) []string {
+	var missing []string
+	for _, arg := range prompt.Arguments {
+		if arg.Required && strings.TrimSpace(arguments[arg.Name]) == "" {
+			missing = append(missing, arg.Name)
+		}
+	}
+	return missing
+}
+
+// configPromptProvider serves the prompts users define in the config file.
+// Templates use Go text/template syntax, e.g. "Summarize {{.topic}}".
+type configPromptProvider struct {
+	prompts   []Prompt
+	templates map[string]*template.Template
+}
+
+// newConfigPromptProvider parses the prompt templates from the configuration
+func newConfigPromptProvider(defs []config.PromptConfig) (*configPromptProvider, error) {
+	p := &configPromptProvider{
+		templates: make(map[string]*template.Template),
+	}
+
+	for _, def := range defs {
+		tmpl, err := template.New(def.Name).Option("missingkey=zero").Parse(def.Template)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template for prompt %q: %w", def.Name, err)
+		}
+
+		prompt := Prompt{
+			Name:        def.Name,
+			Description: def.Description,
+		}
+		for _, arg := range def.Arguments {
+			prompt.Arguments = append(prompt.Arguments, PromptArgument{
+				Name:        arg.Name,
+				Description: arg.Description,
+				Required:    arg.Required,
+			})
+		}
+
+		p.prompts = append(p.prompts, prompt)
+		p.templates[def.Name] = tmpl
+	}
+
+	return p, nil
+}
+
+// GetPrompts returns the configured prompts
+func (p *configPromptProvider) GetPrompts() []Prompt {
+	return p.prompts
+}
+
+// GetPrompt renders a configured prompt with the given arguments
+func (p *configPromptProvider) GetPrompt(ctx context.Context, name string, arguments map[string]string) (*PromptResult, error) {
+	tmpl, ok := p.templates[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown prompt: %s", name)
+	}
+
+	data := make(map[string]string, len(arguments))
+	for k, v := range arguments {
+		data[k] = v
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return nil, fmt.Errorf("failed to render prompt %q: %w", name, err)
+	}
+
+	var description string
+	for _, prompt := range p.prompts {
+		if prompt.Name == name {
+			description = prompt.Description
+			break
+		}
+	}
+
+	return UserPrompt(description, sb.String()), nil
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go.ngs.io/google-mcp-server/config"
+	"go.ngs.io/google-mcp-server/internal/mcptest"
+)
+
+// stubPromptService is a ServiceHandler that also contributes prompts
+type stubPromptService struct {
+	stubService
+}
+
+func (stubPromptService) GetPrompts() []Prompt {
+	return []Prompt{
+		{Name: "stub_greet", Arguments: []PromptArgument{{Name: "who", Required: true}}},
+		{Name: "weekly_review", Description: "service version"},
+	}
+}
+
+func (stubPromptService) GetPrompt(ctx context.Context, name string, arguments map[string]string) (*PromptResult, error) {
+	return UserPrompt("", "hello "+arguments["who"]), nil
+}
+
+func TestConfigPromptProvider(t *testing.T) {
+	provider, err := newConfigPromptProvider([]config.PromptConfig{
+		{
+			Name:        "weekly_review",
+			Description: "Review my week",
+			Arguments:   []config.PromptArgumentConfig{{Name: "focus", Required: true}},
+			Template:    "Review my week with a focus on {{.focus}}.{{if .extra}} Also {{.extra}}.{{end}}",
+		},
+	})
+	if err != nil {
+		t.Fatalf("newConfigPromptProvider failed: %v", err)
+	}
+
+	prompts := provider.GetPrompts()
+	if len(prompts) != 1 || prompts[0].Name != "weekly_review" || !prompts[0].Arguments[0].Required {
+		t.Fatalf("unexpected prompts: %+v", prompts)
+	}
+
+	result, err := provider.GetPrompt(context.Background(), "weekly_review", map[string]string{"focus": "hiring"})
+	if err != nil {
+		t.Fatalf("GetPrompt failed: %v", err)
+	}
+	if len(result.Messages) != 1 || result.Messages[0].Role != "user" {
+		t.Fatalf("unexpected messages: %+v", result.Messages)
+	}
+	if text := result.Messages[0].Content.Text; text != "Review my week with a focus on hiring." {
+		t.Errorf("unexpected rendered text: %q", text)
+	}
+	if result.Description != "Review my week" {
+		t.Errorf("unexpected description: %q", result.Description)
+	}
+
+	if _, err := provider.GetPrompt(context.Background(), "missing", nil); err == nil {
+		t.Error("expected error for unknown prompt")
+	}
+}
+
+func TestPromptsListAndGet(t *testing.T) {
+	srv := NewMCPServer(&config.Config{
+		Prompts: []config.PromptConfig{{Name: "weekly_review", Description: "user version", Template: "Review"}},
+	})
+	srv.RegisterService("stub", stubPromptService{})
+
+	// Config prompts win over service prompts with the same name
+	if len(srv.prompts) != 2 {
+		t.Fatalf("expected 2 prompts, got %+v", srv.prompts)
+	}
+	if _, userDefined := srv.promptMap["weekly_review"].(*configPromptProvider); !userDefined {
+		t.Error("expected config prompt to take precedence")
+	}
+
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+	sessionID := initializeSession(t, endpoint)
+
+	resp := mcptest.Post(t, endpoint, sessionID, "application/json", `{"jsonrpc":"2.0","id":2,"method":"prompts/get","params":{"name":"stub_greet","arguments":{"who":"world"}}}`)
+	var got struct {
+		Result PromptResult `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode prompts/get: %v", err)
+	}
+	_ = resp.Body.Close()
+	if len(got.Result.Messages) != 1 || got.Result.Messages[0].Content.Text != "hello world" {
+		t.Errorf("unexpected prompt result: %+v", got.Result)
+	}
+
+	// Missing required arguments are a protocol error
+	resp = mcptest.Post(t, endpoint, sessionID, "application/json", `{"jsonrpc":"2.0","id":3,"method":"prompts/get","params":{"name":"stub_greet"}}`)
+	var failed struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&failed); err != nil {
+		t.Fatalf("failed to decode prompts/get error: %v", err)
+	}
+	_ = resp.Body.Close()
+	if !strings.Contains(failed.Error.Message, "who") {
+		t.Errorf("expected missing argument error, got %q", failed.Error.Message)
+	}
+}
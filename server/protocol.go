@@ -0,0 +1,122 @@
+package server
+
+import (
+	"net/http"
+)
+
+// Protocol versions supported by the server, newest first. Versions are dates,
+// so they order correctly as strings.
+const (
+	ProtocolVersion20250618 = "2025-06-18"
+	ProtocolVersion20250326 = "2025-03-26"
+	ProtocolVersion20241105 = "2024-11-05"
+
+	// LatestProtocolVersion is offered to clients requesting a version the
+	// server does not support
+	LatestProtocolVersion = ProtocolVersion20250618
+)
+
+var supportedProtocolVersions = []string{
+	ProtocolVersion20250618,
+	ProtocolVersion20250326,
+	ProtocolVersion20241105,
+}
+
+// protocolVersionHeader is sent by Streamable HTTP clients on every request
+// after initialization (required since 2025-06-18)
+const protocolVersionHeader = "Mcp-Protocol-Version"
+
+// negotiateProtocolVersion returns the version requested by the client if the
+// server supports it, and the latest supported version otherwise. The client
+// disconnects if it cannot use the version offered.
+func negotiateProtocolVersion(requested string) string {
+	if isSupportedProtocolVersion(requested) {
+		return requested
+	}
+	return LatestProtocolVersion
+}
+
+func isSupportedProtocolVersion(version string) bool {
+	for _, v := range supportedProtocolVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// Feature is a protocol feature whose use depends on the negotiated version
+// and, for client features, on the capabilities the client declared
+type Feature int
+
+const (
+	// FeatureToolAnnotations covers the annotations field of tools
+	FeatureToolAnnotations Feature = iota
+	// FeatureProgressMessages covers the message field of progress notifications
+	FeatureProgressMessages
+	// FeatureCompletions covers the completions server capability
+	FeatureCompletions
+	// FeatureStructuredOutput covers tool output schemas and structuredContent
+	FeatureStructuredOutput
+	// FeatureElicitation covers elicitation/create requests to the client
+	FeatureElicitation
+)
+
+// featureVersions is the protocol version that introduced each feature
+var featureVersions = map[Feature]string{
+	FeatureToolAnnotations:  ProtocolVersion20250326,
+	FeatureProgressMessages: ProtocolVersion20250326,
+	FeatureCompletions:      ProtocolVersion20250326,
+	FeatureStructuredOutput: ProtocolVersion20250618,
+	FeatureElicitation:      ProtocolVersion20250618,
+}
+
+// ClientInfo identifies the client implementation of a session
+type ClientInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// ClientCapabilities are the capabilities a client declares in initialize.
+// A capability is declared when its field is non-nil.
+type ClientCapabilities struct {
+	Roots *struct {
+		ListChanged bool `json:"listChanged,omitempty"`
+	} `json:"roots,omitempty"`
+	Sampling    *struct{} `json:"sampling,omitempty"`
+	Elicitation *struct{} `json:"elicitation,omitempty"`
+}
+
+// toolsForSession returns tools without the fields the negotiated protocol
+// version does not define, so older clients see the schema they expect
+func toolsForSession(session *Session, tools []Tool) []Tool {
+	annotations := session.Supports(FeatureToolAnnotations)
+	structured := session.Supports(FeatureStructuredOutput)
+	if annotations && structured {
+		return tools
+	}
+
+	adapted := make([]Tool, len(tools))
+	for i, tool := range tools {
+		if !annotations {
+			tool.Annotations = nil
+		}
+		if !structured {
+			tool.OutputSchema = nil
+		}
+		adapted[i] = tool
+	}
+	return adapted
+}
+
+// checkProtocolHeader rejects HTTP requests announcing a protocol version the
+// server does not support. Requests without the header are accepted, as
+// clients older than 2025-06-18 do not send it.
+func checkProtocolHeader(w http.ResponseWriter, r *http.Request) bool {
+	version := r.Header.Get(protocolVersionHeader)
+	if version == "" || isSupportedProtocolVersion(version) {
+		return true
+	}
+	http.Error(w, "unsupported protocol version "+version, http.StatusBadRequest)
+	return false
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.ngs.io/google-mcp-server/config"
+	"go.ngs.io/google-mcp-server/internal/mcptest"
+)
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	tests := []struct {
+		requested string
+		expected  string
+	}{
+		{requested: "2024-11-05", expected: "2024-11-05"},
+		{requested: "2025-03-26", expected: "2025-03-26"},
+		{requested: "2025-06-18", expected: "2025-06-18"},
+		{requested: "2099-01-01", expected: LatestProtocolVersion},
+		{requested: "", expected: LatestProtocolVersion},
+	}
+
+	for _, tt := range tests {
+		if got := negotiateProtocolVersion(tt.requested); got != tt.expected {
+			t.Errorf("negotiateProtocolVersion(%q) = %q, want %q", tt.requested, got, tt.expected)
+		}
+	}
+}
+
+func TestSessionSupports(t *testing.T) {
+	elicitation := ClientCapabilities{Elicitation: &struct{}{}}
+
+	tests := []struct {
+		name         string
+		version      string
+		capabilities ClientCapabilities
+		feature      Feature
+		expected     bool
+	}{
+		{name: "not initialized", feature: FeatureToolAnnotations, expected: false},
+		{name: "annotations on 2024-11-05", version: ProtocolVersion20241105, feature: FeatureToolAnnotations, expected: false},
+		{name: "annotations on 2025-03-26", version: ProtocolVersion20250326, feature: FeatureToolAnnotations, expected: true},
+		{name: "structured output on 2025-03-26", version: ProtocolVersion20250326, feature: FeatureStructuredOutput, expected: false},
+		{name: "structured output on 2025-06-18", version: ProtocolVersion20250618, feature: FeatureStructuredOutput, expected: true},
+		{name: "elicitation without capability", version: ProtocolVersion20250618, feature: FeatureElicitation, expected: false},
+		{name: "elicitation with capability", version: ProtocolVersion20250618, capabilities: elicitation, feature: FeatureElicitation, expected: true},
+		{name: "elicitation on an older version", version: ProtocolVersion20250326, capabilities: elicitation, feature: FeatureElicitation, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := &Session{}
+			if tt.version != "" {
+				session.initialize(tt.version, ClientInfo{Name: "test"}, tt.capabilities)
+			}
+			if got := session.Supports(tt.feature); got != tt.expected {
+				t.Errorf("Supports() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// sessionService has a tool that reports what it finds out about its session
+type sessionService struct {
+	stubService
+}
+
+func (sessionService) GetTools() []Tool {
+	return []Tool{{
+		Name:         "stub_session",
+		Description:  "Describes the session",
+		InputSchema:  InputSchema{Type: "object"},
+		OutputSchema: &OutputSchema{Type: "object"},
+		Annotations:  ReadOnlyAnnotations(),
+	}}
+}
+
+func (sessionService) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
+	session, ok := SessionFromContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+	return map[string]interface{}{
+		"version":     session.ProtocolVersion(),
+		"client":      session.ClientInfo().Name,
+		"elicitation": session.Supports(FeatureElicitation),
+	}, nil
+}
+
+func TestProtocolNegotiation(t *testing.T) {
+	srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP}})
+	srv.RegisterService("stub", sessionService{})
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+
+	// An unsupported version is answered with the latest one
+	resp := mcptest.Post(t, endpoint, "", "application/json",
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2099-01-01","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`)
+	var initResult struct {
+		Result struct {
+			ProtocolVersion string                     `json:"protocolVersion"`
+			Capabilities    map[string]json.RawMessage `json:"capabilities"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&initResult); err != nil {
+		t.Fatalf("failed to decode initialize response: %v", err)
+	}
+	_ = resp.Body.Close()
+	if initResult.Result.ProtocolVersion != LatestProtocolVersion {
+		t.Errorf("expected %s to be offered, got %s", LatestProtocolVersion, initResult.Result.ProtocolVersion)
+	}
+	if _, ok := initResult.Result.Capabilities["completions"]; !ok {
+		t.Error("expected the completions capability on the latest version")
+	}
+
+	callTool := func(sessionID string) (tools string, result map[string]json.RawMessage) {
+		t.Helper()
+		resp := mcptest.Post(t, endpoint, sessionID, "application/json", `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+		var list struct {
+			Result json.RawMessage `json:"result"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+			t.Fatalf("failed to decode tools/list response: %v", err)
+		}
+		_ = resp.Body.Close()
+
+		resp = mcptest.Post(t, endpoint, sessionID, "application/json",
+			`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"stub_session","arguments":{}}}`)
+		var call struct {
+			Result map[string]json.RawMessage `json:"result"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&call); err != nil {
+			t.Fatalf("failed to decode tools/call response: %v", err)
+		}
+		_ = resp.Body.Close()
+		return string(list.Result), call.Result
+	}
+
+	// Clients on the first protocol version see none of the newer fields
+	oldSession := mcptest.Initialize(t, endpoint, ProtocolVersion20241105, `{}`)
+	tools, result := callTool(oldSession)
+	if strings.Contains(tools, "annotations") || strings.Contains(tools, "outputSchema") {
+		t.Errorf("expected tools without annotations or output schema, got %s", tools)
+	}
+	if _, ok := result["structuredContent"]; ok {
+		t.Errorf("expected no structuredContent on %s, got %s", ProtocolVersion20241105, result["structuredContent"])
+	}
+
+	// The latest version gets them, and handlers see the negotiated state
+	newSession := mcptest.Initialize(t, endpoint, ProtocolVersion20250618, `{"elicitation":{}}`)
+	tools, result = callTool(newSession)
+	if !strings.Contains(tools, "annotations") || !strings.Contains(tools, "outputSchema") {
+		t.Errorf("expected tools with annotations and output schema, got %s", tools)
+	}
+	var state struct {
+		Version     string `json:"version"`
+		Client      string `json:"client"`
+		Elicitation bool   `json:"elicitation"`
+	}
+	if err := json.Unmarshal(result["structuredContent"], &state); err != nil {
+		t.Fatalf("expected structuredContent, got %s: %v", result["structuredContent"], err)
+	}
+	if state.Version != ProtocolVersion20250618 || state.Client != "test" || !state.Elicitation {
+		t.Errorf("unexpected session state seen by the handler: %+v", state)
+	}
+
+	// Requests announcing an unsupported version are rejected
+	req, _ := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(`{"jsonrpc":"2.0","id":4,"method":"tools/list"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(sessionHeader, newSession)
+	req.Header.Set(protocolVersionHeader, "1999-01-01")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unsupported protocol version header, got %d", resp.StatusCode)
+	}
+}
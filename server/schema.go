@@ -0,0 +1,351 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Formats of string properties that are checked during validation. Other
+// formats are passed on to the client as hints only.
+const (
+	FormatDateTime = "date-time" // RFC 3339, e.g. 2024-01-15T09:00:00Z
+	FormatDate     = "date"      // e.g. 2024-01-15
+	FormatEmail    = "email"
+	FormatURI      = "uri"
+)
+
+// Property represents a property in the input schema. It covers the part of
+// JSON Schema the tools need, and validateArguments enforces all of it
+// before a call is dispatched.
+type Property struct {
+	Type        string              `json:"type,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Items       *Property           `json:"items,omitempty"`
+	Enum        []string            `json:"enum,omitempty"`
+	Properties  map[string]Property `json:"properties,omitempty"` // fields of an object
+	Required    []string            `json:"required,omitempty"`   // required fields of an object
+	OneOf       []Property          `json:"oneOf,omitempty"`
+	Default     interface{}         `json:"default,omitempty"`
+	Format      string              `json:"format,omitempty"`
+	Pattern     string              `json:"pattern,omitempty"`
+
+	// Bounds of numbers; use Bound to set them
+	Minimum          *float64 `json:"minimum,omitempty"`
+	Maximum          *float64 `json:"maximum,omitempty"`
+	ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+
+	// Bounds of string lengths and array sizes; zero means unbounded
+	MinLength int `json:"minLength,omitempty"`
+	MaxLength int `json:"maxLength,omitempty"`
+	MinItems  int `json:"minItems,omitempty"`
+	MaxItems  int `json:"maxItems,omitempty"`
+}
+
+// Bound returns a pointer to v for the numeric bounds of a Property
+func Bound(v float64) *float64 {
+	return &v
+}
+
+// FieldError describes an argument that does not match the tool's schema
+type FieldError struct {
+	Field   string `json:"field"` // path of the argument, e.g. values[2][0]
+	Message string `json:"message"`
+}
+
+// ValidationError lists every argument of a call that does not match the
+// tool's input schema
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		if field.Field == "" {
+			problems[i] = field.Message
+			continue
+		}
+		problems[i] = field.Field + " " + field.Message
+	}
+	return "invalid arguments: " + strings.Join(problems, "; ")
+}
+
+// validateArguments checks the arguments of a call against the input schema
+// of the tool and fills in the defaults of missing properties. It returns the
+// arguments to pass to the handler, unchanged unless a default was applied.
+func validateArguments(schema InputSchema, arguments json.RawMessage) (json.RawMessage, error) {
+	if len(bytes.TrimSpace(arguments)) == 0 || bytes.Equal(bytes.TrimSpace(arguments), []byte("null")) {
+		arguments = json.RawMessage("{}")
+	}
+
+	// Numbers are kept as written so re-encoding cannot change them
+	decoder := json.NewDecoder(bytes.NewReader(arguments))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, &ValidationError{Fields: []FieldError{{Message: "must be a JSON object"}}}
+	}
+
+	root := Property{Type: "object", Properties: schema.Properties, Required: schema.Required}
+	v := validator{}
+	value, changed := v.validate("", root, value)
+	if len(v.errors) > 0 {
+		return nil, &ValidationError{Fields: v.errors}
+	}
+	if !changed {
+		return arguments, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply argument defaults: %w", err)
+	}
+	return data, nil
+}
+
+// validator collects the field errors of one value
+type validator struct {
+	errors []FieldError
+}
+
+func (v *validator) fail(path, format string, args ...interface{}) {
+	v.errors = append(v.errors, FieldError{Field: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// validate checks value against prop and returns it with defaults applied,
+// and whether any were
+func (v *validator) validate(path string, prop Property, value interface{}) (interface{}, bool) {
+	if len(prop.OneOf) > 0 {
+		return v.validateOneOf(path, prop, value)
+	}
+
+	switch prop.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			v.fail(path, "must be an object")
+			return value, false
+		}
+		return v.validateObject(path, prop, object)
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			v.fail(path, "must be an array")
+			return value, false
+		}
+		return v.validateArray(path, prop, array)
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			v.fail(path, "must be a string")
+			return value, false
+		}
+		v.validateString(path, prop, s)
+	case "number", "integer":
+		n, ok := value.(json.Number)
+		if !ok && prop.Type == "integer" {
+			v.fail(path, "must be an integer")
+			return value, false
+		}
+		if !ok {
+			v.fail(path, "must be a number")
+			return value, false
+		}
+		v.validateNumber(path, prop, n)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.fail(path, "must be a boolean")
+		}
+	case "null":
+		if value != nil {
+			v.fail(path, "must be null")
+		}
+	}
+	return value, false
+}
+
+// validateObject checks the fields of an object. Defaults go into a copy, so
+// an alternative of oneOf that does not match leaves the value untouched.
+func (v *validator) validateObject(path string, prop Property, object map[string]interface{}) (interface{}, bool) {
+	changed := false
+	set := func(name string, value interface{}) {
+		if !changed {
+			copied := make(map[string]interface{}, len(object)+1)
+			for k, v := range object {
+				copied[k] = v
+			}
+			object = copied
+			changed = true
+		}
+		object[name] = value
+	}
+	for _, name := range prop.Required {
+		if value, ok := object[name]; !ok || value == nil {
+			v.fail(joinPath(path, name), "is required")
+		}
+	}
+
+	names := make([]string, 0, len(prop.Properties))
+	for name := range prop.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field := prop.Properties[name]
+		value, ok := object[name]
+		// Optional arguments sent as null are treated as omitted
+		if !ok || value == nil {
+			if field.Default != nil {
+				set(name, field.Default)
+			}
+			continue
+		}
+		value, fieldChanged := v.validate(joinPath(path, name), field, value)
+		if fieldChanged {
+			set(name, value)
+		}
+	}
+	return object, changed
+}
+
+func (v *validator) validateArray(path string, prop Property, array []interface{}) (interface{}, bool) {
+	if prop.MinItems > 0 && len(array) < prop.MinItems {
+		v.fail(path, "must have at least %d item(s)", prop.MinItems)
+	}
+	if prop.MaxItems > 0 && len(array) > prop.MaxItems {
+		v.fail(path, "must have at most %d item(s)", prop.MaxItems)
+	}
+	if prop.Items == nil {
+		return array, false
+	}
+	changed := false
+	for i, item := range array {
+		item, itemChanged := v.validate(fmt.Sprintf("%s[%d]", path, i), *prop.Items, item)
+		if itemChanged {
+			if !changed {
+				array = append([]interface{}(nil), array...)
+				changed = true
+			}
+			array[i] = item
+		}
+	}
+	return array, changed
+}
+
+func (v *validator) validateString(path string, prop Property, s string) {
+	if len(prop.Enum) > 0 && !contains(prop.Enum, s) {
+		v.fail(path, "must be one of: %s", strings.Join(prop.Enum, ", "))
+		return
+	}
+	length := len([]rune(s))
+	if prop.MinLength > 0 && length < prop.MinLength {
+		v.fail(path, "must be at least %d character(s) long", prop.MinLength)
+	}
+	if prop.MaxLength > 0 && length > prop.MaxLength {
+		v.fail(path, "must be at most %d character(s) long", prop.MaxLength)
+	}
+	if prop.Pattern != "" {
+		if re, err := regexp.Compile(prop.Pattern); err == nil && !re.MatchString(s) {
+			v.fail(path, "must match the pattern %s", prop.Pattern)
+		}
+	}
+
+	switch prop.Format {
+	case FormatDateTime:
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			v.fail(path, "must be an RFC 3339 date-time such as 2024-01-15T09:00:00Z")
+		}
+	case FormatDate:
+		if _, err := time.Parse(time.DateOnly, s); err != nil {
+			v.fail(path, "must be a date such as 2024-01-15")
+		}
+	case FormatEmail:
+		if address, err := mail.ParseAddress(s); err != nil || address.Address != s {
+			v.fail(path, "must be an email address")
+		}
+	case FormatURI:
+		if u, err := url.Parse(s); err != nil || !u.IsAbs() {
+			v.fail(path, "must be an absolute URI")
+		}
+	}
+}
+
+func (v *validator) validateNumber(path string, prop Property, n json.Number) {
+	f, err := n.Float64()
+	if err != nil {
+		v.fail(path, "must be a number")
+		return
+	}
+	if prop.Type == "integer" && f != math.Trunc(f) {
+		v.fail(path, "must be an integer")
+		return
+	}
+	if prop.Minimum != nil && f < *prop.Minimum {
+		v.fail(path, "must be at least %v", *prop.Minimum)
+	}
+	if prop.ExclusiveMinimum != nil && f <= *prop.ExclusiveMinimum {
+		v.fail(path, "must be greater than %v", *prop.ExclusiveMinimum)
+	}
+	if prop.Maximum != nil && f > *prop.Maximum {
+		v.fail(path, "must be at most %v", *prop.Maximum)
+	}
+}
+
+// validateOneOf accepts a value that matches exactly one of the alternatives
+func (v *validator) validateOneOf(path string, prop Property, value interface{}) (interface{}, bool) {
+	matches := 0
+	var result interface{}
+	var changed bool
+	for _, alternative := range prop.OneOf {
+		attempt := validator{}
+		validated, altChanged := attempt.validate(path, alternative, value)
+		if len(attempt.errors) == 0 {
+			matches++
+			result, changed = validated, altChanged
+		}
+	}
+
+	switch matches {
+	case 1:
+		return result, changed
+	case 0:
+		types := make([]string, 0, len(prop.OneOf))
+		for _, alternative := range prop.OneOf {
+			if alternative.Type != "" && !contains(types, alternative.Type) {
+				types = append(types, alternative.Type)
+			}
+		}
+		if len(types) == len(prop.OneOf) {
+			v.fail(path, "must be one of these types: %s", strings.Join(types, ", "))
+		} else {
+			v.fail(path, "does not match any of the allowed forms")
+		}
+	default:
+		v.fail(path, "matches more than one of the allowed forms")
+	}
+	return value, false
+}
+
+// joinPath appends a field name to the path of its object
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func contains(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
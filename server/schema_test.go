@@ -0,0 +1,193 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.ngs.io/google-mcp-server/config"
+	"go.ngs.io/google-mcp-server/internal/mcptest"
+)
+
+// tableSchema exercises every keyword the validator supports
+var tableSchema = InputSchema{
+	Type: "object",
+	Properties: map[string]Property{
+		"title": {Type: "string", MinLength: 1, MaxLength: 10},
+		"rows":  {Type: "integer", Minimum: Bound(1), Maximum: Bound(20)},
+		"width": {Type: "number", ExclusiveMinimum: Bound(0), Default: 400},
+		"mode":  {Type: "string", Enum: []string{"RAW", "USER_ENTERED"}, Default: "RAW"},
+		"id":    {Type: "string", Pattern: "^[a-z]+$"},
+		"start": {Type: "string", Format: FormatDateTime},
+		"day":   {Type: "string", Format: FormatDate},
+		"owner": {Type: "string", Format: FormatEmail},
+		"link":  {Type: "string", Format: FormatURI},
+		"cells": {
+			Type:     "array",
+			MinItems: 1,
+			MaxItems: 2,
+			Items: &Property{
+				Type:  "array",
+				Items: &Property{OneOf: []Property{{Type: "string"}, {Type: "number"}, {Type: "null"}}},
+			},
+		},
+		"position": {
+			Type: "object",
+			Properties: map[string]Property{
+				"x": {Type: "number", Default: 50},
+				"y": {Type: "number"},
+			},
+			Required: []string{"y"},
+		},
+	},
+	Required: []string{"title"},
+}
+
+func TestValidateArguments(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   string
+		fields []FieldError
+	}{
+		{"valid", `{"title":"Q3","rows":3,"width":10.5,"mode":"USER_ENTERED","id":"abc","start":"2024-01-15T09:00:00+09:00","day":"2024-01-15","owner":"a@example.com","link":"https://example.com/x","cells":[["a",1,null]],"position":{"x":1,"y":2}}`, nil},
+		{"integral float", `{"title":"Q3","rows":3.0}`, nil},
+		{"null optional", `{"title":"Q3","rows":null}`, nil},
+		{"missing required", `{}`, []FieldError{{"title", "is required"}}},
+		{"null required", `{"title":null}`, []FieldError{{"title", "is required"}}},
+		{"not an object", `[1]`, []FieldError{{"", "must be an object"}}},
+		{"malformed", `{"title":`, []FieldError{{"", "must be a JSON object"}}},
+		{"wrong type", `{"title":3}`, []FieldError{{"title", "must be a string"}}},
+		{"string length", `{"title":"far too long a title"}`, []FieldError{{"title", "must be at most 10 character(s) long"}}},
+		{"not an integer", `{"title":"Q3","rows":2.5}`, []FieldError{{"rows", "must be an integer"}}},
+		{"numeric string", `{"title":"Q3","rows":"2"}`, []FieldError{{"rows", "must be an integer"}}},
+		{"minimum", `{"title":"Q3","rows":0}`, []FieldError{{"rows", "must be at least 1"}}},
+		{"maximum", `{"title":"Q3","rows":21}`, []FieldError{{"rows", "must be at most 20"}}},
+		{"exclusive minimum", `{"title":"Q3","width":0}`, []FieldError{{"width", "must be greater than 0"}}},
+		{"enum", `{"title":"Q3","mode":"raw"}`, []FieldError{{"mode", "must be one of: RAW, USER_ENTERED"}}},
+		{"pattern", `{"title":"Q3","id":"ABC"}`, []FieldError{{"id", "must match the pattern ^[a-z]+$"}}},
+		{"date-time", `{"title":"Q3","start":"2024-01-15 09:00"}`, []FieldError{{"start", "must be an RFC 3339 date-time such as 2024-01-15T09:00:00Z"}}},
+		{"date", `{"title":"Q3","day":"15/01/2024"}`, []FieldError{{"day", "must be a date such as 2024-01-15"}}},
+		{"email", `{"title":"Q3","owner":"Ann <a@example.com>"}`, []FieldError{{"owner", "must be an email address"}}},
+		{"uri", `{"title":"Q3","link":"example.com"}`, []FieldError{{"link", "must be an absolute URI"}}},
+		{"min items", `{"title":"Q3","cells":[]}`, []FieldError{{"cells", "must have at least 1 item(s)"}}},
+		{"max items", `{"title":"Q3","cells":[[],[],[]]}`, []FieldError{{"cells", "must have at most 2 item(s)"}}},
+		{"nested item", `{"title":"Q3","cells":[["a"],["b",true]]}`, []FieldError{{"cells[1][1]", "must be one of these types: string, number, null"}}},
+		{"nested required", `{"title":"Q3","position":{"x":1}}`, []FieldError{{"position.y", "is required"}}},
+		{"nested type", `{"title":"Q3","position":{"y":"top"}}`, []FieldError{{"position.y", "must be a number"}}},
+		{"several", `{"rows":0,"mode":"x"}`, []FieldError{{"title", "is required"}, {"mode", "must be one of: RAW, USER_ENTERED"}, {"rows", "must be at least 1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validateArguments(tableSchema, json.RawMessage(tt.args))
+			if tt.fields == nil {
+				if err != nil {
+					t.Fatalf("expected %s to be valid, got %v", tt.args, err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected a validation error for %s, got %v", tt.args, err)
+			}
+			if !reflect.DeepEqual(validationErr.Fields, tt.fields) {
+				t.Errorf("expected %v, got %v", tt.fields, validationErr.Fields)
+			}
+		})
+	}
+}
+
+func TestValidateArgumentsAppliesDefaults(t *testing.T) {
+	tests := []struct {
+		args string
+		want string
+	}{
+		{`{"title":"Q3"}`, `{"mode":"RAW","title":"Q3","width":400}`},
+		{`{"title":"Q3","mode":null,"width":12345678901234567890}`, `{"mode":"RAW","title":"Q3","width":12345678901234567890}`},
+		{`{"title":"Q3","position":{"y":2}}`, `{"mode":"RAW","position":{"x":50,"y":2},"title":"Q3","width":400}`},
+	}
+	for _, tt := range tests {
+		got, err := validateArguments(tableSchema, json.RawMessage(tt.args))
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", tt.args, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("expected %s to become %s, got %s", tt.args, tt.want, got)
+		}
+	}
+
+	// Arguments without missing defaults are passed on untouched
+	args := json.RawMessage(`{ "title": "Q3", "mode": "RAW", "width": 1e2 }`)
+	got, err := validateArguments(tableSchema, args)
+	if err != nil || string(got) != string(args) {
+		t.Errorf("expected the arguments to be unchanged, got %s (%v)", got, err)
+	}
+	if got, err := validateArguments(InputSchema{Type: "object"}, nil); err != nil || string(got) != "{}" {
+		t.Errorf("expected missing arguments to become an empty object, got %s (%v)", got, err)
+	}
+}
+
+// validatedService records the arguments its tool receives
+type validatedService struct {
+	stubService
+	arguments chan json.RawMessage
+}
+
+func (s *validatedService) GetTools() []Tool {
+	return []Tool{{Name: "stub_table", InputSchema: tableSchema}}
+}
+
+func (s *validatedService) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
+	s.arguments <- arguments
+	return "ok", nil
+}
+
+func TestToolCallArgumentsValidatedBeforeDispatch(t *testing.T) {
+	service := &validatedService{arguments: make(chan json.RawMessage, 1)}
+	srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP}})
+	srv.RegisterService("stub", service)
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+	sessionID := initializeSession(t, endpoint)
+
+	resp := mcptest.Post(t, endpoint, sessionID, "application/json",
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"stub_table","arguments":{"rows":0,"cells":[["a",{}]]}}}`)
+	defer func() { _ = resp.Body.Close() }()
+	var msg struct {
+		Result callToolResult `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !msg.Result.IsError {
+		t.Fatalf("expected an error result, got %+v", msg.Result)
+	}
+	var body struct {
+		Error toolErrorDetail `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(msg.Result.Content[0].Text), &body); err != nil {
+		t.Fatalf("error content is not JSON: %v", err)
+	}
+	want := []FieldError{
+		{"title", "is required"},
+		{"cells[0][1]", "must be one of these types: string, number, null"},
+		{"rows", "must be at least 1"},
+	}
+	if body.Error.Category != CategoryInvalidArgument || !reflect.DeepEqual(body.Error.Fields, want) {
+		t.Errorf("unexpected error detail: %+v", body.Error)
+	}
+	select {
+	case args := <-service.arguments:
+		t.Fatalf("expected the tool not to run, got %s", args)
+	default:
+	}
+
+	resp = mcptest.Post(t, endpoint, sessionID, "application/json",
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"stub_table","arguments":{"title":"Q3"}}}`)
+	_ = resp.Body.Close()
+	if args := <-service.arguments; string(args) != `{"mode":"RAW","title":"Q3","width":400}` {
+		t.Errorf("expected the tool to receive the defaults, got %s", args)
+	}
+}
@@ -0,0 +1,253 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// schemaCache holds the generated schema of each argument type
+var schemaCache sync.Map // reflect.Type -> InputSchema
+
+// SchemaOf generates the input schema of a tool from its argument struct.
+// Properties are named by their json tags and described by these tags:
+//
+//	description:"Shown to the model"
+//	required:"true"
+//	enum:"a,b,c"
+//	default:"10"
+//	format:"date-time"   (see the Format constants)
+//	pattern:"^[a-z]+$"
+//	minimum:"1"  maximum:"100"  exclusiveMinimum:"0"
+//	minLength:"1"  maxLength:"256"  minItems:"1"  maxItems:"10"
+//
+// Strings, booleans, integers, floats, slices, maps and nested structs are
+// supported; pointers are optional values of their element type. Embedded
+// structs contribute their fields, as with encoding/json. Types that
+// implement PropertySchemer describe themselves, and the tags of their
+// fields are applied on top. SchemaOf panics on malformed tags, which are
+// programming errors.
+func SchemaOf(t reflect.Type) InputSchema {
+	if cached, ok := schemaCache.Load(t); ok {
+		return copySchema(cached.(InputSchema))
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("server: arguments of a tool must be a struct, not %s", t))
+	}
+	object := propertyOf(t, "")
+	schema := InputSchema{Type: "object", Properties: object.Properties, Required: object.Required}
+	schemaCache.Store(t, schema)
+	return copySchema(schema)
+}
+
+// PropertySchemer is implemented by argument types whose schema cannot be
+// expressed with tags, such as arrays of values of several types
+type PropertySchemer interface {
+	PropertySchema() Property
+}
+
+// propertySchemerType is the reflect.Type of PropertySchemer
+var propertySchemerType = reflect.TypeFor[PropertySchemer]()
+
+// copySchema copies the top level of a cached schema, so that services can
+// add properties to the schema of a tool without affecting other tools
+func copySchema(schema InputSchema) InputSchema {
+	properties := make(map[string]Property, len(schema.Properties))
+	for name, prop := range schema.Properties {
+		properties[name] = prop
+	}
+	schema.Properties = properties
+	schema.Required = append([]string(nil), schema.Required...)
+	return schema
+}
+
+// propertyOf generates the schema of a value of type t
+func propertyOf(t reflect.Type, path string) Property {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Implements(propertySchemerType) {
+		return reflect.Zero(t).Interface().(PropertySchemer).PropertySchema()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return Property{Type: "string"}
+	case reflect.Bool:
+		return Property{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Property{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Property{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		items := propertyOf(t.Elem(), path+"[]")
+		return Property{Type: "array", Items: &items}
+	case reflect.Map:
+		return Property{Type: "object"}
+	case reflect.Interface:
+		// Any JSON value
+		return Property{}
+	case reflect.Struct:
+		object := Property{Type: "object", Properties: map[string]Property{}}
+		addFields(&object, t, path)
+		return object
+	}
+	panic(fmt.Sprintf("server: %s: unsupported argument type %s", path, t))
+}
+
+// addFields adds the fields of struct type t to the schema of an object
+func addFields(object *Property, t reflect.Type, path string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				addFields(object, embedded, path)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldPath := joinPath(path, name)
+		prop := propertyOf(field.Type, fieldPath)
+		applyTags(&prop, field, fieldPath)
+		object.Properties[name] = prop
+		if field.Tag.Get("required") == "true" {
+			object.Required = append(object.Required, name)
+		}
+	}
+}
+
+// applyTags sets the keywords given by the struct tags of a field
+func applyTags(prop *Property, field reflect.StructField, path string) {
+	tag := field.Tag
+	for key, target := range map[string]*string{
+		"description": &prop.Description,
+		"format":      &prop.Format,
+		"pattern":     &prop.Pattern,
+	} {
+		if value, ok := tag.Lookup(key); ok {
+			*target = value
+		}
+	}
+	if enum := tag.Get("enum"); enum != "" {
+		prop.Enum = strings.Split(enum, ",")
+	}
+
+	for key, target := range map[string]**float64{
+		"minimum":          &prop.Minimum,
+		"maximum":          &prop.Maximum,
+		"exclusiveMinimum": &prop.ExclusiveMinimum,
+	} {
+		if value, ok := tag.Lookup(key); ok {
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				panic(fmt.Sprintf("server: %s: invalid %s %q", path, key, value))
+			}
+			*target = Bound(f)
+		}
+	}
+	for key, target := range map[string]*int{
+		"minLength": &prop.MinLength,
+		"maxLength": &prop.MaxLength,
+		"minItems":  &prop.MinItems,
+		"maxItems":  &prop.MaxItems,
+	} {
+		if value, ok := tag.Lookup(key); ok {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				panic(fmt.Sprintf("server: %s: invalid %s %q", path, key, value))
+			}
+			*target = n
+		}
+	}
+
+	if value, ok := tag.Lookup("default"); ok {
+		var err error
+		switch prop.Type {
+		case "string":
+			prop.Default = value
+		case "boolean":
+			prop.Default, err = strconv.ParseBool(value)
+		case "integer":
+			prop.Default, err = strconv.ParseInt(value, 10, 64)
+		case "number":
+			prop.Default, err = strconv.ParseFloat(value, 64)
+		default:
+			err = errors.New("only scalars can have defaults")
+		}
+		if err != nil {
+			panic(fmt.Sprintf("server: %s: invalid default %q: %v", path, value, err))
+		}
+	}
+}
+
+// CheckSchema reports every way in which the input schema of a tool and its
+// argument struct disagree: properties without a field, fields without a
+// property, and differences in type, requiredness or constraints.
+func CheckSchema(schema InputSchema, argType reflect.Type) error {
+	want := SchemaOf(argType)
+	var problems []string
+
+	names := map[string]bool{}
+	for name := range schema.Properties {
+		names[name] = true
+	}
+	for name := range want.Properties {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		have, inSchema := schema.Properties[name]
+		field, inStruct := want.Properties[name]
+		switch {
+		case !inStruct:
+			problems = append(problems, fmt.Sprintf("%s is in the schema but not in %s", name, argType))
+		case !inSchema:
+			problems = append(problems, fmt.Sprintf("%s is a field of %s but not in the schema", name, argType))
+		case have.Type != field.Type:
+			problems = append(problems, fmt.Sprintf("%s has type %s in the schema but %s in %s", name, have.Type, field.Type, argType))
+		case !reflect.DeepEqual(have, field):
+			problems = append(problems, fmt.Sprintf("%s differs from the field of %s", name, argType))
+		}
+	}
+
+	required := append([]string(nil), schema.Required...)
+	wantRequired := append([]string(nil), want.Required...)
+	sort.Strings(required)
+	sort.Strings(wantRequired)
+	if strings.Join(required, ",") != strings.Join(wantRequired, ",") {
+		problems = append(problems, fmt.Sprintf("required is [%s] in the schema but [%s] in %s",
+			strings.Join(required, ", "), strings.Join(wantRequired, ", "), argType))
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type commonArgs struct {
+	Account string `json:"account,omitempty" description:"Account to use"`
+}
+
+type cellArgs struct {
+	Row    int    `json:"row" required:"true" minimum:"0"`
+	Column int    `json:"column" required:"true" minimum:"0"`
+	Text   string `json:"text"`
+}
+
+type generatedArgs struct {
+	Title    string      `json:"title" required:"true" minLength:"1" maxLength:"100" description:"Title of the table"`
+	Rows     int64       `json:"rows" minimum:"1" maximum:"20" default:"3"`
+	Width    float64     `json:"width" exclusiveMinimum:"0" default:"400"`
+	Mode     *string     `json:"mode,omitempty" enum:"RAW,USER_ENTERED" default:"RAW"`
+	Start    string      `json:"start" format:"date-time"`
+	ID       string      `json:"id" pattern:"^[a-z]+$"`
+	Header   bool        `json:"header" default:"true"`
+	Tags     []string    `json:"tags" maxItems:"5"`
+	Cells    []cellArgs  `json:"cells" minItems:"1"`
+	Value    interface{} `json:"value"`
+	Options  map[string]string
+	Internal string `json:"-"`
+	commonArgs
+}
+
+func TestSchemaOf(t *testing.T) {
+	schema := SchemaOf(reflect.TypeFor[generatedArgs]())
+
+	want := InputSchema{
+		Type: "object",
+		Properties: map[string]Property{
+			"title":  {Type: "string", Description: "Title of the table", MinLength: 1, MaxLength: 100},
+			"rows":   {Type: "integer", Minimum: Bound(1), Maximum: Bound(20), Default: int64(3)},
+			"width":  {Type: "number", ExclusiveMinimum: Bound(0), Default: float64(400)},
+			"mode":   {Type: "string", Enum: []string{"RAW", "USER_ENTERED"}, Default: "RAW"},
+			"start":  {Type: "string", Format: FormatDateTime},
+			"id":     {Type: "string", Pattern: "^[a-z]+$"},
+			"header": {Type: "boolean", Default: true},
+			"tags":   {Type: "array", Items: &Property{Type: "string"}, MaxItems: 5},
+			"cells": {Type: "array", MinItems: 1, Items: &Property{
+				Type: "object",
+				Properties: map[string]Property{
+					"row":    {Type: "integer", Minimum: Bound(0)},
+					"column": {Type: "integer", Minimum: Bound(0)},
+					"text":   {Type: "string"},
+				},
+				Required: []string{"row", "column"},
+			}},
+			"value":   {},
+			"Options": {Type: "object"},
+			"account": {Type: "string", Description: "Account to use"},
+		},
+		Required: []string{"title"},
+	}
+	if !reflect.DeepEqual(schema, want) {
+		got, _ := json.MarshalIndent(schema, "", "  ")
+		t.Errorf("unexpected schema:\n%s", got)
+	}
+
+	// Callers may extend the schema they get without changing the next one
+	schema.Properties["extra"] = Property{Type: "string"}
+	if _, ok := SchemaOf(reflect.TypeFor[generatedArgs]()).Properties["extra"]; ok {
+		t.Error("expected changes to a generated schema not to leak into the cache")
+	}
+
+	// Generated schemas accept what the struct decodes
+	if _, err := validateArguments(SchemaOf(reflect.TypeFor[generatedArgs]()),
+		json.RawMessage(`{"title":"Q3","cells":[{"row":0,"column":1,"text":"x"}],"value":[1,"a"]}`)); err != nil {
+		t.Errorf("expected valid arguments, got %v", err)
+	}
+}
+
+// scores describes its items, which tags cannot
+type scores []interface{}
+
+func (scores) PropertySchema() Property {
+	return Property{Type: "array", Items: &Property{OneOf: []Property{{Type: "integer"}, {Type: "null"}}}}
+}
+
+func TestSchemaOfPropertySchemer(t *testing.T) {
+	schema := SchemaOf(reflect.TypeFor[struct {
+		Scores scores `json:"scores" required:"true" minItems:"1" description:"Scores, null when missing"`
+	}]())
+
+	want := Property{
+		Type:        "array",
+		Description: "Scores, null when missing",
+		MinItems:    1,
+		Items:       &Property{OneOf: []Property{{Type: "integer"}, {Type: "null"}}},
+	}
+	if !reflect.DeepEqual(schema.Properties["scores"], want) {
+		got, _ := json.MarshalIndent(schema.Properties["scores"], "", "  ")
+		t.Errorf("unexpected schema:\n%s", got)
+	}
+}
+
+func TestSchemaOfEmptyStruct(t *testing.T) {
+	data, err := json.Marshal(SchemaOf(reflect.TypeFor[struct{}]()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"type":"object","properties":{}}` {
+		t.Errorf("unexpected schema %s", data)
+	}
+}
+
+func TestSchemaOfPanicsOnMalformedTags(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), "count: invalid minimum") {
+			t.Errorf("expected a panic naming the field, got %v", r)
+		}
+	}()
+	SchemaOf(reflect.TypeFor[struct {
+		Count int `json:"count" minimum:"one"`
+	}]())
+}
+
+func TestCheckSchema(t *testing.T) {
+	argType := reflect.TypeFor[cellArgs]()
+	if err := CheckSchema(SchemaOf(argType), argType); err != nil {
+		t.Fatalf("expected a generated schema to match, got %v", err)
+	}
+
+	drifted := InputSchema{
+		Type: "object",
+		Properties: map[string]Property{
+			"row":    {Type: "number", Minimum: Bound(0)},
+			"column": {Type: "integer"},
+			"style":  {Type: "string"},
+		},
+		Required: []string{"row"},
+	}
+	err := CheckSchema(drifted, argType)
+	if err == nil {
+		t.Fatal("expected the drifted schema to be reported")
+	}
+	for _, problem := range []string{
+		"column differs from the field",
+		"row has type number in the schema but integer",
+		"style is in the schema but not in",
+		"text is a field of server.cellArgs but not in the schema",
+		"required is [row] in the schema but [column, row]",
+	} {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("expected %q in %v", problem, err)
+		}
+	}
+}
+
+func TestToolSet(t *testing.T) {
+	set := ToolSet{
+		NewTool(Tool{Name: "add", Annotations: ReadOnlyAnnotations()}, func(ctx context.Context, args cellArgs) (interface{}, error) {
+			return args.Row + args.Column, nil
+		}),
+	}
+
+	tools := set.Tools()
+	if len(tools) != 1 || tools[0].Name != "add" || tools[0].InputSchema.Properties["row"].Type != "integer" {
+		t.Fatalf("unexpected tools %+v", tools)
+	}
+	if definition, ok := set.Lookup("add"); !ok || definition.ArgumentType() != reflect.TypeFor[cellArgs]() {
+		t.Errorf("expected to find the definition of add")
+	}
+
+	result, err := set.Call(context.Background(), "add", json.RawMessage(`{"row":2,"column":3}`))
+	if err != nil || result != 5 {
+		t.Errorf("expected 5, got %v (%v)", result, err)
+	}
+	if _, err := set.Call(context.Background(), "add", json.RawMessage(`{"row":"2"}`)); err == nil || !strings.HasPrefix(err.Error(), "invalid arguments:") {
+		t.Errorf("expected undecodable arguments to fail, got %v", err)
+	}
+	if _, err := set.Call(context.Background(), "sub", nil); err == nil || err.Error() != "unknown tool: sub" {
+		t.Errorf("expected an unknown tool error, got %v", err)
+	}
+}
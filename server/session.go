@@ -0,0 +1,182 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// Session represents a single client connection to the server.
+// Stdio has exactly one session; the HTTP transport creates one per
+// initialize request and identifies it with the Mcp-Session-Id header.
+type Session struct {
+	id   string
+	conn *jsonrpc2.Conn
+
+	// stream is set for sessions served over the HTTP transport
+	stream *httpStream
+
+	// logLevel is the minimum level of log messages sent to the client,
+	// changed with logging/setLevel
+	logLevel slog.LevelVar
+
+	// logRequested is set once the client calls logging/setLevel; only
+	// then does it receive records not tied to one of its requests
+	logRequested atomic.Bool
+
+	// state negotiated by initialize, read by handlers through the accessors
+	stateMu         sync.RWMutex
+	protocolVersion string
+	clientInfo      ClientInfo
+	capabilities    ClientCapabilities
+
+	// inflight holds the cancel functions of requests being handled,
+	// keyed by JSON-RPC request ID
+	inflight   map[string]context.CancelCauseFunc
+	inflightMu sync.Mutex
+}
+
+// ID returns the session identifier
+func (s *Session) ID() string {
+	return s.id
+}
+
+// ProtocolVersion returns the protocol version negotiated with the client,
+// or an empty string before initialization
+func (s *Session) ProtocolVersion() string {
+	s.stateMu.RLock()
+	defer s.stateMu.RUnlock()
+	return s.protocolVersion
+}
+
+// ClientInfo returns the name and version the client sent in initialize
+func (s *Session) ClientInfo() ClientInfo {
+	s.stateMu.RLock()
+	defer s.stateMu.RUnlock()
+	return s.clientInfo
+}
+
+// ClientCapabilities returns the capabilities the client declared
+func (s *Session) ClientCapabilities() ClientCapabilities {
+	s.stateMu.RLock()
+	defer s.stateMu.RUnlock()
+	return s.capabilities
+}
+
+// Supports reports whether a feature may be used with this session: the
+// negotiated version must define it and, for features the client implements,
+// the client must have declared the capability
+func (s *Session) Supports(feature Feature) bool {
+	s.stateMu.RLock()
+	defer s.stateMu.RUnlock()
+
+	if s.protocolVersion == "" || s.protocolVersion < featureVersions[feature] {
+		return false
+	}
+	if feature == FeatureElicitation {
+		return s.capabilities.Elicitation != nil
+	}
+	return true
+}
+
+// canSendRequests reports whether the server can send the client a request,
+// such as elicitation/create, and expect it to arrive. Over HTTP that needs
+// an open SSE stream; stdio always can.
+func (s *Session) canSendRequests() bool {
+	return s.stream == nil || s.stream.canSendRequests()
+}
+
+// initialize records the state negotiated by the initialize request
+func (s *Session) initialize(version string, info ClientInfo, capabilities ClientCapabilities) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	s.protocolVersion = version
+	s.clientInfo = info
+	s.capabilities = capabilities
+}
+
+// close terminates the underlying JSON-RPC connection
+func (s *Session) close() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	if err == jsonrpc2.ErrClosed {
+		return nil
+	}
+	return err
+}
+
+// newSessionID returns a cryptographically random session identifier
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate session ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// sessionContextKey is the context key for the session serving a request
+type sessionContextKey struct{}
+
+// withSession returns a context carrying the session serving a request
+func withSession(ctx context.Context, session *Session) context.Context {
+	return context.WithValue(ctx, sessionContextKey{}, session)
+}
+
+// SessionFromContext returns the session serving the request, if any. Tool
+// handlers use it to check what the client supports.
+func SessionFromContext(ctx context.Context) (*Session, bool) {
+	session, ok := ctx.Value(sessionContextKey{}).(*Session)
+	return session, ok && session != nil
+}
+
+// addSession tracks a session so it can be found by ID and closed on Stop.
+// New sessions receive log messages at the configured level.
+func (s *MCPServer) addSession(session *Session) {
+	session.logLevel.Set(s.logLevel)
+
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	s.sessions[session.id] = session
+	s.updateSinkLevel()
+}
+
+// removeSession stops tracking a session and cancels its in-flight requests
+func (s *MCPServer) removeSession(id string) {
+	s.sessionsMu.Lock()
+	session, ok := s.sessions[id]
+	delete(s.sessions, id)
+	s.updateSinkLevel()
+	s.sessionsMu.Unlock()
+
+	if ok {
+		session.cancelAll(errSessionClosed)
+		s.subscriptions.removeSession(session)
+	}
+}
+
+// getSession looks up a session by ID
+func (s *MCPServer) getSession(id string) (*Session, bool) {
+	s.sessionsMu.RLock()
+	defer s.sessionsMu.RUnlock()
+	session, ok := s.sessions[id]
+	return session, ok
+}
+
+// allSessions returns a snapshot of the open sessions
+func (s *MCPServer) allSessions() []*Session {
+	s.sessionsMu.RLock()
+	defer s.sessionsMu.RUnlock()
+	sessions := make([]*Session, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
@@ -0,0 +1,305 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// ErrNotWatchable is returned by a ChangeDetector for resources it cannot
+// watch. Subscribing to such a resource fails.
+var ErrNotWatchable = errors.New("resource does not support subscriptions")
+
+// ChangeDetector is implemented by services whose resources can be subscribed
+// to. Each service decides how to detect changes cheaply, e.g. with a Gmail
+// history ID or a Drive changes page token, and encodes its position in an
+// opaque cursor.
+type ChangeDetector interface {
+	// DetectChanges reports whether the resource at uri changed since cursor
+	// was returned, along with the cursor to pass next time. An empty cursor
+	// asks for the current position and never reports a change.
+	DetectChanges(ctx context.Context, uri string, cursor string) (changed bool, next string, err error)
+}
+
+// detectTimeout bounds a single change check so one slow API cannot hold up
+// the other subscriptions
+const detectTimeout = 30 * time.Second
+
+// resourceUpdatedParams are the params of notifications/resources/updated
+type resourceUpdatedParams struct {
+	URI string `json:"uri"`
+}
+
+// resourceWatch tracks one subscribed URI. Sessions subscribing to the same
+// URI share the watch, so the API is polled once per URI.
+type resourceWatch struct {
+	detector ChangeDetector
+	cursor   string
+	sessions map[*Session]struct{}
+}
+
+// subscriptionManager polls subscribed resources and notifies the sessions
+// watching them. The poll loop only runs while there are subscriptions.
+type subscriptionManager struct {
+	interval time.Duration
+
+	mu      sync.Mutex
+	watches map[string]*resourceWatch
+	stop    chan struct{} // closed to end the poll loop; nil when not polling
+}
+
+func newSubscriptionManager(interval time.Duration) *subscriptionManager {
+	return &subscriptionManager{
+		interval: interval,
+		watches:  make(map[string]*resourceWatch),
+	}
+}
+
+// subscribe adds session to the watchers of uri. The first subscription to a
+// URI records the detector's current cursor so that only later changes are
+// reported.
+func (m *subscriptionManager) subscribe(ctx context.Context, session *Session, uri string, detector ChangeDetector) error {
+	m.mu.Lock()
+	if watch, ok := m.watches[uri]; ok {
+		watch.sessions[session] = struct{}{}
+		m.mu.Unlock()
+		return nil
+	}
+	m.mu.Unlock()
+
+	_, cursor, err := detector.DetectChanges(ctx, uri, "")
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Another session may have subscribed while the cursor was fetched
+	watch, ok := m.watches[uri]
+	if !ok {
+		watch = &resourceWatch{detector: detector, cursor: cursor, sessions: make(map[*Session]struct{})}
+		m.watches[uri] = watch
+	}
+	watch.sessions[session] = struct{}{}
+	m.startLocked()
+	return nil
+}
+
+// unsubscribe removes session from the watchers of uri
+func (m *subscriptionManager) unsubscribe(session *Session, uri string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if watch, ok := m.watches[uri]; ok {
+		delete(watch.sessions, session)
+		if len(watch.sessions) == 0 {
+			delete(m.watches, uri)
+		}
+	}
+	m.stopIfIdleLocked()
+}
+
+// removeSession drops every subscription of a closed session
+func (m *subscriptionManager) removeSession(session *Session) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for uri, watch := range m.watches {
+		delete(watch.sessions, session)
+		if len(watch.sessions) == 0 {
+			delete(m.watches, uri)
+		}
+	}
+	m.stopIfIdleLocked()
+}
+
+// close ends the poll loop regardless of remaining subscriptions
+func (m *subscriptionManager) close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+}
+
+// startLocked starts the poll loop if it is not running. Callers hold m.mu.
+func (m *subscriptionManager) startLocked() {
+	if m.stop != nil || m.interval <= 0 {
+		return
+	}
+	m.stop = make(chan struct{})
+	go m.run(m.stop)
+}
+
+// stopIfIdleLocked ends the poll loop once nothing is subscribed. Callers
+// hold m.mu.
+func (m *subscriptionManager) stopIfIdleLocked() {
+	if len(m.watches) == 0 && m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+}
+
+// run polls every interval until stop is closed
+func (m *subscriptionManager) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.poll(ctx)
+		case <-stop:
+			return
+		}
+	}
+}
+
+// poll checks every subscribed resource once and notifies the watchers of
+// those that changed. A failed check keeps the previous cursor, so the change
+// is picked up by a later poll.
+func (m *subscriptionManager) poll(ctx context.Context) {
+	type pending struct {
+		uri      string
+		detector ChangeDetector
+		cursor   string
+	}
+
+	m.mu.Lock()
+	checks := make([]pending, 0, len(m.watches))
+	for uri, watch := range m.watches {
+		checks = append(checks, pending{uri: uri, detector: watch.detector, cursor: watch.cursor})
+	}
+	m.mu.Unlock()
+
+	for _, check := range checks {
+		if ctx.Err() != nil {
+			return
+		}
+		detectCtx, cancel := context.WithTimeout(ctx, detectTimeout)
+		changed, next, err := check.detector.DetectChanges(detectCtx, check.uri, check.cursor)
+		cancel()
+		if err != nil {
+			logger.Warn("failed to check resource for changes", "uri", check.uri, "error", err)
+			continue
+		}
+
+		m.mu.Lock()
+		watch, ok := m.watches[check.uri]
+		var sessions []*Session
+		if ok {
+			watch.cursor = next
+			if changed {
+				for session := range watch.sessions {
+					sessions = append(sessions, session)
+				}
+			}
+		}
+		m.mu.Unlock()
+
+		for _, session := range sessions {
+			logger.Debug("resource updated", "uri", check.uri, "session", session.ID())
+			if err := session.conn.Notify(ctx, "notifications/resources/updated", resourceUpdatedParams{URI: check.uri}); err != nil {
+				logger.Debug("failed to send resource update", "uri", check.uri, "error", err)
+			}
+		}
+	}
+}
+
+// changeDetectorFor returns the detector of the service serving uri
+func (s *MCPServer) changeDetectorFor(uri string) (ChangeDetector, error) {
+	handler, route, _ := s.resolveResource(uri)
+	var owner interface{}
+	switch {
+	case handler != nil:
+		owner = handler
+	case route != nil:
+		owner = route.handler
+	default:
+		return nil, fmt.Errorf("resource not found: %s", uri)
+	}
+
+	detector, ok := owner.(ChangeDetector)
+	if !ok {
+		return nil, ErrNotWatchable
+	}
+	return detector, nil
+}
+
+func (h *Handler) handleResourceSubscribe(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	uri, ok := h.parseSubscriptionParams(ctx, conn, req)
+	if !ok {
+		return
+	}
+
+	detector, err := h.server.changeDetectorFor(uri)
+	if err == nil {
+		err = h.server.subscriptions.subscribe(ctx, h.session, uri, detector)
+	}
+	if requestCancelled(ctx) {
+		logger.InfoContext(ctx, "resource subscription abandoned", "uri", uri, "cause", context.Cause(ctx))
+		return
+	}
+	if err != nil {
+		logger.WarnContext(ctx, "resource subscription failed", "uri", uri, "error", err)
+		if err := conn.ReplyWithError(ctx, req.ID, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidParams,
+			Message: fmt.Sprintf("cannot subscribe to %s: %v", uri, err),
+		}); err != nil {
+			logger.Error("failed to send reply", "error", err)
+		}
+		return
+	}
+
+	logger.DebugContext(ctx, "resource subscribed", "uri", uri)
+	if err := conn.Reply(ctx, req.ID, struct{}{}); err != nil {
+		logger.Error("failed to send reply", "error", err)
+	}
+}
+
+func (h *Handler) handleResourceUnsubscribe(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	uri, ok := h.parseSubscriptionParams(ctx, conn, req)
+	if !ok {
+		return
+	}
+
+	h.server.subscriptions.unsubscribe(h.session, uri)
+	logger.DebugContext(ctx, "resource unsubscribed", "uri", uri)
+	if err := conn.Reply(ctx, req.ID, struct{}{}); err != nil {
+		logger.Error("failed to send reply", "error", err)
+	}
+}
+
+// parseSubscriptionParams reads the URI of a subscribe or unsubscribe request,
+// replying with an error if it is missing
+func (h *Handler) parseSubscriptionParams(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (string, bool) {
+	var params struct {
+		URI string `json:"uri"`
+	}
+	if req.Params != nil {
+		if err := json.Unmarshal(*req.Params, &params); err != nil {
+			params.URI = ""
+		}
+	}
+	if params.URI == "" {
+		if err := conn.ReplyWithError(ctx, req.ID, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidParams,
+			Message: "missing resource uri",
+		}); err != nil {
+			logger.Error("failed to send reply", "error", err)
+		}
+		return "", false
+	}
+	return params.URI, true
+}
@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"go.ngs.io/google-mcp-server/config"
+	"go.ngs.io/google-mcp-server/internal/mcptest"
+)
+
+// watchedService serves a resource whose version is bumped by the test, and
+// detects changes by comparing versions
+type watchedService struct {
+	stubService
+
+	mu      sync.Mutex
+	version int
+}
+
+func (s *watchedService) GetResources() []Resource {
+	return []Resource{
+		{URI: "stub://watched", Name: "Watched"},
+		{URI: "stub://static", Name: "Static"},
+	}
+}
+
+func (s *watchedService) bump() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.version++
+}
+
+func (s *watchedService) DetectChanges(ctx context.Context, uri string, cursor string) (bool, string, error) {
+	if uri != "stub://watched" {
+		return false, "", ErrNotWatchable
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current := strconv.Itoa(s.version)
+	return cursor != "" && cursor != current, current, nil
+}
+
+// subscriptionRequest sends a subscribe or unsubscribe request and returns
+// the JSON-RPC error code, or 0 on success
+func subscriptionRequest(t *testing.T, endpoint, sessionID, method, uri string) int {
+	t.Helper()
+	resp := mcptest.Post(t, endpoint, sessionID, "application/json",
+		`{"jsonrpc":"2.0","id":20,"method":"`+method+`","params":{"uri":"`+uri+`"}}`)
+	defer func() { _ = resp.Body.Close() }()
+	var result struct {
+		Error *struct {
+			Code int `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode %s response: %v", method, err)
+	}
+	if result.Error != nil {
+		return result.Error.Code
+	}
+	return 0
+}
+
+// listenFor opens the standalone SSE stream of a session and sends the params
+// of every notification with the given method to the returned channel
+func listenFor(t *testing.T, endpoint, sessionID, method string) <-chan json.RawMessage {
+	t.Helper()
+	messages := mcptest.Listen(t, endpoint, sessionID, method)
+	params := make(chan json.RawMessage, 10)
+	go func() {
+		defer close(params)
+		for msg := range messages {
+			var envelope struct {
+				Params json.RawMessage `json:"params"`
+			}
+			if json.Unmarshal(msg, &envelope) == nil {
+				params <- envelope.Params
+			}
+		}
+	}()
+	return params
+}
+
+// listenForUpdates returns the URIs of resource update notifications sent to
+// a session
+func listenForUpdates(t *testing.T, endpoint, sessionID string) <-chan string {
+	t.Helper()
+	notifications := listenFor(t, endpoint, sessionID, "notifications/resources/updated")
+	updates := make(chan string, 10)
+	go func() {
+		for params := range notifications {
+			var updated resourceUpdatedParams
+			if json.Unmarshal(params, &updated) == nil {
+				updates <- updated.URI
+			}
+		}
+	}()
+	return updates
+}
+
+func TestResourceSubscriptions(t *testing.T) {
+	service := &watchedService{}
+	srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP}})
+	srv.RegisterService("stub", service)
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+	sessionID := initializeSession(t, endpoint)
+	updates := listenForUpdates(t, endpoint, sessionID)
+
+	if code := subscriptionRequest(t, endpoint, sessionID, "resources/subscribe", "stub://static"); code == 0 {
+		t.Error("expected subscribing to an unwatchable resource to fail")
+	}
+	if code := subscriptionRequest(t, endpoint, sessionID, "resources/subscribe", "stub://missing"); code == 0 {
+		t.Error("expected subscribing to an unknown resource to fail")
+	}
+	if code := subscriptionRequest(t, endpoint, sessionID, "resources/subscribe", "stub://watched"); code != 0 {
+		t.Fatalf("subscribe failed with code %d", code)
+	}
+
+	// Nothing changed yet
+	srv.subscriptions.poll(context.Background())
+	select {
+	case uri := <-updates:
+		t.Fatalf("expected no update before a change, got %s", uri)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	service.bump()
+	srv.subscriptions.poll(context.Background())
+	select {
+	case uri := <-updates:
+		if uri != "stub://watched" {
+			t.Errorf("expected an update for stub://watched, got %s", uri)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected an update after the resource changed")
+	}
+
+	// The change is reported once
+	srv.subscriptions.poll(context.Background())
+	select {
+	case uri := <-updates:
+		t.Fatalf("expected the change to be reported once, got another update for %s", uri)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if code := subscriptionRequest(t, endpoint, sessionID, "resources/unsubscribe", "stub://watched"); code != 0 {
+		t.Fatalf("unsubscribe failed with code %d", code)
+	}
+	service.bump()
+	srv.subscriptions.poll(context.Background())
+	select {
+	case uri := <-updates:
+		t.Fatalf("expected no update after unsubscribing, got %s", uri)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSubscriptionsEndWithSession(t *testing.T) {
+	service := &watchedService{}
+	srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP}})
+	srv.RegisterService("stub", service)
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+	sessionID := initializeSession(t, endpoint)
+
+	if code := subscriptionRequest(t, endpoint, sessionID, "resources/subscribe", "stub://watched"); code != 0 {
+		t.Fatalf("subscribe failed with code %d", code)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, endpoint, nil)
+	req.Header.Set(sessionHeader, sessionID)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	srv.subscriptions.mu.Lock()
+	defer srv.subscriptions.mu.Unlock()
+	if len(srv.subscriptions.watches) != 0 || srv.subscriptions.stop != nil {
+		t.Errorf("expected no watches and no poll loop after the session closed, got %d watches", len(srv.subscriptions.watches))
+	}
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.ngs.io/google-mcp-server/config"
+)
+
+// TimeoutError is the error of a tool call that ran past its deadline
+type TimeoutError struct {
+	Tool    string
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s did not finish within %s. It may still finish, so changes it makes may have been applied anyway; check before retrying, narrow the request or raise its timeout in the configuration", e.Tool, e.Timeout)
+}
+
+// toolTimeout returns how long a tool may run: its own override, else its
+// service's, else the global timeout. Zero means no deadline.
+func (s *MCPServer) toolTimeout(service, tool string) time.Duration {
+	global := s.config.Global
+	if seconds, ok := global.ToolTimeouts[tool]; ok {
+		return time.Duration(seconds) * time.Second
+	}
+	if seconds, ok := global.ServiceTimeouts[service]; ok {
+		return time.Duration(seconds) * time.Second
+	}
+	if global.Timeout > 0 {
+		return time.Duration(global.Timeout) * time.Second
+	}
+	return config.DefaultTimeout * time.Second
+}
+
+// callTool runs a tool under its deadline. A handler that ignores its
+// context is left behind when the deadline passes, so the client gets its
+// answer on time either way; what the handler returns later is dropped.
+// The handler keeps the request's worker slot until it returns, so handlers
+// left behind still count against MaxConcurrency.
+func (h *Handler) callTool(ctx context.Context, entry toolEntry, name string, arguments json.RawMessage) (interface{}, error) {
+	timeout := h.server.toolTimeout(entry.service, name)
+	if timeout <= 0 {
+		return entry.handler.HandleToolCall(ctx, name, arguments)
+	}
+
+	timeoutErr := &TimeoutError{Tool: name, Timeout: timeout}
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, timeoutErr)
+	defer cancel()
+
+	type outcome struct {
+		result interface{}
+		err    error
+	}
+	done := make(chan outcome, 1)
+	release := holdWorkerSlot(ctx)
+	go func() {
+		defer release()
+		result, err := entry.handler.HandleToolCall(ctx, name, arguments)
+		done <- outcome{result, err}
+	}()
+
+	select {
+	case out := <-done:
+		// The handler may give up with the context's error just as the
+		// deadline passes; report that as the timeout it is
+		if out.err != nil && errors.Is(context.Cause(ctx), timeoutErr) {
+			return nil, timeoutErr
+		}
+		return out.result, out.err
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	}
+}
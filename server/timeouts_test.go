@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.ngs.io/google-mcp-server/config"
+	"go.ngs.io/google-mcp-server/internal/mcptest"
+)
+
+func TestToolTimeout(t *testing.T) {
+	srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{
+		Timeout:         60,
+		ServiceTimeouts: map[string]int{"slides": 600},
+		ToolTimeouts:    map[string]int{"slides_markdown_create": 900, "drive_file_upload": 0},
+	}})
+
+	tests := []struct {
+		service, tool string
+		want          time.Duration
+	}{
+		{"calendar", "calendar_events_list", time.Minute},
+		{"slides", "slides_get", 10 * time.Minute},
+		{"slides", "slides_markdown_create", 15 * time.Minute},
+		{"drive", "drive_file_upload", 0},
+	}
+	for _, tt := range tests {
+		if got := srv.toolTimeout(tt.service, tt.tool); got != tt.want {
+			t.Errorf("toolTimeout(%s, %s) = %v, want %v", tt.service, tt.tool, got, tt.want)
+		}
+	}
+
+	if got := NewMCPServer(&config.Config{}).toolTimeout("gmail", "gmail_messages_list"); got != config.DefaultTimeout*time.Second {
+		t.Errorf("expected the default timeout without configuration, got %v", got)
+	}
+}
+
+// stuckService has a tool that never returns, whatever its context says
+type stuckService struct {
+	stubService
+	release chan struct{}
+}
+
+func (stuckService) GetTools() []Tool {
+	return []Tool{
+		{Name: "stub_stuck", Description: "Never returns", InputSchema: InputSchema{Type: "object"}},
+	}
+}
+
+func (s stuckService) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
+	<-s.release
+	return nil, nil
+}
+
+func TestToolCallTimesOut(t *testing.T) {
+	service := stuckService{release: make(chan struct{})}
+	var once sync.Once
+	unstick := func() { once.Do(func() { close(service.release) }) }
+	defer unstick()
+	srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{
+		Transport:    config.TransportHTTP,
+		ToolTimeouts: map[string]int{"stub_stuck": 1},
+	}})
+	srv.RegisterService("stub", service)
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+	sessionID := initializeSession(t, endpoint)
+
+	start := time.Now()
+	resp := mcptest.Post(t, endpoint, sessionID, "application/json",
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"stub_stuck","arguments":{}}}`)
+	defer func() { _ = resp.Body.Close() }()
+	var msg struct {
+		Result struct {
+			Content []Content `json:"content"`
+			IsError bool      `json:"isError"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+		t.Fatalf("failed to decode tools/call response: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the call to be answered at its deadline, took %v", elapsed)
+	}
+
+	var body struct {
+		Error toolErrorDetail `json:"error"`
+	}
+	if !msg.Result.IsError || json.Unmarshal([]byte(msg.Result.Content[0].Text), &body) != nil {
+		t.Fatalf("expected an error result, got %+v", msg.Result)
+	}
+	if body.Error.Category != CategoryTimeout || body.Error.Tool != "stub_stuck" {
+		t.Errorf("expected a timeout of stub_stuck, got %+v", body.Error)
+	}
+	if !strings.Contains(body.Error.Message, "may have been applied") {
+		t.Errorf("expected the error to warn that the call may still take effect, got %q", body.Error.Message)
+	}
+
+	// The handler left behind keeps its worker slot until it returns
+	time.Sleep(100 * time.Millisecond)
+	if got := srv.workers.inFlight(); got != 1 {
+		t.Errorf("expected the stuck handler to hold a worker slot, got %d in flight", got)
+	}
+	unstick()
+	deadline := time.Now().Add(5 * time.Second)
+	for srv.workers.inFlight() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := srv.workers.inFlight(); got != 0 {
+		t.Errorf("expected the slot to be freed once the handler returned, got %d in flight", got)
+	}
+}
+
+func TestTimeoutErrorCategory(t *testing.T) {
+	err := fmt.Errorf("listing files: %w", &TimeoutError{Tool: "drive_files_list", Timeout: time.Minute})
+	if got := classifyError(err); got != CategoryTimeout {
+		t.Errorf("expected %q, got %q", CategoryTimeout, got)
+	}
+}
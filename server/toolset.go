@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// ToolDefinition is a tool declared together with its typed handler. The
+// input schema is generated from the handler's argument struct, so the
+// arguments a tool advertises are always the ones it decodes.
+type ToolDefinition struct {
+	Tool    Tool
+	argType reflect.Type
+	call    func(ctx context.Context, arguments json.RawMessage) (interface{}, error)
+}
+
+// NewTool declares a tool handled by handle. The name, description,
+// annotations and output schema are taken from tool; its input schema is
+// generated from A with SchemaOf.
+func NewTool[A any](tool Tool, handle func(ctx context.Context, args A) (interface{}, error)) ToolDefinition {
+	argType := reflect.TypeFor[A]()
+	tool.InputSchema = SchemaOf(argType)
+	return ToolDefinition{
+		Tool:    tool,
+		argType: argType,
+		call: func(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
+			var args A
+			if len(arguments) > 0 {
+				if err := json.Unmarshal(arguments, &args); err != nil {
+					return nil, fmt.Errorf("invalid arguments: %w", err)
+				}
+			}
+			return handle(ctx, args)
+		},
+	}
+}
+
+// ArgumentType returns the struct the tool's arguments are decoded into
+func (d ToolDefinition) ArgumentType() reflect.Type {
+	return d.argType
+}
+
+// Call decodes the arguments and runs the handler
+func (d ToolDefinition) Call(ctx context.Context, arguments json.RawMessage) (interface{}, error) {
+	return d.call(ctx, arguments)
+}
+
+// ToolSet is the tools of a service, declared once. Services return
+// Tools() from GetTools and dispatch HandleToolCall with Call.
+type ToolSet []ToolDefinition
+
+// ToolDefiner is implemented by services whose tools are declared as a
+// ToolSet, which lets tests check each advertised schema against the
+// argument struct behind it
+type ToolDefiner interface {
+	ToolDefinitions() ToolSet
+}
+
+// Tools returns the tools of the set
+func (s ToolSet) Tools() []Tool {
+	tools := make([]Tool, len(s))
+	for i, definition := range s {
+		tools[i] = definition.Tool
+	}
+	return tools
+}
+
+// Lookup finds the definition of the named tool
+func (s ToolSet) Lookup(name string) (ToolDefinition, bool) {
+	for _, definition := range s {
+		if definition.Tool.Name == name {
+			return definition, true
+		}
+	}
+	return ToolDefinition{}, false
+}
+
+// Call runs the named tool
+func (s ToolSet) Call(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
+	definition, ok := s.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown tool: %s", name)
+	}
+	return definition.Call(ctx, arguments)
+}
@@ -0,0 +1,227 @@
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// URITemplate is a compiled RFC 6570 URI template used to route resource reads.
+//
+// The supported subset covers what resource URIs need:
+//   - {var}   simple expansion, matches a single path segment
+//   - {+var}  reserved expansion, may span multiple segments
+//   - {?a,b}  form-style query, optional, must be the last expression
+//
+// Expressions may list several comma-separated variables.
+type URITemplate struct {
+	raw        string
+	pattern    *regexp.Regexp
+	pathVars   []string
+	queryVars  []string
+	expansions []templatePart
+}
+
+// templatePart is either a literal or an expression of a parsed template
+type templatePart struct {
+	literal  string
+	operator byte
+	vars     []string
+}
+
+// ParseURITemplate compiles an RFC 6570 URI template
+func ParseURITemplate(raw string) (*URITemplate, error) {
+	t := &URITemplate{raw: raw}
+
+	var re strings.Builder
+	re.WriteString("^")
+
+	rest := raw
+	for len(rest) > 0 {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			t.addLiteral(&re, rest)
+			break
+		}
+		if open > 0 {
+			t.addLiteral(&re, rest[:open])
+		}
+
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated expression in URI template %q", raw)
+		}
+		expr := rest[open+1 : open+end]
+		rest = rest[open+end+1:]
+
+		if expr == "" {
+			return nil, fmt.Errorf("empty expression in URI template %q", raw)
+		}
+
+		var operator byte
+		switch expr[0] {
+		case '+', '?':
+			operator = expr[0]
+			expr = expr[1:]
+		default:
+			if !isVarChar(expr[0]) {
+				return nil, fmt.Errorf("unsupported operator %q in URI template %q", expr[0], raw)
+			}
+		}
+
+		vars := strings.Split(expr, ",")
+		for _, name := range vars {
+			if name == "" {
+				return nil, fmt.Errorf("empty variable name in URI template %q", raw)
+			}
+			for i := 0; i < len(name); i++ {
+				if !isVarChar(name[i]) {
+					return nil, fmt.Errorf("invalid variable name %q in URI template %q", name, raw)
+				}
+			}
+		}
+
+		t.expansions = append(t.expansions, templatePart{operator: operator, vars: vars})
+
+		if operator == '?' {
+			if rest != "" {
+				return nil, fmt.Errorf("query expression must be last in URI template %q", raw)
+			}
+			t.queryVars = vars
+			continue
+		}
+
+		for i, name := range vars {
+			if i > 0 {
+				re.WriteString(",")
+			}
+			if operator == '+' {
+				re.WriteString("(.+?)")
+			} else {
+				re.WriteString("([^/?#,]+)")
+			}
+			t.pathVars = append(t.pathVars, name)
+		}
+	}
+
+	re.WriteString("$")
+	pattern, err := regexp.Compile(re.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile URI template %q: %w", raw, err)
+	}
+	t.pattern = pattern
+
+	return t, nil
+}
+
+// MustParseURITemplate is like ParseURITemplate but panics if the template
+// cannot be parsed. It is meant for templates known at compile time.
+func MustParseURITemplate(raw string) *URITemplate {
+	t, err := ParseURITemplate(raw)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// addLiteral appends a literal section to both the matcher and the expansion
+func (t *URITemplate) addLiteral(re *strings.Builder, literal string) {
+	re.WriteString(regexp.QuoteMeta(literal))
+	t.expansions = append(t.expansions, templatePart{literal: literal})
+}
+
+// isVarChar reports whether c may appear in a variable name
+func isVarChar(c byte) bool {
+	return c == '_' || c == '.' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// String returns the template as written
+func (t *URITemplate) String() string {
+	return t.raw
+}
+
+// Variables returns the names of all variables in the template
+func (t *URITemplate) Variables() []string {
+	vars := make([]string, 0, len(t.pathVars)+len(t.queryVars))
+	vars = append(vars, t.pathVars...)
+	vars = append(vars, t.queryVars...)
+	return vars
+}
+
+// Match reports whether uri matches the template and returns the extracted,
+// percent-decoded variables. Query variables absent from uri are omitted.
+func (t *URITemplate) Match(uri string) (map[string]string, bool) {
+	path, query := uri, ""
+	if t.queryVars != nil {
+		if i := strings.IndexByte(uri, '?'); i >= 0 {
+			path, query = uri[:i], uri[i+1:]
+		}
+	}
+
+	m := t.pattern.FindStringSubmatch(path)
+	if m == nil {
+		return nil, false
+	}
+
+	vars := make(map[string]string, len(t.pathVars)+len(t.queryVars))
+	for i, name := range t.pathVars {
+		value, err := url.PathUnescape(m[i+1])
+		if err != nil {
+			return nil, false
+		}
+		vars[name] = value
+	}
+
+	if query != "" {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return nil, false
+		}
+		for _, name := range t.queryVars {
+			if v := values.Get(name); v != "" {
+				vars[name] = v
+			}
+		}
+	}
+
+	return vars, true
+}
+
+// Expand builds a URI from the template. Missing path variables expand to
+// an empty string and missing query variables are left out.
+func (t *URITemplate) Expand(vars map[string]string) string {
+	var sb strings.Builder
+	for _, part := range t.expansions {
+		switch {
+		case part.literal != "":
+			sb.WriteString(part.literal)
+		case part.operator == '?':
+			sep := "?"
+			for _, name := range part.vars {
+				value, ok := vars[name]
+				if !ok || value == "" {
+					continue
+				}
+				sb.WriteString(sep)
+				sb.WriteString(url.QueryEscape(name))
+				sb.WriteString("=")
+				sb.WriteString(url.QueryEscape(value))
+				sep = "&"
+			}
+		default:
+			for i, name := range part.vars {
+				if i > 0 {
+					sb.WriteString(",")
+				}
+				if part.operator == '+' {
+					sb.WriteString(vars[name])
+				} else {
+					sb.WriteString(url.PathEscape(vars[name]))
+				}
+			}
+		}
+	}
+	return sb.String()
+}
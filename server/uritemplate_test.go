@@ -0,0 +1,106 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseURITemplateErrors(t *testing.T) {
+	tests := []string{
+		"drive://file/{id",
+		"drive://file/{}",
+		"drive://file/{#id}",
+		"drive://file/{i d}",
+		"drive://file/{id,}",
+		"drive://file/{?account}/{id}",
+	}
+	for _, raw := range tests {
+		if _, err := ParseURITemplate(raw); err == nil {
+			t.Errorf("expected error for %q", raw)
+		}
+	}
+}
+
+func TestMustParseURITemplatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for an invalid template")
+		}
+	}()
+	MustParseURITemplate("drive://file/{id")
+}
+
+func TestURITemplateMatch(t *testing.T) {
+	tests := []struct {
+		template string
+		uri      string
+		want     map[string]string
+		ok       bool
+	}{
+		{"drive://file/{id}", "drive://file/abc123", map[string]string{"id": "abc123"}, true},
+		{"drive://file/{id}", "drive://file/abc/def", nil, false},
+		{"drive://file/{id}", "drive://file/", nil, false},
+		{"drive://file/{id}", "gmail://file/abc", nil, false},
+		{"drive://file/{id}", "drive://file/a%20b", map[string]string{"id": "a b"}, true},
+		{
+			"calendar://event/{calendarId}/{eventId}",
+			"calendar://event/team%40example.com/ev1",
+			map[string]string{"calendarId": "team@example.com", "eventId": "ev1"},
+			true,
+		},
+		{"docs://{+path}", "docs://a/b/c", map[string]string{"path": "a/b/c"}, true},
+		{"drive://file/{id}{?account}", "drive://file/abc", map[string]string{"id": "abc"}, true},
+		{
+			"drive://file/{id}{?account}",
+			"drive://file/abc?account=me%40example.com",
+			map[string]string{"id": "abc", "account": "me@example.com"},
+			true,
+		},
+		{"drive://file/{id}{?account}", "drive://file/abc?other=1", map[string]string{"id": "abc"}, true},
+		{"drive://file/{id}", "drive://file/abc?account=x", nil, false},
+		{"tasks://list/{a,b}", "tasks://list/x,y", map[string]string{"a": "x", "b": "y"}, true},
+	}
+
+	for _, tt := range tests {
+		tmpl, err := ParseURITemplate(tt.template)
+		if err != nil {
+			t.Fatalf("ParseURITemplate(%q) error: %v", tt.template, err)
+		}
+		got, ok := tmpl.Match(tt.uri)
+		if ok != tt.ok {
+			t.Errorf("%q.Match(%q) ok = %v, want %v", tt.template, tt.uri, ok, tt.ok)
+			continue
+		}
+		if ok && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q.Match(%q) = %v, want %v", tt.template, tt.uri, got, tt.want)
+		}
+	}
+}
+
+func TestURITemplateExpand(t *testing.T) {
+	tmpl, err := ParseURITemplate("calendar://event/{calendarId}/{eventId}{?account}")
+	if err != nil {
+		t.Fatalf("ParseURITemplate error: %v", err)
+	}
+
+	if got := tmpl.Variables(); !reflect.DeepEqual(got, []string{"calendarId", "eventId", "account"}) {
+		t.Errorf("Variables() = %v", got)
+	}
+
+	got := tmpl.Expand(map[string]string{"calendarId": "a b", "eventId": "e1"})
+	if want := "calendar://event/a%20b/e1"; got != want {
+		t.Errorf("Expand() = %q, want %q", got, want)
+	}
+
+	vars := map[string]string{"calendarId": "primary", "eventId": "e1", "account": "me@example.com"}
+	uri := tmpl.Expand(vars)
+	if want := "calendar://event/primary/e1?account=me%40example.com"; uri != want {
+		t.Errorf("Expand() = %q, want %q", uri, want)
+	}
+
+	// Expansion and matching round-trip
+	matched, ok := tmpl.Match(uri)
+	if !ok || !reflect.DeepEqual(matched, vars) {
+		t.Errorf("Match(Expand()) = %v, %v; want %v", matched, ok, vars)
+	}
+}
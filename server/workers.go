@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// workerPool bounds the number of requests handled at the same time across
+// all sessions. Requests over the limit wait for a slot in their own
+// goroutine, so the read loop of a connection never blocks and a queued
+// request can still be cancelled.
+type workerPool struct {
+	slots   chan struct{}
+	running atomic.Int64
+}
+
+// newWorkerPool creates a pool running at most size requests at once
+func newWorkerPool(size int) *workerPool {
+	return &workerPool{slots: make(chan struct{}, size)}
+}
+
+// acquire waits for a free slot, or until ctx is done
+func (p *workerPool) acquire(ctx context.Context) error {
+	// A cancelled request gives up its place even when a slot is free
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case p.slots <- struct{}{}:
+		p.running.Add(1)
+		requestsInFlight.Add(1)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees the slot taken by acquire
+func (p *workerPool) release() {
+	p.running.Add(-1)
+	requestsInFlight.Add(-1)
+	<-p.slots
+}
+
+// workerSlot is the slot a request took from the pool. Work the request
+// leaves running, such as a tool handler past its deadline, holds it too,
+// so the slot is freed only when the last holder lets go.
+type workerSlot struct {
+	pool    *workerPool
+	holders atomic.Int32
+}
+
+// workerSlotKey is the context key of the request's workerSlot
+type workerSlotKey struct{}
+
+// withWorkerSlot returns a copy of ctx holding a slot acquired from p. The
+// caller releases it with the returned function.
+func (p *workerPool) withWorkerSlot(ctx context.Context) (context.Context, func()) {
+	slot := &workerSlot{pool: p}
+	slot.holders.Add(1)
+	return context.WithValue(ctx, workerSlotKey{}, slot), slot.release
+}
+
+// holdWorkerSlot keeps the slot of the request in ctx taken until the
+// returned function is called. Without a slot it does nothing.
+func holdWorkerSlot(ctx context.Context) func() {
+	slot, ok := ctx.Value(workerSlotKey{}).(*workerSlot)
+	if !ok {
+		return func() {}
+	}
+	slot.holders.Add(1)
+	return slot.release
+}
+
+func (s *workerSlot) release() {
+	if s.holders.Add(-1) == 0 {
+		s.pool.release()
+	}
+}
+
+// inFlight returns the number of requests holding a slot
+func (p *workerPool) inFlight() int {
+	return int(p.running.Load())
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"go.ngs.io/google-mcp-server/config"
+	"go.ngs.io/google-mcp-server/internal/mcptest"
+)
+
+func TestWorkerPool(t *testing.T) {
+	pool := newWorkerPool(1)
+	gauge := requestsInFlight.Value()
+	if err := pool.acquire(context.Background()); err != nil {
+		t.Fatalf("expected a free slot, got %v", err)
+	}
+	if pool.inFlight() != 1 {
+		t.Errorf("expected 1 request in flight, got %d", pool.inFlight())
+	}
+	if got := requestsInFlight.Value() - gauge; got != 1 {
+		t.Errorf("expected the in-flight gauge to count the request, got %v", got)
+	}
+
+	// The pool is full, so the next request waits until its context ends
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := pool.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the queued request to time out, got %v", err)
+	}
+
+	pool.release()
+	if pool.inFlight() != 0 {
+		t.Errorf("expected no request in flight, got %d", pool.inFlight())
+	}
+
+	// A cancelled request does not take a free slot
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if err := pool.acquire(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a cancelled request to be refused, got %v", err)
+	}
+}
+
+// gatedService has a tool that runs until it is let through or cancelled,
+// and reports the ID of each call it starts
+type gatedService struct {
+	stubService
+	started chan string
+	gate    chan struct{}
+}
+
+func (gatedService) GetTools() []Tool {
+	return []Tool{{Name: "stub_gated", Description: "Waits at a gate", InputSchema: InputSchema{
+		Type:       "object",
+		Properties: map[string]Property{"call": {Type: "string"}},
+	}}}
+}
+
+func (s gatedService) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
+	var args struct {
+		Call string `json:"call"`
+	}
+	_ = json.Unmarshal(arguments, &args)
+	s.started <- args.Call
+
+	select {
+	case <-s.gate:
+		return map[string]string{"call": args.Call}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestRequestsBeyondMaxConcurrencyAreQueued(t *testing.T) {
+	service := gatedService{started: make(chan string, 3), gate: make(chan struct{})}
+	srv := NewMCPServer(&config.Config{Global: config.GlobalConfig{Transport: config.TransportHTTP, MaxConcurrency: 1}})
+	srv.RegisterService("stub", service)
+	ts := newTestServerFor(t, srv)
+	endpoint := ts.URL + httpEndpointPath
+	sessionID := initializeSession(t, endpoint)
+
+	// Calls block until answered, so each is posted from its own goroutine
+	answered := make(chan string, 3)
+	call := func(id, name string) {
+		go func() {
+			req, _ := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(
+				`{"jsonrpc":"2.0","id":`+id+`,"method":"tools/call","params":{"name":"stub_gated","arguments":{"call":"`+name+`"}}}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept", "application/json")
+			req.Header.Set(sessionHeader, sessionID)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return
+			}
+			defer func() { _ = resp.Body.Close() }()
+			body, _ := io.ReadAll(resp.Body)
+			if len(body) > 0 {
+				answered <- name
+			}
+		}()
+	}
+	expectStarted := func(want string) {
+		t.Helper()
+		select {
+		case got := <-service.started:
+			if got != want {
+				t.Fatalf("expected call %s to start, got %s", want, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("call %s did not start", want)
+		}
+	}
+
+	call("10", "first")
+	expectStarted("first")
+
+	// The only slot is taken, so the next call waits for it
+	call("11", "second")
+	select {
+	case got := <-service.started:
+		t.Fatalf("expected call %s to wait for a free slot", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Requests answered inline are not held up by the busy pool
+	resp := mcptest.Post(t, endpoint, sessionID, "application/json", `{"jsonrpc":"2.0","id":12,"method":"tools/list"}`)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for tools/list, got %d", resp.StatusCode)
+	}
+
+	// A queued request can be cancelled; it never runs
+	resp = mcptest.Post(t, endpoint, sessionID, "application/json",
+		`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":11}}`)
+	_ = resp.Body.Close()
+
+	call("13", "third")
+	service.gate <- struct{}{}
+	select {
+	case got := <-answered:
+		if got != "first" {
+			t.Fatalf("expected the first call to be answered, got %s", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("first call was not answered")
+	}
+
+	// The freed slot goes to the third call, not the cancelled second one
+	expectStarted("third")
+	service.gate <- struct{}{}
+	select {
+	case got := <-answered:
+		if got != "third" {
+			t.Fatalf("expected the third call to be answered, got %s", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("third call was not answered")
+	}
+
+	// Slots are released once the handler returns, just after it replies
+	deadline := time.Now().Add(5 * time.Second)
+	for srv.workers.inFlight() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected every slot to be released, got %d in flight", srv.workers.inFlight())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
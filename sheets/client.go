@@ -26,8 +26,8 @@ func NewClient(ctx context.Context, oauth *auth.OAuthClient) (*Client, error) {
 }
 
 // GetSpreadsheet gets spreadsheet metadata
-func (c *Client) GetSpreadsheet(spreadsheetID string) (*sheets.Spreadsheet, error) {
-	spreadsheet, err := c.service.Spreadsheets.Get(spreadsheetID).Do()
+func (c *Client) GetSpreadsheet(ctx context.Context, spreadsheetID string) (*sheets.Spreadsheet, error) {
+	spreadsheet, err := c.service.Spreadsheets.Get(spreadsheetID).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get spreadsheet: %w", err)
 	}
@@ -35,21 +35,26 @@ func (c *Client) GetSpreadsheet(spreadsheetID string) (*sheets.Spreadsheet, erro
 }
 
 // GetValues gets cell values from a range
-func (c *Client) GetValues(spreadsheetID, range_ string) (*sheets.ValueRange, error) {
-	values, err := c.service.Spreadsheets.Values.Get(spreadsheetID, range_).Do()
+func (c *Client) GetValues(ctx context.Context, spreadsheetID, range_ string) (*sheets.ValueRange, error) {
+	values, err := c.service.Spreadsheets.Values.Get(spreadsheetID, range_).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get values: %w", err)
 	}
 	return values, nil
 }
 
-// UpdateValues updates cell values in a range
-func (c *Client) UpdateValues(spreadsheetID, range_ string, values [][]interface{}) (*sheets.UpdateValuesResponse, error) {
+// UpdateValues updates cell values in a range. An empty valueInputOption
+// means USER_ENTERED and an empty majorDimension means rows.
+func (c *Client) UpdateValues(ctx context.Context, spreadsheetID, range_ string, values [][]interface{}, valueInputOption, majorDimension string) (*sheets.UpdateValuesResponse, error) {
+	if valueInputOption == "" {
+		valueInputOption = "USER_ENTERED"
+	}
 	valueRange := &sheets.ValueRange{
-		Values: values,
+		Values:         values,
+		MajorDimension: majorDimension,
 	}
 	response, err := c.service.Spreadsheets.Values.Update(spreadsheetID, range_, valueRange).
-		ValueInputOption("USER_ENTERED").Do()
+		ValueInputOption(valueInputOption).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to update values: %w", err)
 	}
@@ -18,154 +18,139 @@ func NewHandler(client *Client) *Handler {
 	return &Handler{client: client}
 }
 
-// GetTools returns the available Sheets tools
-func (h *Handler) GetTools() []server.Tool {
-	return []server.Tool{
-		{
-			Name:        "sheets_spreadsheet_get",
-			Description: "Get spreadsheet metadata",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"spreadsheet_id": {
-						Type:        "string",
-						Description: "Spreadsheet ID",
-					},
+// Arguments of the Sheets tools. The input schemas are generated from these
+// structs, see server.SchemaOf.
+
+type spreadsheetArgs struct {
+	SpreadsheetID string `json:"spreadsheet_id" required:"true" description:"Spreadsheet ID"`
+}
+
+type getValuesArgs struct {
+	SpreadsheetID string `json:"spreadsheet_id" required:"true" description:"Spreadsheet ID"`
+	Range         string `json:"range" required:"true" description:"A1 notation range (e.g., 'Sheet1!A1:B10')"`
+}
+
+type updateValuesArgs struct {
+	SpreadsheetID    string     `json:"spreadsheet_id" required:"true" description:"Spreadsheet ID"`
+	Range            string     `json:"range" required:"true" description:"A1 notation range"`
+	Values           cellValues `json:"values" required:"true" minItems:"1" description:"2D array of values, one inner array per row (or per column with major_dimension COLUMNS)"`
+	ValueInputOption string     `json:"value_input_option" enum:"USER_ENTERED,RAW" default:"USER_ENTERED" description:"USER_ENTERED parses values as if typed into the UI (formulas, dates, numbers); RAW stores them as given"`
+	MajorDimension   string     `json:"major_dimension" enum:"ROWS,COLUMNS" default:"ROWS" description:"Whether the inner arrays of values are rows or columns"`
+}
+
+// cellValues are the rows (or columns) of cells written by
+// sheets_values_update. A cell is a string, number, boolean or null.
+type cellValues [][]interface{}
+
+// PropertySchema implements server.PropertySchemer
+func (cellValues) PropertySchema() server.Property {
+	return server.Property{
+		Type: "array",
+		Items: &server.Property{
+			Type: "array",
+			Items: &server.Property{
+				Description: "Cell value; null leaves the cell unchanged and an empty string clears it",
+				OneOf: []server.Property{
+					{Type: "string"},
+					{Type: "number"},
+					{Type: "boolean"},
+					{Type: "null"},
 				},
-				Required: []string{"spreadsheet_id"},
 			},
 		},
-		{
+	}
+}
+
+// ToolDefinitions declares the Sheets tools together with their handlers
+func (h *Handler) ToolDefinitions() server.ToolSet {
+	return server.ToolSet{
+		server.NewTool(server.Tool{
+			Name:        "sheets_spreadsheet_get",
+			Description: "Get spreadsheet metadata",
+			Annotations: server.ReadOnlyAnnotations(),
+		}, h.handleSpreadsheetGet),
+		server.NewTool(server.Tool{
 			Name:        "sheets_values_get",
 			Description: "Get cell values from a range",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"spreadsheet_id": {
-						Type:        "string",
-						Description: "Spreadsheet ID",
-					},
-					"range": {
-						Type:        "string",
-						Description: "A1 notation range (e.g., 'Sheet1!A1:B10')",
-					},
-				},
-				Required: []string{"spreadsheet_id", "range"},
-			},
-		},
-		{
+			Annotations: server.ReadOnlyAnnotations(),
+		}, h.handleValuesGet),
+		server.NewTool(server.Tool{
 			Name:        "sheets_values_update",
 			Description: "Update cell values in a range",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"spreadsheet_id": {
-						Type:        "string",
-						Description: "Spreadsheet ID",
-					},
-					"range": {
-						Type:        "string",
-						Description: "A1 notation range",
-					},
-					"values": {
-						Type:        "array",
-						Description: "2D array of values",
-						Items: &server.Property{
-							Type: "array",
-						},
-					},
-				},
-				Required: []string{"spreadsheet_id", "range", "values"},
-			},
-		},
+			Annotations: server.DestructiveAnnotations(),
+		}, h.handleValuesUpdate),
 	}
 }
 
+// GetTools returns the available Sheets tools
+func (h *Handler) GetTools() []server.Tool {
+	return h.ToolDefinitions().Tools()
+}
+
 // HandleToolCall handles a tool call for Sheets service
 func (h *Handler) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
-	switch name {
-	case "sheets_spreadsheet_get":
-		var args struct {
-			SpreadsheetID string `json:"spreadsheet_id"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		spreadsheet, err := h.client.GetSpreadsheet(args.SpreadsheetID)
-		if err != nil {
-			return nil, err
-		}
+	return h.ToolDefinitions().Call(ctx, name, arguments)
+}
 
-		// Format spreadsheet metadata for response
-		result := map[string]interface{}{
-			"spreadsheetId":  spreadsheet.SpreadsheetId,
-			"spreadsheetUrl": spreadsheet.SpreadsheetUrl,
-			"title":          spreadsheet.Properties.Title,
-		}
+func (h *Handler) handleSpreadsheetGet(ctx context.Context, args spreadsheetArgs) (interface{}, error) {
+	spreadsheet, err := h.client.GetSpreadsheet(ctx, args.SpreadsheetID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Format spreadsheet metadata for response
+	result := map[string]interface{}{
+		"spreadsheetId":  spreadsheet.SpreadsheetId,
+		"spreadsheetUrl": spreadsheet.SpreadsheetUrl,
+		"title":          spreadsheet.Properties.Title,
+	}
 
-		// Add sheets information
-		if len(spreadsheet.Sheets) > 0 {
-			sheets := make([]map[string]interface{}, len(spreadsheet.Sheets))
-			for i, sheet := range spreadsheet.Sheets {
-				sheets[i] = map[string]interface{}{
-					"sheetId": sheet.Properties.SheetId,
-					"title":   sheet.Properties.Title,
-					"index":   sheet.Properties.Index,
-				}
+	// Add sheets information
+	if len(spreadsheet.Sheets) > 0 {
+		sheets := make([]map[string]interface{}, len(spreadsheet.Sheets))
+		for i, sheet := range spreadsheet.Sheets {
+			sheets[i] = map[string]interface{}{
+				"sheetId": sheet.Properties.SheetId,
+				"title":   sheet.Properties.Title,
+				"index":   sheet.Properties.Index,
 			}
-			result["sheets"] = sheets
 		}
+		result["sheets"] = sheets
+	}
 
-		return result, nil
-
-	case "sheets_values_get":
-		var args struct {
-			SpreadsheetID string `json:"spreadsheet_id"`
-			Range         string `json:"range"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		values, err := h.client.GetValues(args.SpreadsheetID, args.Range)
-		if err != nil {
-			return nil, err
-		}
+	return result, nil
+}
 
-		// Format values response
-		result := map[string]interface{}{
-			"range":          values.Range,
-			"majorDimension": values.MajorDimension,
-			"values":         values.Values,
-		}
-		return result, nil
+func (h *Handler) handleValuesGet(ctx context.Context, args getValuesArgs) (interface{}, error) {
+	values, err := h.client.GetValues(ctx, args.SpreadsheetID, args.Range)
+	if err != nil {
+		return nil, err
+	}
 
-	case "sheets_values_update":
-		var args struct {
-			SpreadsheetID string          `json:"spreadsheet_id"`
-			Range         string          `json:"range"`
-			Values        [][]interface{} `json:"values"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		response, err := h.client.UpdateValues(args.SpreadsheetID, args.Range, args.Values)
-		if err != nil {
-			return nil, err
-		}
+	// Format values response
+	result := map[string]interface{}{
+		"range":          values.Range,
+		"majorDimension": values.MajorDimension,
+		"values":         values.Values,
+	}
+	return result, nil
+}
 
-		// Format update response
-		result := map[string]interface{}{
-			"spreadsheetId":  response.SpreadsheetId,
-			"updatedRange":   response.UpdatedRange,
-			"updatedRows":    response.UpdatedRows,
-			"updatedColumns": response.UpdatedColumns,
-			"updatedCells":   response.UpdatedCells,
-		}
-		return result, nil
+func (h *Handler) handleValuesUpdate(ctx context.Context, args updateValuesArgs) (interface{}, error) {
+	response, err := h.client.UpdateValues(ctx, args.SpreadsheetID, args.Range, args.Values, args.ValueInputOption, args.MajorDimension)
+	if err != nil {
+		return nil, err
+	}
 
-	default:
-		return nil, fmt.Errorf("unknown tool: %s", name)
+	// Format update response
+	result := map[string]interface{}{
+		"spreadsheetId":  response.SpreadsheetId,
+		"updatedRange":   response.UpdatedRange,
+		"updatedRows":    response.UpdatedRows,
+		"updatedColumns": response.UpdatedColumns,
+		"updatedCells":   response.UpdatedCells,
 	}
+	return result, nil
 }
 
 // GetResources returns the available Sheets resources
@@ -3,15 +3,19 @@ package slides
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"regexp"
 	"time"
 	"unicode/utf16"
 
+	"go.ngs.io/google-mcp-server/logging"
 	"google.golang.org/api/option"
 	"google.golang.org/api/slides/v1"
 )
 
+var logger = logging.Logger("slides")
+
 type FormatRange struct {
 	Start  int
 	End    int
@@ -20,7 +24,8 @@ type FormatRange struct {
 }
 
 type Client struct {
-	service *slides.Service
+	service    *slides.Service
+	httpClient *http.Client
 }
 
 func NewClient(ctx context.Context, client *http.Client) (*Client, error) {
@@ -28,23 +33,65 @@ func NewClient(ctx context.Context, client *http.Client) (*Client, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create slides service: %w", err)
 	}
-	return &Client{service: service}, nil
+	return &Client{service: service, httpClient: client}, nil
+}
+
+// maxThumbnailSize bounds the size of a fetched slide thumbnail (10MB)
+const maxThumbnailSize = 10 * 1024 * 1024
+
+// GetSlideThumbnail renders a slide as a PNG of the given size (SMALL, MEDIUM
+// or LARGE) and returns the thumbnail with the image data
+func (c *Client) GetSlideThumbnail(ctx context.Context, presentationId, slideId, size string) (*slides.Thumbnail, []byte, error) {
+	thumbnail, err := c.service.Presentations.Pages.GetThumbnail(presentationId, slideId).
+		ThumbnailPropertiesMimeType("PNG").
+		ThumbnailPropertiesThumbnailSize(size).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	httpClient := c.httpClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, thumbnail.ContentUrl, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid thumbnail URL: %w", err)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch thumbnail: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("failed to fetch thumbnail: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxThumbnailSize+1))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read thumbnail: %w", err)
+	}
+	if len(data) > maxThumbnailSize {
+		return nil, nil, fmt.Errorf("thumbnail exceeded maximum size of %d bytes", maxThumbnailSize)
+	}
+	return thumbnail, data, nil
 }
 
-func (c *Client) CreatePresentation(title string) (*slides.Presentation, error) {
+func (c *Client) CreatePresentation(ctx context.Context, title string) (*slides.Presentation, error) {
 	presentation := &slides.Presentation{
 		Title: title,
 	}
-	return c.service.Presentations.Create(presentation).Do()
+	return c.service.Presentations.Create(presentation).Context(ctx).Do()
 }
 
-func (c *Client) GetPresentation(presentationId string) (*slides.Presentation, error) {
-	return c.service.Presentations.Get(presentationId).Do()
+func (c *Client) GetPresentation(ctx context.Context, presentationId string) (*slides.Presentation, error) {
+	return c.service.Presentations.Get(presentationId).Context(ctx).Do()
 }
 
 // GetLayoutId gets the layout ID by name from a presentation
-func (c *Client) GetLayoutId(presentationId string, layoutName string) (string, error) {
-	presentation, err := c.GetPresentation(presentationId)
+func (c *Client) GetLayoutId(ctx context.Context, presentationId string, layoutName string) (string, error) {
+	presentation, err := c.GetPresentation(ctx, presentationId)
 	if err != nil {
 		return "", err
 	}
@@ -82,13 +129,13 @@ func (c *Client) GetLayoutId(presentationId string, layoutName string) (string,
 	return "", fmt.Errorf("no layout found with name: %s", layoutName)
 }
 
-func (c *Client) ListPresentations() ([]*slides.Presentation, error) {
+func (c *Client) ListPresentations(ctx context.Context) ([]*slides.Presentation, error) {
 	// Note: Slides API doesn't have a direct list method like Drive
 	// This would typically be done through Drive API
 	return nil, fmt.Errorf("use Drive API to list presentations")
 }
 
-func (c *Client) CreateSlide(presentationId string, insertionIndex int) (*slides.BatchUpdatePresentationResponse, error) {
+func (c *Client) CreateSlide(ctx context.Context, presentationId string, insertionIndex int) (*slides.BatchUpdatePresentationResponse, error) {
 	createSlideReq := &slides.CreateSlideRequest{}
 
 	// Only set InsertionIndex if it's >= 0
@@ -107,11 +154,11 @@ func (c *Client) CreateSlide(presentationId string, insertionIndex int) (*slides
 		Requests: requests,
 	}
 
-	return c.service.Presentations.BatchUpdate(presentationId, req).Do()
+	return c.service.Presentations.BatchUpdate(presentationId, req).Context(ctx).Do()
 }
 
 // CreateSlideWithLayout creates a new slide with a specific layout
-func (c *Client) CreateSlideWithLayout(presentationId string, layoutId string, insertionIndex int) (*slides.BatchUpdatePresentationResponse, error) {
+func (c *Client) CreateSlideWithLayout(ctx context.Context, presentationId string, layoutId string, insertionIndex int) (*slides.BatchUpdatePresentationResponse, error) {
 	createSlideReq := &slides.CreateSlideRequest{
 		SlideLayoutReference: &slides.LayoutReference{
 			LayoutId: layoutId,
@@ -134,10 +181,10 @@ func (c *Client) CreateSlideWithLayout(presentationId string, layoutId string, i
 		Requests: requests,
 	}
 
-	return c.service.Presentations.BatchUpdate(presentationId, req).Do()
+	return c.service.Presentations.BatchUpdate(presentationId, req).Context(ctx).Do()
 }
 
-func (c *Client) DeleteSlide(presentationId string, slideId string) (*slides.BatchUpdatePresentationResponse, error) {
+func (c *Client) DeleteSlide(ctx context.Context, presentationId string, slideId string) (*slides.BatchUpdatePresentationResponse, error) {
 	requests := []*slides.Request{
 		{
 			DeleteObject: &slides.DeleteObjectRequest{
@@ -150,10 +197,10 @@ func (c *Client) DeleteSlide(presentationId string, slideId string) (*slides.Bat
 		Requests: requests,
 	}
 
-	return c.service.Presentations.BatchUpdate(presentationId, req).Do()
+	return c.service.Presentations.BatchUpdate(presentationId, req).Context(ctx).Do()
 }
 
-func (c *Client) DuplicateSlide(presentationId string, slideId string) (*slides.BatchUpdatePresentationResponse, error) {
+func (c *Client) DuplicateSlide(ctx context.Context, presentationId string, slideId string) (*slides.BatchUpdatePresentationResponse, error) {
 	requests := []*slides.Request{
 		{
 			DuplicateObject: &slides.DuplicateObjectRequest{
@@ -166,7 +213,7 @@ func (c *Client) DuplicateSlide(presentationId string, slideId string) (*slides.
 		Requests: requests,
 	}
 
-	return c.service.Presentations.BatchUpdate(presentationId, req).Do()
+	return c.service.Presentations.BatchUpdate(presentationId, req).Context(ctx).Do()
 }
 
 // ReplaceAllTextInShape replaces all text in existing shapes on a slide
@@ -196,7 +243,7 @@ func (c *Client) processMarkdownText(text string) (string, []FormatRange) {
 	return result, []FormatRange{}
 }
 
-func (c *Client) ReplaceAllTextInSlide(presentationId string, slideId string, oldText, newText string) (*slides.BatchUpdatePresentationResponse, error) {
+func (c *Client) ReplaceAllTextInSlide(ctx context.Context, presentationId string, slideId string, oldText, newText string) (*slides.BatchUpdatePresentationResponse, error) {
 	requests := []*slides.Request{
 		{
 			ReplaceAllText: &slides.ReplaceAllTextRequest{
@@ -214,11 +261,11 @@ func (c *Client) ReplaceAllTextInSlide(presentationId string, slideId string, ol
 		Requests: requests,
 	}
 
-	return c.service.Presentations.BatchUpdate(presentationId, req).Do()
+	return c.service.Presentations.BatchUpdate(presentationId, req).Context(ctx).Do()
 }
 
 // InsertTextInPlaceholder inserts text into a placeholder shape
-func (c *Client) InsertTextInPlaceholder(presentationId string, shapeId string, text string) (*slides.BatchUpdatePresentationResponse, error) {
+func (c *Client) InsertTextInPlaceholder(ctx context.Context, presentationId string, shapeId string, text string) (*slides.BatchUpdatePresentationResponse, error) {
 	// Process markdown formatting properly for placeholders
 	processedText, formatRanges := c.processMarkdownTextWithFormatting(text)
 
@@ -257,7 +304,7 @@ func (c *Client) InsertTextInPlaceholder(presentationId string, shapeId string,
 		Requests: requests,
 	}
 
-	return c.service.Presentations.BatchUpdate(presentationId, req).Do()
+	return c.service.Presentations.BatchUpdate(presentationId, req).Context(ctx).Do()
 }
 
 // processMarkdownTextWithFormatting processes markdown with proper formatting for placeholders
@@ -481,7 +528,7 @@ func (c *Client) processMarkdownTextWithFormatting(text string) (string, []Forma
 }
 
 // DeleteTextInPlaceholder deletes existing text in a placeholder
-func (c *Client) DeleteTextInPlaceholder(presentationId string, shapeId string) (*slides.BatchUpdatePresentationResponse, error) {
+func (c *Client) DeleteTextInPlaceholder(ctx context.Context, presentationId string, shapeId string) (*slides.BatchUpdatePresentationResponse, error) {
 	requests := []*slides.Request{
 		{
 			DeleteText: &slides.DeleteTextRequest{
@@ -497,10 +544,10 @@ func (c *Client) DeleteTextInPlaceholder(presentationId string, shapeId string)
 		Requests: requests,
 	}
 
-	return c.service.Presentations.BatchUpdate(presentationId, req).Do()
+	return c.service.Presentations.BatchUpdate(presentationId, req).Context(ctx).Do()
 }
 
-func (c *Client) AddTextBox(presentationId string, slideId string, text string, x, y, width, height float64) (*slides.BatchUpdatePresentationResponse, error) {
+func (c *Client) AddTextBox(ctx context.Context, presentationId string, slideId string, text string, x, y, width, height float64) (*slides.BatchUpdatePresentationResponse, error) {
 	// Validate dimensions to avoid "affine transform is not invertible" error
 	if width <= 0 {
 		width = 400 // Default width
@@ -557,7 +604,7 @@ func (c *Client) AddTextBox(presentationId string, slideId string, text string,
 		Requests: requests,
 	}
 
-	resp, err := c.service.Presentations.BatchUpdate(presentationId, req).Do()
+	resp, err := c.service.Presentations.BatchUpdate(presentationId, req).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to add text box: %w", err)
 	}
@@ -570,7 +617,7 @@ func (c *Client) AddTextBox(presentationId string, slideId string, text string,
 	return resp, nil
 }
 
-func (c *Client) AddCodeTextBox(presentationId string, slideId string, text string, x, y, width, height float64) (*slides.BatchUpdatePresentationResponse, error) {
+func (c *Client) AddCodeTextBox(ctx context.Context, presentationId string, slideId string, text string, x, y, width, height float64) (*slides.BatchUpdatePresentationResponse, error) {
 	// Validate dimensions
 	if width <= 0 {
 		width = 400
@@ -636,10 +683,10 @@ func (c *Client) AddCodeTextBox(presentationId string, slideId string, text stri
 		Requests: requests,
 	}
 
-	return c.service.Presentations.BatchUpdate(presentationId, req).Do()
+	return c.service.Presentations.BatchUpdate(presentationId, req).Context(ctx).Do()
 }
 
-func (c *Client) AddImage(presentationId string, slideId string, imageUrl string, x, y, width, height float64) (*slides.BatchUpdatePresentationResponse, error) {
+func (c *Client) AddImage(ctx context.Context, presentationId string, slideId string, imageUrl string, x, y, width, height float64) (*slides.BatchUpdatePresentationResponse, error) {
 	elementId := fmt.Sprintf("image_%s", generateId())
 
 	requests := []*slides.Request{
@@ -675,10 +722,10 @@ func (c *Client) AddImage(presentationId string, slideId string, imageUrl string
 		Requests: requests,
 	}
 
-	return c.service.Presentations.BatchUpdate(presentationId, req).Do()
+	return c.service.Presentations.BatchUpdate(presentationId, req).Context(ctx).Do()
 }
 
-func (c *Client) AddTable(presentationId string, slideId string, rows, columns int, x, y, width, height float64) (*slides.BatchUpdatePresentationResponse, error) {
+func (c *Client) AddTable(ctx context.Context, presentationId string, slideId string, rows, columns int, x, y, width, height float64) (*slides.BatchUpdatePresentationResponse, error) {
 	// Validate dimensions to avoid "affine transform is not invertible" error
 	if width <= 0 {
 		width = 400 // Default width
@@ -729,10 +776,10 @@ func (c *Client) AddTable(presentationId string, slideId string, rows, columns i
 		Requests: requests,
 	}
 
-	return c.service.Presentations.BatchUpdate(presentationId, req).Do()
+	return c.service.Presentations.BatchUpdate(presentationId, req).Context(ctx).Do()
 }
 
-func (c *Client) AddShape(presentationId string, slideId string, shapeType string, x, y, width, height float64) (*slides.BatchUpdatePresentationResponse, error) {
+func (c *Client) AddShape(ctx context.Context, presentationId string, slideId string, shapeType string, x, y, width, height float64) (*slides.BatchUpdatePresentationResponse, error) {
 	// Validate dimensions to avoid "affine transform is not invertible" error
 	if width <= 0 {
 		width = 100 // Default width
@@ -776,16 +823,16 @@ func (c *Client) AddShape(presentationId string, slideId string, shapeType strin
 		Requests: requests,
 	}
 
-	return c.service.Presentations.BatchUpdate(presentationId, req).Do()
+	return c.service.Presentations.BatchUpdate(presentationId, req).Context(ctx).Do()
 }
 
-func (c *Client) ApplyTemplate(presentationId string, templateId string) (*slides.BatchUpdatePresentationResponse, error) {
+func (c *Client) ApplyTemplate(ctx context.Context, presentationId string, templateId string) (*slides.BatchUpdatePresentationResponse, error) {
 	// This would require fetching the template and applying its layouts
 	// Complex operation that might need multiple API calls
 	return nil, fmt.Errorf("template application not yet implemented")
 }
 
-func (c *Client) SetSlideLayout(presentationId string, slideId string, layoutId string) (*slides.BatchUpdatePresentationResponse, error) {
+func (c *Client) SetSlideLayout(ctx context.Context, presentationId string, slideId string, layoutId string) (*slides.BatchUpdatePresentationResponse, error) {
 	requests := []*slides.Request{
 		{
 			UpdatePageProperties: &slides.UpdatePagePropertiesRequest{
@@ -802,19 +849,19 @@ func (c *Client) SetSlideLayout(presentationId string, slideId string, layoutId
 		Requests: requests,
 	}
 
-	return c.service.Presentations.BatchUpdate(presentationId, req).Do()
+	return c.service.Presentations.BatchUpdate(presentationId, req).Context(ctx).Do()
 }
 
-func (c *Client) BatchUpdate(presentationId string, requests []*slides.Request) (*slides.BatchUpdatePresentationResponse, error) {
+func (c *Client) BatchUpdate(ctx context.Context, presentationId string, requests []*slides.Request) (*slides.BatchUpdatePresentationResponse, error) {
 	req := &slides.BatchUpdatePresentationRequest{
 		Requests: requests,
 	}
 
-	return c.service.Presentations.BatchUpdate(presentationId, req).Do()
+	return c.service.Presentations.BatchUpdate(presentationId, req).Context(ctx).Do()
 }
 
 // InsertTextInTableCell inserts text into a specific table cell
-func (c *Client) InsertTextInTableCell(presentationId string, tableId string, row, col int, text string) (*slides.BatchUpdatePresentationResponse, error) {
+func (c *Client) InsertTextInTableCell(ctx context.Context, presentationId string, tableId string, row, col int, text string) (*slides.BatchUpdatePresentationResponse, error) {
 	requests := []*slides.Request{
 		{
 			InsertText: &slides.InsertTextRequest{
@@ -833,11 +880,43 @@ func (c *Client) InsertTextInTableCell(presentationId string, tableId string, ro
 		Requests: requests,
 	}
 
-	return c.service.Presentations.BatchUpdate(presentationId, req).Do()
+	return c.service.Presentations.BatchUpdate(presentationId, req).Context(ctx).Do()
+}
+
+// FillTable inserts text into the cells of a table in a single batch update.
+// data is indexed by row and then column; empty strings are skipped.
+func (c *Client) FillTable(ctx context.Context, presentationId string, tableId string, data [][]string) (*slides.BatchUpdatePresentationResponse, error) {
+	var requests []*slides.Request
+	for row, cells := range data {
+		for col, text := range cells {
+			if text == "" {
+				continue
+			}
+			requests = append(requests, &slides.Request{
+				InsertText: &slides.InsertTextRequest{
+					ObjectId: tableId,
+					CellLocation: &slides.TableCellLocation{
+						RowIndex:    int64(row),
+						ColumnIndex: int64(col),
+					},
+					Text: text,
+				},
+			})
+		}
+	}
+	if len(requests) == 0 {
+		return nil, nil
+	}
+
+	req := &slides.BatchUpdatePresentationRequest{
+		Requests: requests,
+	}
+
+	return c.service.Presentations.BatchUpdate(presentationId, req).Context(ctx).Do()
 }
 
 // ApplyCodeFormattingToPlaceholder applies Courier New font to specific text ranges in a placeholder
-func (c *Client) ApplyCodeFormattingToPlaceholder(presentationId string, shapeId string, codeRanges []struct {
+func (c *Client) ApplyCodeFormattingToPlaceholder(ctx context.Context, presentationId string, shapeId string, codeRanges []struct {
 	start int
 	end   int
 }) error {
@@ -870,7 +949,7 @@ func (c *Client) ApplyCodeFormattingToPlaceholder(presentationId string, shapeId
 		Requests: requests,
 	}
 
-	_, err := c.service.Presentations.BatchUpdate(presentationId, req).Do()
+	_, err := c.service.Presentations.BatchUpdate(presentationId, req).Context(ctx).Do()
 	return err
 }
 
@@ -254,7 +254,7 @@ func TestApplyCodeFormattingRanges(t *testing.T) {
 			// Just verify the method exists and handles nil/empty cases
 			if len(tt.ranges) == 0 {
 				client := &Client{}
-				err := client.ApplyCodeFormattingToPlaceholder("test-id", "shape-id", tt.ranges)
+				err := client.ApplyCodeFormattingToPlaceholder(context.Background(), "test-id", "shape-id", tt.ranges)
 				if err != nil {
 					t.Errorf("ApplyCodeFormattingToPlaceholder() with empty ranges should not error: %v", err)
 				}
@@ -1,11 +1,13 @@
 package slides
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
 	"unicode/utf16"
 
+	"go.ngs.io/google-mcp-server/server"
 	"google.golang.org/api/slides/v1"
 )
 
@@ -269,11 +271,11 @@ func (mc *MarkdownConverter) estimateLineHeight(line string) float64 {
 	}
 }
 
-func (mc *MarkdownConverter) CreateSlidesFromMarkdown(markdown string) ([]*slides.Page, error) {
+func (mc *MarkdownConverter) CreateSlidesFromMarkdown(ctx context.Context, markdown string) ([]*slides.Page, error) {
 	parsedSlides := mc.ParseMarkdown(markdown)
 
 	// Get current presentation to check existing slides
-	presentation, err := mc.client.GetPresentation(mc.presentationId)
+	presentation, err := mc.client.GetPresentation(ctx, mc.presentationId)
 	if err != nil {
 		return nil, err
 	}
@@ -281,27 +283,27 @@ func (mc *MarkdownConverter) CreateSlidesFromMarkdown(markdown string) ([]*slide
 	// Delete the first slide if it exists (the default title slide)
 	if len(presentation.Slides) > 0 {
 		firstSlideId := presentation.Slides[0].ObjectId
-		_, err := mc.client.DeleteSlide(mc.presentationId, firstSlideId)
+		_, err := mc.client.DeleteSlide(ctx, mc.presentationId, firstSlideId)
 		if err != nil {
 			// Log error but continue
-			fmt.Printf("Warning: failed to delete first slide: %v\n", err)
+			logger.Warn("failed to delete first slide", "presentation", mc.presentationId, "error", err)
 		}
 	}
 
 	// Get the TITLE_AND_BODY layout ID
-	layoutId, err := mc.client.GetLayoutId(mc.presentationId, "TITLE_AND_BODY")
+	layoutId, err := mc.client.GetLayoutId(ctx, mc.presentationId, "TITLE_AND_BODY")
 	if err != nil {
 		// Fallback to blank slides if layout not found
-		fmt.Printf("Warning: failed to get TITLE_AND_BODY layout: %v\n", err)
+		logger.Warn("TITLE_AND_BODY layout not found, using blank slides", "presentation", mc.presentationId, "error", err)
 		layoutId = ""
 	}
 
 	// Get the TITLE layout ID for title slides (slides with only two headings)
-	titleLayoutId, _ := mc.client.GetLayoutId(mc.presentationId, "TITLE")
+	titleLayoutId, _ := mc.client.GetLayoutId(ctx, mc.presentationId, "TITLE")
 
 	// Create all slides fresh
 	// Get the TITLE_ONLY layout ID for slides with tables
-	titleOnlyLayoutId, _ := mc.client.GetLayoutId(mc.presentationId, "TITLE_ONLY")
+	titleOnlyLayoutId, _ := mc.client.GetLayoutId(ctx, mc.presentationId, "TITLE_ONLY")
 
 	for i, slide := range parsedSlides {
 		// Check if slide contains tables or images (both need more space)
@@ -345,22 +347,22 @@ func (mc *MarkdownConverter) CreateSlidesFromMarkdown(markdown string) ([]*slide
 		var layoutType string
 		if isTitleSlide && titleLayoutId != "" {
 			// Use TITLE layout for title slides
-			resp, err = mc.client.CreateSlideWithLayout(mc.presentationId, titleLayoutId, -1)
+			resp, err = mc.client.CreateSlideWithLayout(ctx, mc.presentationId, titleLayoutId, -1)
 			useLayoutBased = true
 			layoutType = "TITLE"
 		} else if needsTitleOnlyLayout && titleOnlyLayoutId != "" {
 			// Use TITLE_ONLY layout for slides with tables or images
-			resp, err = mc.client.CreateSlideWithLayout(mc.presentationId, titleOnlyLayoutId, -1)
+			resp, err = mc.client.CreateSlideWithLayout(ctx, mc.presentationId, titleOnlyLayoutId, -1)
 			useLayoutBased = true
 			layoutType = "TITLE_ONLY"
 		} else if layoutId != "" {
 			// Use TITLE_AND_BODY layout for regular slides
-			resp, err = mc.client.CreateSlideWithLayout(mc.presentationId, layoutId, -1)
+			resp, err = mc.client.CreateSlideWithLayout(ctx, mc.presentationId, layoutId, -1)
 			useLayoutBased = true
 			layoutType = "TITLE_AND_BODY"
 		} else {
 			// Fallback to blank slide
-			resp, err = mc.client.CreateSlide(mc.presentationId, -1)
+			resp, err = mc.client.CreateSlide(ctx, mc.presentationId, -1)
 			useLayoutBased = false
 			layoutType = "BLANK"
 		}
@@ -380,26 +382,28 @@ func (mc *MarkdownConverter) CreateSlidesFromMarkdown(markdown string) ([]*slide
 		if useLayoutBased {
 			if layoutType == "TITLE" {
 				// Special handling for title slides
-				err = mc.populateSlideWithTitleLayout(slideId, slide)
+				err = mc.populateSlideWithTitleLayout(ctx, slideId, slide)
 			} else if layoutType == "TITLE_ONLY" {
 				// Special handling for slides with tables (TITLE_ONLY layout)
-				err = mc.populateSlideWithTableLayout(slideId, slide)
+				err = mc.populateSlideWithTableLayout(ctx, slideId, slide)
 			} else {
 				// Regular TITLE_AND_BODY layout
-				err = mc.populateSlideWithLayout(slideId, slide)
+				err = mc.populateSlideWithLayout(ctx, slideId, slide)
 			}
 		} else {
 			// Blank slide
-			err = mc.populateSlide(slideId, slide)
+			err = mc.populateSlide(ctx, slideId, slide)
 		}
 
 		if err != nil {
 			return nil, fmt.Errorf("failed to populate slide %d: %w", i+1, err)
 		}
+
+		server.ReportProgress(ctx, float64(i+1), float64(len(parsedSlides)), fmt.Sprintf("created slide %d of %d", i+1, len(parsedSlides)))
 	}
 
 	// Return updated presentation slides
-	updatedPresentation, err := mc.client.GetPresentation(mc.presentationId)
+	updatedPresentation, err := mc.client.GetPresentation(ctx, mc.presentationId)
 	if err != nil {
 		return nil, err
 	}
@@ -408,9 +412,9 @@ func (mc *MarkdownConverter) CreateSlidesFromMarkdown(markdown string) ([]*slide
 }
 
 // populateSlideWithLayout populates a slide that uses a predefined layout
-func (mc *MarkdownConverter) populateSlideWithLayout(slideId string, slide MarkdownSlide) error {
+func (mc *MarkdownConverter) populateSlideWithLayout(ctx context.Context, slideId string, slide MarkdownSlide) error {
 	// Get the slide to find placeholder shapes
-	presentation, err := mc.client.GetPresentation(mc.presentationId)
+	presentation, err := mc.client.GetPresentation(ctx, mc.presentationId)
 	if err != nil {
 		return fmt.Errorf("failed to get presentation: %w", err)
 	}
@@ -445,10 +449,10 @@ func (mc *MarkdownConverter) populateSlideWithLayout(slideId string, slide Markd
 	if titlePlaceholderId != "" && slide.Title != "" {
 		// Delete existing placeholder text
 		// Ignore error as placeholder might be empty
-		_, _ = mc.client.DeleteTextInPlaceholder(mc.presentationId, titlePlaceholderId)
+		_, _ = mc.client.DeleteTextInPlaceholder(ctx, mc.presentationId, titlePlaceholderId)
 
 		// Insert new title text
-		_, err = mc.client.InsertTextInPlaceholder(mc.presentationId, titlePlaceholderId, slide.Title)
+		_, err = mc.client.InsertTextInPlaceholder(ctx, mc.presentationId, titlePlaceholderId, slide.Title)
 		if err != nil {
 			return fmt.Errorf("failed to insert title: %w", err)
 		}
@@ -458,7 +462,7 @@ func (mc *MarkdownConverter) populateSlideWithLayout(slideId string, slide Markd
 	if bodyPlaceholderId != "" && len(slide.Content) > 0 {
 		// Delete existing placeholder text
 		// Ignore error as placeholder might be empty
-		_, _ = mc.client.DeleteTextInPlaceholder(mc.presentationId, bodyPlaceholderId)
+		_, _ = mc.client.DeleteTextInPlaceholder(ctx, mc.presentationId, bodyPlaceholderId)
 
 		// Find the first heading (Level 2 or 3) to use as title if slide.Title is empty
 		var slideTitle string
@@ -528,9 +532,9 @@ func (mc *MarkdownConverter) populateSlideWithLayout(slideId string, slide Markd
 		// If we found a heading and no slide title was set, use it as title
 		if slideTitle != "" && slide.Title == "" && titlePlaceholderId != "" {
 			// Ignore error as placeholder might be empty
-			_, _ = mc.client.DeleteTextInPlaceholder(mc.presentationId, titlePlaceholderId)
+			_, _ = mc.client.DeleteTextInPlaceholder(ctx, mc.presentationId, titlePlaceholderId)
 
-			_, err = mc.client.InsertTextInPlaceholder(mc.presentationId, titlePlaceholderId, slideTitle)
+			_, err = mc.client.InsertTextInPlaceholder(ctx, mc.presentationId, titlePlaceholderId, slideTitle)
 			if err != nil {
 				return fmt.Errorf("failed to insert title: %w", err)
 			}
@@ -538,14 +542,14 @@ func (mc *MarkdownConverter) populateSlideWithLayout(slideId string, slide Markd
 
 		if len(bodyText) > 0 {
 			combinedText := strings.Join(bodyText, "\n")
-			_, err = mc.client.InsertTextInPlaceholder(mc.presentationId, bodyPlaceholderId, combinedText)
+			_, err = mc.client.InsertTextInPlaceholder(ctx, mc.presentationId, bodyPlaceholderId, combinedText)
 			if err != nil {
 				return fmt.Errorf("failed to insert body text: %w", err)
 			}
 
 			// Apply Courier New font to code blocks
 			if len(codeRanges) > 0 {
-				err = mc.client.ApplyCodeFormattingToPlaceholder(mc.presentationId, bodyPlaceholderId, codeRanges)
+				err = mc.client.ApplyCodeFormattingToPlaceholder(ctx, mc.presentationId, bodyPlaceholderId, codeRanges)
 				if err != nil {
 					// Return the error so we can see what's happening
 					return fmt.Errorf("failed to apply code formatting: %w", err)
@@ -560,9 +564,9 @@ func (mc *MarkdownConverter) populateSlideWithLayout(slideId string, slide Markd
 // populateSlideWithTitleLayout populates a slide with TITLE layout (for title slides with only headings)
 // This function is used for slides that contain only headings (typically 2: title and subtitle)
 // It maps the headings to the appropriate title and subtitle placeholders in the TITLE layout
-func (mc *MarkdownConverter) populateSlideWithTitleLayout(slideId string, slide MarkdownSlide) error {
+func (mc *MarkdownConverter) populateSlideWithTitleLayout(ctx context.Context, slideId string, slide MarkdownSlide) error {
 	// Get the slide to find placeholder shapes
-	presentation, err := mc.client.GetPresentation(mc.presentationId)
+	presentation, err := mc.client.GetPresentation(ctx, mc.presentationId)
 	if err != nil {
 		return fmt.Errorf("failed to get presentation: %w", err)
 	}
@@ -623,9 +627,9 @@ func (mc *MarkdownConverter) populateSlideWithTitleLayout(slideId string, slide
 	// Insert title
 	if titlePlaceholderId != "" && titleText != "" {
 		// Ignore error as placeholder might be empty
-		_, _ = mc.client.DeleteTextInPlaceholder(mc.presentationId, titlePlaceholderId)
+		_, _ = mc.client.DeleteTextInPlaceholder(ctx, mc.presentationId, titlePlaceholderId)
 
-		_, err = mc.client.InsertTextInPlaceholder(mc.presentationId, titlePlaceholderId, titleText)
+		_, err = mc.client.InsertTextInPlaceholder(ctx, mc.presentationId, titlePlaceholderId, titleText)
 		if err != nil {
 			return fmt.Errorf("failed to insert title: %w", err)
 		}
@@ -634,9 +638,9 @@ func (mc *MarkdownConverter) populateSlideWithTitleLayout(slideId string, slide
 	// Insert subtitle
 	if subtitlePlaceholderId != "" && subtitleText != "" {
 		// Ignore error as placeholder might be empty
-		_, _ = mc.client.DeleteTextInPlaceholder(mc.presentationId, subtitlePlaceholderId)
+		_, _ = mc.client.DeleteTextInPlaceholder(ctx, mc.presentationId, subtitlePlaceholderId)
 
-		_, err = mc.client.InsertTextInPlaceholder(mc.presentationId, subtitlePlaceholderId, subtitleText)
+		_, err = mc.client.InsertTextInPlaceholder(ctx, mc.presentationId, subtitlePlaceholderId, subtitleText)
 		if err != nil {
 			return fmt.Errorf("failed to insert subtitle: %w", err)
 		}
@@ -647,9 +651,9 @@ func (mc *MarkdownConverter) populateSlideWithTitleLayout(slideId string, slide
 
 // populateSlideWithTableLayout populates a slide with TITLE_ONLY layout that contains tables or images
 // This layout provides more space for content that needs it (tables, images)
-func (mc *MarkdownConverter) populateSlideWithTableLayout(slideId string, slide MarkdownSlide) error {
+func (mc *MarkdownConverter) populateSlideWithTableLayout(ctx context.Context, slideId string, slide MarkdownSlide) error {
 	// Get the slide to find placeholder shapes
-	presentation, err := mc.client.GetPresentation(mc.presentationId)
+	presentation, err := mc.client.GetPresentation(ctx, mc.presentationId)
 	if err != nil {
 		return fmt.Errorf("failed to get presentation: %w", err)
 	}
@@ -693,10 +697,10 @@ func (mc *MarkdownConverter) populateSlideWithTableLayout(slideId string, slide
 	if titlePlaceholderId != "" && titleText != "" {
 		// Delete existing placeholder text
 		// Ignore error as placeholder might be empty
-		_, _ = mc.client.DeleteTextInPlaceholder(mc.presentationId, titlePlaceholderId)
+		_, _ = mc.client.DeleteTextInPlaceholder(ctx, mc.presentationId, titlePlaceholderId)
 
 		// Insert title text
-		_, err = mc.client.InsertTextInPlaceholder(mc.presentationId, titlePlaceholderId, titleText)
+		_, err = mc.client.InsertTextInPlaceholder(ctx, mc.presentationId, titlePlaceholderId, titleText)
 		if err != nil {
 			return fmt.Errorf("failed to insert title: %w", err)
 		}
@@ -718,7 +722,7 @@ func (mc *MarkdownConverter) populateSlideWithTableLayout(slideId string, slide
 				fontSize = H1FontSize
 			}
 
-			_, err := mc.client.AddTextBox(
+			_, err := mc.client.AddTextBox(ctx,
 				mc.presentationId,
 				slideId,
 				element.Content,
@@ -738,7 +742,7 @@ func (mc *MarkdownConverter) populateSlideWithTableLayout(slideId string, slide
 				prefix = "1. "
 			}
 
-			_, err := mc.client.AddTextBox(
+			_, err := mc.client.AddTextBox(ctx,
 				mc.presentationId,
 				slideId,
 				prefix+element.Content,
@@ -754,7 +758,7 @@ func (mc *MarkdownConverter) populateSlideWithTableLayout(slideId string, slide
 
 		case "code":
 			// Add code block with Courier New font
-			_, err := mc.client.AddCodeTextBox(
+			_, err := mc.client.AddCodeTextBox(ctx,
 				mc.presentationId,
 				slideId,
 				element.Content,
@@ -774,7 +778,7 @@ func (mc *MarkdownConverter) populateSlideWithTableLayout(slideId string, slide
 			imageHeight := SlideHeight * 0.5
 			imageX := (SlideWidth - imageWidth) / 2
 
-			_, err := mc.client.AddImage(
+			_, err := mc.client.AddImage(ctx,
 				mc.presentationId,
 				slideId,
 				element.Content,
@@ -791,7 +795,7 @@ func (mc *MarkdownConverter) populateSlideWithTableLayout(slideId string, slide
 			// Add alt text as caption below image if present
 			if element.AltText != "" {
 				captionWidth := imageWidth
-				_, err := mc.client.AddTextBox(
+				_, err := mc.client.AddTextBox(ctx,
 					mc.presentationId,
 					slideId,
 					element.AltText,
@@ -814,7 +818,7 @@ func (mc *MarkdownConverter) populateSlideWithTableLayout(slideId string, slide
 					tableWidth := SlideWidth - MarginLeft - MarginRight
 					tableHeight := float64(rows) * 30.0
 
-					resp, err := mc.client.AddTable(
+					resp, err := mc.client.AddTable(ctx,
 						mc.presentationId,
 						slideId,
 						rows,
@@ -846,7 +850,7 @@ func (mc *MarkdownConverter) populateSlideWithTableLayout(slideId string, slide
 							// Insert text into each cell
 							for colIdx, cellText := range cellTexts {
 								if colIdx < cols {
-									_, err := mc.client.InsertTextInTableCell(
+									_, err := mc.client.InsertTextInTableCell(ctx,
 										mc.presentationId,
 										tableId,
 										rowIdx,
@@ -855,7 +859,7 @@ func (mc *MarkdownConverter) populateSlideWithTableLayout(slideId string, slide
 									)
 									if err != nil {
 										// Log error but continue with other cells
-										fmt.Printf("Warning: failed to insert text in cell [%d,%d]: %v\n", rowIdx, colIdx, err)
+										logger.Warn("failed to insert table cell text", "row", rowIdx, "column", colIdx, "error", err)
 									}
 								}
 							}
@@ -871,13 +875,13 @@ func (mc *MarkdownConverter) populateSlideWithTableLayout(slideId string, slide
 	return nil
 }
 
-func (mc *MarkdownConverter) populateSlide(slideId string, slide MarkdownSlide) error {
+func (mc *MarkdownConverter) populateSlide(ctx context.Context, slideId string, slide MarkdownSlide) error {
 	// All slides are now blank, so we add text boxes for everything
 	currentY := MarginTop
 
 	// Add title if exists
 	if slide.Title != "" {
-		resp, err := mc.client.AddTextBox(
+		resp, err := mc.client.AddTextBox(ctx,
 			mc.presentationId,
 			slideId,
 			slide.Title,
@@ -909,7 +913,7 @@ func (mc *MarkdownConverter) populateSlide(slideId string, slide MarkdownSlide)
 				fontSize = H3FontSize
 			}
 
-			_, err := mc.client.AddTextBox(
+			_, err := mc.client.AddTextBox(ctx,
 				mc.presentationId,
 				slideId,
 				element.Content,
@@ -930,7 +934,7 @@ func (mc *MarkdownConverter) populateSlide(slideId string, slide MarkdownSlide)
 			}
 			indent := float64(element.Level) * 20.0
 
-			_, err := mc.client.AddTextBox(
+			_, err := mc.client.AddTextBox(ctx,
 				mc.presentationId,
 				slideId,
 				prefix+element.Content,
@@ -946,7 +950,7 @@ func (mc *MarkdownConverter) populateSlide(slideId string, slide MarkdownSlide)
 
 		case "code":
 			// Add code block with Courier New font
-			_, err := mc.client.AddCodeTextBox(
+			_, err := mc.client.AddCodeTextBox(ctx,
 				mc.presentationId,
 				slideId,
 				element.Content,
@@ -966,7 +970,7 @@ func (mc *MarkdownConverter) populateSlide(slideId string, slide MarkdownSlide)
 			imageHeight := SlideHeight * 0.5
 			imageX := (SlideWidth - imageWidth) / 2
 
-			_, err := mc.client.AddImage(
+			_, err := mc.client.AddImage(ctx,
 				mc.presentationId,
 				slideId,
 				element.Content,
@@ -983,7 +987,7 @@ func (mc *MarkdownConverter) populateSlide(slideId string, slide MarkdownSlide)
 			// Add alt text as caption below image if present
 			if element.AltText != "" {
 				captionWidth := imageWidth
-				_, err := mc.client.AddTextBox(
+				_, err := mc.client.AddTextBox(ctx,
 					mc.presentationId,
 					slideId,
 					element.AltText,
@@ -1006,7 +1010,7 @@ func (mc *MarkdownConverter) populateSlide(slideId string, slide MarkdownSlide)
 					tableWidth := SlideWidth - MarginLeft - MarginRight
 					tableHeight := float64(rows) * 30.0
 
-					resp, err := mc.client.AddTable(
+					resp, err := mc.client.AddTable(ctx,
 						mc.presentationId,
 						slideId,
 						rows,
@@ -1039,7 +1043,7 @@ func (mc *MarkdownConverter) populateSlide(slideId string, slide MarkdownSlide)
 							// Insert text into each cell
 							for colIdx, cellText := range cellTexts {
 								if colIdx < cols {
-									_, err := mc.client.InsertTextInTableCell(
+									_, err := mc.client.InsertTextInTableCell(ctx,
 										mc.presentationId,
 										tableId,
 										rowIdx,
@@ -1048,7 +1052,7 @@ func (mc *MarkdownConverter) populateSlide(slideId string, slide MarkdownSlide)
 									)
 									if err != nil {
 										// Log error but continue with other cells
-										fmt.Printf("Warning: failed to insert text in cell [%d,%d]: %v\n", rowIdx, colIdx, err)
+										logger.Warn("failed to insert table cell text", "row", rowIdx, "column", colIdx, "error", err)
 									}
 								}
 							}
@@ -1064,9 +1068,9 @@ func (mc *MarkdownConverter) populateSlide(slideId string, slide MarkdownSlide)
 	return nil
 }
 
-func (mc *MarkdownConverter) UpdateSlidesFromMarkdown(markdown string) error {
+func (mc *MarkdownConverter) UpdateSlidesFromMarkdown(ctx context.Context, markdown string) error {
 	// Get current presentation
-	presentation, err := mc.client.GetPresentation(mc.presentationId)
+	presentation, err := mc.client.GetPresentation(ctx, mc.presentationId)
 	if err != nil {
 		return err
 	}
@@ -1074,7 +1078,7 @@ func (mc *MarkdownConverter) UpdateSlidesFromMarkdown(markdown string) error {
 	// Delete all existing slides except the first one
 	if len(presentation.Slides) > 1 {
 		for i := 1; i < len(presentation.Slides); i++ {
-			_, err := mc.client.DeleteSlide(mc.presentationId, presentation.Slides[i].ObjectId)
+			_, err := mc.client.DeleteSlide(ctx, mc.presentationId, presentation.Slides[i].ObjectId)
 			if err != nil {
 				return err
 			}
@@ -1082,6 +1086,6 @@ func (mc *MarkdownConverter) UpdateSlidesFromMarkdown(markdown string) error {
 	}
 
 	// Create new slides from markdown
-	_, err = mc.CreateSlidesFromMarkdown(markdown)
+	_, err = mc.CreateSlidesFromMarkdown(ctx, markdown)
 	return err
 }
@@ -1,6 +1,7 @@
 package slides
 
 import (
+	"context"
 	"reflect"
 	"strings"
 	"testing"
@@ -786,12 +787,12 @@ func TestApplyCodeFormattingToPlaceholder(t *testing.T) {
 		service: &slides.Service{},
 	}
 
-	err := client.ApplyCodeFormattingToPlaceholder("test-id", "shape-id", nil)
+	err := client.ApplyCodeFormattingToPlaceholder(context.Background(), "test-id", "shape-id", nil)
 	if err != nil {
 		t.Errorf("ApplyCodeFormattingToPlaceholder() with nil ranges returned error: %v", err)
 	}
 
-	err = client.ApplyCodeFormattingToPlaceholder("test-id", "shape-id", []struct {
+	err = client.ApplyCodeFormattingToPlaceholder(context.Background(), "test-id", "shape-id", []struct {
 		start int
 		end   int
 	}{})
@@ -3,7 +3,6 @@ package slides
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 
 	"go.ngs.io/google-mcp-server/auth"
 	"go.ngs.io/google-mcp-server/server"
@@ -19,64 +18,60 @@ func NewMultiAccountService(authManager *auth.AccountManager) *MultiAccountServi
 	}
 }
 
-func (s *MultiAccountService) GetTools() []server.Tool {
-	return []server.Tool{
-		{
+// presentationsListAllAccountsArgs are the arguments of
+// slides_presentations_list_all_accounts
+type presentationsListAllAccountsArgs struct {
+	MaxResults int `json:"max_results" description:"Maximum number of presentations per account"`
+}
+
+// ToolDefinitions declares the Slides tools that span all accounts
+func (s *MultiAccountService) ToolDefinitions() server.ToolSet {
+	return server.ToolSet{
+		server.NewTool(server.Tool{
 			Name:        "slides_presentations_list_all_accounts",
 			Description: "List presentations from all authenticated Google accounts",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"max_results": {
-						Type:        "number",
-						Description: "Maximum number of presentations per account",
-					},
-				},
-			},
-		},
+			Annotations: server.ReadOnlyAnnotations(),
+		}, s.handlePresentationsListAllAccounts),
 	}
 }
 
-func (s *MultiAccountService) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
-	switch name {
-	case "slides_presentations_list_all_accounts":
-		var args map[string]interface{}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, err
-		}
-
-		maxResults := 10
-		if mr, ok := args["max_results"].(float64); ok {
-			maxResults = int(mr)
-		}
+func (s *MultiAccountService) GetTools() []server.Tool {
+	return s.ToolDefinitions().Tools()
+}
 
-		accounts := s.authManager.ListAccounts()
-		allPresentations := []map[string]interface{}{}
+func (s *MultiAccountService) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
+	return s.ToolDefinitions().Call(ctx, name, arguments)
+}
 
-		for _, account := range accounts {
-			// Skip if no OAuth client
-			if account.OAuthClient == nil {
-				continue
-			}
+func (s *MultiAccountService) handlePresentationsListAllAccounts(ctx context.Context, args presentationsListAllAccountsArgs) (interface{}, error) {
+	maxResults := args.MaxResults
+	if maxResults == 0 {
+		maxResults = 10
+	}
 
-			// Create Drive client to list presentations (Slides API doesn't have direct list)
-			// We would typically use Drive API to list presentations
-			// For now, we'll return a placeholder
-			accountPresentations := map[string]interface{}{
-				"account":     account.Email,
-				"note":        "Use Drive API with mimeType='application/vnd.google-apps.presentation' to list presentations",
-				"max_results": maxResults,
-			}
+	accounts := s.authManager.ListAccounts()
+	allPresentations := []map[string]interface{}{}
 
-			allPresentations = append(allPresentations, accountPresentations)
+	for _, account := range accounts {
+		// Skip if no OAuth client
+		if account.OAuthClient == nil {
+			continue
 		}
 
-		return map[string]interface{}{
-			"accounts":      len(accounts),
-			"presentations": allPresentations,
-		}, nil
+		// Create Drive client to list presentations (Slides API doesn't have direct list)
+		// We would typically use Drive API to list presentations
+		// For now, we'll return a placeholder
+		accountPresentations := map[string]interface{}{
+			"account":     account.Email,
+			"note":        "Use Drive API with mimeType='application/vnd.google-apps.presentation' to list presentations",
+			"max_results": maxResults,
+		}
 
-	default:
-		return nil, fmt.Errorf("unknown tool: %s", name)
+		allPresentations = append(allPresentations, accountPresentations)
 	}
+
+	return map[string]interface{}{
+		"accounts":      len(accounts),
+		"presentations": allPresentations,
+	}, nil
 }
@@ -0,0 +1,76 @@
+package slides
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.ngs.io/google-mcp-server/server"
+)
+
+// GetPrompts returns the Slides prompt templates
+func (s *Service) GetPrompts() []server.Prompt {
+	return []server.Prompt{
+		{
+			Name:        "slides_doc_to_deck",
+			Description: "Turn a Google Doc into a slide deck",
+			Arguments: []server.PromptArgument{
+				{
+					Name:        "document_id",
+					Description: "ID of the Google Doc to convert",
+					Required:    true,
+				},
+				{
+					Name:        "title",
+					Description: "Title of the new presentation (defaults to the document title)",
+				},
+				{
+					Name:        "max_slides",
+					Description: "Upper bound on the number of slides (default: 12)",
+				},
+				{
+					Name:        "account",
+					Description: "Email address of the account to use (optional)",
+				},
+			},
+		},
+	}
+}
+
+// GetPrompt renders a Slides prompt template
+func (s *Service) GetPrompt(ctx context.Context, name string, arguments map[string]string) (*server.PromptResult, error) {
+	switch name {
+	case "slides_doc_to_deck":
+		return docToDeckPrompt(arguments), nil
+	default:
+		return nil, fmt.Errorf("unknown prompt: %s", name)
+	}
+}
+
+// docToDeckPrompt builds the instructions for converting a document into slides
+func docToDeckPrompt(arguments map[string]string) *server.PromptResult {
+	documentID := strings.TrimSpace(arguments["document_id"])
+	maxSlides := strings.TrimSpace(arguments["max_slides"])
+	if maxSlides == "" {
+		maxSlides = "12"
+	}
+	account := strings.TrimSpace(arguments["account"])
+
+	var sb strings.Builder
+	sb.WriteString("Turn this document into a presentation.\n\n")
+	fmt.Fprintf(&sb, "1. Read the document with docs_document_get (document_id %q).\n", documentID)
+	fmt.Fprintf(&sb, "2. Outline at most %s slides: a title slide, one slide per main section, and a closing summary slide.\n", maxSlides)
+	sb.WriteString("3. Write the outline as Markdown: '# ' for the title slide, '## ' for section slides, bullet lists of at most 5 short points per slide, and '---' between slides.\n")
+	if title := strings.TrimSpace(arguments["title"]); title != "" {
+		fmt.Fprintf(&sb, "4. Create the deck with slides_markdown_create using title %q", title)
+	} else {
+		sb.WriteString("4. Create the deck with slides_markdown_create, using the document title as the presentation title")
+	}
+	if account != "" {
+		fmt.Fprintf(&sb, " and account %q", account)
+	}
+	sb.WriteString(".\n")
+	sb.WriteString("5. Reply with the presentation link and a one-line description of each slide.")
+
+	return server.UserPrompt("Convert a Google Doc into a slide deck", sb.String())
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
 
 	"go.ngs.io/google-mcp-server/auth"
 	"go.ngs.io/google-mcp-server/server"
@@ -20,442 +19,272 @@ func NewService(authManager *auth.AccountManager) *Service {
 	}
 }
 
-func (s *Service) GetTools() []server.Tool {
-	return []server.Tool{
-		{
+// Arguments of the Slides tools. The input schemas are generated from these
+// structs, see server.SchemaOf.
+
+type accountArgs struct {
+	Account string `json:"account,omitempty" description:"Email address of the account to use (optional)"`
+}
+
+type presentationCreateArgs struct {
+	Title string `json:"title" required:"true" description:"Presentation title"`
+	accountArgs
+}
+
+type presentationArgs struct {
+	PresentationID string `json:"presentation_id" required:"true" description:"Presentation ID"`
+	accountArgs
+}
+
+type slideCreateArgs struct {
+	PresentationID string `json:"presentation_id" required:"true" description:"Presentation ID"`
+	InsertionIndex int    `json:"insertion_index" description:"Position to insert the slide (0-based)"`
+	accountArgs
+}
+
+type slideDeleteArgs struct {
+	PresentationID string `json:"presentation_id" required:"true" description:"Presentation ID"`
+	SlideID        string `json:"slide_id" required:"true" description:"Slide ID to delete"`
+	accountArgs
+}
+
+type slideDuplicateArgs struct {
+	PresentationID string `json:"presentation_id" required:"true" description:"Presentation ID"`
+	SlideID        string `json:"slide_id" required:"true" description:"Slide ID to duplicate"`
+	accountArgs
+}
+
+type markdownCreateArgs struct {
+	Title    string `json:"title" required:"true" description:"Presentation title"`
+	Markdown string `json:"markdown" required:"true" description:"Markdown content (use --- for page breaks)"`
+	accountArgs
+}
+
+type markdownUpdateArgs struct {
+	PresentationID string `json:"presentation_id" required:"true" description:"Presentation ID to update"`
+	Markdown       string `json:"markdown" required:"true" description:"Markdown content (use --- for page breaks)"`
+	accountArgs
+}
+
+type markdownAppendArgs struct {
+	PresentationID string `json:"presentation_id" required:"true" description:"Presentation ID"`
+	Markdown       string `json:"markdown" required:"true" description:"Markdown content to append"`
+	accountArgs
+}
+
+// boxArgs place an element on a slide; unset values take the defaults of
+// the element
+type boxArgs struct {
+	X      *float64 `json:"x,omitempty" description:"X position in points"`
+	Y      *float64 `json:"y,omitempty" description:"Y position in points"`
+	Width  *float64 `json:"width,omitempty" description:"Width in points"`
+	Height *float64 `json:"height,omitempty" description:"Height in points"`
+}
+
+// box returns the position and size of the element, using the given
+// defaults for unset values
+func (b boxArgs) box(x, y, width, height float64) (float64, float64, float64, float64) {
+	return valueOr(b.X, x), valueOr(b.Y, y), valueOr(b.Width, width), valueOr(b.Height, height)
+}
+
+// valueOr returns *v, or fallback when v is nil
+func valueOr(v *float64, fallback float64) float64 {
+	if v == nil {
+		return fallback
+	}
+	return *v
+}
+
+type addTextArgs struct {
+	PresentationID string `json:"presentation_id" required:"true" description:"Presentation ID"`
+	SlideID        string `json:"slide_id" required:"true" description:"Slide ID"`
+	Text           string `json:"text" required:"true" description:"Text content"`
+	boxArgs
+	accountArgs
+}
+
+type addImageArgs struct {
+	PresentationID string `json:"presentation_id" required:"true" description:"Presentation ID"`
+	SlideID        string `json:"slide_id" required:"true" description:"Slide ID"`
+	ImageURL       string `json:"image_url" required:"true" description:"Image URL"`
+	boxArgs
+	accountArgs
+}
+
+type addTableArgs struct {
+	PresentationID string     `json:"presentation_id" required:"true" description:"Presentation ID"`
+	SlideID        string     `json:"slide_id" required:"true" description:"Slide ID"`
+	Rows           int        `json:"rows" required:"true" minimum:"1" description:"Number of rows"`
+	Columns        int        `json:"columns" required:"true" minimum:"1" description:"Number of columns"`
+	Data           tableCells `json:"data" description:"Cell text, one array per row starting at the top left; must fit within rows and columns"`
+	X              *float64   `json:"x,omitempty" default:"50" description:"X position in points"`
+	Y              *float64   `json:"y,omitempty" default:"50" description:"Y position in points"`
+	Width          *float64   `json:"width,omitempty" exclusiveMinimum:"0" default:"400" description:"Width in points"`
+	Height         *float64   `json:"height,omitempty" exclusiveMinimum:"0" default:"200" description:"Height in points"`
+	accountArgs
+}
+
+// tableCells are the text of the cells of a table, row by row
+type tableCells [][]string
+
+// PropertySchema implements server.PropertySchemer
+func (tableCells) PropertySchema() server.Property {
+	return server.Property{
+		Type: "array",
+		Items: &server.Property{
+			Type:        "array",
+			Description: "Cells of one row, left to right; empty strings leave a cell blank",
+			Items:       &server.Property{Type: "string"},
+		},
+	}
+}
+
+type addShapeArgs struct {
+	PresentationID string `json:"presentation_id" required:"true" description:"Presentation ID"`
+	SlideID        string `json:"slide_id" required:"true" description:"Slide ID"`
+	ShapeType      string `json:"shape_type" required:"true" description:"Shape type (e.g., RECTANGLE, ELLIPSE, TRIANGLE)"`
+	boxArgs
+	accountArgs
+}
+
+type setLayoutArgs struct {
+	PresentationID string `json:"presentation_id" required:"true" description:"Presentation ID"`
+	SlideID        string `json:"slide_id" required:"true" description:"Slide ID"`
+	LayoutID       string `json:"layout_id" required:"true" description:"Layout ID"`
+	accountArgs
+}
+
+type slideThumbnailArgs struct {
+	PresentationID string `json:"presentation_id" required:"true" description:"Presentation ID"`
+	SlideID        string `json:"slide_id" required:"true" description:"Slide ID to render"`
+	Size           string `json:"size" enum:"SMALL,MEDIUM,LARGE" description:"Thumbnail size (default MEDIUM, 800px wide)"`
+	accountArgs
+}
+
+type shareArgs struct {
+	PresentationID string `json:"presentation_id" required:"true" description:"Presentation ID"`
+	Role           string `json:"role" required:"true" enum:"reader,writer,commenter" description:"Permission role (reader, writer, commenter)"`
+	accountArgs
+}
+
+// ToolDefinitions declares the Slides tools together with their handlers
+func (s *Service) ToolDefinitions() server.ToolSet {
+	return server.ToolSet{
+		server.NewTool(server.Tool{
 			Name:        "slides_presentation_create",
 			Description: "Create a new Google Slides presentation",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"title": {
-						Type:        "string",
-						Description: "Presentation title",
-					},
-					"account": {
-						Type:        "string",
-						Description: "Email address of the account to use (optional)",
-					},
-				},
-				Required: []string{"title"},
-			},
-		},
-		{
+			Annotations: server.AdditiveAnnotations(),
+		}, s.handlePresentationCreate),
+		server.NewTool(server.Tool{
 			Name:        "slides_presentation_get",
 			Description: "Get Google Slides presentation metadata",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"presentation_id": {
-						Type:        "string",
-						Description: "Presentation ID",
-					},
-					"account": {
-						Type:        "string",
-						Description: "Email address of the account to use (optional)",
-					},
-				},
-				Required: []string{"presentation_id"},
-			},
-		},
-		{
+			Annotations: server.ReadOnlyAnnotations(),
+		}, s.handlePresentationGet),
+		server.NewTool(server.Tool{
 			Name:        "slides_slide_create",
 			Description: "Create a new slide in a presentation",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"presentation_id": {
-						Type:        "string",
-						Description: "Presentation ID",
-					},
-					"insertion_index": {
-						Type:        "number",
-						Description: "Position to insert the slide (0-based)",
-					},
-					"account": {
-						Type:        "string",
-						Description: "Email address of the account to use (optional)",
-					},
-				},
-				Required: []string{"presentation_id"},
-			},
-		},
-		{
+			Annotations: server.AdditiveAnnotations(),
+		}, s.handleSlideCreate),
+		server.NewTool(server.Tool{
 			Name:        "slides_slide_delete",
 			Description: "Delete a slide from a presentation",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"presentation_id": {
-						Type:        "string",
-						Description: "Presentation ID",
-					},
-					"slide_id": {
-						Type:        "string",
-						Description: "Slide ID to delete",
-					},
-					"account": {
-						Type:        "string",
-						Description: "Email address of the account to use (optional)",
-					},
-				},
-				Required: []string{"presentation_id", "slide_id"},
-			},
-		},
-		{
+			Annotations: server.DestructiveAnnotations(),
+		}, s.handleSlideDelete),
+		server.NewTool(server.Tool{
 			Name:        "slides_slide_duplicate",
 			Description: "Duplicate a slide in a presentation",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"presentation_id": {
-						Type:        "string",
-						Description: "Presentation ID",
-					},
-					"slide_id": {
-						Type:        "string",
-						Description: "Slide ID to duplicate",
-					},
-					"account": {
-						Type:        "string",
-						Description: "Email address of the account to use (optional)",
-					},
-				},
-				Required: []string{"presentation_id", "slide_id"},
-			},
-		},
-		{
+			Annotations: server.AdditiveAnnotations(),
+		}, s.handleSlideDuplicate),
+		server.NewTool(server.Tool{
 			Name:        "slides_markdown_create",
 			Description: "Create a new presentation from Markdown content with automatic pagination",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"title": {
-						Type:        "string",
-						Description: "Presentation title",
-					},
-					"markdown": {
-						Type:        "string",
-						Description: "Markdown content (use --- for page breaks)",
-					},
-					"account": {
-						Type:        "string",
-						Description: "Email address of the account to use (optional)",
-					},
-				},
-				Required: []string{"title", "markdown"},
-			},
-		},
-		{
+			Annotations: server.AdditiveAnnotations(),
+		}, s.handleMarkdownCreate),
+		server.NewTool(server.Tool{
 			Name:        "slides_markdown_update",
 			Description: "Update an existing presentation with Markdown content",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"presentation_id": {
-						Type:        "string",
-						Description: "Presentation ID to update",
-					},
-					"markdown": {
-						Type:        "string",
-						Description: "Markdown content (use --- for page breaks)",
-					},
-					"account": {
-						Type:        "string",
-						Description: "Email address of the account to use (optional)",
-					},
-				},
-				Required: []string{"presentation_id", "markdown"},
-			},
-		},
-		{
+			Annotations: server.DestructiveAnnotations(),
+		}, s.handleMarkdownUpdate),
+		server.NewTool(server.Tool{
 			Name:        "slides_markdown_append",
 			Description: "Append slides from Markdown to an existing presentation",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"presentation_id": {
-						Type:        "string",
-						Description: "Presentation ID",
-					},
-					"markdown": {
-						Type:        "string",
-						Description: "Markdown content to append",
-					},
-					"account": {
-						Type:        "string",
-						Description: "Email address of the account to use (optional)",
-					},
-				},
-				Required: []string{"presentation_id", "markdown"},
-			},
-		},
-		{
+			Annotations: server.AdditiveAnnotations(),
+		}, s.handleMarkdownAppend),
+		server.NewTool(server.Tool{
 			Name:        "slides_add_text",
 			Description: "Add a text box to a slide",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"presentation_id": {
-						Type:        "string",
-						Description: "Presentation ID",
-					},
-					"slide_id": {
-						Type:        "string",
-						Description: "Slide ID",
-					},
-					"text": {
-						Type:        "string",
-						Description: "Text content",
-					},
-					"x": {
-						Type:        "number",
-						Description: "X position in points",
-					},
-					"y": {
-						Type:        "number",
-						Description: "Y position in points",
-					},
-					"width": {
-						Type:        "number",
-						Description: "Width in points",
-					},
-					"height": {
-						Type:        "number",
-						Description: "Height in points",
-					},
-					"account": {
-						Type:        "string",
-						Description: "Email address of the account to use (optional)",
-					},
-				},
-				Required: []string{"presentation_id", "slide_id", "text"},
-			},
-		},
-		{
+			Annotations: server.AdditiveAnnotations(),
+		}, s.handleAddText),
+		server.NewTool(server.Tool{
 			Name:        "slides_add_image",
 			Description: "Add an image to a slide",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"presentation_id": {
-						Type:        "string",
-						Description: "Presentation ID",
-					},
-					"slide_id": {
-						Type:        "string",
-						Description: "Slide ID",
-					},
-					"image_url": {
-						Type:        "string",
-						Description: "Image URL",
-					},
-					"x": {
-						Type:        "number",
-						Description: "X position in points",
-					},
-					"y": {
-						Type:        "number",
-						Description: "Y position in points",
-					},
-					"width": {
-						Type:        "number",
-						Description: "Width in points",
-					},
-					"height": {
-						Type:        "number",
-						Description: "Height in points",
-					},
-					"account": {
-						Type:        "string",
-						Description: "Email address of the account to use (optional)",
-					},
-				},
-				Required: []string{"presentation_id", "slide_id", "image_url"},
-			},
-		},
-		{
+			Annotations: server.AdditiveAnnotations(),
+		}, s.handleAddImage),
+		server.NewTool(server.Tool{
 			Name:        "slides_add_table",
 			Description: "Add a table to a slide",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"presentation_id": {
-						Type:        "string",
-						Description: "Presentation ID",
-					},
-					"slide_id": {
-						Type:        "string",
-						Description: "Slide ID",
-					},
-					"rows": {
-						Type:        "number",
-						Description: "Number of rows",
-					},
-					"columns": {
-						Type:        "number",
-						Description: "Number of columns",
-					},
-					"x": {
-						Type:        "number",
-						Description: "X position in points",
-					},
-					"y": {
-						Type:        "number",
-						Description: "Y position in points",
-					},
-					"width": {
-						Type:        "number",
-						Description: "Width in points",
-					},
-					"height": {
-						Type:        "number",
-						Description: "Height in points",
-					},
-					"account": {
-						Type:        "string",
-						Description: "Email address of the account to use (optional)",
-					},
-				},
-				Required: []string{"presentation_id", "slide_id", "rows", "columns"},
-			},
-		},
-		{
+			Annotations: server.AdditiveAnnotations(),
+		}, s.handleAddTable),
+		server.NewTool(server.Tool{
 			Name:        "slides_add_shape",
 			Description: "Add a shape to a slide",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"presentation_id": {
-						Type:        "string",
-						Description: "Presentation ID",
-					},
-					"slide_id": {
-						Type:        "string",
-						Description: "Slide ID",
-					},
-					"shape_type": {
-						Type:        "string",
-						Description: "Shape type (e.g., RECTANGLE, ELLIPSE, TRIANGLE)",
-					},
-					"x": {
-						Type:        "number",
-						Description: "X position in points",
-					},
-					"y": {
-						Type:        "number",
-						Description: "Y position in points",
-					},
-					"width": {
-						Type:        "number",
-						Description: "Width in points",
-					},
-					"height": {
-						Type:        "number",
-						Description: "Height in points",
-					},
-					"account": {
-						Type:        "string",
-						Description: "Email address of the account to use (optional)",
-					},
-				},
-				Required: []string{"presentation_id", "slide_id", "shape_type"},
-			},
-		},
-		{
+			Annotations: server.AdditiveAnnotations(),
+		}, s.handleAddShape),
+		server.NewTool(server.Tool{
 			Name:        "slides_set_layout",
 			Description: "Set the layout of a slide",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"presentation_id": {
-						Type:        "string",
-						Description: "Presentation ID",
-					},
-					"slide_id": {
-						Type:        "string",
-						Description: "Slide ID",
-					},
-					"layout_id": {
-						Type:        "string",
-						Description: "Layout ID",
-					},
-					"account": {
-						Type:        "string",
-						Description: "Email address of the account to use (optional)",
-					},
-				},
-				Required: []string{"presentation_id", "slide_id", "layout_id"},
-			},
-		},
-		{
+			Annotations: server.IdempotentAnnotations(),
+		}, s.handleSetLayout),
+		server.NewTool(server.Tool{
 			Name:        "slides_export_pdf",
 			Description: "Export presentation as PDF (returns download URL)",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"presentation_id": {
-						Type:        "string",
-						Description: "Presentation ID",
-					},
-					"account": {
-						Type:        "string",
-						Description: "Email address of the account to use (optional)",
-					},
-				},
-				Required: []string{"presentation_id"},
-			},
-		},
-		{
+			Annotations: server.ReadOnlyAnnotations(),
+		}, s.handleExportPDF),
+		server.NewTool(server.Tool{
+			Name:        "slides_slide_thumbnail",
+			Description: "Render a slide as a PNG image",
+			Annotations: server.ReadOnlyAnnotations(),
+		}, s.handleSlideThumbnail),
+		server.NewTool(server.Tool{
 			Name:        "slides_share",
 			Description: "Create a shareable link for a presentation",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"presentation_id": {
-						Type:        "string",
-						Description: "Presentation ID",
-					},
-					"role": {
-						Type:        "string",
-						Description: "Permission role (reader, writer, commenter)",
-						Enum:        []string{"reader", "writer", "commenter"},
-					},
-					"account": {
-						Type:        "string",
-						Description: "Email address of the account to use (optional)",
-					},
-				},
-				Required: []string{"presentation_id", "role"},
-			},
-		},
+			Annotations: server.IdempotentAnnotations(),
+		}, s.handleShare),
 	}
 }
 
+func (s *Service) GetTools() []server.Tool {
+	return s.ToolDefinitions().Tools()
+}
+
 func (s *Service) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
-	var args map[string]interface{}
-	if err := json.Unmarshal(arguments, &args); err != nil {
-		return nil, err
-	}
+	return s.ToolDefinitions().Call(ctx, name, arguments)
+}
 
-	// Get account email if specified
-	accountEmail, _ := args["account"].(string)
+// NewHandler serves the Slides tools of one account and of all accounts,
+// and the Slides prompts, as one service
+func NewHandler(authManager *auth.AccountManager) *server.CombinedHandler {
+	service := NewService(authManager)
+	handler := server.NewCombinedHandler(service.ToolDefinitions(), NewMultiAccountService(authManager).ToolDefinitions())
+	handler.SetPromptProvider(service)
+	return handler
+}
 
-	// Get account and HTTP client
+// clientFor returns a Slides client of the named account, or of the first
+// account when none is named
+func (s *Service) clientFor(ctx context.Context, accountEmail string) (*Client, *auth.Account, error) {
 	var account *auth.Account
-	var httpClient *http.Client
-
 	if accountEmail != "" {
 		var err error
 		account, err = s.authManager.GetAccount(accountEmail)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get account: %w", err)
+			return nil, nil, fmt.Errorf("failed to get account: %w", err)
 		}
 	} else {
 		// Use first available account
 		accounts := s.authManager.ListAccounts()
 		if len(accounts) == 0 {
-			return nil, fmt.Errorf("no authenticated accounts available. Please authenticate using accounts_add")
+			return nil, nil, fmt.Errorf("no authenticated accounts available. Please authenticate using accounts_add")
 		}
 		account = accounts[0]
 	}
@@ -463,355 +292,410 @@ func (s *Service) HandleToolCall(ctx context.Context, name string, arguments jso
 	// Check if account has required scopes for Slides
 	if err := s.authManager.CheckScopes(ctx, account, "slides"); err != nil {
 		if scopeErr, ok := err.(*auth.ScopeError); ok {
-			return nil, fmt.Errorf("%v\n\nTo fix this, run: accounts_refresh (and select account: %s)", scopeErr, account.Email)
+			return nil, nil, fmt.Errorf("%v\n\nTo fix this, run: accounts_refresh (and select account: %s)", scopeErr, account.Email)
 		}
-		return nil, err
+		return nil, nil, err
 	}
 
 	if account.OAuthClient == nil {
-		return nil, fmt.Errorf("no OAuth client for account: %s. Please re-authenticate using accounts_refresh", account.Email)
+		return nil, nil, fmt.Errorf("no OAuth client for account: %s. Please re-authenticate using accounts_refresh", account.Email)
 	}
 
-	httpClient = account.OAuthClient.GetHTTPClient()
-
 	// Create Slides client
-	client, err := NewClient(ctx, httpClient)
+	client, err := NewClient(ctx, account.OAuthClient.GetHTTPClient())
 	if err != nil {
 		// Check if this is an API disabled error
 		if auth.IsAPIDisabledError(err) {
-			return nil, fmt.Errorf(
+			return nil, nil, fmt.Errorf(
 				"Google Slides API is not enabled for this project.\n"+
 					"Please enable it at: https://console.cloud.google.com/apis/library/slides.googleapis.com\n"+
 					"Then re-authenticate using: accounts_refresh (account: %s)",
 				account.Email,
 			)
 		}
+		return nil, nil, err
+	}
+	return client, account, nil
+}
+
+func (s *Service) handlePresentationCreate(ctx context.Context, args presentationCreateArgs) (interface{}, error) {
+	client, account, err := s.clientFor(ctx, args.Account)
+	if err != nil {
 		return nil, err
 	}
 
-	switch name {
-	case "slides_presentation_create":
-		title, _ := args["title"].(string)
-		presentation, err := client.CreatePresentation(title)
-		if err != nil {
-			// Check if this is an API disabled error
-			if auth.IsAPIDisabledError(err) {
-				return nil, fmt.Errorf(
-					"Google Slides API is not enabled for this project.\n"+
-						"Account: %s\n"+
-						"Please enable it at: https://console.cloud.google.com/apis/library/slides.googleapis.com\n"+
-						"After enabling, wait a few minutes then re-authenticate using: accounts_refresh",
-					account.Email,
-				)
-			}
-			return nil, err
+	presentation, err := client.CreatePresentation(ctx, args.Title)
+	if err != nil {
+		// Check if this is an API disabled error
+		if auth.IsAPIDisabledError(err) {
+			return nil, fmt.Errorf(
+				"Google Slides API is not enabled for this project.\n"+
+					"Account: %s\n"+
+					"Please enable it at: https://console.cloud.google.com/apis/library/slides.googleapis.com\n"+
+					"After enabling, wait a few minutes then re-authenticate using: accounts_refresh",
+				account.Email,
+			)
 		}
-		return map[string]interface{}{
-			"presentation_id": presentation.PresentationId,
-			"title":           presentation.Title,
-			"url":             fmt.Sprintf("https://docs.google.com/presentation/d/%s/edit", presentation.PresentationId),
-		}, nil
+		return nil, err
+	}
+	return map[string]interface{}{
+		"presentation_id": presentation.PresentationId,
+		"title":           presentation.Title,
+		"url":             fmt.Sprintf("https://docs.google.com/presentation/d/%s/edit", presentation.PresentationId),
+	}, nil
+}
 
-	case "slides_presentation_get":
-		presentationId, _ := args["presentation_id"].(string)
-		presentation, err := client.GetPresentation(presentationId)
-		if err != nil {
-			return nil, err
-		}
+func (s *Service) handlePresentationGet(ctx context.Context, args presentationArgs) (interface{}, error) {
+	client, _, err := s.clientFor(ctx, args.Account)
+	if err != nil {
+		return nil, err
+	}
 
-		// Build detailed slide information
-		slides := make([]map[string]interface{}, 0, len(presentation.Slides))
-		for i, slide := range presentation.Slides {
-			slideInfo := map[string]interface{}{
-				"index":    i + 1,
-				"slide_id": slide.ObjectId,
-				"layout":   "",
-				"title":    "",
-				"elements": []map[string]interface{}{},
-			}
+	presentation, err := client.GetPresentation(ctx, args.PresentationID)
+	if err != nil {
+		return nil, err
+	}
 
-			// Get layout information
-			if slide.SlideProperties != nil && slide.SlideProperties.LayoutObjectId != "" {
-				for _, layout := range presentation.Layouts {
-					if layout.ObjectId == slide.SlideProperties.LayoutObjectId {
-						if layout.LayoutProperties != nil {
-							slideInfo["layout"] = layout.LayoutProperties.Name
-						}
-						break
+	// Build detailed slide information
+	slides := make([]map[string]interface{}, 0, len(presentation.Slides))
+	for i, slide := range presentation.Slides {
+		slideInfo := map[string]interface{}{
+			"index":    i + 1,
+			"slide_id": slide.ObjectId,
+			"layout":   "",
+			"title":    "",
+			"elements": []map[string]interface{}{},
+		}
+
+		// Get layout information
+		if slide.SlideProperties != nil && slide.SlideProperties.LayoutObjectId != "" {
+			for _, layout := range presentation.Layouts {
+				if layout.ObjectId == slide.SlideProperties.LayoutObjectId {
+					if layout.LayoutProperties != nil {
+						slideInfo["layout"] = layout.LayoutProperties.Name
 					}
+					break
 				}
 			}
+		}
 
-			// Extract elements information
-			elements := make([]map[string]interface{}, 0)
-			for _, element := range slide.PageElements {
-				elementInfo := map[string]interface{}{
-					"element_id": element.ObjectId,
-					"type":       "",
-				}
+		// Extract elements information
+		elements := make([]map[string]interface{}, 0)
+		for _, element := range slide.PageElements {
+			elementInfo := map[string]interface{}{
+				"element_id": element.ObjectId,
+				"type":       "",
+			}
 
-				if element.Shape != nil {
-					elementInfo["type"] = "shape"
-					if element.Shape.ShapeType != "" {
-						elementInfo["shape_type"] = element.Shape.ShapeType
-					}
-					if element.Shape.Text != nil && len(element.Shape.Text.TextElements) > 0 {
-						text := ""
-						fontFamily := ""
-						for _, textElement := range element.Shape.Text.TextElements {
-							if textElement.TextRun != nil {
-								text += textElement.TextRun.Content
-								if textElement.TextRun.Style != nil && textElement.TextRun.Style.FontFamily != "" {
-									fontFamily = textElement.TextRun.Style.FontFamily
-								}
+			if element.Shape != nil {
+				elementInfo["type"] = "shape"
+				if element.Shape.ShapeType != "" {
+					elementInfo["shape_type"] = element.Shape.ShapeType
+				}
+				if element.Shape.Text != nil && len(element.Shape.Text.TextElements) > 0 {
+					text := ""
+					fontFamily := ""
+					for _, textElement := range element.Shape.Text.TextElements {
+						if textElement.TextRun != nil {
+							text += textElement.TextRun.Content
+							if textElement.TextRun.Style != nil && textElement.TextRun.Style.FontFamily != "" {
+								fontFamily = textElement.TextRun.Style.FontFamily
 							}
 						}
-						elementInfo["text"] = text
-						if fontFamily != "" {
-							elementInfo["font_family"] = fontFamily
-						}
 					}
-					if element.Shape.Placeholder != nil {
-						elementInfo["placeholder_type"] = element.Shape.Placeholder.Type
-						if element.Shape.Placeholder.Type == "TITLE" || element.Shape.Placeholder.Type == "CENTERED_TITLE" {
-							slideInfo["title"] = elementInfo["text"]
-						}
+					elementInfo["text"] = text
+					if fontFamily != "" {
+						elementInfo["font_family"] = fontFamily
 					}
-				} else if element.Table != nil {
-					elementInfo["type"] = "table"
-					elementInfo["rows"] = element.Table.Rows
-					elementInfo["columns"] = element.Table.Columns
-				} else if element.Image != nil {
-					elementInfo["type"] = "image"
-					if element.Image.ContentUrl != "" {
-						elementInfo["url"] = element.Image.ContentUrl
+				}
+				if element.Shape.Placeholder != nil {
+					elementInfo["placeholder_type"] = element.Shape.Placeholder.Type
+					if element.Shape.Placeholder.Type == "TITLE" || element.Shape.Placeholder.Type == "CENTERED_TITLE" {
+						slideInfo["title"] = elementInfo["text"]
 					}
 				}
-
-				elements = append(elements, elementInfo)
+			} else if element.Table != nil {
+				elementInfo["type"] = "table"
+				elementInfo["rows"] = element.Table.Rows
+				elementInfo["columns"] = element.Table.Columns
+			} else if element.Image != nil {
+				elementInfo["type"] = "image"
+				if element.Image.ContentUrl != "" {
+					elementInfo["url"] = element.Image.ContentUrl
+				}
 			}
-			slideInfo["elements"] = elements
-			slides = append(slides, slideInfo)
-		}
 
-		return map[string]interface{}{
-			"presentation_id": presentation.PresentationId,
-			"title":           presentation.Title,
-			"slides_count":    len(presentation.Slides),
-			"url":             fmt.Sprintf("https://docs.google.com/presentation/d/%s/edit", presentation.PresentationId),
-			"slides":          slides,
-		}, nil
-
-	case "slides_slide_create":
-		presentationId, _ := args["presentation_id"].(string)
-		insertionIndex := 0
-		if idx, ok := args["insertion_index"].(float64); ok {
-			insertionIndex = int(idx)
-		}
-
-		resp, err := client.CreateSlide(presentationId, insertionIndex)
-		if err != nil {
-			return nil, err
-		}
-
-		if len(resp.Replies) > 0 && resp.Replies[0].CreateSlide != nil {
-			return map[string]interface{}{
-				"slide_id": resp.Replies[0].CreateSlide.ObjectId,
-			}, nil
+			elements = append(elements, elementInfo)
 		}
-		return map[string]interface{}{"success": true}, nil
+		slideInfo["elements"] = elements
+		slides = append(slides, slideInfo)
+	}
 
-	case "slides_slide_delete":
-		presentationId, _ := args["presentation_id"].(string)
-		slideId, _ := args["slide_id"].(string)
+	return map[string]interface{}{
+		"presentation_id": presentation.PresentationId,
+		"title":           presentation.Title,
+		"slides_count":    len(presentation.Slides),
+		"url":             fmt.Sprintf("https://docs.google.com/presentation/d/%s/edit", presentation.PresentationId),
+		"slides":          slides,
+	}, nil
+}
 
-		_, err := client.DeleteSlide(presentationId, slideId)
-		if err != nil {
-			return nil, err
-		}
-		return map[string]interface{}{"success": true}, nil
+func (s *Service) handleSlideCreate(ctx context.Context, args slideCreateArgs) (interface{}, error) {
+	client, _, err := s.clientFor(ctx, args.Account)
+	if err != nil {
+		return nil, err
+	}
 
-	case "slides_slide_duplicate":
-		presentationId, _ := args["presentation_id"].(string)
-		slideId, _ := args["slide_id"].(string)
+	resp, err := client.CreateSlide(ctx, args.PresentationID, args.InsertionIndex)
+	if err != nil {
+		return nil, err
+	}
 
-		resp, err := client.DuplicateSlide(presentationId, slideId)
-		if err != nil {
-			return nil, err
-		}
+	if len(resp.Replies) > 0 && resp.Replies[0].CreateSlide != nil {
+		return map[string]interface{}{
+			"slide_id": resp.Replies[0].CreateSlide.ObjectId,
+		}, nil
+	}
+	return map[string]interface{}{"success": true}, nil
+}
 
-		if len(resp.Replies) > 0 && resp.Replies[0].DuplicateObject != nil {
-			return map[string]interface{}{
-				"new_slide_id": resp.Replies[0].DuplicateObject.ObjectId,
-			}, nil
-		}
-		return map[string]interface{}{"success": true}, nil
+func (s *Service) handleSlideDelete(ctx context.Context, args slideDeleteArgs) (interface{}, error) {
+	client, _, err := s.clientFor(ctx, args.Account)
+	if err != nil {
+		return nil, err
+	}
 
-	case "slides_markdown_create":
-		title, _ := args["title"].(string)
-		markdown, _ := args["markdown"].(string)
+	if _, err := client.DeleteSlide(ctx, args.PresentationID, args.SlideID); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"success": true}, nil
+}
 
-		// Create new presentation
-		presentation, err := client.CreatePresentation(title)
-		if err != nil {
-			return nil, err
-		}
+func (s *Service) handleSlideDuplicate(ctx context.Context, args slideDuplicateArgs) (interface{}, error) {
+	client, _, err := s.clientFor(ctx, args.Account)
+	if err != nil {
+		return nil, err
+	}
 
-		// Convert markdown and create slides
-		converter := NewMarkdownConverter(client, presentation.PresentationId)
-		slides, err := converter.CreateSlidesFromMarkdown(markdown)
-		if err != nil {
-			return nil, err
-		}
+	resp, err := client.DuplicateSlide(ctx, args.PresentationID, args.SlideID)
+	if err != nil {
+		return nil, err
+	}
 
+	if len(resp.Replies) > 0 && resp.Replies[0].DuplicateObject != nil {
 		return map[string]interface{}{
-			"presentation_id": presentation.PresentationId,
-			"title":           presentation.Title,
-			"slides_created":  len(slides),
-			"url":             fmt.Sprintf("https://docs.google.com/presentation/d/%s/edit", presentation.PresentationId),
+			"new_slide_id": resp.Replies[0].DuplicateObject.ObjectId,
 		}, nil
+	}
+	return map[string]interface{}{"success": true}, nil
+}
+
+func (s *Service) handleMarkdownCreate(ctx context.Context, args markdownCreateArgs) (interface{}, error) {
+	client, _, err := s.clientFor(ctx, args.Account)
+	if err != nil {
+		return nil, err
+	}
 
-	case "slides_markdown_update":
-		presentationId, _ := args["presentation_id"].(string)
-		markdown, _ := args["markdown"].(string)
+	// Create new presentation
+	presentation, err := client.CreatePresentation(ctx, args.Title)
+	if err != nil {
+		return nil, err
+	}
 
-		converter := NewMarkdownConverter(client, presentationId)
-		err := converter.UpdateSlidesFromMarkdown(markdown)
-		if err != nil {
-			return nil, err
-		}
+	// Convert markdown and create slides
+	converter := NewMarkdownConverter(client, presentation.PresentationId)
+	slides, err := converter.CreateSlidesFromMarkdown(ctx, args.Markdown)
+	if err != nil {
+		return nil, err
+	}
 
-		return map[string]interface{}{
-			"presentation_id": presentationId,
-			"success":         true,
-		}, nil
+	return map[string]interface{}{
+		"presentation_id": presentation.PresentationId,
+		"title":           presentation.Title,
+		"slides_created":  len(slides),
+		"url":             fmt.Sprintf("https://docs.google.com/presentation/d/%s/edit", presentation.PresentationId),
+	}, nil
+}
 
-	case "slides_markdown_append":
-		presentationId, _ := args["presentation_id"].(string)
-		markdown, _ := args["markdown"].(string)
+func (s *Service) handleMarkdownUpdate(ctx context.Context, args markdownUpdateArgs) (interface{}, error) {
+	client, _, err := s.clientFor(ctx, args.Account)
+	if err != nil {
+		return nil, err
+	}
 
-		converter := NewMarkdownConverter(client, presentationId)
-		slides, err := converter.CreateSlidesFromMarkdown(markdown)
-		if err != nil {
-			return nil, err
-		}
+	converter := NewMarkdownConverter(client, args.PresentationID)
+	if err := converter.UpdateSlidesFromMarkdown(ctx, args.Markdown); err != nil {
+		return nil, err
+	}
 
-		return map[string]interface{}{
-			"presentation_id": presentationId,
-			"slides_added":    len(slides),
-			"success":         true,
-		}, nil
+	return map[string]interface{}{
+		"presentation_id": args.PresentationID,
+		"success":         true,
+	}, nil
+}
 
-	case "slides_add_text":
-		presentationId, _ := args["presentation_id"].(string)
-		slideId, _ := args["slide_id"].(string)
-		text, _ := args["text"].(string)
+func (s *Service) handleMarkdownAppend(ctx context.Context, args markdownAppendArgs) (interface{}, error) {
+	client, _, err := s.clientFor(ctx, args.Account)
+	if err != nil {
+		return nil, err
+	}
 
-		x := getFloatOrDefault(args, "x", 50)
-		y := getFloatOrDefault(args, "y", 50)
-		width := getFloatOrDefault(args, "width", 300)
-		height := getFloatOrDefault(args, "height", 100)
+	converter := NewMarkdownConverter(client, args.PresentationID)
+	slides, err := converter.CreateSlidesFromMarkdown(ctx, args.Markdown)
+	if err != nil {
+		return nil, err
+	}
 
-		_, err := client.AddTextBox(presentationId, slideId, text, x, y, width, height)
-		if err != nil {
-			return nil, err
-		}
-		return map[string]interface{}{"success": true}, nil
+	return map[string]interface{}{
+		"presentation_id": args.PresentationID,
+		"slides_added":    len(slides),
+		"success":         true,
+	}, nil
+}
 
-	case "slides_add_image":
-		presentationId, _ := args["presentation_id"].(string)
-		slideId, _ := args["slide_id"].(string)
-		imageUrl, _ := args["image_url"].(string)
+func (s *Service) handleAddText(ctx context.Context, args addTextArgs) (interface{}, error) {
+	client, _, err := s.clientFor(ctx, args.Account)
+	if err != nil {
+		return nil, err
+	}
 
-		x := getFloatOrDefault(args, "x", 50)
-		y := getFloatOrDefault(args, "y", 50)
-		width := getFloatOrDefault(args, "width", 400)
-		height := getFloatOrDefault(args, "height", 300)
+	x, y, width, height := args.box(50, 50, 300, 100)
+	if _, err := client.AddTextBox(ctx, args.PresentationID, args.SlideID, args.Text, x, y, width, height); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"success": true}, nil
+}
 
-		_, err := client.AddImage(presentationId, slideId, imageUrl, x, y, width, height)
-		if err != nil {
-			return nil, err
-		}
-		return map[string]interface{}{"success": true}, nil
+func (s *Service) handleAddImage(ctx context.Context, args addImageArgs) (interface{}, error) {
+	client, _, err := s.clientFor(ctx, args.Account)
+	if err != nil {
+		return nil, err
+	}
 
-	case "slides_add_table":
-		presentationId, _ := args["presentation_id"].(string)
-		slideId, _ := args["slide_id"].(string)
+	x, y, width, height := args.box(50, 50, 400, 300)
+	if _, err := client.AddImage(ctx, args.PresentationID, args.SlideID, args.ImageURL, x, y, width, height); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"success": true}, nil
+}
 
-		rows := int(getFloatOrDefault(args, "rows", 3))
-		columns := int(getFloatOrDefault(args, "columns", 3))
-		x := getFloatOrDefault(args, "x", 50)
-		y := getFloatOrDefault(args, "y", 50)
-		width := getFloatOrDefault(args, "width", 400)
-		height := getFloatOrDefault(args, "height", 200)
+func (s *Service) handleAddTable(ctx context.Context, args addTableArgs) (interface{}, error) {
+	if err := checkTableData(args.Data, args.Rows, args.Columns); err != nil {
+		return nil, err
+	}
 
-		_, err := client.AddTable(presentationId, slideId, rows, columns, x, y, width, height)
-		if err != nil {
+	client, _, err := s.clientFor(ctx, args.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	x, y, width, height := valueOr(args.X, 50), valueOr(args.Y, 50), valueOr(args.Width, 400), valueOr(args.Height, 200)
+	resp, err := client.AddTable(ctx, args.PresentationID, args.SlideID, args.Rows, args.Columns, x, y, width, height)
+	if err != nil {
+		return nil, err
+	}
+	if len(args.Data) > 0 && len(resp.Replies) > 0 && resp.Replies[0].CreateTable != nil {
+		if _, err := client.FillTable(ctx, args.PresentationID, resp.Replies[0].CreateTable.ObjectId, args.Data); err != nil {
 			return nil, err
 		}
-		return map[string]interface{}{"success": true}, nil
+	}
+	return map[string]interface{}{"success": true}, nil
+}
 
-	case "slides_add_shape":
-		presentationId, _ := args["presentation_id"].(string)
-		slideId, _ := args["slide_id"].(string)
-		shapeType, _ := args["shape_type"].(string)
+func (s *Service) handleAddShape(ctx context.Context, args addShapeArgs) (interface{}, error) {
+	client, _, err := s.clientFor(ctx, args.Account)
+	if err != nil {
+		return nil, err
+	}
 
-		x := getFloatOrDefault(args, "x", 50)
-		y := getFloatOrDefault(args, "y", 50)
-		width := getFloatOrDefault(args, "width", 100)
-		height := getFloatOrDefault(args, "height", 100)
+	x, y, width, height := args.box(50, 50, 100, 100)
+	if _, err := client.AddShape(ctx, args.PresentationID, args.SlideID, args.ShapeType, x, y, width, height); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"success": true}, nil
+}
 
-		_, err := client.AddShape(presentationId, slideId, shapeType, x, y, width, height)
-		if err != nil {
-			return nil, err
-		}
-		return map[string]interface{}{"success": true}, nil
+func (s *Service) handleSetLayout(ctx context.Context, args setLayoutArgs) (interface{}, error) {
+	client, _, err := s.clientFor(ctx, args.Account)
+	if err != nil {
+		return nil, err
+	}
 
-	case "slides_set_layout":
-		presentationId, _ := args["presentation_id"].(string)
-		slideId, _ := args["slide_id"].(string)
-		layoutId, _ := args["layout_id"].(string)
+	if _, err := client.SetSlideLayout(ctx, args.PresentationID, args.SlideID, args.LayoutID); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"success": true}, nil
+}
 
-		_, err := client.SetSlideLayout(presentationId, slideId, layoutId)
-		if err != nil {
-			return nil, err
-		}
-		return map[string]interface{}{"success": true}, nil
+func (s *Service) handleExportPDF(ctx context.Context, args presentationArgs) (interface{}, error) {
+	if _, _, err := s.clientFor(ctx, args.Account); err != nil {
+		return nil, err
+	}
 
-	case "slides_export_pdf":
-		presentationId, _ := args["presentation_id"].(string)
+	// Generate export URL
+	exportUrl := fmt.Sprintf("https://docs.google.com/presentation/d/%s/export/pdf", args.PresentationID)
 
-		// Generate export URL
-		exportUrl := fmt.Sprintf("https://docs.google.com/presentation/d/%s/export/pdf", presentationId)
+	return map[string]interface{}{
+		"presentation_id": args.PresentationID,
+		"export_url":      exportUrl,
+	}, nil
+}
 
-		return map[string]interface{}{
-			"presentation_id": presentationId,
-			"export_url":      exportUrl,
-		}, nil
+func (s *Service) handleSlideThumbnail(ctx context.Context, args slideThumbnailArgs) (interface{}, error) {
+	client, account, err := s.clientFor(ctx, args.Account)
+	if err != nil {
+		return nil, err
+	}
 
-	case "slides_share":
-		// This would typically use Drive API for sharing
-		presentationId, _ := args["presentation_id"].(string)
-		role, _ := args["role"].(string)
+	size := args.Size
+	if size == "" {
+		size = "MEDIUM"
+	}
 
-		// For now, just return the public URL
-		shareUrl := fmt.Sprintf("https://docs.google.com/presentation/d/%s/edit?usp=sharing", presentationId)
+	thumbnail, data, err := client.GetSlideThumbnail(ctx, args.PresentationID, args.SlideID, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get slide thumbnail: %w", err)
+	}
 
-		return map[string]interface{}{
-			"presentation_id": presentationId,
-			"share_url":       shareUrl,
-			"role":            role,
-			"note":            "Use Drive API for actual permission management",
-		}, nil
+	return &server.ToolResult{
+		Structured: map[string]interface{}{
+			"presentation_id": args.PresentationID,
+			"slide_id":        args.SlideID,
+			"width":           thumbnail.Width,
+			"height":          thumbnail.Height,
+			"account":         account.Email,
+		},
+		Content: []server.Content{server.ImageContent(data, "image/png")},
+	}, nil
+}
 
-	default:
-		return nil, fmt.Errorf("unknown tool: %s", name)
+func (s *Service) handleShare(ctx context.Context, args shareArgs) (interface{}, error) {
+	if _, _, err := s.clientFor(ctx, args.Account); err != nil {
+		return nil, err
 	}
+
+	// This would typically use Drive API for sharing; for now, just return
+	// the public URL
+	shareUrl := fmt.Sprintf("https://docs.google.com/presentation/d/%s/edit?usp=sharing", args.PresentationID)
+
+	return map[string]interface{}{
+		"presentation_id": args.PresentationID,
+		"share_url":       shareUrl,
+		"role":            args.Role,
+		"note":            "Use Drive API for actual permission management",
+	}, nil
 }
 
-func getFloatOrDefault(args map[string]interface{}, key string, defaultValue float64) float64 {
-	if val, ok := args[key].(float64); ok {
-		return val
+// checkTableData checks that the data argument of slides_add_table fits the
+// table
+func checkTableData(data [][]string, rows, columns int) error {
+	if len(data) > rows {
+		return fmt.Errorf("invalid argument: data has %d rows but the table has %d", len(data), rows)
+	}
+	for i, cells := range data {
+		if len(cells) > columns {
+			return fmt.Errorf("invalid argument: row %d of data has %d cells but the table has %d columns", i, len(cells), columns)
+		}
 	}
-	return defaultValue
+	return nil
 }
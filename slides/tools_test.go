@@ -3,6 +3,7 @@ package slides
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"go.ngs.io/google-mcp-server/auth"
@@ -31,6 +32,7 @@ func TestServiceGetTools(t *testing.T) {
 		"slides_add_shape",
 		"slides_set_layout",
 		"slides_export_pdf",
+		"slides_slide_thumbnail",
 		"slides_share",
 		// "slides_presentations_list_all_accounts" is in MultiAccountService, not Service
 	}
@@ -69,7 +71,7 @@ func TestHandleToolCallErrors(t *testing.T) {
 			toolName:  "unknown_tool",
 			args:      json.RawMessage(`{}`),
 			wantError: true,
-			errorMsg:  "no authenticated accounts", // Error comes from auth check first
+			errorMsg:  "unknown tool: unknown_tool",
 		},
 		{
 			name:      "Invalid JSON arguments",
@@ -294,8 +296,59 @@ func TestToolInputValidation(t *testing.T) {
 	}
 }
 
+func TestCheckTableData(t *testing.T) {
+	var data tableCells
+	if err := json.Unmarshal([]byte(`[["Name","Score"],["Ann"]]`), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := checkTableData(data, 3, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := checkTableData(nil, 3, 2); err != nil {
+		t.Errorf("expected no data to fit, got %v", err)
+	}
+
+	if err := checkTableData(data, 1, 2); err == nil || !strings.Contains(err.Error(), "data has 2 rows") {
+		t.Errorf("expected too many rows to be rejected, got %v", err)
+	}
+	if err := checkTableData(data, 3, 1); err == nil || !strings.Contains(err.Error(), "row 0 of data has 2 cells") {
+		t.Errorf("expected too many cells to be rejected, got %v", err)
+	}
+}
+
 // Helper function
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && s[:len(substr)] == substr ||
 		len(s) > len(substr) && contains(s[1:], substr)
 }
+
+func TestDocToDeckPrompt(t *testing.T) {
+	service := NewService(&auth.AccountManager{})
+
+	prompts := service.GetPrompts()
+	if len(prompts) != 1 || prompts[0].Name != "slides_doc_to_deck" {
+		t.Fatalf("unexpected prompts: %+v", prompts)
+	}
+
+	result, err := service.GetPrompt(context.Background(), "slides_doc_to_deck", map[string]string{
+		"document_id": "doc-123",
+		"title":       "Q3 Review",
+	})
+	if err != nil {
+		t.Fatalf("GetPrompt failed: %v", err)
+	}
+	if len(result.Messages) != 1 {
+		t.Fatalf("expected one message, got %d", len(result.Messages))
+	}
+	text := result.Messages[0].Content.Text
+	for _, want := range []string{`"doc-123"`, `"Q3 Review"`, "docs_document_get", "slides_markdown_create"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("prompt text missing %q:\n%s", want, text)
+		}
+	}
+
+	if _, err := service.GetPrompt(context.Background(), "unknown", nil); err == nil {
+		t.Error("expected error for unknown prompt")
+	}
+}
@@ -5,10 +5,13 @@ import (
 	"fmt"
 
 	"go.ngs.io/google-mcp-server/auth"
+	"go.ngs.io/google-mcp-server/logging"
 	"google.golang.org/api/option"
 	"google.golang.org/api/tasks/v1"
 )
 
+var logger = logging.Logger("tasks")
+
 // Client wraps the Google Tasks API client
 type Client struct {
 	service *tasks.Service
@@ -41,11 +44,11 @@ func NewClientWithHTTPClient(ctx context.Context, httpClient option.ClientOption
 // --- Task List Operations ---
 
 // ListTaskLists lists all task lists
-func (c *Client) ListTaskLists() ([]*tasks.TaskList, error) {
+func (c *Client) ListTaskLists(ctx context.Context) ([]*tasks.TaskList, error) {
 	var taskLists []*tasks.TaskList
 
 	call := c.service.Tasklists.List()
-	err := call.Pages(context.Background(), func(page *tasks.TaskLists) error {
+	err := call.Pages(ctx, func(page *tasks.TaskLists) error {
 		taskLists = append(taskLists, page.Items...)
 		return nil
 	})
@@ -58,8 +61,8 @@ func (c *Client) ListTaskLists() ([]*tasks.TaskList, error) {
 }
 
 // GetTaskList gets a specific task list by ID
-func (c *Client) GetTaskList(taskListID string) (*tasks.TaskList, error) {
-	taskList, err := c.service.Tasklists.Get(taskListID).Do()
+func (c *Client) GetTaskList(ctx context.Context, taskListID string) (*tasks.TaskList, error) {
+	taskList, err := c.service.Tasklists.Get(taskListID).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get task list: %w", err)
 	}
@@ -67,12 +70,12 @@ func (c *Client) GetTaskList(taskListID string) (*tasks.TaskList, error) {
 }
 
 // CreateTaskList creates a new task list
-func (c *Client) CreateTaskList(title string) (*tasks.TaskList, error) {
+func (c *Client) CreateTaskList(ctx context.Context, title string) (*tasks.TaskList, error) {
 	taskList := &tasks.TaskList{
 		Title: title,
 	}
 
-	created, err := c.service.Tasklists.Insert(taskList).Do()
+	created, err := c.service.Tasklists.Insert(taskList).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create task list: %w", err)
 	}
@@ -80,12 +83,12 @@ func (c *Client) CreateTaskList(title string) (*tasks.TaskList, error) {
 }
 
 // UpdateTaskList updates an existing task list
-func (c *Client) UpdateTaskList(taskListID, title string) (*tasks.TaskList, error) {
+func (c *Client) UpdateTaskList(ctx context.Context, taskListID, title string) (*tasks.TaskList, error) {
 	taskList := &tasks.TaskList{
 		Title: title,
 	}
 
-	updated, err := c.service.Tasklists.Update(taskListID, taskList).Do()
+	updated, err := c.service.Tasklists.Update(taskListID, taskList).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to update task list: %w", err)
 	}
@@ -93,8 +96,8 @@ func (c *Client) UpdateTaskList(taskListID, title string) (*tasks.TaskList, erro
 }
 
 // DeleteTaskList deletes a task list
-func (c *Client) DeleteTaskList(taskListID string) error {
-	err := c.service.Tasklists.Delete(taskListID).Do()
+func (c *Client) DeleteTaskList(ctx context.Context, taskListID string) error {
+	err := c.service.Tasklists.Delete(taskListID).Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("failed to delete task list: %w", err)
 	}
@@ -114,7 +117,7 @@ type ListTasksOptions struct {
 }
 
 // ListTasks lists tasks in a task list with options
-func (c *Client) ListTasks(taskListID string, opts *ListTasksOptions) ([]*tasks.Task, error) {
+func (c *Client) ListTasks(ctx context.Context, taskListID string, opts *ListTasksOptions) ([]*tasks.Task, error) {
 	call := c.service.Tasks.List(taskListID)
 
 	if opts != nil {
@@ -134,7 +137,7 @@ func (c *Client) ListTasks(taskListID string, opts *ListTasksOptions) ([]*tasks.
 	}
 
 	var allTasks []*tasks.Task
-	err := call.Pages(context.Background(), func(page *tasks.Tasks) error {
+	err := call.Pages(ctx, func(page *tasks.Tasks) error {
 		allTasks = append(allTasks, page.Items...)
 		return nil
 	})
@@ -147,8 +150,8 @@ func (c *Client) ListTasks(taskListID string, opts *ListTasksOptions) ([]*tasks.
 }
 
 // GetTask gets a specific task
-func (c *Client) GetTask(taskListID, taskID string) (*tasks.Task, error) {
-	task, err := c.service.Tasks.Get(taskListID, taskID).Do()
+func (c *Client) GetTask(ctx context.Context, taskListID, taskID string) (*tasks.Task, error) {
+	task, err := c.service.Tasks.Get(taskListID, taskID).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get task: %w", err)
 	}
@@ -166,7 +169,7 @@ type CreateTaskOptions struct {
 }
 
 // CreateTask creates a new task
-func (c *Client) CreateTask(taskListID string, opts *CreateTaskOptions) (*tasks.Task, error) {
+func (c *Client) CreateTask(ctx context.Context, taskListID string, opts *CreateTaskOptions) (*tasks.Task, error) {
 	task := &tasks.Task{
 		Title: opts.Title,
 	}
@@ -192,7 +195,7 @@ func (c *Client) CreateTask(taskListID string, opts *CreateTaskOptions) (*tasks.
 		call = call.Previous(opts.PreviousTaskID)
 	}
 
-	created, err := call.Do()
+	created, err := call.Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create task: %w", err)
 	}
@@ -208,9 +211,9 @@ type UpdateTaskOptions struct {
 }
 
 // UpdateTask updates an existing task
-func (c *Client) UpdateTask(taskListID, taskID string, opts *UpdateTaskOptions) (*tasks.Task, error) {
+func (c *Client) UpdateTask(ctx context.Context, taskListID, taskID string, opts *UpdateTaskOptions) (*tasks.Task, error) {
 	// First, get the current task
-	task, err := c.GetTask(taskListID, taskID)
+	task, err := c.GetTask(ctx, taskListID, taskID)
 	if err != nil {
 		return nil, err
 	}
@@ -229,7 +232,7 @@ func (c *Client) UpdateTask(taskListID, taskID string, opts *UpdateTaskOptions)
 		task.Status = *opts.Status
 	}
 
-	updated, err := c.service.Tasks.Update(taskListID, taskID, task).Do()
+	updated, err := c.service.Tasks.Update(taskListID, taskID, task).Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to update task: %w", err)
 	}
@@ -237,8 +240,8 @@ func (c *Client) UpdateTask(taskListID, taskID string, opts *UpdateTaskOptions)
 }
 
 // DeleteTask deletes a task
-func (c *Client) DeleteTask(taskListID, taskID string) error {
-	err := c.service.Tasks.Delete(taskListID, taskID).Do()
+func (c *Client) DeleteTask(ctx context.Context, taskListID, taskID string) error {
+	err := c.service.Tasks.Delete(taskListID, taskID).Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("failed to delete task: %w", err)
 	}
@@ -246,23 +249,23 @@ func (c *Client) DeleteTask(taskListID, taskID string) error {
 }
 
 // CompleteTask marks a task as completed
-func (c *Client) CompleteTask(taskListID, taskID string) (*tasks.Task, error) {
+func (c *Client) CompleteTask(ctx context.Context, taskListID, taskID string) (*tasks.Task, error) {
 	status := "completed"
-	return c.UpdateTask(taskListID, taskID, &UpdateTaskOptions{
+	return c.UpdateTask(ctx, taskListID, taskID, &UpdateTaskOptions{
 		Status: &status,
 	})
 }
 
 // UncompleteTask marks a task as needs action
-func (c *Client) UncompleteTask(taskListID, taskID string) (*tasks.Task, error) {
+func (c *Client) UncompleteTask(ctx context.Context, taskListID, taskID string) (*tasks.Task, error) {
 	status := "needsAction"
-	return c.UpdateTask(taskListID, taskID, &UpdateTaskOptions{
+	return c.UpdateTask(ctx, taskListID, taskID, &UpdateTaskOptions{
 		Status: &status,
 	})
 }
 
 // MoveTask moves a task to a new position (optionally under a new parent)
-func (c *Client) MoveTask(taskListID, taskID string, parent, previous string) (*tasks.Task, error) {
+func (c *Client) MoveTask(ctx context.Context, taskListID, taskID string, parent, previous string) (*tasks.Task, error) {
 	call := c.service.Tasks.Move(taskListID, taskID)
 
 	if parent != "" {
@@ -272,7 +275,7 @@ func (c *Client) MoveTask(taskListID, taskID string, parent, previous string) (*
 		call = call.Previous(previous)
 	}
 
-	moved, err := call.Do()
+	moved, err := call.Context(ctx).Do()
 	if err != nil {
 		return nil, fmt.Errorf("failed to move task: %w", err)
 	}
@@ -280,8 +283,8 @@ func (c *Client) MoveTask(taskListID, taskID string, parent, previous string) (*
 }
 
 // ClearCompleted removes all completed tasks from a task list
-func (c *Client) ClearCompleted(taskListID string) error {
-	err := c.service.Tasks.Clear(taskListID).Do()
+func (c *Client) ClearCompleted(ctx context.Context, taskListID string) error {
+	err := c.service.Tasks.Clear(taskListID).Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("failed to clear completed tasks: %w", err)
 	}
@@ -289,8 +292,8 @@ func (c *Client) ClearCompleted(taskListID string) error {
 }
 
 // GetDefaultTaskList returns the default task list (usually the first one)
-func (c *Client) GetDefaultTaskList() (*tasks.TaskList, error) {
-	taskLists, err := c.ListTaskLists()
+func (c *Client) GetDefaultTaskList(ctx context.Context) (*tasks.TaskList, error) {
+	taskLists, err := c.ListTaskLists(ctx)
 	if err != nil {
 		return nil, err
 	}
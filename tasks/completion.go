@@ -0,0 +1,37 @@
+package tasks
+
+import (
+	"context"
+
+	"go.ngs.io/google-mcp-server/server"
+)
+
+// CompleteArgument suggests task list IDs, matched by ID or title, for
+// tasklist_id arguments and the id variable of the task list resource template
+func (h *MultiAccountHandler) CompleteArgument(ctx context.Context, ref server.CompletionRef, argument string, arguments map[string]string) ([]server.CompletionCandidate, error) {
+	switch {
+	case argument == "tasklist_id":
+	case argument == "id" && ref.Type == server.RefResource && ref.URI == taskListURITemplate:
+	default:
+		return nil, nil
+	}
+
+	client, err := h.getClientForAccount(ctx, arguments["account"])
+	if err != nil {
+		return nil, err
+	}
+
+	taskLists, err := client.ListTaskLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []server.CompletionCandidate{{Value: "default", Label: "Default task list"}}
+	for _, list := range taskLists {
+		candidates = append(candidates, server.CompletionCandidate{
+			Value: list.Id,
+			Label: list.Title,
+		})
+	}
+	return candidates, nil
+}
@@ -0,0 +1,62 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ConfirmationMessage implements server.Confirmer for deleting a task list
+// and clearing completed tasks. The message names the list and counts the
+// tasks that would be lost; when they cannot be looked up it falls back to
+// the list ID.
+func (h *MultiAccountHandler) ConfirmationMessage(ctx context.Context, name string, arguments json.RawMessage) (string, error) {
+	if name != "tasks_delete_tasklist" && name != "tasks_clear_completed" {
+		return "", nil
+	}
+
+	var args struct {
+		TaskListID string `json:"tasklist_id"`
+		Account    string `json:"account"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	listName := fmt.Sprintf("task list %s", args.TaskListID)
+	total, completed := -1, -1
+	if client, err := h.getClientForAccount(ctx, args.Account); err == nil {
+		taskListID := args.TaskListID
+		if name == "tasks_clear_completed" {
+			taskListID, err = h.resolveTaskListID(ctx, client, taskListID)
+		}
+		if err == nil {
+			if list, err := client.GetTaskList(ctx, taskListID); err == nil {
+				listName = fmt.Sprintf("task list %q", list.Title)
+			}
+			if items, err := client.ListTasks(ctx, taskListID, &ListTasksOptions{ShowCompleted: true, ShowHidden: true}); err == nil {
+				total, completed = len(items), 0
+				for _, task := range items {
+					if task.Status == "completed" {
+						completed++
+					}
+				}
+			}
+		}
+	}
+
+	if name == "tasks_delete_tasklist" {
+		if total < 0 {
+			return fmt.Sprintf("Delete the %s and all of its tasks?", listName), nil
+		}
+		return fmt.Sprintf("Delete the %s and its %d task(s)?", listName, total), nil
+	}
+	switch completed {
+	case 0:
+		return fmt.Sprintf("Clear the completed tasks of the %s? It has none, so nothing will be removed.", listName), nil
+	case -1:
+		return fmt.Sprintf("Remove all completed tasks from the %s?", listName), nil
+	default:
+		return fmt.Sprintf("Remove %d completed task(s) from the %s?", completed, listName), nil
+	}
+}
@@ -10,32 +10,27 @@ import (
 
 // TestHandlerGetTools verifies that the handler returns the expected tools
 func TestHandlerGetTools(t *testing.T) {
-	// Create a handler with nil client (just testing tool definitions)
-	handler := &Handler{client: nil}
+	// Create a handler with nil dependencies (just testing tool definitions)
+	handler := &MultiAccountHandler{}
 
 	tools := handler.GetTools()
 
-	// Verify we have the expected number of tools
-	expectedToolCount := 13 // All tools except the multi-account one
-	if len(tools) != expectedToolCount {
-		t.Errorf("Expected %d tools, got %d", expectedToolCount, len(tools))
-	}
-
 	// Verify tool names
 	expectedTools := map[string]bool{
-		"tasks_list_tasklists":  true,
-		"tasks_get_tasklist":    true,
-		"tasks_create_tasklist": true,
-		"tasks_update_tasklist": true,
-		"tasks_delete_tasklist": true,
-		"tasks_list_tasks":      true,
-		"tasks_get_task":        true,
-		"tasks_create_task":     true,
-		"tasks_update_task":     true,
-		"tasks_delete_task":     true,
-		"tasks_complete_task":   true,
-		"tasks_move_task":       true,
-		"tasks_clear_completed": true,
+		"tasks_list_tasklists":              true,
+		"tasks_list_tasklists_all_accounts": true,
+		"tasks_get_tasklist":                true,
+		"tasks_create_tasklist":             true,
+		"tasks_update_tasklist":             true,
+		"tasks_delete_tasklist":             true,
+		"tasks_list_tasks":                  true,
+		"tasks_get_task":                    true,
+		"tasks_create_task":                 true,
+		"tasks_update_task":                 true,
+		"tasks_delete_task":                 true,
+		"tasks_complete_task":               true,
+		"tasks_move_task":                   true,
+		"tasks_clear_completed":             true,
 	}
 
 	for _, tool := range tools {
@@ -84,7 +79,7 @@ func TestMultiAccountHandlerGetTools(t *testing.T) {
 
 // TestToolSchemas verifies that tool schemas are properly defined
 func TestToolSchemas(t *testing.T) {
-	handler := &Handler{client: nil}
+	handler := &MultiAccountHandler{}
 	tools := handler.GetTools()
 
 	toolMap := make(map[string]server.Tool)
@@ -149,21 +144,6 @@ func TestFormatTask(t *testing.T) {
 	}
 }
 
-// TestHandleToolCallUnknownTool verifies unknown tool handling
-func TestHandleToolCallUnknownTool(t *testing.T) {
-	handler := &Handler{client: nil}
-
-	_, err := handler.HandleToolCall(context.Background(), "unknown_tool", json.RawMessage(`{}`))
-	if err == nil {
-		t.Error("Expected error for unknown tool")
-	}
-
-	expectedErr := "unknown tool: unknown_tool"
-	if err.Error() != expectedErr {
-		t.Errorf("Expected error '%s', got '%s'", expectedErr, err.Error())
-	}
-}
-
 // TestMultiAccountHandlerUnknownTool verifies unknown tool handling in multi-account handler
 func TestMultiAccountHandlerUnknownTool(t *testing.T) {
 	handler := &MultiAccountHandler{
@@ -184,7 +164,7 @@ func TestMultiAccountHandlerUnknownTool(t *testing.T) {
 
 // TestInvalidArgumentsHandling verifies error handling for invalid arguments
 func TestInvalidArgumentsHandling(t *testing.T) {
-	handler := &Handler{client: nil}
+	handler := &MultiAccountHandler{}
 
 	// Test with invalid JSON
 	_, err := handler.HandleToolCall(context.Background(), "tasks_get_task", json.RawMessage(`{invalid`))
@@ -4,462 +4,188 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 
 	"go.ngs.io/google-mcp-server/server"
 )
 
-// validateID validates a resource ID
-func validateID(id, name string) error {
-	if id == "" {
-		return fmt.Errorf("%s is required", name)
-	}
-	if len(id) > 256 {
-		return fmt.Errorf("%s is too long (max 256 characters)", name)
-	}
-	return nil
+// Arguments of the Tasks tools. The input schemas are generated from these
+// structs, see server.SchemaOf.
+
+type accountArgs struct {
+	Account string `json:"account,omitempty" description:"Email address of the account to use (optional)"`
+}
+
+type taskListArgs struct {
+	TaskListID string `json:"tasklist_id" required:"true" maxLength:"256" description:"The ID of the task list"`
+	accountArgs
+}
+
+type createTaskListArgs struct {
+	Title string `json:"title" required:"true" description:"Title of the new task list"`
+	accountArgs
+}
+
+type updateTaskListArgs struct {
+	TaskListID string `json:"tasklist_id" required:"true" maxLength:"256" description:"The ID of the task list to update"`
+	Title      string `json:"title" required:"true" description:"New title for the task list"`
+	accountArgs
+}
+
+type deleteTaskListArgs struct {
+	TaskListID string `json:"tasklist_id" required:"true" maxLength:"256" description:"The ID of the task list to delete"`
+	accountArgs
+}
+
+type listTasksArgs struct {
+	TaskListID    string `json:"tasklist_id" required:"true" maxLength:"256" description:"The ID of the task list (use 'default' for the primary task list)"`
+	ShowCompleted bool   `json:"show_completed" default:"false" description:"Whether to show completed tasks"`
+	ShowHidden    bool   `json:"show_hidden" default:"false" description:"Whether to show hidden tasks"`
+	MaxResults    int64  `json:"max_results" minimum:"1" maximum:"100" description:"Maximum number of tasks to return"`
+	DueMin        string `json:"due_min" format:"date-time" description:"Lower bound for task due date (RFC3339 format)"`
+	DueMax        string `json:"due_max" format:"date-time" description:"Upper bound for task due date (RFC3339 format)"`
+	accountArgs
+}
+
+type taskArgs struct {
+	TaskListID string `json:"tasklist_id" required:"true" maxLength:"256" description:"The ID of the task list"`
+	TaskID     string `json:"task_id" required:"true" maxLength:"256" description:"The ID of the task"`
+	accountArgs
+}
+
+type createTaskArgs struct {
+	TaskListID string `json:"tasklist_id" required:"true" maxLength:"256" description:"The ID of the task list (use 'default' for the primary task list)"`
+	Title      string `json:"title" required:"true" description:"Title of the task"`
+	Notes      string `json:"notes" description:"Additional notes or description for the task"`
+	Due        string `json:"due" description:"Due date (RFC3339 format, e.g., 2025-02-06T00:00:00Z or just 2025-02-06)"`
+	Parent     string `json:"parent" description:"Parent task ID to create this as a subtask"`
+	accountArgs
+}
+
+type updateTaskArgs struct {
+	TaskListID string  `json:"tasklist_id" required:"true" maxLength:"256" description:"The ID of the task list"`
+	TaskID     string  `json:"task_id" required:"true" maxLength:"256" description:"The ID of the task to update"`
+	Title      *string `json:"title,omitempty" description:"New title for the task"`
+	Notes      *string `json:"notes,omitempty" description:"New notes for the task"`
+	Due        *string `json:"due,omitempty" format:"date-time" description:"New due date (RFC3339 format)"`
+	Status     *string `json:"status,omitempty" enum:"needsAction,completed" description:"Task status: 'needsAction' or 'completed'"`
+	accountArgs
 }
 
-// Handler implements the ServiceHandler interface for Tasks
-type Handler struct {
-	client *Client
+type moveTaskArgs struct {
+	TaskListID string `json:"tasklist_id" required:"true" maxLength:"256" description:"The ID of the task list"`
+	TaskID     string `json:"task_id" required:"true" maxLength:"256" description:"The ID of the task to move"`
+	Parent     string `json:"parent" description:"New parent task ID (empty string to make it a top-level task)"`
+	Previous   string `json:"previous" description:"ID of the task to position after (empty for first position)"`
+	accountArgs
 }
 
-// NewHandler creates a new Tasks handler
-func NewHandler(client *Client) *Handler {
-	return &Handler{client: client}
+type clearCompletedArgs struct {
+	TaskListID string `json:"tasklist_id" required:"true" maxLength:"256" description:"The ID of the task list to clear completed tasks from"`
+	accountArgs
 }
 
-// GetTools returns the available Tasks tools
-func (h *Handler) GetTools() []server.Tool {
-	return []server.Tool{
+// ToolDefinitions declares the Tasks tools together with their handlers
+func (h *MultiAccountHandler) ToolDefinitions() server.ToolSet {
+	return server.ToolSet{
 		// Task List Tools
-		{
+		server.NewTool(server.Tool{
 			Name:        "tasks_list_tasklists",
 			Description: "List all task lists for the authenticated user",
-			InputSchema: server.InputSchema{
-				Type:       "object",
-				Properties: map[string]server.Property{},
-			},
-		},
-		{
+			Annotations: server.ReadOnlyAnnotations(),
+		}, h.handleListTaskLists),
+		server.NewTool(server.Tool{
+			Name:        "tasks_list_tasklists_all_accounts",
+			Description: "List all task lists from all authenticated accounts",
+			Annotations: server.ReadOnlyAnnotations(),
+		}, h.handleListTaskListsAllAccounts),
+		server.NewTool(server.Tool{
 			Name:        "tasks_get_tasklist",
 			Description: "Get details of a specific task list",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"tasklist_id": {
-						Type:        "string",
-						Description: "The ID of the task list",
-					},
-				},
-				Required: []string{"tasklist_id"},
-			},
-		},
-		{
+			Annotations: server.ReadOnlyAnnotations(),
+		}, h.handleGetTaskList),
+		server.NewTool(server.Tool{
 			Name:        "tasks_create_tasklist",
 			Description: "Create a new task list",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"title": {
-						Type:        "string",
-						Description: "Title of the new task list",
-					},
-				},
-				Required: []string{"title"},
-			},
-		},
-		{
+			Annotations: server.AdditiveAnnotations(),
+		}, h.handleCreateTaskList),
+		server.NewTool(server.Tool{
 			Name:        "tasks_update_tasklist",
 			Description: "Update an existing task list",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"tasklist_id": {
-						Type:        "string",
-						Description: "The ID of the task list to update",
-					},
-					"title": {
-						Type:        "string",
-						Description: "New title for the task list",
-					},
-				},
-				Required: []string{"tasklist_id", "title"},
-			},
-		},
-		{
+			Annotations: server.DestructiveAnnotations(),
+		}, h.handleUpdateTaskList),
+		server.NewTool(server.Tool{
 			Name:        "tasks_delete_tasklist",
 			Description: "Delete a task list",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"tasklist_id": {
-						Type:        "string",
-						Description: "The ID of the task list to delete",
-					},
-				},
-				Required: []string{"tasklist_id"},
-			},
-		},
+			Annotations: server.DestructiveAnnotations(),
+		}, h.handleDeleteTaskList),
+
 		// Task Tools
-		{
+		server.NewTool(server.Tool{
 			Name:        "tasks_list_tasks",
 			Description: "List tasks in a task list",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"tasklist_id": {
-						Type:        "string",
-						Description: "The ID of the task list (use 'default' for the primary task list)",
-					},
-					"show_completed": {
-						Type:        "boolean",
-						Description: "Whether to show completed tasks (default: false)",
-					},
-					"show_hidden": {
-						Type:        "boolean",
-						Description: "Whether to show hidden tasks (default: false)",
-					},
-					"max_results": {
-						Type:        "number",
-						Description: "Maximum number of tasks to return",
-					},
-					"due_min": {
-						Type:        "string",
-						Description: "Lower bound for task due date (RFC3339 format)",
-					},
-					"due_max": {
-						Type:        "string",
-						Description: "Upper bound for task due date (RFC3339 format)",
-					},
-				},
-				Required: []string{"tasklist_id"},
-			},
-		},
-		{
+			Annotations: server.ReadOnlyAnnotations(),
+		}, h.handleListTasks),
+		server.NewTool(server.Tool{
 			Name:        "tasks_get_task",
 			Description: "Get details of a specific task",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"tasklist_id": {
-						Type:        "string",
-						Description: "The ID of the task list",
-					},
-					"task_id": {
-						Type:        "string",
-						Description: "The ID of the task",
-					},
-				},
-				Required: []string{"tasklist_id", "task_id"},
-			},
-		},
-		{
+			Annotations: server.ReadOnlyAnnotations(),
+		}, h.handleGetTask),
+		server.NewTool(server.Tool{
 			Name:        "tasks_create_task",
 			Description: "Create a new task in a task list",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"tasklist_id": {
-						Type:        "string",
-						Description: "The ID of the task list (use 'default' for the primary task list)",
-					},
-					"title": {
-						Type:        "string",
-						Description: "Title of the task",
-					},
-					"notes": {
-						Type:        "string",
-						Description: "Additional notes or description for the task",
-					},
-					"due": {
-						Type:        "string",
-						Description: "Due date (RFC3339 format, e.g., 2025-02-06T00:00:00Z or just 2025-02-06)",
-					},
-					"parent": {
-						Type:        "string",
-						Description: "Parent task ID to create this as a subtask",
-					},
-				},
-				Required: []string{"tasklist_id", "title"},
-			},
-		},
-		{
+			Annotations: server.AdditiveAnnotations(),
+		}, h.handleCreateTask),
+		server.NewTool(server.Tool{
 			Name:        "tasks_update_task",
 			Description: "Update an existing task",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"tasklist_id": {
-						Type:        "string",
-						Description: "The ID of the task list",
-					},
-					"task_id": {
-						Type:        "string",
-						Description: "The ID of the task to update",
-					},
-					"title": {
-						Type:        "string",
-						Description: "New title for the task",
-					},
-					"notes": {
-						Type:        "string",
-						Description: "New notes for the task",
-					},
-					"due": {
-						Type:        "string",
-						Description: "New due date (RFC3339 format)",
-					},
-					"status": {
-						Type:        "string",
-						Description: "Task status: 'needsAction' or 'completed'",
-						Enum:        []string{"needsAction", "completed"},
-					},
-				},
-				Required: []string{"tasklist_id", "task_id"},
-			},
-		},
-		{
+			Annotations: server.DestructiveAnnotations(),
+		}, h.handleUpdateTask),
+		server.NewTool(server.Tool{
 			Name:        "tasks_delete_task",
 			Description: "Delete a task",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"tasklist_id": {
-						Type:        "string",
-						Description: "The ID of the task list",
-					},
-					"task_id": {
-						Type:        "string",
-						Description: "The ID of the task to delete",
-					},
-				},
-				Required: []string{"tasklist_id", "task_id"},
-			},
-		},
-		{
+			Annotations: server.DestructiveAnnotations(),
+		}, h.handleDeleteTask),
+		server.NewTool(server.Tool{
 			Name:        "tasks_complete_task",
 			Description: "Mark a task as completed",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"tasklist_id": {
-						Type:        "string",
-						Description: "The ID of the task list",
-					},
-					"task_id": {
-						Type:        "string",
-						Description: "The ID of the task to complete",
-					},
-				},
-				Required: []string{"tasklist_id", "task_id"},
-			},
-		},
-		{
+			Annotations: server.IdempotentAnnotations(),
+		}, h.handleCompleteTask),
+		server.NewTool(server.Tool{
 			Name:        "tasks_move_task",
 			Description: "Move a task to a new position (reorder or change parent)",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"tasklist_id": {
-						Type:        "string",
-						Description: "The ID of the task list",
-					},
-					"task_id": {
-						Type:        "string",
-						Description: "The ID of the task to move",
-					},
-					"parent": {
-						Type:        "string",
-						Description: "New parent task ID (empty string to make it a top-level task)",
-					},
-					"previous": {
-						Type:        "string",
-						Description: "ID of the task to position after (empty for first position)",
-					},
-				},
-				Required: []string{"tasklist_id", "task_id"},
-			},
-		},
-		{
+			Annotations: server.IdempotentAnnotations(),
+		}, h.handleMoveTask),
+		server.NewTool(server.Tool{
 			Name:        "tasks_clear_completed",
 			Description: "Remove all completed tasks from a task list",
-			InputSchema: server.InputSchema{
-				Type: "object",
-				Properties: map[string]server.Property{
-					"tasklist_id": {
-						Type:        "string",
-						Description: "The ID of the task list to clear completed tasks from",
-					},
-				},
-				Required: []string{"tasklist_id"},
-			},
-		},
+			Annotations: server.DestructiveAnnotations(),
+		}, h.handleClearCompleted),
 	}
 }
 
-// GetResources returns available resources (none for Tasks)
-func (h *Handler) GetResources() []server.Resource {
-	return []server.Resource{}
+// GetTools returns the available Tasks tools with multi-account support
+func (h *MultiAccountHandler) GetTools() []server.Tool {
+	return h.ToolDefinitions().Tools()
 }
 
-// HandleResourceCall handles resource calls (not implemented for Tasks)
-func (h *Handler) HandleResourceCall(ctx context.Context, uri string) (interface{}, error) {
-	return nil, fmt.Errorf("resources not supported for tasks service")
+// HandleToolCall handles a tool call
+func (h *MultiAccountHandler) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
+	return h.ToolDefinitions().Call(ctx, name, arguments)
 }
 
-// HandleToolCall handles a tool call for Tasks service
-func (h *Handler) HandleToolCall(ctx context.Context, name string, arguments json.RawMessage) (interface{}, error) {
-	switch name {
-	// Task List operations
-	case "tasks_list_tasklists":
-		return h.handleListTaskLists(ctx)
-
-	case "tasks_get_tasklist":
-		var args struct {
-			TaskListID string `json:"tasklist_id"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleGetTaskList(ctx, args.TaskListID)
-
-	case "tasks_create_tasklist":
-		var args struct {
-			Title string `json:"title"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleCreateTaskList(ctx, args.Title)
-
-	case "tasks_update_tasklist":
-		var args struct {
-			TaskListID string `json:"tasklist_id"`
-			Title      string `json:"title"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleUpdateTaskList(ctx, args.TaskListID, args.Title)
-
-	case "tasks_delete_tasklist":
-		var args struct {
-			TaskListID string `json:"tasklist_id"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleDeleteTaskList(ctx, args.TaskListID)
-
-	// Task operations
-	case "tasks_list_tasks":
-		var args struct {
-			TaskListID    string `json:"tasklist_id"`
-			ShowCompleted bool   `json:"show_completed"`
-			ShowHidden    bool   `json:"show_hidden"`
-			MaxResults    int64  `json:"max_results"`
-			DueMin        string `json:"due_min"`
-			DueMax        string `json:"due_max"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleListTasks(ctx, args.TaskListID, args.ShowCompleted, args.ShowHidden, args.MaxResults, args.DueMin, args.DueMax)
-
-	case "tasks_get_task":
-		var args struct {
-			TaskListID string `json:"tasklist_id"`
-			TaskID     string `json:"task_id"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleGetTask(ctx, args.TaskListID, args.TaskID)
-
-	case "tasks_create_task":
-		var args struct {
-			TaskListID string `json:"tasklist_id"`
-			Title      string `json:"title"`
-			Notes      string `json:"notes"`
-			Due        string `json:"due"`
-			Parent     string `json:"parent"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleCreateTask(ctx, args.TaskListID, args.Title, args.Notes, args.Due, args.Parent)
-
-	case "tasks_update_task":
-		var args struct {
-			TaskListID string  `json:"tasklist_id"`
-			TaskID     string  `json:"task_id"`
-			Title      *string `json:"title,omitempty"`
-			Notes      *string `json:"notes,omitempty"`
-			Due        *string `json:"due,omitempty"`
-			Status     *string `json:"status,omitempty"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleUpdateTask(ctx, args.TaskListID, args.TaskID, args.Title, args.Notes, args.Due, args.Status)
-
-	case "tasks_delete_task":
-		var args struct {
-			TaskListID string `json:"tasklist_id"`
-			TaskID     string `json:"task_id"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleDeleteTask(ctx, args.TaskListID, args.TaskID)
-
-	case "tasks_complete_task":
-		var args struct {
-			TaskListID string `json:"tasklist_id"`
-			TaskID     string `json:"task_id"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleCompleteTask(ctx, args.TaskListID, args.TaskID)
-
-	case "tasks_move_task":
-		var args struct {
-			TaskListID string `json:"tasklist_id"`
-			TaskID     string `json:"task_id"`
-			Parent     string `json:"parent"`
-			Previous   string `json:"previous"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleMoveTask(ctx, args.TaskListID, args.TaskID, args.Parent, args.Previous)
-
-	case "tasks_clear_completed":
-		var args struct {
-			TaskListID string `json:"tasklist_id"`
-		}
-		if err := json.Unmarshal(arguments, &args); err != nil {
-			return nil, fmt.Errorf("invalid arguments: %w", err)
-		}
-		return h.handleClearCompleted(ctx, args.TaskListID)
+// --- Handler implementations ---
 
-	default:
-		return nil, fmt.Errorf("unknown tool: %s", name)
+func (h *MultiAccountHandler) handleListTaskLists(ctx context.Context, args accountArgs) (interface{}, error) {
+	client, err := h.getClientForAccount(ctx, args.Account)
+	if err != nil {
+		return nil, err
 	}
-}
 
-// --- Handler implementations ---
-
-func (h *Handler) handleListTaskLists(ctx context.Context) (interface{}, error) {
-	taskLists, err := h.client.ListTaskLists()
+	taskLists, err := client.ListTaskLists(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// Format response
 	result := make([]map[string]interface{}, len(taskLists))
 	for i, tl := range taskLists {
 		result[i] = map[string]interface{}{
@@ -472,14 +198,17 @@ func (h *Handler) handleListTaskLists(ctx context.Context) (interface{}, error)
 	return map[string]interface{}{
 		"tasklists": result,
 		"count":     len(result),
+		"account":   args.Account,
 	}, nil
 }
 
-func (h *Handler) handleGetTaskList(ctx context.Context, taskListID string) (interface{}, error) {
-	if err := validateID(taskListID, "tasklist_id"); err != nil {
+func (h *MultiAccountHandler) handleGetTaskList(ctx context.Context, args taskListArgs) (interface{}, error) {
+	client, err := h.getClientForAccount(ctx, args.Account)
+	if err != nil {
 		return nil, err
 	}
-	taskList, err := h.client.GetTaskList(taskListID)
+
+	taskList, err := client.GetTaskList(ctx, args.TaskListID)
 	if err != nil {
 		return nil, err
 	}
@@ -488,11 +217,17 @@ func (h *Handler) handleGetTaskList(ctx context.Context, taskListID string) (int
 		"id":      taskList.Id,
 		"title":   taskList.Title,
 		"updated": taskList.Updated,
+		"account": args.Account,
 	}, nil
 }
 
-func (h *Handler) handleCreateTaskList(ctx context.Context, title string) (interface{}, error) {
-	taskList, err := h.client.CreateTaskList(title)
+func (h *MultiAccountHandler) handleCreateTaskList(ctx context.Context, args createTaskListArgs) (interface{}, error) {
+	client, err := h.getClientForAccount(ctx, args.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	taskList, err := client.CreateTaskList(ctx, args.Title)
 	if err != nil {
 		return nil, err
 	}
@@ -500,15 +235,18 @@ func (h *Handler) handleCreateTaskList(ctx context.Context, title string) (inter
 	return map[string]interface{}{
 		"id":      taskList.Id,
 		"title":   taskList.Title,
-		"message": fmt.Sprintf("Task list '%s' created successfully", title),
+		"account": args.Account,
+		"message": fmt.Sprintf("Task list '%s' created successfully", args.Title),
 	}, nil
 }
 
-func (h *Handler) handleUpdateTaskList(ctx context.Context, taskListID, title string) (interface{}, error) {
-	if err := validateID(taskListID, "tasklist_id"); err != nil {
+func (h *MultiAccountHandler) handleUpdateTaskList(ctx context.Context, args updateTaskListArgs) (interface{}, error) {
+	client, err := h.getClientForAccount(ctx, args.Account)
+	if err != nil {
 		return nil, err
 	}
-	taskList, err := h.client.UpdateTaskList(taskListID, title)
+
+	taskList, err := client.UpdateTaskList(ctx, args.TaskListID, args.Title)
 	if err != nil {
 		return nil, err
 	}
@@ -516,58 +254,52 @@ func (h *Handler) handleUpdateTaskList(ctx context.Context, taskListID, title st
 	return map[string]interface{}{
 		"id":      taskList.Id,
 		"title":   taskList.Title,
+		"account": args.Account,
 		"message": "Task list updated successfully",
 	}, nil
 }
 
-func (h *Handler) handleDeleteTaskList(ctx context.Context, taskListID string) (interface{}, error) {
-	if err := validateID(taskListID, "tasklist_id"); err != nil {
+func (h *MultiAccountHandler) handleDeleteTaskList(ctx context.Context, args deleteTaskListArgs) (interface{}, error) {
+	client, err := h.getClientForAccount(ctx, args.Account)
+	if err != nil {
 		return nil, err
 	}
-	if err := h.client.DeleteTaskList(taskListID); err != nil {
+
+	if err := client.DeleteTaskList(ctx, args.TaskListID); err != nil {
 		return nil, err
 	}
 
 	return map[string]interface{}{
 		"status":  "deleted",
+		"account": args.Account,
 		"message": "Task list deleted successfully",
 	}, nil
 }
 
-func (h *Handler) resolveTaskListID(taskListID string) (string, error) {
-	if taskListID == "default" || taskListID == "" {
-		defaultList, err := h.client.GetDefaultTaskList()
-		if err != nil {
-			return "", err
-		}
-		return defaultList.Id, nil
-	}
-	if err := validateID(taskListID, "tasklist_id"); err != nil {
-		return "", err
+func (h *MultiAccountHandler) handleListTasks(ctx context.Context, args listTasksArgs) (interface{}, error) {
+	client, err := h.getClientForAccount(ctx, args.Account)
+	if err != nil {
+		return nil, err
 	}
-	return taskListID, nil
-}
 
-func (h *Handler) handleListTasks(ctx context.Context, taskListID string, showCompleted, showHidden bool, maxResults int64, dueMin, dueMax string) (interface{}, error) {
-	resolvedID, err := h.resolveTaskListID(taskListID)
+	resolvedID, err := h.resolveTaskListID(ctx, client, args.TaskListID)
 	if err != nil {
 		return nil, err
 	}
 
 	opts := &ListTasksOptions{
-		ShowCompleted: showCompleted,
-		ShowHidden:    showHidden,
-		MaxResults:    maxResults,
-		DueMin:        dueMin,
-		DueMax:        dueMax,
+		ShowCompleted: args.ShowCompleted,
+		ShowHidden:    args.ShowHidden,
+		MaxResults:    args.MaxResults,
+		DueMin:        args.DueMin,
+		DueMax:        args.DueMax,
 	}
 
-	tasks, err := h.client.ListTasks(resolvedID, opts)
+	tasks, err := client.ListTasks(ctx, resolvedID, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	// Format response
 	result := make([]map[string]interface{}, len(tasks))
 	for i, t := range tasks {
 		result[i] = formatTask(t)
@@ -577,146 +309,175 @@ func (h *Handler) handleListTasks(ctx context.Context, taskListID string, showCo
 		"tasks":       result,
 		"count":       len(result),
 		"tasklist_id": resolvedID,
+		"account":     args.Account,
 	}, nil
 }
 
-func (h *Handler) handleGetTask(ctx context.Context, taskListID, taskID string) (interface{}, error) {
-	if err := validateID(taskID, "task_id"); err != nil {
+func (h *MultiAccountHandler) handleGetTask(ctx context.Context, args taskArgs) (interface{}, error) {
+	client, err := h.getClientForAccount(ctx, args.Account)
+	if err != nil {
 		return nil, err
 	}
-	resolvedID, err := h.resolveTaskListID(taskListID)
+
+	resolvedID, err := h.resolveTaskListID(ctx, client, args.TaskListID)
 	if err != nil {
 		return nil, err
 	}
 
-	task, err := h.client.GetTask(resolvedID, taskID)
+	task, err := client.GetTask(ctx, resolvedID, args.TaskID)
 	if err != nil {
 		return nil, err
 	}
 
-	return formatTask(task), nil
+	result := formatTask(task)
+	result["account"] = args.Account
+	return result, nil
 }
 
-func (h *Handler) handleCreateTask(ctx context.Context, taskListID, title, notes, due, parent string) (interface{}, error) {
-	resolvedID, err := h.resolveTaskListID(taskListID)
+func (h *MultiAccountHandler) handleCreateTask(ctx context.Context, args createTaskArgs) (interface{}, error) {
+	client, err := h.getClientForAccount(ctx, args.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvedID, err := h.resolveTaskListID(ctx, client, args.TaskListID)
 	if err != nil {
 		return nil, err
 	}
 
 	opts := &CreateTaskOptions{
-		Title:  title,
-		Notes:  notes,
-		Due:    due,
-		Parent: parent,
+		Title:  args.Title,
+		Notes:  args.Notes,
+		Due:    args.Due,
+		Parent: args.Parent,
 	}
 
-	task, err := h.client.CreateTask(resolvedID, opts)
+	task, err := client.CreateTask(ctx, resolvedID, opts)
 	if err != nil {
 		return nil, err
 	}
 
 	result := formatTask(task)
-	result["message"] = fmt.Sprintf("Task '%s' created successfully", title)
+	result["account"] = args.Account
+	result["message"] = fmt.Sprintf("Task '%s' created successfully", args.Title)
 	return result, nil
 }
 
-func (h *Handler) handleUpdateTask(ctx context.Context, taskListID, taskID string, title, notes, due, status *string) (interface{}, error) {
-	if err := validateID(taskID, "task_id"); err != nil {
+func (h *MultiAccountHandler) handleUpdateTask(ctx context.Context, args updateTaskArgs) (interface{}, error) {
+	client, err := h.getClientForAccount(ctx, args.Account)
+	if err != nil {
 		return nil, err
 	}
-	resolvedID, err := h.resolveTaskListID(taskListID)
+
+	resolvedID, err := h.resolveTaskListID(ctx, client, args.TaskListID)
 	if err != nil {
 		return nil, err
 	}
 
 	opts := &UpdateTaskOptions{
-		Title:  title,
-		Notes:  notes,
-		Due:    due,
-		Status: status,
+		Title:  args.Title,
+		Notes:  args.Notes,
+		Due:    args.Due,
+		Status: args.Status,
 	}
 
-	task, err := h.client.UpdateTask(resolvedID, taskID, opts)
+	task, err := client.UpdateTask(ctx, resolvedID, args.TaskID, opts)
 	if err != nil {
 		return nil, err
 	}
 
 	result := formatTask(task)
+	result["account"] = args.Account
 	result["message"] = "Task updated successfully"
 	return result, nil
 }
 
-func (h *Handler) handleDeleteTask(ctx context.Context, taskListID, taskID string) (interface{}, error) {
-	if err := validateID(taskID, "task_id"); err != nil {
+func (h *MultiAccountHandler) handleDeleteTask(ctx context.Context, args taskArgs) (interface{}, error) {
+	client, err := h.getClientForAccount(ctx, args.Account)
+	if err != nil {
 		return nil, err
 	}
-	resolvedID, err := h.resolveTaskListID(taskListID)
+
+	resolvedID, err := h.resolveTaskListID(ctx, client, args.TaskListID)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := h.client.DeleteTask(resolvedID, taskID); err != nil {
+	if err := client.DeleteTask(ctx, resolvedID, args.TaskID); err != nil {
 		return nil, err
 	}
 
 	return map[string]interface{}{
 		"status":  "deleted",
-		"task_id": taskID,
+		"task_id": args.TaskID,
+		"account": args.Account,
 		"message": "Task deleted successfully",
 	}, nil
 }
 
-func (h *Handler) handleCompleteTask(ctx context.Context, taskListID, taskID string) (interface{}, error) {
-	if err := validateID(taskID, "task_id"); err != nil {
+func (h *MultiAccountHandler) handleCompleteTask(ctx context.Context, args taskArgs) (interface{}, error) {
+	client, err := h.getClientForAccount(ctx, args.Account)
+	if err != nil {
 		return nil, err
 	}
-	resolvedID, err := h.resolveTaskListID(taskListID)
+
+	resolvedID, err := h.resolveTaskListID(ctx, client, args.TaskListID)
 	if err != nil {
 		return nil, err
 	}
 
-	task, err := h.client.CompleteTask(resolvedID, taskID)
+	task, err := client.CompleteTask(ctx, resolvedID, args.TaskID)
 	if err != nil {
 		return nil, err
 	}
 
 	result := formatTask(task)
+	result["account"] = args.Account
 	result["message"] = "Task marked as completed"
 	return result, nil
 }
 
-func (h *Handler) handleMoveTask(ctx context.Context, taskListID, taskID, parent, previous string) (interface{}, error) {
-	if err := validateID(taskID, "task_id"); err != nil {
+func (h *MultiAccountHandler) handleMoveTask(ctx context.Context, args moveTaskArgs) (interface{}, error) {
+	client, err := h.getClientForAccount(ctx, args.Account)
+	if err != nil {
 		return nil, err
 	}
-	resolvedID, err := h.resolveTaskListID(taskListID)
+
+	resolvedID, err := h.resolveTaskListID(ctx, client, args.TaskListID)
 	if err != nil {
 		return nil, err
 	}
 
-	task, err := h.client.MoveTask(resolvedID, taskID, parent, previous)
+	task, err := client.MoveTask(ctx, resolvedID, args.TaskID, args.Parent, args.Previous)
 	if err != nil {
 		return nil, err
 	}
 
 	result := formatTask(task)
+	result["account"] = args.Account
 	result["message"] = "Task moved successfully"
 	return result, nil
 }
 
-func (h *Handler) handleClearCompleted(ctx context.Context, taskListID string) (interface{}, error) {
-	resolvedID, err := h.resolveTaskListID(taskListID)
+func (h *MultiAccountHandler) handleClearCompleted(ctx context.Context, args clearCompletedArgs) (interface{}, error) {
+	client, err := h.getClientForAccount(ctx, args.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvedID, err := h.resolveTaskListID(ctx, client, args.TaskListID)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := h.client.ClearCompleted(resolvedID); err != nil {
+	if err := client.ClearCompleted(ctx, resolvedID); err != nil {
 		return nil, err
 	}
 
 	return map[string]interface{}{
 		"status":      "cleared",
 		"tasklist_id": resolvedID,
+		"account":     args.Account,
 		"message":     "All completed tasks cleared successfully",
 	}, nil
 }
@@ -727,11 +488,11 @@ func formatTask(t interface{}) map[string]interface{} {
 	data := make(map[string]interface{})
 	jsonData, err := json.Marshal(t)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to marshal task data: %v\n", err)
+		logger.Warn("failed to marshal task data", "error", err)
 		return data
 	}
 	if err := json.Unmarshal(jsonData, &data); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to unmarshal task data: %v\n", err)
+		logger.Warn("failed to unmarshal task data", "error", err)
 		return data
 	}
 